@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
 	"time"
@@ -12,172 +11,329 @@ import (
 	"github.com/qct/bitmex-go/swagger"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
-	"golang.org/x/net/websocket"
 )
 
 type Bitmex struct {
-	swaggerOrderApi *swagger.OrderApiService
-	ctx             context.Context
+	swaggerOrderApi     *swagger.OrderApiService
+	swaggerPositionApi  *swagger.PositionApiService
+	swaggerUserApi      *swagger.UserApiService
+	swaggerExecutionApi *swagger.ExecutionApiService
+	ctx                 context.Context
 }
 
+// XBt is the currency bitmex denominates margin in, satoshis
+const XBt = "XBt"
+
+// ExecTypeFunding is the type of the executions bitmex records funding
+// payments as
+const ExecTypeFunding = "Funding"
+
 var log = logrus.New()
 
-// Create a new Bitmex api that can market buy/sell and limit buy/sell
-func New(apiKey, secretKey string) *Bitmex {
+// Create a new Bitmex api that can market buy/sell and limit buy/sell, and
+// query positions and margin, sending requests to the REST api at basePath
+func New(apiKey, secretKey, basePath string) *Bitmex {
+
+	// error responses are turned into an *APIError by our transport, which
+	// also holds requests while our rate limit is used up
+	config := swagger.NewConfiguration()
+	config.HTTPClient = &http.Client{
+		Transport: &transport{next: http.DefaultTransport},
+	}
 
-	apiClient := swagger.NewAPIClient(swagger.NewConfiguration())
+	apiClient := swagger.NewAPIClient(config)
 	auth := context.WithValue(context.TODO(), swagger.ContextAPIKey, swagger.APIKey{
 		Key:    apiKey,
 		Secret: secretKey,
 	})
 
-	apiClient.ChangeBasePath("https://testnet.bitmex.com/api/v1")
+	apiClient.ChangeBasePath(basePath)
 
-	return &Bitmex{swaggerOrderApi: apiClient.OrderApi, ctx: auth}
+	return &Bitmex{
+		swaggerOrderApi:     apiClient.OrderApi,
+		swaggerPositionApi:  apiClient.PositionApi,
+		swaggerUserApi:      apiClient.UserApi,
+		swaggerExecutionApi: apiClient.ExecutionApi,
+		ctx:                 auth,
+	}
 }
 
-func (o *Bitmex) MarketBuy(orderQty float64) (resp *http.Response, orderId string, err error) {
+// maxOrderQty is the largest quantity the swagger client can send exactly,
+// as it sends quantities as float32
+const maxOrderQty = 1 << 24
 
-	params := map[string]interface{}{
-		"symbol":   "XBTUSD",
-		"ordType":  "Market",
-		"orderQty": float32(orderQty),
+// wholeContracts converts a quantity to what the swagger client sends.
+// Bitmex only trades whole contracts, so fractional quantities are refused
+// rather than rounded by bitmex
+func wholeContracts(qty float64) (float32, error) {
+	if qty != math.Trunc(qty) {
+		return 0, fmt.Errorf("%v is not a whole number of contracts: %w", qty, ErrInvalidQty)
 	}
-	order, response, err := o.swaggerOrderApi.OrderNew(o.ctx, "XBTUSD", params)
-	if err != nil || response.StatusCode != 200 {
-		return response, order.OrderID, err
+	if math.Abs(qty) > maxOrderQty {
+		return 0, fmt.Errorf("%v is more than %d contracts: %w", qty, maxOrderQty, ErrInvalidQty)
 	}
-	return response, order.OrderID, nil
+
+	return float32(qty), nil
 }
 
-func (o *Bitmex) MarketSell(orderQty float64) (resp *http.Response, orderId string, err error) {
+// MarketOrder places a market order tagged with our own client order id,
+// buying for positive quantities and selling for negative ones. Bitmex
+// rejects a second order with the same client order id, so an order that
+// may or may not have been placed can be looked up with OrderByClientID
+// before it is retried
+func (o *Bitmex) MarketOrder(clOrdID, symbol string, qty float64) (swagger.Order, error) {
+	sendQty, err := wholeContracts(qty)
+	if err != nil {
+		return swagger.Order{}, err
+	}
 
 	params := map[string]interface{}{
-		"symbol":   "XBTUSD",
+		"symbol":   symbol,
 		"ordType":  "Market",
-		"orderQty": float32(-orderQty),
+		"orderQty": sendQty,
+		"clOrdID":  clOrdID,
 	}
-	order, response, err := o.swaggerOrderApi.OrderNew(o.ctx, "XBTUSD", params)
-	if err != nil || response.StatusCode != 200 {
-		return response, order.OrderID, err
+	order, response, err := o.swaggerOrderApi.OrderNew(o.ctx, symbol, params)
+	if err != nil {
+		return swagger.Order{}, fmt.Errorf("could not place order: %w", err)
 	}
-	return response, order.OrderID, nil
+	if response.StatusCode != http.StatusOK {
+		return swagger.Order{}, fmt.Errorf("could not place order: %s", response.Status)
+	}
+
+	return order, nil
 }
 
-func (o *Bitmex) LimitBuy(orderQty float64, price float64) (resp *http.Response, orderId string, err error) {
-	if price <= 0 {
-		return nil, "", errors.New("price must be positive")
+// OrderByClientID returns our order in the instrument with the given client
+// order id, and false if there is none
+func (o *Bitmex) OrderByClientID(symbol, clOrdID string) (swagger.Order, bool, error) {
+	filter, err := json.Marshal(map[string]string{"clOrdID": clOrdID})
+	if err != nil {
+		return swagger.Order{}, false, err
 	}
 
-	params := map[string]interface{}{
-		"symbol":   "XBTUSD",
-		"ordType":  "Limit",
-		"orderQty": float32(orderQty),
-		"price":    price,
+	orders, response, err := o.swaggerOrderApi.OrderGetOrders(o.ctx, map[string]interface{}{
+		"symbol": symbol,
+		"filter": string(filter),
+	})
+	if err != nil {
+		return swagger.Order{}, false, fmt.Errorf("could not get orders: %w", err)
 	}
-	order, response, err := o.swaggerOrderApi.OrderNew(o.ctx, "XBTUSD", params)
-	if err != nil || response.StatusCode != 200 {
-		return response, order.OrderID, err
+	if response.StatusCode != http.StatusOK {
+		return swagger.Order{}, false, fmt.Errorf("could not get orders: %s", response.Status)
 	}
-	return response, order.OrderID, nil
-}
 
-func (o *Bitmex) LimitSell(orderQty float64, price float64) (resp *http.Response, orderId string, err error) {
+	for _, order := range orders {
+		if order.ClOrdID == clOrdID {
+			return order, true, nil
+		}
+	}
 
-	if price <= 0 {
-		return nil, "", errors.New("price must be positive")
+	return swagger.Order{}, false, nil
+}
+
+// Position returns our position in the instrument. If we have never traded
+// it, an empty position is returned
+func (o *Bitmex) Position(symbol string) (swagger.Position, error) {
+	filter, err := json.Marshal(map[string]string{"symbol": symbol})
+	if err != nil {
+		return swagger.Position{}, err
 	}
 
-	params := map[string]interface{}{
-		"symbol":   "XBTUSD",
-		"ordType":  "Limit",
-		"orderQty": float32(-orderQty),
-		"price":    price,
+	positions, response, err := o.swaggerPositionApi.PositionGet(o.ctx, map[string]interface{}{
+		"filter": string(filter),
+	})
+	if err != nil {
+		return swagger.Position{}, fmt.Errorf("could not get position: %w", err)
 	}
-	order, response, err := o.swaggerOrderApi.OrderNew(o.ctx, "XBTUSD", params)
-	if err != nil || response.StatusCode != 200 {
-		return response, order.OrderID, err
+	if response.StatusCode != http.StatusOK {
+		return swagger.Position{}, fmt.Errorf("could not get position: %s", response.Status)
 	}
-	return response, order.OrderID, nil
+
+	for _, position := range positions {
+		if position.Symbol == symbol {
+			return position, nil
+		}
+	}
+
+	return swagger.Position{Symbol: symbol}, nil
 }
 
-// ListenToPrice opens a websocket to bitmex, and extracts
-// latest price updates
+// executionsPageSize is how many executions we ask bitmex for at a time,
+// the most it returns per request
+const executionsPageSize = 500
 
-// NOTE: MUST be run in a goroutine
-func ListenToPrice(getPrice func(asset string) float64,
-	setPrice func(asset string, price float64) error) error {
+// FundingExecutions returns the funding payments on our position in the
+// instrument since the given time, oldest first. The funding rate is the
+// commission of each execution, and ExecComm is what we paid in satoshis,
+// negative if we received funding
+func (o *Bitmex) FundingExecutions(symbol string, since time.Time) ([]swagger.Execution, error) {
+	return o.executions(symbol, ExecTypeFunding, since)
+}
 
-	// we subscribe to XBTUSD instrument updates. This includes several different updates
-	// but we only care about priceUpdates
-	const wsURL = "wss://www.bitmex.com/realtime?subscribe=instrument:XBTUSD"
-	conn, err := websocket.Dial(wsURL, "", "http://localhost/")
-	if err != nil {
-		log.Fatalf("could not dial bitmex: %w", err)
-	}
+// TradeExecutions returns the executions filling our orders in all
+// instruments since the given time, oldest first
+func (o *Bitmex) TradeExecutions(since time.Time) ([]swagger.Execution, error) {
+	return o.executions("", ExecTypeTrade, since)
+}
 
-	type price struct {
-		Symbol            string    `json:"symbol"`
-		LastPrice         float64   `json:"lastPrice"`
-		LastTickDirection string    `json:"lastTickDirection"`
-		LastChangePcnt    float64   `json:"lastChangePcnt"`
-		Timestamp         time.Time `json:"timestamp"`
+// executions returns our executions of the given type since the given
+// time, oldest first. An empty symbol returns executions in all
+// instruments. The swagger client does not format startTime the way bitmex
+// expects, so we page backwards from the latest execution until we pass
+// the time ourselves
+func (o *Bitmex) executions(symbol, execType string, since time.Time) ([]swagger.Execution, error) {
+	filter, err := json.Marshal(map[string]string{"execType": execType})
+	if err != nil {
+		return nil, err
 	}
 
-	type priceUpdate struct {
-		Table  string  `json:"table"`
-		Action string  `json:"action"`
-		Data   []price `json:"data"`
-	}
+	// newest first, as we get them
+	var executions []swagger.Execution
+	for start := 0; ; start += executionsPageSize {
+		params := map[string]interface{}{
+			"filter":  string(filter),
+			"count":   float32(executionsPageSize),
+			"start":   float32(start),
+			"reverse": true,
+		}
+		if symbol != "" {
+			params["symbol"] = symbol
+		}
 
-	for {
-		var msg string
-		err = websocket.Message.Receive(conn, &msg)
+		page, response, err := o.swaggerExecutionApi.ExecutionGetTradeHistory(o.ctx, params)
 		if err != nil {
-			log.WithError(err).Error("could not receive from bitmex websocket")
+			return nil, fmt.Errorf("could not get executions: %w", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("could not get executions: %s", response.Status)
 		}
 
-		// TODO: Try to open new connection
-		if err == io.EOF {
-			// start new socket..
-			fmt.Println("received EOF from websocket")
-			go func() {
-				_ = ListenToPrice(getPrice, setPrice)
-			}()
+		done := len(page) < executionsPageSize
+		for _, execution := range page {
+			if execution.TransactTime.Before(since) {
+				done = true
+				break
+			}
+			executions = append(executions, execution)
+		}
+		if done {
 			break
 		}
+	}
 
-		var lastPrice priceUpdate
+	for i, j := 0, len(executions)-1; i < j; i, j = i+1, j-1 {
+		executions[i], executions[j] = executions[j], executions[i]
+	}
 
-		err := json.Unmarshal([]byte(msg), &lastPrice)
-		if err != nil {
-			log.WithError(err).WithField("msg", msg).Error("could not unmarshal message")
-		}
-		// the priceUpdate response from bitmex is not unique and many responses unmarshal successfully
-		// we only care about the ones where the Data array has data
-		if len(lastPrice.Data) == 0 {
-			continue
-		}
-		if lastPrice.Data[0].LastPrice == 0 {
-			continue
-		}
+	return executions, nil
+}
+
+// Margin returns the margin status of our account, denominated in satoshis
+func (o *Bitmex) Margin() (swagger.Margin, error) {
+	margin, response, err := o.swaggerUserApi.UserGetMargin(o.ctx, map[string]interface{}{
+		"currency": XBt,
+	})
+	if err != nil {
+		return swagger.Margin{}, fmt.Errorf("could not get margin: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return swagger.Margin{}, fmt.Errorf("could not get margin: %s", response.Status)
+	}
 
-		data := lastPrice.Data[0]
+	return margin, nil
+}
 
-		log.WithFields(logrus.Fields{
-			"symbol":            data.Symbol,
-			"lastPrice":         data.LastPrice,
-			"lastTickDirection": data.LastTickDirection,
-			"lastChangePercent": data.LastChangePcnt,
-		}).Trace("new price")
+// WalletBalance returns the deposits and realised profit of our account, in
+// satoshis
+func (o *Bitmex) WalletBalance() (int64, error) {
+	margin, err := o.Margin()
+	if err != nil {
+		return 0, err
+	}
 
-		if math.Abs(data.LastPrice-getPrice("USD")) > 1 {
-			// update the price
-			err = setPrice("USD", data.LastPrice)
-			if err != nil {
-				log.WithError(err).Error("could not set new bitmex price")
-			}
-		}
+	return int64(margin.WalletBalance), nil
+}
+
+// AvailableMargin returns the satoshis of our account left to open new
+// positions with
+func (o *Bitmex) AvailableMargin() (int64, error) {
+	margin, err := o.Margin()
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(margin.AvailableMargin), nil
+}
+
+// MarketBuy market buys orderQty contracts of the instrument
+func (o *Bitmex) MarketBuy(symbol string, orderQty float64) (resp *http.Response, orderId string, err error) {
+	return o.placeOrder(symbol, "Market", orderQty, 0)
+}
+
+// MarketSell market sells orderQty contracts of the instrument
+func (o *Bitmex) MarketSell(symbol string, orderQty float64) (resp *http.Response, orderId string, err error) {
+	return o.placeOrder(symbol, "Market", -orderQty, 0)
+}
+
+// LimitBuy places a limit order buying orderQty contracts of the instrument
+func (o *Bitmex) LimitBuy(symbol string, orderQty float64, price float64) (resp *http.Response, orderId string, err error) {
+	if price <= 0 {
+		return nil, "", errors.New("price must be positive")
+	}
+
+	return o.placeOrder(symbol, "Limit", orderQty, price)
+}
+
+// LimitSell places a limit order selling orderQty contracts of the
+// instrument
+func (o *Bitmex) LimitSell(symbol string, orderQty float64, price float64) (resp *http.Response, orderId string, err error) {
+	if price <= 0 {
+		return nil, "", errors.New("price must be positive")
+	}
+
+	return o.placeOrder(symbol, "Limit", -orderQty, price)
+}
+
+// placeOrder places an order buying for positive quantities and selling
+// for negative ones. The price is only sent for limit orders. The order id
+// is only returned if the order was placed
+func (o *Bitmex) placeOrder(symbol, ordType string, qty, price float64) (*http.Response, string, error) {
+	sendQty, err := wholeContracts(qty)
+	if err != nil {
+		return nil, "", err
+	}
+
+	params := map[string]interface{}{
+		"symbol":   symbol,
+		"ordType":  ordType,
+		"orderQty": sendQty,
+	}
+	if ordType == "Limit" {
+		params["price"] = price
+	}
+
+	order, response, err := o.swaggerOrderApi.OrderNew(o.ctx, symbol, params)
+	if err != nil {
+		return response, "", fmt.Errorf("could not place order: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return response, "", fmt.Errorf("could not place order: %s", response.Status)
+	}
+
+	return response, order.OrderID, nil
+}
+
+// CancelAll cancels all our open orders in the instrument
+func (o *Bitmex) CancelAll(symbol string) error {
+	_, response, err := o.swaggerOrderApi.OrderCancelAll(o.ctx, map[string]interface{}{
+		"symbol": symbol,
+	})
+	if err != nil {
+		return fmt.Errorf("could not cancel orders: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not cancel orders: %s", response.Status)
 	}
 
 	return nil
@@ -0,0 +1,47 @@
+package bitmex
+
+import (
+	"fmt"
+
+	"github.com/qct/bitmex-go/swagger"
+)
+
+// Endpoints are the urls of the bitmex REST and realtime apis
+type Endpoints struct {
+	REST     string
+	Realtime string
+}
+
+var (
+	MainnetEndpoints = Endpoints{
+		REST:     swagger.BASE_URL,
+		Realtime: "wss://www.bitmex.com/realtime",
+	}
+
+	TestnetEndpoints = Endpoints{
+		REST:     swagger.TESTNET_BASE_URL,
+		Realtime: "wss://testnet.bitmex.com/realtime",
+	}
+
+	// LocalEndpoints point to a mock exchange running on this machine,
+	// started with `mockexchange`
+	LocalEndpoints = Endpoints{
+		REST:     "http://localhost:8090/api/v1",
+		Realtime: "ws://localhost:8090/realtime",
+	}
+)
+
+// EndpointsForNetwork returns the endpoints that match the given bitcoin
+// network, so we trade and price contracts on the same venue
+func EndpointsForNetwork(network string) (Endpoints, error) {
+	switch network {
+	case "mainnet":
+		return MainnetEndpoints, nil
+	case "testnet":
+		return TestnetEndpoints, nil
+	case "regtest", "simnet":
+		return LocalEndpoints, nil
+	}
+
+	return Endpoints{}, fmt.Errorf("unknown network %q", network)
+}
@@ -0,0 +1,36 @@
+package bitmex
+
+import "testing"
+
+func TestEndpointsForNetwork(t *testing.T) {
+	tests := []struct {
+		network string
+		want    Endpoints
+		invalid bool
+	}{
+		{network: "mainnet", want: MainnetEndpoints},
+		{network: "testnet", want: TestnetEndpoints},
+		{network: "regtest", want: LocalEndpoints},
+		{network: "simnet", want: LocalEndpoints},
+		{network: "signet", invalid: true},
+		{network: "", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.network, func(t *testing.T) {
+			endpoints, err := EndpointsForNetwork(test.network)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("got endpoints %v for unknown network", endpoints)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoints != test.want {
+				t.Fatalf("endpoints = %v, want %v", endpoints, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package bitmex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by bitmex are wrapped in an *APIError, which can be
+// compared to these with errors.Is
+var (
+	ErrRateLimited       = errors.New("rate limited")
+	ErrOverloaded        = errors.New("system overloaded")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidQty        = errors.New("invalid order quantity")
+	ErrAuth              = errors.New("authentication failed")
+)
+
+// overloadedRetryAfter is how long to wait after bitmex says it is
+// overloaded, as it does not tell us
+const overloadedRetryAfter = 500 * time.Millisecond
+
+// APIError is an error response from bitmex
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Message is the error message bitmex sent
+	Message string
+
+	kind       error
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bitmex error %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns which of our errors the response was, if any
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// RetryAfter returns how long bitmex asked us to wait before sending
+// another request, zero if it did not
+func (e *APIError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// newAPIError classifies an error response from bitmex
+func newAPIError(res *http.Response, body []byte) *APIError {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+			Name    string `json:"name"`
+		} `json:"error"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &payload) == nil && payload.Error.Message != "" {
+		message = payload.Error.Message
+	}
+	if message == "" {
+		message = res.Status
+	}
+
+	apiErr := &APIError{StatusCode: res.StatusCode, Message: message}
+
+	lower := strings.ToLower(message)
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		apiErr.kind = ErrRateLimited
+		apiErr.retryAfter = retryAfterHeader(res.Header)
+
+	case res.StatusCode == http.StatusServiceUnavailable:
+		apiErr.kind = ErrOverloaded
+		apiErr.retryAfter = overloadedRetryAfter
+
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		apiErr.kind = ErrAuth
+
+	case strings.Contains(lower, "insufficient"):
+		apiErr.kind = ErrInsufficientFunds
+
+	case strings.Contains(lower, "orderqty") || strings.Contains(lower, "order quantity"):
+		apiErr.kind = ErrInvalidQty
+	}
+
+	return apiErr
+}
+
+// retryAfterHeader returns how long the Retry-After header tells us to
+// wait, falling back to when our rate limit resets
+func retryAfterHeader(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if reset, ok := rateLimitReset(header); ok {
+		return time.Until(reset)
+	}
+
+	return 0
+}
+
+// rateLimitReset returns when our rate limit resets, from the
+// x-ratelimit-reset header
+func rateLimitReset(header http.Header) (time.Time, bool) {
+	reset, err := strconv.ParseInt(header.Get("X-Ratelimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(reset, 0), true
+}
+
+// transport turns error responses into an *APIError, since the swagger
+// client only reports their status. It also keeps track of our rate limit,
+// and refuses to send requests once it is used up until it resets, so we
+// are not banned for ignoring it
+type transport struct {
+	next http.RoundTripper
+
+	mu sync.Mutex
+	// limitedUntil is when we can send requests again, after using up our
+	// rate limit or being told to back off
+	limitedUntil time.Time
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	wait := time.Until(t.limitedUntil)
+	t.mu.Unlock()
+	if wait > 0 {
+		return nil, &APIError{
+			StatusCode: http.StatusTooManyRequests,
+			Message:    "rate limit used up, not sending request",
+			kind:       ErrRateLimited,
+			retryAfter: wait,
+		}
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.trackRateLimit(res.Header)
+
+	if res.StatusCode < http.StatusMultipleChoices {
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s response: %w", res.Status, err)
+	}
+
+	apiErr := newAPIError(res, body)
+	if apiErr.retryAfter > 0 {
+		t.backOff(apiErr.retryAfter)
+	}
+
+	return nil, apiErr
+}
+
+// trackRateLimit stops us from sending requests until our rate limit
+// resets, if the response used up the last of it
+func (t *transport) trackRateLimit(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-Ratelimit-Remaining"))
+	if err != nil || remaining > 0 {
+		return
+	}
+
+	reset, ok := rateLimitReset(header)
+	if !ok {
+		return
+	}
+
+	log.WithField("reset", reset).Warn("bitmex rate limit used up, holding requests until it resets")
+	t.backOff(time.Until(reset))
+}
+
+func (t *transport) backOff(wait time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until := time.Now().Add(wait)
+	if until.After(t.limitedUntil) {
+		t.limitedUntil = until
+	}
+}
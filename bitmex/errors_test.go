@@ -0,0 +1,211 @@
+package bitmex
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  http.Header
+		body    string
+		kind    error
+		message string
+		// retryAfter is the least we should be told to wait
+		retryAfter time.Duration
+	}{
+		{
+			name:       "rate limited with retry after",
+			status:     http.StatusTooManyRequests,
+			header:     http.Header{"Retry-After": []string{"3"}},
+			body:       `{"error": {"message": "Rate limit exceeded", "name": "RateLimitError"}}`,
+			kind:       ErrRateLimited,
+			message:    "Rate limit exceeded",
+			retryAfter: 3 * time.Second,
+		},
+		{
+			name:   "rate limited until reset",
+			status: http.StatusTooManyRequests,
+			header: http.Header{"X-Ratelimit-Reset": []string{
+				strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)}},
+			kind:       ErrRateLimited,
+			message:    "429 Too Many Requests",
+			retryAfter: 58 * time.Second,
+		},
+		{
+			name:       "overloaded",
+			status:     http.StatusServiceUnavailable,
+			body:       `{"error": {"message": "The system is currently overloaded. Please try again later."}}`,
+			kind:       ErrOverloaded,
+			message:    "The system is currently overloaded. Please try again later.",
+			retryAfter: overloadedRetryAfter,
+		},
+		{
+			name:    "unauthorized",
+			status:  http.StatusUnauthorized,
+			body:    `{"error": {"message": "Invalid API Key."}}`,
+			kind:    ErrAuth,
+			message: "Invalid API Key.",
+		},
+		{
+			name:    "forbidden",
+			status:  http.StatusForbidden,
+			body:    "forbidden",
+			kind:    ErrAuth,
+			message: "forbidden",
+		},
+		{
+			name:    "insufficient funds",
+			status:  http.StatusBadRequest,
+			body:    `{"error": {"message": "Account has insufficient Available Balance, 100 XBt required"}}`,
+			kind:    ErrInsufficientFunds,
+			message: "Account has insufficient Available Balance, 100 XBt required",
+		},
+		{
+			name:    "invalid quantity",
+			status:  http.StatusBadRequest,
+			body:    `{"error": {"message": "Invalid orderQty"}}`,
+			kind:    ErrInvalidQty,
+			message: "Invalid orderQty",
+		},
+		{
+			name:    "unknown error",
+			status:  http.StatusBadRequest,
+			body:    `{"error": {"message": "Invalid ordType"}}`,
+			message: "Invalid ordType",
+		},
+	}
+
+	kinds := []error{ErrRateLimited, ErrOverloaded, ErrInsufficientFunds, ErrInvalidQty, ErrAuth}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := &http.Response{
+				StatusCode: test.status,
+				Status:     strconv.Itoa(test.status) + " " + http.StatusText(test.status),
+				Header:     test.header,
+			}
+			apiErr := newAPIError(res, []byte(test.body))
+
+			for _, kind := range kinds {
+				want := kind == test.kind
+				if errors.Is(apiErr, kind) != want {
+					t.Fatalf("errors.Is(%v, %v) = %v, want %v", apiErr, kind, !want, want)
+				}
+			}
+			if apiErr.Message != test.message {
+				t.Fatalf("message = %q, want %q", apiErr.Message, test.message)
+			}
+			if apiErr.StatusCode != test.status {
+				t.Fatalf("status = %d, want %d", apiErr.StatusCode, test.status)
+			}
+			if apiErr.RetryAfter() < test.retryAfter || (test.retryAfter == 0 && apiErr.RetryAfter() != 0) {
+				t.Fatalf("retry after = %s, want %s", apiErr.RetryAfter(), test.retryAfter)
+			}
+		})
+	}
+}
+
+// staticTransport answers every request with the same response
+type staticTransport struct {
+	status int
+	header http.Header
+	body   string
+	// requests is how many requests were sent
+	requests int
+}
+
+func (s *staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests++
+
+	header := s.header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		StatusCode: s.status,
+		Status:     http.StatusText(s.status),
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(s.body)),
+	}, nil
+}
+
+func TestTransport(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		// fail is whether the first request fails
+		fail bool
+		// held is whether the next request is refused without being sent
+		held bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{
+			name:   "ok with rate limit left",
+			status: http.StatusOK,
+			header: http.Header{"X-Ratelimit-Remaining": []string{"10"}, "X-Ratelimit-Reset": []string{reset}},
+		},
+		{
+			name:   "ok using up rate limit",
+			status: http.StatusOK,
+			header: http.Header{"X-Ratelimit-Remaining": []string{"0"}, "X-Ratelimit-Reset": []string{reset}},
+			held:   true,
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": []string{"30"}},
+			fail:   true,
+			held:   true,
+		},
+		{name: "overloaded", status: http.StatusServiceUnavailable, fail: true, held: true},
+		{name: "bad request", status: http.StatusBadRequest, fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := &staticTransport{status: test.status, header: test.header, body: "{}"}
+			tr := &transport{next: next}
+
+			req, err := http.NewRequest(http.MethodGet, "http://bitmex.test/api/v1/order", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := tr.RoundTrip(req)
+			if test.fail {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("error = %v, want an APIError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else {
+				res.Body.Close()
+			}
+
+			_, err = tr.RoundTrip(req)
+			if test.held {
+				if !errors.Is(err, ErrRateLimited) {
+					t.Fatalf("error = %v, want %v", err, ErrRateLimited)
+				}
+				if next.requests != 1 {
+					t.Fatalf("sent %d requests while backing off, want 1", next.requests)
+				}
+			} else if next.requests != 2 {
+				t.Fatalf("sent %d requests, want 2", next.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package bitmex
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/qct/bitmex-go/swagger"
+	"github.com/sirupsen/logrus"
+)
+
+// ExecTypeTrade is the type of the executions filling our orders
+const ExecTypeTrade = "Trade"
+
+// statuses of orders that will not change anymore
+const (
+	OrdStatusFilled   = "Filled"
+	OrdStatusCanceled = "Canceled"
+	OrdStatusRejected = "Rejected"
+)
+
+// the topics of our account we subscribe to
+var accountTopics = []string{"execution", "order", "position"}
+
+// ExecutionFeed follows what happens to the orders and positions of our
+// account, through the authenticated topics of the bitmex realtime
+// websocket. Fills, order updates and position updates are passed to the
+// functions set with OnFill, OnOrder and OnPosition.
+type ExecutionFeed struct {
+	url       string
+	apiKey    string
+	secretKey string
+
+	onFill        func(execution swagger.Execution)
+	onOrder       func(order swagger.Order)
+	onPosition    func(position swagger.Position)
+	onReconnect   func(lostAt time.Time)
+	onStateChange func(state ConnectionState, err error)
+
+	mu       sync.Mutex
+	realtime *Realtime
+	// lostAt is when we lost our connection to bitmex, zero while we are
+	// connected or have never been
+	lostAt    time.Time
+	connected bool
+	// orders are our open orders, and positions our positions. Bitmex only
+	// sends the fields that changed in updates, so we apply them to the
+	// full rows we have
+	orders    map[string]swagger.Order
+	positions map[string]swagger.Position
+}
+
+// NewExecutionFeed creates a new ExecutionFeed that connects to the
+// realtime api at url, authenticated with the api key. It does not receive
+// anything before Listen is called
+func NewExecutionFeed(url, apiKey, secretKey string) *ExecutionFeed {
+	return &ExecutionFeed{
+		url:       url,
+		apiKey:    apiKey,
+		secretKey: secretKey,
+		orders:    make(map[string]swagger.Order),
+		positions: make(map[string]swagger.Position),
+
+		onFill:        func(swagger.Execution) {},
+		onOrder:       func(swagger.Order) {},
+		onPosition:    func(swagger.Position) {},
+		onReconnect:   func(time.Time) {},
+		onStateChange: func(ConnectionState, error) {},
+	}
+}
+
+// Name returns the name of the feed
+func (f *ExecutionFeed) Name() string {
+	return sourceName + " executions"
+}
+
+// OnFill sets a function that is called every time one of our orders is
+// filled, in full or in part. Executions bitmex sent before are sent again
+// when we reconnect, so they must be told apart by their ExecID. MUST be
+// called before Listen.
+func (f *ExecutionFeed) OnFill(fn func(execution swagger.Execution)) {
+	f.onFill = fn
+}
+
+// OnOrder sets a function that is called every time one of our orders
+// changes, with the full order. MUST be called before Listen.
+func (f *ExecutionFeed) OnOrder(fn func(order swagger.Order)) {
+	f.onOrder = fn
+}
+
+// OnPosition sets a function that is called every time one of our
+// positions changes, with the full position. MUST be called before Listen.
+func (f *ExecutionFeed) OnPosition(fn func(position swagger.Position)) {
+	f.onPosition = fn
+}
+
+// OnReconnect sets a function that is called every time we reconnect after
+// losing our connection, with when it was lost. Bitmex only resends the
+// latest executions when we reconnect, so anything older we missed while
+// disconnected must be fetched from the REST api. MUST be called before
+// Listen.
+func (f *ExecutionFeed) OnReconnect(fn func(lostAt time.Time)) {
+	f.onReconnect = fn
+}
+
+// OnStateChange sets a function that is called every time the connection
+// to bitmex changes state. MUST be called before Listen.
+func (f *ExecutionFeed) OnStateChange(fn func(state ConnectionState, err error)) {
+	f.onStateChange = fn
+}
+
+// Listen connects to bitmex and passes on what happens to our orders and
+// positions. Lost connections are reopened, so Listen only returns if Close
+// is called.
+// NOTE: MUST be run in a goroutine
+func (f *ExecutionFeed) Listen() error {
+	f.mu.Lock()
+	f.realtime = NewRealtime(f.url, accountTopics, f.handleMessage)
+	f.realtime.Authenticate(f.apiKey, f.secretKey)
+	f.realtime.OnStateChange(f.handleStateChange)
+	f.mu.Unlock()
+
+	return f.realtime.Run()
+}
+
+// Close closes the connection to bitmex
+func (f *ExecutionFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.realtime != nil {
+		f.realtime.Close()
+	}
+}
+
+// handleStateChange passes the state change on, and calls onReconnect when
+// we reconnect after losing our connection
+func (f *ExecutionFeed) handleStateChange(state ConnectionState, err error) {
+	f.onStateChange(state, err)
+
+	f.mu.Lock()
+	var lostAt time.Time
+	switch {
+	case state == Connected:
+		lostAt = f.lostAt
+		f.lostAt = time.Time{}
+		f.connected = true
+	case f.connected:
+		f.lostAt = time.Now()
+		f.connected = false
+	}
+	f.mu.Unlock()
+
+	if !lostAt.IsZero() {
+		// the realtime api is read from the goroutine calling us, so we
+		// must not hold it up with REST requests
+		go f.onReconnect(lostAt)
+	}
+}
+
+// handleMessage passes a message on to the handler of its table
+func (f *ExecutionFeed) handleMessage(msg []byte) {
+	var update struct {
+		Table  string            `json:"table"`
+		Action string            `json:"action"`
+		Data   []json.RawMessage `json:"data"`
+	}
+	err := json.Unmarshal(msg, &update)
+	if err != nil {
+		log.WithError(err).WithField("msg", string(msg)).Error("could not unmarshal message")
+		return
+	}
+
+	for _, row := range update.Data {
+		switch update.Table {
+		case "execution":
+			err = f.handleExecution(row)
+		case "order":
+			err = f.handleOrder(update.Action, row)
+		case "position":
+			err = f.handlePosition(update.Action, row)
+		}
+		if err != nil {
+			log.WithError(err).WithFields(logrus.Fields{
+				"table": update.Table,
+				"row":   string(row),
+			}).Error("could not unmarshal row")
+		}
+	}
+}
+
+// handleExecution passes on executions filling our orders. Executions are
+// never updated, so every row is complete
+func (f *ExecutionFeed) handleExecution(row json.RawMessage) error {
+	var execution swagger.Execution
+	err := json.Unmarshal(row, &execution)
+	if err != nil {
+		return err
+	}
+
+	if execution.ExecType == ExecTypeTrade {
+		f.onFill(execution)
+	}
+
+	return nil
+}
+
+// handleOrder applies the row to the order it belongs to, and passes the
+// full order on. Orders that will not change anymore are forgotten
+func (f *ExecutionFeed) handleOrder(action string, row json.RawMessage) error {
+	var key struct {
+		OrderID string `json:"orderID"`
+	}
+	err := json.Unmarshal(row, &key)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	order := f.orders[key.OrderID]
+	err = json.Unmarshal(row, &order)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+
+	switch {
+	case action == "delete", order.OrdStatus == OrdStatusFilled,
+		order.OrdStatus == OrdStatusCanceled, order.OrdStatus == OrdStatusRejected:
+		delete(f.orders, key.OrderID)
+	default:
+		f.orders[key.OrderID] = order
+	}
+	f.mu.Unlock()
+
+	if action != "delete" {
+		f.onOrder(order)
+	}
+
+	return nil
+}
+
+// handlePosition applies the row to the position it belongs to, and passes
+// the full position on
+func (f *ExecutionFeed) handlePosition(action string, row json.RawMessage) error {
+	var key struct {
+		Symbol string `json:"symbol"`
+	}
+	err := json.Unmarshal(row, &key)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	position := f.positions[key.Symbol]
+	err = json.Unmarshal(row, &position)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+
+	if action == "delete" {
+		delete(f.positions, key.Symbol)
+	} else {
+		f.positions[key.Symbol] = position
+	}
+	f.mu.Unlock()
+
+	if action != "delete" {
+		f.onPosition(position)
+	}
+
+	return nil
+}
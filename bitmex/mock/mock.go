@@ -0,0 +1,783 @@
+// Package mock implements a small subset of the bitmex REST and realtime
+// apis, so lasd can be run against a local exchange on regtest.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/qct/bitmex-go/swagger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// DefaultBalance is the wallet balance the account starts with, 1 BTC
+const DefaultBalance = 100000000
+
+// Exchange is a mock bitmex exchange. Market orders fill immediately at
+// the current price, and limit orders fill immediately at their limit price.
+type Exchange struct {
+	// volatility is the standard deviation of each price move, in percent
+	volatility float64
+
+	mu sync.Mutex
+	// balance is the wallet balance of the account, in satoshis
+	balance     int64
+	instruments map[string]*instrument
+	orders      []swagger.Order
+	// fundingRate is the rate charged every funding interval, paid by
+	// longs to shorts when positive
+	fundingRate float64
+	// executions are the fills and funding payments so far
+	executions []swagger.Execution
+	// subscribers are websocket connections, with the symbols they are
+	// subscribed to
+	subscribers map[*subscriber]map[string]bool
+	// accountSubscribers are authenticated websocket connections, with the
+	// topics of the account they are subscribed to
+	accountSubscribers map[*subscriber]map[string]bool
+
+	// rateLimit is how many REST requests are served every minute, zero is
+	// no limit. requests counts those served since windowStart
+	rateLimit   int
+	requests    int
+	windowStart time.Time
+
+	upgrader websocket.Upgrader
+}
+
+// instrument is the state of a single instrument on the exchange
+type instrument struct {
+	price    float64
+	position float64
+}
+
+// subscriber is a websocket connection, with writes serialized
+type subscriber struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (s *subscriber) write(msg interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn.WriteJSON(msg)
+}
+
+// NewExchange creates a new mock exchange trading the given instruments,
+// starting at the given prices
+func NewExchange(prices map[string]float64, volatilityPercent float64) *Exchange {
+	instruments := make(map[string]*instrument)
+	for symbol, price := range prices {
+		instruments[symbol] = &instrument{price: price}
+	}
+
+	return &Exchange{
+		volatility:  volatilityPercent,
+		balance:     DefaultBalance,
+		instruments: instruments,
+		subscribers: make(map[*subscriber]map[string]bool),
+
+		accountSubscribers: make(map[*subscriber]map[string]bool),
+	}
+}
+
+// Handler returns a http.Handler serving the REST api under /api/v1, and
+// the realtime api under /realtime
+func (e *Exchange) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/order", e.handleNewOrder).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/order", e.handleGetOrders).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/order/all", e.handleCancelAll).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/position", e.handleGetPosition).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/user/margin", e.handleGetMargin).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/execution/tradeHistory", e.handleGetTradeHistory).Methods(http.MethodGet)
+	router.HandleFunc("/realtime", e.handleRealtime)
+	router.Use(e.limitRate)
+
+	return router
+}
+
+// SetRateLimit sets how many REST requests are served every minute. Zero
+// is no limit
+func (e *Exchange) SetRateLimit(perMinute int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rateLimit = perMinute
+}
+
+// limitRate rate limits REST requests like bitmex does, telling clients how
+// many requests they have left and when the limit resets in the
+// x-ratelimit headers, and rejecting requests past the limit with 429
+func (e *Exchange) limitRate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/realtime" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		e.mu.Lock()
+		limit := e.rateLimit
+		now := time.Now()
+		if now.Sub(e.windowStart) >= time.Minute {
+			e.windowStart = now
+			e.requests = 0
+		}
+		e.requests++
+		remaining := limit - e.requests
+		reset := e.windowStart.Add(time.Minute)
+		e.mu.Unlock()
+
+		if limit == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-Ratelimit-Limit", strconv.Itoa(limit))
+		w.Header().Set("X-Ratelimit-Remaining", strconv.Itoa(int(math.Max(float64(remaining), 0))))
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+		if remaining < 0 {
+			retryAfter := int(math.Ceil(time.Until(reset).Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeError(w, http.StatusTooManyRequests, "Rate limit exceeded, retry in 1 minute.")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Run moves all prices randomly every interval, and pushes the new prices
+// to all subscribers
+// NOTE: MUST be run in a goroutine
+func (e *Exchange) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.mu.Lock()
+		prices := make(map[string]float64)
+		for symbol, instrument := range e.instruments {
+			instrument.price *= 1 + rand.NormFloat64()*e.volatility/100
+			prices[symbol] = instrument.price
+		}
+		e.mu.Unlock()
+
+		for symbol, price := range prices {
+			e.broadcast(symbol, price)
+		}
+	}
+}
+
+// SetPrice sets the current price of an instrument, and pushes it to all
+// subscribers
+func (e *Exchange) SetPrice(symbol string, price float64) error {
+	e.mu.Lock()
+	instrument, ok := e.instruments[symbol]
+	if !ok {
+		e.mu.Unlock()
+		return fmt.Errorf("unknown instrument %s", symbol)
+	}
+	instrument.price = price
+	e.mu.Unlock()
+
+	e.broadcast(symbol, price)
+	return nil
+}
+
+// SetBalance sets the wallet balance of the account, in satoshis
+func (e *Exchange) SetBalance(balance int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.balance = balance
+}
+
+// SetFundingRate sets the rate charged every funding interval
+func (e *Exchange) SetFundingRate(rate float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.fundingRate = rate
+}
+
+// RunFunding charges funding every interval, like bitmex does every 8
+// hours
+// NOTE: MUST be run in a goroutine
+func (e *Exchange) RunFunding(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.ChargeFunding()
+	}
+}
+
+// ChargeFunding charges funding on every open position at the current
+// funding rate, and records it as a funding execution
+func (e *Exchange) ChargeFunding() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	for symbol, instrument := range e.instruments {
+		if instrument.position == 0 {
+			continue
+		}
+
+		// longs pay shorts when the rate is positive
+		paid := math.Round(instrument.position / instrument.price * e.fundingRate * 1e8)
+		e.balance -= int64(paid)
+
+		side := "Buy"
+		if instrument.position < 0 {
+			side = "Sell"
+		}
+
+		e.executions = append(e.executions, swagger.Execution{
+			ExecID:       uuid.New().String(),
+			Symbol:       symbol,
+			Side:         side,
+			LastQty:      float32(math.Abs(instrument.position)),
+			LastPx:       instrument.price,
+			Currency:     "XBt",
+			ExecType:     "Funding",
+			Commission:   e.fundingRate,
+			ExecComm:     float32(paid),
+			TransactTime: now,
+			Timestamp:    now,
+		})
+
+		log.WithFields(logrus.Fields{
+			"symbol":   symbol,
+			"position": instrument.position,
+			"rate":     e.fundingRate,
+			"paid":     paid,
+		}).Info("charged funding")
+	}
+}
+
+// handleNewOrder fills an order right away. The swagger client sends all
+// parameters as a JSON object of strings
+func (e *Exchange) handleNewOrder(w http.ResponseWriter, r *http.Request) {
+	var params map[string]string
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid body: %v", err))
+		return
+	}
+
+	// like bitmex, we only trade whole contracts
+	qty, err := strconv.ParseFloat(params["orderQty"], 64)
+	if err != nil || qty == 0 || qty != math.Trunc(qty) {
+		writeError(w, http.StatusBadRequest, "invalid orderQty")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instrument, ok := e.instruments[params["symbol"]]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown symbol %q", params["symbol"]))
+		return
+	}
+
+	if params["clOrdID"] != "" {
+		for _, order := range e.orders {
+			if order.ClOrdID == params["clOrdID"] {
+				writeError(w, http.StatusBadRequest, "Duplicate clOrdID")
+				return
+			}
+		}
+	}
+
+	fillPrice := instrument.price
+	if params["ordType"] == "Limit" {
+		fillPrice, err = strconv.ParseFloat(params["price"], 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid price")
+			return
+		}
+	}
+
+	side := "Buy"
+	if qty < 0 {
+		side = "Sell"
+	}
+
+	now := time.Now()
+	order := swagger.Order{
+		OrderID:      uuid.New().String(),
+		ClOrdID:      params["clOrdID"],
+		Symbol:       params["symbol"],
+		Side:         side,
+		OrderQty:     float32(math.Abs(qty)),
+		Price:        fillPrice,
+		OrdType:      params["ordType"],
+		OrdStatus:    "Filled",
+		CumQty:       float32(math.Abs(qty)),
+		AvgPx:        fillPrice,
+		Text:         params["text"],
+		TransactTime: now,
+		Timestamp:    now,
+	}
+
+	execution := swagger.Execution{
+		ExecID:       uuid.New().String(),
+		OrderID:      order.OrderID,
+		ClOrdID:      order.ClOrdID,
+		Symbol:       order.Symbol,
+		Side:         order.Side,
+		LastQty:      order.CumQty,
+		LastPx:       order.AvgPx,
+		OrderQty:     order.OrderQty,
+		OrdType:      order.OrdType,
+		OrdStatus:    order.OrdStatus,
+		ExecType:     "Trade",
+		CumQty:       order.CumQty,
+		AvgPx:        order.AvgPx,
+		TransactTime: now,
+		Timestamp:    now,
+	}
+
+	instrument.position += qty
+	e.orders = append(e.orders, order)
+	e.executions = append(e.executions, execution)
+	go e.publishFill(order, execution, instrument.position)
+
+	log.WithFields(logrus.Fields{
+		"symbol":   params["symbol"],
+		"side":     side,
+		"qty":      qty,
+		"price":    fillPrice,
+		"position": instrument.position,
+	}).Info("filled order")
+
+	writeJSON(w, order)
+}
+
+// handleGetOrders returns all orders, filtered by the symbol query
+// parameter, and the fields in the JSON filter query parameter
+func (e *Exchange) handleGetOrders(w http.ResponseWriter, r *http.Request) {
+	filter := make(map[string]string)
+	if raw := r.URL.Query().Get("filter"); raw != "" {
+		err := json.Unmarshal([]byte(raw), &filter)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid filter: %v", err))
+			return
+		}
+	}
+	if symbol := r.URL.Query().Get("symbol"); symbol != "" {
+		filter["symbol"] = symbol
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	orders := []swagger.Order{}
+	for _, order := range e.orders {
+		fields := map[string]string{
+			"orderID": order.OrderID,
+			"clOrdID": order.ClOrdID,
+			"symbol":  order.Symbol,
+			"side":    order.Side,
+		}
+
+		matches := true
+		for field, value := range filter {
+			if fields[field] != value {
+				matches = false
+			}
+		}
+		if matches {
+			orders = append(orders, order)
+		}
+	}
+
+	writeJSON(w, orders)
+}
+
+// handleGetTradeHistory returns funding executions, filtered by the symbol
+// query parameter and the execType of the JSON filter, oldest first unless
+// reverse is set
+func (e *Exchange) handleGetTradeHistory(w http.ResponseWriter, r *http.Request) {
+	filter := make(map[string]string)
+	if raw := r.URL.Query().Get("filter"); raw != "" {
+		err := json.Unmarshal([]byte(raw), &filter)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid filter: %v", err))
+			return
+		}
+	}
+	symbol := r.URL.Query().Get("symbol")
+	reverse := r.URL.Query().Get("reverse") == "true"
+	count := 100
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid count")
+			return
+		}
+		count = int(parsed)
+	}
+	start := 0
+	if raw := r.URL.Query().Get("start"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, "invalid start")
+			return
+		}
+		start = int(parsed)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	executions := []swagger.Execution{}
+	for i := range e.executions {
+		execution := e.executions[i]
+		if reverse {
+			execution = e.executions[len(e.executions)-1-i]
+		}
+
+		if symbol != "" && execution.Symbol != symbol {
+			continue
+		}
+		if execType, ok := filter["execType"]; ok && execution.ExecType != execType {
+			continue
+		}
+		if start > 0 {
+			start--
+			continue
+		}
+		if len(executions) == count {
+			break
+		}
+		executions = append(executions, execution)
+	}
+
+	writeJSON(w, executions)
+}
+
+// handleCancelAll cancels all open orders. As every order is filled right
+// away, there is never anything to cancel
+func (e *Exchange) handleCancelAll(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, []swagger.Order{})
+}
+
+func (e *Exchange) handleGetPosition(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	positions := []swagger.Position{}
+	for symbol, instrument := range e.instruments {
+		positions = append(positions, swagger.Position{
+			Symbol:     symbol,
+			CurrentQty: float32(instrument.position),
+			MarkPrice:  instrument.price,
+			IsOpen:     instrument.position != 0,
+		})
+	}
+
+	writeJSON(w, positions)
+}
+
+// handleGetMargin reports the margin of the account. Every instrument is
+// treated as an inverse contract like XBTUSD, held without leverage
+func (e *Exchange) handleGetMargin(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var used float64
+	for _, instrument := range e.instruments {
+		used += math.Abs(instrument.position) / instrument.price * 1e8
+	}
+
+	writeJSON(w, swagger.Margin{
+		Currency:        "XBt",
+		WalletBalance:   float32(e.balance),
+		MarginBalance:   float32(e.balance),
+		MaintMargin:     float32(used),
+		AvailableMargin: float32(float64(e.balance) - used),
+	})
+}
+
+// handleRealtime serves the realtime api. Clients can subscribe to
+// instrument updates, and ping the server. Authenticated clients can also
+// subscribe to the execution, order and position topics. Any api key is
+// accepted
+func (e *Exchange) handleRealtime(w http.ResponseWriter, r *http.Request) {
+	conn, err := e.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Error("could not upgrade connection")
+		return
+	}
+	defer conn.Close()
+
+	sub := &subscriber{conn: conn}
+	defer func() {
+		e.mu.Lock()
+		delete(e.subscribers, sub)
+		delete(e.accountSubscribers, sub)
+		e.mu.Unlock()
+	}()
+
+	authenticated := false
+
+	err = sub.write(map[string]interface{}{
+		"info":    "Welcome to the mock BitMEX Realtime API.",
+		"version": "mock",
+	})
+	if err != nil {
+		return
+	}
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if string(msg) == "ping" {
+			sub.mu.Lock()
+			err = conn.WriteMessage(websocket.TextMessage, []byte("pong"))
+			sub.mu.Unlock()
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		var op struct {
+			Op   string        `json:"op"`
+			Args []interface{} `json:"args"`
+		}
+		err = json.Unmarshal(msg, &op)
+		if err != nil || (op.Op != "subscribe" && op.Op != "authKeyExpires") {
+			_ = sub.write(map[string]string{"error": "unknown message: " + string(msg)})
+			continue
+		}
+
+		if op.Op == "authKeyExpires" {
+			authenticated = true
+			_ = sub.write(map[string]interface{}{
+				"success": true,
+				"request": op,
+			})
+			continue
+		}
+
+		for _, arg := range op.Args {
+			topic, _ := arg.(string)
+			if accountTopics[topic] {
+				if !authenticated {
+					_ = sub.write(map[string]interface{}{
+						"status":  401,
+						"error":   "User requested an account-locked subscription but no authorization was provided.",
+						"request": op,
+					})
+					continue
+				}
+
+				partial := e.subscribeAccount(sub, topic)
+				_ = sub.write(map[string]interface{}{
+					"success":   true,
+					"subscribe": topic,
+				})
+				_ = sub.write(partial)
+				continue
+			}
+
+			price, ok := e.subscribe(sub, topic)
+			if !ok {
+				_ = sub.write(map[string]string{"error": "unknown topic: " + topic})
+				continue
+			}
+			symbol := strings.TrimPrefix(topic, "instrument:")
+
+			_ = sub.write(map[string]interface{}{
+				"success":   true,
+				"subscribe": topic,
+			})
+			_ = sub.write(instrumentUpdate("partial", symbol, price))
+		}
+	}
+}
+
+// subscribe subscribes to an instrument topic, and returns the current
+// price of the instrument
+func (e *Exchange) subscribe(sub *subscriber, topic string) (float64, bool) {
+	if !strings.HasPrefix(topic, "instrument:") {
+		return 0, false
+	}
+	symbol := strings.TrimPrefix(topic, "instrument:")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instrument, ok := e.instruments[symbol]
+	if !ok {
+		return 0, false
+	}
+
+	if e.subscribers[sub] == nil {
+		e.subscribers[sub] = make(map[string]bool)
+	}
+	e.subscribers[sub][symbol] = true
+
+	return instrument.price, true
+}
+
+// accountTopics are the topics only authenticated clients can subscribe to
+var accountTopics = map[string]bool{
+	"execution": true,
+	"order":     true,
+	"position":  true,
+}
+
+// subscribeAccount subscribes to a topic of the account, and returns the
+// partial message with the current state of the topic. We do not keep
+// executions or open orders, so only positions are sent
+func (e *Exchange) subscribeAccount(sub *subscriber, topic string) interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.accountSubscribers[sub] == nil {
+		e.accountSubscribers[sub] = make(map[string]bool)
+	}
+	e.accountSubscribers[sub][topic] = true
+
+	data := []interface{}{}
+	if topic == "position" {
+		for symbol, instrument := range e.instruments {
+			data = append(data, positionRow(symbol, instrument.position))
+		}
+	}
+
+	return map[string]interface{}{
+		"table":  topic,
+		"action": "partial",
+		"data":   data,
+	}
+}
+
+// publishFill pushes the execution filling the order, the order and the
+// new position to the subscribers of each topic
+func (e *Exchange) publishFill(order swagger.Order, execution swagger.Execution, position float64) {
+	messages := map[string]interface{}{
+		"execution": map[string]interface{}{
+			"table":  "execution",
+			"action": "insert",
+			"data":   []swagger.Execution{execution},
+		},
+		"order": map[string]interface{}{
+			"table":  "order",
+			"action": "insert",
+			"data":   []swagger.Order{order},
+		},
+		"position": map[string]interface{}{
+			"table":  "position",
+			"action": "update",
+			"data":   []interface{}{positionRow(order.Symbol, position)},
+		},
+	}
+
+	e.mu.Lock()
+	subscribers := make(map[*subscriber][]string)
+	for sub, topics := range e.accountSubscribers {
+		for _, topic := range []string{"execution", "order", "position"} {
+			if topics[topic] {
+				subscribers[sub] = append(subscribers[sub], topic)
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	for sub, topics := range subscribers {
+		for _, topic := range topics {
+			err := sub.write(messages[topic])
+			if err != nil {
+				log.WithError(err).Debug("could not push fill to subscriber")
+			}
+		}
+	}
+}
+
+func positionRow(symbol string, position float64) interface{} {
+	return map[string]interface{}{
+		"account":    0,
+		"symbol":     symbol,
+		"currency":   "XBt",
+		"currentQty": position,
+		"timestamp":  time.Now().UTC(),
+	}
+}
+
+// broadcast pushes the price of an instrument to all its subscribers
+func (e *Exchange) broadcast(symbol string, price float64) {
+	e.mu.Lock()
+	var subscribers []*subscriber
+	for sub, symbols := range e.subscribers {
+		if symbols[symbol] {
+			subscribers = append(subscribers, sub)
+		}
+	}
+	e.mu.Unlock()
+
+	update := instrumentUpdate("update", symbol, price)
+	for _, sub := range subscribers {
+		err := sub.write(update)
+		if err != nil {
+			log.WithError(err).Debug("could not push price to subscriber")
+		}
+	}
+}
+
+func instrumentUpdate(action, symbol string, price float64) interface{} {
+	return map[string]interface{}{
+		"table":  "instrument",
+		"action": action,
+		"data": []map[string]interface{}{{
+			"symbol":    symbol,
+			"lastPrice": price,
+			"markPrice": price,
+			"timestamp": time.Now().UTC(),
+		}},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.WithError(err).Error("could not write response")
+	}
+}
+
+// writeError writes an error on the same form as bitmex does
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"message": message,
+			"name":    "HTTPError",
+		},
+	})
+}
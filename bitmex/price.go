@@ -0,0 +1,190 @@
+package bitmex
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// XBTUSD is the bitmex perpetual bitcoin/dollar swap, which we use to price
+// contracts unless configured otherwise
+const XBTUSD = "XBTUSD"
+
+// BXBT is the bitmex bitcoin/dollar index, a weighted average of the
+// prices on several spot exchanges
+const BXBT = ".BXBT"
+
+// markSuffix is added to the symbol of an instrument to get the symbol its
+// mark price is reported under
+const markSuffix = ".mark"
+
+// MarkSymbol returns the symbol the mark price of an instrument is reported
+// under. Bitmex uses the mark price to calculate unrealised profit and
+// liquidations, and it is less prone to wicks than the last price
+func MarkSymbol(symbol string) string {
+	return symbol + markSuffix
+}
+
+// sourceName is the name we attach to prices originating from bitmex
+const sourceName = "bitmex"
+
+var _ oracle.Source = &PriceFeed{}
+
+// PriceFeed is a PriceOracle backed by the bitmex realtime websocket
+type PriceFeed struct {
+	oracle.Notifier
+
+	mu     sync.RWMutex
+	prices map[string]oracle.Price
+
+	// url is the url of the realtime api we connect to
+	url           string
+	symbols       []string
+	realtime      *Realtime
+	onStateChange func(state ConnectionState, err error)
+}
+
+// NewPriceFeed creates a new PriceFeed that connects to the realtime api at
+// url, and receives the prices of the given instruments. It does not
+// receive any prices before Listen is called
+func NewPriceFeed(url string, symbols []string) *PriceFeed {
+	return &PriceFeed{
+		url:     url,
+		symbols: symbols,
+		prices:  make(map[string]oracle.Price),
+
+		onStateChange: func(ConnectionState, error) {},
+	}
+}
+
+// Name returns the name of the feed
+func (f *PriceFeed) Name() string {
+	return sourceName
+}
+
+// LatestPrice returns the latest price received from bitmex for the symbol
+func (f *PriceFeed) LatestPrice(symbol string) (oracle.Price, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	price, ok := f.prices[symbol]
+	if !ok {
+		return oracle.Price{}, fmt.Errorf("%s: %w", symbol, oracle.ErrNoPrice)
+	}
+
+	return price, nil
+}
+
+// OnStateChange sets a function that is called every time the connection
+// to bitmex changes state. MUST be called before Listen.
+func (f *PriceFeed) OnStateChange(fn func(state ConnectionState, err error)) {
+	f.onStateChange = fn
+}
+
+// Listen connects to bitmex and updates the feed with new prices. The
+// latest price is updated and passed on to subscribers on every tick, so
+// its timestamp tells how fresh it is. Lost connections are reopened, so
+// Listen only returns if Close is called.
+// NOTE: MUST be run in a goroutine
+func (f *PriceFeed) Listen() error {
+	// we subscribe to instrument updates. This includes several different updates
+	// but we only care about priceUpdates
+	var topics []string
+	for _, symbol := range f.symbols {
+		topics = append(topics, "instrument:"+symbol)
+	}
+
+	f.mu.Lock()
+	f.realtime = NewRealtime(f.url, topics, f.handleInstrumentUpdate)
+	f.realtime.OnStateChange(f.onStateChange)
+	f.mu.Unlock()
+
+	return f.realtime.Run()
+}
+
+// Close closes the connection to bitmex
+func (f *PriceFeed) Close() {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.realtime != nil {
+		f.realtime.Close()
+	}
+}
+
+// handleInstrumentUpdate extracts the latest price from an instrument update
+func (f *PriceFeed) handleInstrumentUpdate(msg []byte) {
+	type price struct {
+		Symbol            string    `json:"symbol"`
+		LastPrice         float64   `json:"lastPrice"`
+		MarkPrice         float64   `json:"markPrice"`
+		LastTickDirection string    `json:"lastTickDirection"`
+		LastChangePcnt    float64   `json:"lastChangePcnt"`
+		Timestamp         time.Time `json:"timestamp"`
+	}
+
+	type priceUpdate struct {
+		Table  string  `json:"table"`
+		Action string  `json:"action"`
+		Data   []price `json:"data"`
+	}
+
+	var lastPrice priceUpdate
+
+	err := json.Unmarshal(msg, &lastPrice)
+	if err != nil {
+		log.WithError(err).WithField("msg", string(msg)).Error("could not unmarshal message")
+		return
+	}
+	// the priceUpdate response from bitmex is not unique and many responses unmarshal successfully
+	// we only care about the ones with prices in the Data array
+	for _, data := range lastPrice.Data {
+		// not all instrument updates carry a timestamp
+		timestamp := data.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+
+		// the last price and the mark price are updated independently
+		if data.LastPrice != 0 {
+			log.WithFields(logrus.Fields{
+				"symbol":            data.Symbol,
+				"lastPrice":         data.LastPrice,
+				"lastTickDirection": data.LastTickDirection,
+				"lastChangePercent": data.LastChangePcnt,
+			}).Trace("new price")
+
+			f.setPrice(oracle.Price{
+				Symbol:    data.Symbol,
+				Value:     data.LastPrice,
+				Timestamp: timestamp,
+				Source:    sourceName,
+			})
+		}
+
+		if data.MarkPrice != 0 {
+			f.setPrice(oracle.Price{
+				Symbol:    MarkSymbol(data.Symbol),
+				Value:     data.MarkPrice,
+				Timestamp: timestamp,
+				Source:    sourceName,
+			})
+		}
+	}
+}
+
+// setPrice updates the latest price, and notifies subscribers of it. Every
+// tick is passed on, even if the price did not change, so subscribers know
+// it is still fresh
+func (f *PriceFeed) setPrice(price oracle.Price) {
+	f.mu.Lock()
+	f.prices[price.Symbol] = price
+	f.mu.Unlock()
+
+	f.Notify(price)
+}
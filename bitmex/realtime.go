@@ -0,0 +1,321 @@
+package bitmex
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/qct/bitmex-go/swagger"
+	"github.com/sirupsen/logrus"
+)
+
+// ConnectionState is the state of a connection to the bitmex realtime api
+type ConnectionState int
+
+const (
+	Disconnected ConnectionState = iota
+	Connecting
+	Connected
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case Disconnected:
+		return "disconnected"
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	}
+
+	return fmt.Sprintf("unknown(%d)", s)
+}
+
+const (
+	// bitmex recommends sending a ping if no message is received in 5 seconds
+	defaultPingInterval = 5 * time.Second
+	// if we do not receive anything, not even a pong, within this time after
+	// pinging, we consider the connection dead
+	defaultPongTimeout = 5 * time.Second
+
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+
+	// authExpiry is how long the signature we authenticate with is valid
+	authExpiry = time.Minute
+)
+
+// Realtime manages a connection to the bitmex realtime websocket. It
+// reconnects with exponential backoff, detects dead connections by sending
+// heartbeats, and resubscribes to all topics whenever it reconnects.
+type Realtime struct {
+	url    string
+	topics []string
+
+	// apiKey and secretKey authenticate the connection, so we can subscribe
+	// to the topics of our account
+	apiKey    string
+	secretKey string
+
+	onMessage     func(msg []byte)
+	onStateChange func(state ConnectionState, err error)
+
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+
+	quit chan struct{}
+	once sync.Once
+}
+
+// NewRealtime creates a new Realtime connection to url, that subscribes to
+// topics and passes every data message to onMessage. It does not connect
+// before Run is called.
+func NewRealtime(url string, topics []string, onMessage func(msg []byte)) *Realtime {
+	return &Realtime{
+		url:           url,
+		topics:        topics,
+		onMessage:     onMessage,
+		onStateChange: func(ConnectionState, error) {},
+		pingInterval:  defaultPingInterval,
+		pongTimeout:   defaultPongTimeout,
+		minBackoff:    defaultMinBackoff,
+		maxBackoff:    defaultMaxBackoff,
+		quit:          make(chan struct{}),
+	}
+}
+
+// OnStateChange sets a function that is called every time the connection
+// changes state. err is set if the connection was lost or could not be
+// opened. MUST be called before Run.
+func (r *Realtime) OnStateChange(fn func(state ConnectionState, err error)) {
+	r.onStateChange = fn
+}
+
+// Authenticate makes every connection authenticate with the api key before
+// subscribing, which is required for the topics of our account, e.g.
+// execution and position. MUST be called before Run.
+func (r *Realtime) Authenticate(apiKey, secretKey string) {
+	r.apiKey = apiKey
+	r.secretKey = secretKey
+}
+
+// Run connects to bitmex, and keeps reconnecting until Close is called
+// NOTE: MUST be run in a goroutine
+func (r *Realtime) Run() error {
+	backoff := r.minBackoff
+
+	for {
+		r.onStateChange(Connecting, nil)
+
+		connectedAt := time.Now()
+		err := r.connect()
+
+		select {
+		case <-r.quit:
+			r.onStateChange(Disconnected, nil)
+			return nil
+		default:
+		}
+
+		r.onStateChange(Disconnected, err)
+
+		// a connection that stayed up for a while was healthy, so we start
+		// over with a short backoff
+		if time.Since(connectedAt) > r.maxBackoff {
+			backoff = r.minBackoff
+		}
+
+		log.WithError(err).WithFields(logrus.Fields{
+			"url":     r.url,
+			"backoff": backoff,
+		}).Warn("bitmex realtime connection lost, reconnecting")
+
+		select {
+		case <-time.After(backoff):
+		case <-r.quit:
+			return nil
+		}
+
+		backoff *= 2
+		if backoff > r.maxBackoff {
+			backoff = r.maxBackoff
+		}
+	}
+}
+
+// Close closes the connection, and stops reconnecting
+func (r *Realtime) Close() {
+	r.once.Do(func() {
+		close(r.quit)
+	})
+}
+
+// connect opens a single connection, subscribes to our topics and reads
+// from it until the connection fails
+func (r *Realtime) connect() error {
+	conn, _, err := websocket.DefaultDialer.Dial(r.url, nil)
+	if err != nil {
+		return fmt.Errorf("could not dial %s: %w", r.url, err)
+	}
+	defer conn.Close()
+
+	// all writes happen from this goroutine and the pinger, so they need
+	// to be serialized
+	var writeMu sync.Mutex
+	write := func(msg []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		return conn.WriteMessage(websocket.TextMessage, msg)
+	}
+
+	if r.apiKey != "" {
+		auth, err := r.authMessage()
+		if err != nil {
+			return err
+		}
+		err = write(auth)
+		if err != nil {
+			return fmt.Errorf("could not authenticate: %w", err)
+		}
+	}
+
+	subscribe, err := json.Marshal(map[string]interface{}{
+		"op":   "subscribe",
+		"args": r.topics,
+	})
+	if err != nil {
+		return err
+	}
+	err = write(subscribe)
+	if err != nil {
+		return fmt.Errorf("could not subscribe: %w", err)
+	}
+
+	r.onStateChange(Connected, nil)
+
+	// closing the connection makes the read below fail, which is how we
+	// stop reading when Close is called
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-r.quit:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	// send a ping whenever we have not heard from bitmex in a while. If
+	// nothing arrives before the read deadline, the read fails and we
+	// reconnect. The ping is timed from the last message, so bitmex always
+	// has the full pong timeout to answer it
+	var lastMsgMu sync.Mutex
+	lastMsg := time.Now()
+	go func() {
+		timer := time.NewTimer(r.pingInterval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				lastMsgMu.Lock()
+				silent := time.Since(lastMsg)
+				lastMsgMu.Unlock()
+
+				if silent < r.pingInterval {
+					timer.Reset(r.pingInterval - silent)
+					continue
+				}
+
+				err := write([]byte("ping"))
+				if err != nil {
+					log.WithError(err).Debug("could not ping bitmex")
+				}
+				timer.Reset(r.pingInterval)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		err = conn.SetReadDeadline(time.Now().Add(r.pingInterval + r.pongTimeout))
+		if err != nil {
+			return err
+		}
+
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("could not read from bitmex: %w", err)
+		}
+
+		lastMsgMu.Lock()
+		lastMsg = time.Now()
+		lastMsgMu.Unlock()
+
+		r.handleMessage(msg)
+	}
+}
+
+// authMessage returns the message authenticating the connection with our
+// api key, signed like a GET request to /realtime
+func (r *Realtime) authMessage() ([]byte, error) {
+	expires := time.Now().Add(authExpiry).Unix()
+	signature := swagger.Signature(r.secretKey, "GET", "/realtime", "",
+		strconv.FormatInt(expires, 10), "")
+
+	return json.Marshal(map[string]interface{}{
+		"op":   "authKeyExpires",
+		"args": []interface{}{r.apiKey, expires, signature},
+	})
+}
+
+// handleMessage handles control messages from bitmex, and passes data
+// messages on
+func (r *Realtime) handleMessage(msg []byte) {
+	if string(msg) == "pong" {
+		return
+	}
+
+	var control struct {
+		Info      string `json:"info"`
+		Success   bool   `json:"success"`
+		Subscribe string `json:"subscribe"`
+		Error     string `json:"error"`
+		Table     string `json:"table"`
+		Request   struct {
+			Op string `json:"op"`
+		} `json:"request"`
+	}
+	err := json.Unmarshal(msg, &control)
+	if err != nil {
+		log.WithError(err).WithField("msg", string(msg)).Error("could not unmarshal message")
+		return
+	}
+
+	switch {
+	case control.Error != "":
+		log.WithField("error", control.Error).Error("received error from bitmex")
+
+	case control.Request.Op == "authKeyExpires":
+		log.WithField("success", control.Success).Info("authenticated with bitmex")
+
+	case control.Info != "":
+		log.WithField("info", control.Info).Debug("received info from bitmex")
+
+	case control.Subscribe != "":
+		log.WithFields(logrus.Fields{
+			"topic":   control.Subscribe,
+			"success": control.Success,
+		}).Info("subscribed to bitmex topic")
+
+	case control.Table != "":
+		r.onMessage(msg)
+	}
+}
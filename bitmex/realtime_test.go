@@ -0,0 +1,239 @@
+package bitmex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestRealtimeHandleMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		// passed is whether the message is passed on as data
+		passed bool
+	}{
+		{name: "pong", msg: "pong"},
+		{name: "welcome", msg: `{"info": "Welcome to the BitMEX Realtime API.", "version": "2.0.0"}`},
+		{name: "subscribed", msg: `{"success": true, "subscribe": "trade:XBTUSD"}`},
+		{name: "authenticated", msg: `{"success": true, "request": {"op": "authKeyExpires"}}`},
+		{name: "error", msg: `{"status": 400, "error": "Unknown table: foo"}`},
+		{name: "invalid json", msg: "{"},
+		{name: "data", msg: `{"table": "trade", "action": "insert", "data": []}`, passed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			passed := false
+			r := NewRealtime("ws://unused", nil, func([]byte) { passed = true })
+
+			r.handleMessage([]byte(test.msg))
+			if passed != test.passed {
+				t.Fatalf("passed on = %v, want %v", passed, test.passed)
+			}
+		})
+	}
+}
+
+// realtimeServer is a websocket server that records what clients send it,
+// and drops every connection after sending it a data message
+type realtimeServer struct {
+	mu sync.Mutex
+	// subscriptions are the topics of every subscribe message received
+	subscriptions [][]string
+	// pings is how many pings were received
+	pings int
+	// answerPings is whether pings are answered with a pong
+	answerPings bool
+	// holdOpen keeps connections open until the client closes them,
+	// without sending any data
+	holdOpen bool
+}
+
+func (s *realtimeServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if string(msg) == "ping" {
+			s.mu.Lock()
+			s.pings++
+			answer := s.answerPings
+			s.mu.Unlock()
+
+			if answer {
+				conn.WriteMessage(websocket.TextMessage, []byte("pong"))
+			}
+			continue
+		}
+
+		var op struct {
+			Op   string   `json:"op"`
+			Args []string `json:"args"`
+		}
+		if json.Unmarshal(msg, &op) != nil || op.Op != "subscribe" {
+			continue
+		}
+
+		s.mu.Lock()
+		s.subscriptions = append(s.subscriptions, op.Args)
+		holdOpen := s.holdOpen
+		s.mu.Unlock()
+
+		if holdOpen {
+			continue
+		}
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"table": "trade", "data": []}`))
+		return
+	}
+}
+
+// newTestRealtime returns a Realtime connected to the server, with short
+// heartbeats and backoff
+func newTestRealtime(server *httptest.Server, onMessage func([]byte)) *Realtime {
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	r := NewRealtime(url, []string{"trade:XBTUSD", "instrument:XBTUSD"}, onMessage)
+	r.pingInterval = 20 * time.Millisecond
+	r.pongTimeout = 100 * time.Millisecond
+	r.minBackoff = time.Millisecond
+	r.maxBackoff = 10 * time.Millisecond
+
+	return r
+}
+
+// waitFor waits until cond returns true, failing the test if it does not
+// within a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRealtimeResubscribesOnReconnect(t *testing.T) {
+	handler := &realtimeServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var mu sync.Mutex
+	messages := 0
+	var states []ConnectionState
+
+	r := newTestRealtime(server, func([]byte) {
+		mu.Lock()
+		messages++
+		mu.Unlock()
+	})
+	r.OnStateChange(func(state ConnectionState, err error) {
+		mu.Lock()
+		states = append(states, state)
+		mu.Unlock()
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- r.Run()
+	}()
+
+	waitFor(t, "three connections", func() bool {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		return len(handler.subscriptions) >= 3
+	})
+	r.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("run did not return after close")
+	}
+
+	handler.mu.Lock()
+	for i, topics := range handler.subscriptions {
+		if strings.Join(topics, ",") != "trade:XBTUSD,instrument:XBTUSD" {
+			t.Errorf("connection %d subscribed to %v", i, topics)
+		}
+	}
+	handler.mu.Unlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if messages < 2 {
+		t.Fatalf("got %d messages, want one from every connection", messages)
+	}
+	// every connection goes connecting, connected and disconnected
+	for i := 0; i+2 < len(states); i += 3 {
+		if states[i] != Connecting || states[i+1] != Connected || states[i+2] != Disconnected {
+			t.Fatalf("states = %v, want connecting, connected and disconnected in turn", states)
+		}
+	}
+	if states[len(states)-1] != Disconnected {
+		t.Fatalf("last state is %s, want disconnected", states[len(states)-1])
+	}
+}
+
+func TestRealtimeHeartbeat(t *testing.T) {
+	tests := []struct {
+		name        string
+		answerPings bool
+		// reconnects is whether the connection should be dropped for not
+		// answering
+		reconnects bool
+	}{
+		{name: "answered pings keep the connection", answerPings: true},
+		{name: "unanswered pings drop the connection", reconnects: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := &realtimeServer{answerPings: test.answerPings, holdOpen: true}
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			r := newTestRealtime(server, func([]byte) {})
+			go r.Run()
+			defer r.Close()
+
+			waitFor(t, "pings", func() bool {
+				handler.mu.Lock()
+				defer handler.mu.Unlock()
+				return handler.pings >= 3
+			})
+			// wait long enough for a silent connection to time out
+			time.Sleep(3 * (r.pingInterval + r.pongTimeout))
+
+			handler.mu.Lock()
+			connections := len(handler.subscriptions)
+			handler.mu.Unlock()
+
+			if test.reconnects && connections < 2 {
+				t.Fatalf("got %d connections, want a reconnect", connections)
+			}
+			if !test.reconnects && connections != 1 {
+				t.Fatalf("got %d connections, want the first one kept", connections)
+			}
+		})
+	}
+}
@@ -12,12 +12,14 @@ import (
 
 // default value for flags
 const (
-	defaultRPCPort = 10455
+	defaultRPCPort      = 10455
+	defaultAdminRPCPort = 10456
 )
 
 // all flags for this package
 const (
-	flag_rpcport = "rpcport"
+	flag_rpcport      = "rpcport"
+	flag_adminrpcport = "adminrpcport"
 )
 
 func main() {
@@ -31,9 +33,21 @@ func main() {
 			Value: defaultRPCPort,
 			Usage: "port of la daemon",
 		},
+		cli.IntFlag{
+			Name:  flag_adminrpcport,
+			Value: defaultAdminRPCPort,
+			Usage: "port of the admin service of la daemon",
+		},
 	}
 	app.Commands = []cli.Command{
 		closeContractCommand,
+		getPriceHistoryCommand,
+		subscribePricesCommand,
+		confirmPriceCommand,
+		getStatusCommand,
+		listHedgeDriftCommand,
+		listHedgeOrdersCommand,
+		listFundingPaymentsCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -43,6 +57,27 @@ func main() {
 
 // connectToServerDaemon opens a connection to the server daemon
 func connectToServerDaemon(rpcPort int) (larpc.AssetServerClient, func()) {
+	conn := dialServerDaemon(rpcPort)
+
+	cleanUp := func() {
+		conn.Close()
+	}
+
+	return larpc.NewAssetServerClient(conn), cleanUp
+}
+
+// connectToAdminDaemon opens a connection to the admin service of the server daemon
+func connectToAdminDaemon(rpcPort int) (larpc.AdminServerClient, func()) {
+	conn := dialServerDaemon(rpcPort)
+
+	cleanUp := func() {
+		conn.Close()
+	}
+
+	return larpc.NewAdminServerClient(conn), cleanUp
+}
+
+func dialServerDaemon(rpcPort int) *grpc.ClientConn {
 	// Load the specified TLS certificate and build transport credentials
 	// with it.
 	opts := []grpc.DialOption{
@@ -53,12 +88,8 @@ func connectToServerDaemon(rpcPort int) (larpc.AssetServerClient, func()) {
 
 	conn, err := grpc.Dial(rpcServer, opts...)
 	if err != nil {
-		log.Fatalf("unable to connect to RPC server: %w", err)
+		log.Fatalf("unable to connect to RPC server: %v", err)
 	}
 
-	cleanUp := func() {
-		conn.Close()
-	}
-
-	return larpc.NewAssetServerClient(conn), cleanUp
+	return conn
 }
@@ -2,6 +2,14 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -45,3 +53,272 @@ func closeContract(ctx *cli.Context) error {
 	return nil
 }
 
+var getPriceHistoryCommand = cli.Command{
+	Name:     "pricehistory",
+	Category: "Prices",
+	Usage:    "List the prices accepted by the server, and the prices contracts were rebalanced on",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "symbol",
+			Usage: "the symbol to list prices for",
+			Value: "XBTUSD",
+		},
+		cli.StringFlag{
+			Name:  "interval",
+			Usage: "tick | minute | hour | day",
+			Value: "hour",
+		},
+		cli.DurationFlag{
+			Name:  "period",
+			Usage: "how far back to list prices",
+			Value: 24 * time.Hour,
+		},
+	},
+	Action: getPriceHistory,
+}
+
+func getPriceHistory(ctx *cli.Context) error {
+	conn, cleanup := connectToServerDaemon(ctx.GlobalInt(flag_rpcport))
+	defer cleanup()
+
+	interval, ok := larpc.CandleInterval_value[strings.ToUpper(ctx.String("interval"))]
+	if !ok {
+		return fmt.Errorf("unknown interval %q", ctx.String("interval"))
+	}
+
+	start, err := ptypes.TimestampProto(time.Now().Add(-ctx.Duration("period")))
+	if err != nil {
+		return err
+	}
+
+	res, err := conn.GetPriceHistory(context.Background(), &larpc.ServerGetPriceHistoryRequest{
+		Symbol:   ctx.String("symbol"),
+		Interval: larpc.CandleInterval(interval),
+		Start:    start,
+	})
+	if err != nil {
+		log.WithError(err).Error("could not get price history")
+		return err
+	}
+
+	printRespJSON(res)
+
+	return nil
+}
+
+var subscribePricesCommand = cli.Command{
+	Name:     "subscribeprices",
+	Category: "Prices",
+	Usage:    "Print every price the server accepts, until interrupted",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "asset",
+			Usage: "only print prices affecting this asset, can be repeated",
+		},
+	},
+	Action: subscribePrices,
+}
+
+func subscribePrices(ctx *cli.Context) error {
+	conn, cleanup := connectToServerDaemon(ctx.GlobalInt(flag_rpcport))
+	defer cleanup()
+
+	stream, err := conn.SubscribePrices(context.Background(), &larpc.ServerSubscribePricesRequest{
+		Assets: ctx.StringSlice("asset"),
+	})
+	if err != nil {
+		log.WithError(err).Error("could not subscribe to prices")
+		return err
+	}
+
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.WithError(err).Error("could not receive price")
+			return err
+		}
+
+		printRespJSON(update)
+	}
+}
+
+var confirmPriceCommand = cli.Command{
+	Name:     "confirmprice",
+	Category: "Prices",
+	Usage:    "Confirm the price move that tripped the circuit breaker, and resume rebalancing",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "symbol",
+			Usage: "the symbol to confirm the price of",
+			Value: "XBTUSD",
+		},
+	},
+	Action: confirmPrice,
+}
+
+func confirmPrice(ctx *cli.Context) error {
+	conn, cleanup := connectToAdminDaemon(ctx.GlobalInt(flag_adminrpcport))
+	defer cleanup()
+
+	res, err := conn.ConfirmPrice(context.Background(), &larpc.AdminConfirmPriceRequest{
+		Symbol: ctx.String("symbol"),
+	})
+	if err != nil {
+		log.WithError(err).Error("could not confirm price")
+		return err
+	}
+
+	printRespJSON(res)
+
+	return nil
+}
+
+var getStatusCommand = cli.Command{
+	Name:   "status",
+	Usage:  "Show the connection state of the realtime feeds the server depends on, and how far our hedges are off",
+	Action: getStatus,
+}
+
+func getStatus(ctx *cli.Context) error {
+	conn, cleanup := connectToAdminDaemon(ctx.GlobalInt(flag_adminrpcport))
+	defer cleanup()
+
+	res, err := conn.GetStatus(context.Background(), &larpc.AdminGetStatusRequest{})
+	if err != nil {
+		log.WithError(err).Error("could not get status")
+		return err
+	}
+
+	printRespJSON(res)
+
+	return nil
+}
+
+var listHedgeDriftCommand = cli.Command{
+	Name:     "hedgedrift",
+	Category: "Hedging",
+	Usage:    "List the recorded differences between our exchange positions and our open contracts",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "symbol",
+			Usage: "the instrument to list drift for, defaults to all",
+		},
+		cli.Int64Flag{
+			Name:  "limit",
+			Usage: "max number of records to list",
+		},
+	},
+	Action: listHedgeDrift,
+}
+
+func listHedgeDrift(ctx *cli.Context) error {
+	conn, cleanup := connectToAdminDaemon(ctx.GlobalInt(flag_adminrpcport))
+	defer cleanup()
+
+	res, err := conn.ListHedgeDrift(context.Background(), &larpc.AdminListHedgeDriftRequest{
+		Symbol: ctx.String("symbol"),
+		Limit:  ctx.Int64("limit"),
+	})
+	if err != nil {
+		log.WithError(err).Error("could not list hedge drift")
+		return err
+	}
+
+	printRespJSON(res)
+
+	return nil
+}
+
+var listHedgeOrdersCommand = cli.Command{
+	Name:     "hedgeorders",
+	Category: "Hedging",
+	Usage:    "List the orders placed to hedge contracts",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "uuid",
+			Usage: "only list orders hedging this contract",
+		},
+		cli.Int64Flag{
+			Name:  "limit",
+			Usage: "max number of orders to list",
+		},
+	},
+	Action: listHedgeOrders,
+}
+
+func listHedgeOrders(ctx *cli.Context) error {
+	conn, cleanup := connectToAdminDaemon(ctx.GlobalInt(flag_adminrpcport))
+	defer cleanup()
+
+	res, err := conn.ListHedgeOrders(context.Background(), &larpc.AdminListHedgeOrdersRequest{
+		ContractUuid: ctx.String("uuid"),
+		Limit:        ctx.Int64("limit"),
+	})
+	if err != nil {
+		log.WithError(err).Error("could not list hedge orders")
+		return err
+	}
+
+	printRespJSON(res)
+
+	return nil
+}
+
+var listFundingPaymentsCommand = cli.Command{
+	Name:     "funding",
+	Category: "Hedging",
+	Usage:    "List the funding paid or received on our hedges, and what contracts were allocated",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "symbol",
+			Usage: "only list funding paid on this instrument",
+		},
+		cli.StringFlag{
+			Name:  "uuid",
+			Usage: "only list funding allocated to this contract, and its total",
+		},
+		cli.Int64Flag{
+			Name:  "limit",
+			Usage: "max number of payments to list",
+		},
+	},
+	Action: listFundingPayments,
+}
+
+func listFundingPayments(ctx *cli.Context) error {
+	conn, cleanup := connectToAdminDaemon(ctx.GlobalInt(flag_adminrpcport))
+	defer cleanup()
+
+	res, err := conn.ListFundingPayments(context.Background(), &larpc.AdminListFundingPaymentsRequest{
+		Symbol:       ctx.String("symbol"),
+		ContractUuid: ctx.String("uuid"),
+		Limit:        ctx.Int64("limit"),
+	})
+	if err != nil {
+		log.WithError(err).Error("could not list funding payments")
+		return err
+	}
+
+	printRespJSON(res)
+
+	return nil
+}
+
+// printRespJSON prints a response from the server as indented JSON
+func printRespJSON(resp proto.Message) {
+	marshaler := jsonpb.Marshaler{
+		EmitDefaults: true,
+		Indent:       "    ",
+	}
+
+	jsonStr, err := marshaler.MarshalToString(resp)
+	if err != nil {
+		log.WithError(err).Error("could not marshal response")
+		return
+	}
+
+	fmt.Println(jsonStr)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+var _ larpc.AdminServerServer = &AdminServer{}
+
+// AdminServer serves the RPCs used by the operator of the server
+type AdminServer struct {
+	assets AssetServer
+	feeds  *feedMonitor
+}
+
+func (a AdminServer) ConfirmPrice(ctx context.Context, req *larpc.AdminConfirmPriceRequest) (*larpc.AdminConfirmPriceResponse, error) {
+	symbol := req.Symbol
+	if symbol == "" {
+		symbol = bitmex.XBTUSD
+	}
+
+	price, err := a.assets.breaker.Confirm(symbol)
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("symbol", symbol).Infof("operator confirmed price %f", price.Value)
+
+	// resume rebalancing right away instead of waiting for the next price
+	go func() {
+		err := a.assets.SetPrice(price)
+		if err != nil {
+			log.WithError(err).Error("could not set confirmed price")
+		}
+	}()
+
+	timestamp, err := ptypes.TimestampProto(price.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &larpc.AdminConfirmPriceResponse{
+		Price:     price.Value,
+		Source:    price.Source,
+		Timestamp: timestamp,
+	}, nil
+}
+
+func (a AdminServer) GetStatus(ctx context.Context, req *larpc.AdminGetStatusRequest) (*larpc.AdminGetStatusResponse, error) {
+	feeds, err := a.feeds.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := listHedgeJobs(a.assets.db, hedgeJobsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	failedJobs, err := listHedgeJobs(a.assets.db, failedHedgeJobsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	venues, err := venueStatus(a.assets.hedger)
+	if err != nil {
+		return nil, err
+	}
+
+	residuals, err := a.assets.hedgeResiduals()
+	if err != nil {
+		return nil, err
+	}
+
+	return &larpc.AdminGetStatusResponse{
+		Feeds:            feeds,
+		Hedges:           a.assets.reconciler.status(),
+		PendingHedgeJobs: jobs,
+		Venues:           venues,
+		Residuals:        residuals,
+		FailedHedgeJobs:  failedJobs,
+	}, nil
+}
+
+// venueStatus returns the health of the venues we fail over between, if
+// we hedge on more than one
+func venueStatus(hedger hedge.Hedger) ([]*larpc.VenueStatus, error) {
+	failover, ok := hedger.(*hedge.FailoverHedger)
+	if !ok {
+		return nil, nil
+	}
+
+	var statuses []*larpc.VenueStatus
+	for _, venue := range failover.Status() {
+		status := &larpc.VenueStatus{
+			Name: venue.Name,
+			Up:   venue.Up,
+		}
+		if !venue.Up {
+			downUntil, err := ptypes.TimestampProto(venue.DownUntil)
+			if err != nil {
+				return nil, err
+			}
+			status.DownUntil = downUntil
+		}
+		if venue.LastError != nil {
+			status.LastError = venue.LastError.Error()
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,563 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/deribit"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+)
+
+// assetConfig defines the instruments we get prices for, and the assets
+// contracts can be opened in. It is read from the JSON file passed with
+// --assetsconfig, e.g.
+//
+//	{
+//	    "instruments": {
+//	        "XBTUSD": {"base": "BTC", "quote": "USD", "index": ".BXBT", "tickSize": 0.5,
+//	                   "lotSize": 10, "minOrder": 10, "venues": {"deribit": "BTC-PERPETUAL"},
+//	                   "venueLotSizes": {"deribit": 10}}
+//	    },
+//	    "assets": [
+//	        {"name": "USD", "hedge": "XBTUSD", "venues": ["bitmex", "deribit"],
+//	         "pricing": "twap", "twapWindow": "5m"},
+//	        {"name": "NOK", "hedge": "XBTUSD",
+//	         "rebalanceBps": 10, "minRebalanceInterval": "30s", "forceRebalanceInterval": "10m"}
+//	    ]
+//	}
+type assetConfig struct {
+	Instruments map[string]instrumentConfig `json:"instruments"`
+	Assets      []assetDefinition           `json:"assets"`
+}
+
+// instrumentConfig is the two currencies the price of an instrument is an
+// exchange rate between
+type instrumentConfig struct {
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+
+	// Index is the symbol of the index the instrument tracks, e.g. ".BXBT".
+	// It is needed to price assets in the index pricing mode
+	Index string `json:"index,omitempty"`
+	// TickSize is the smallest price increment of the instrument. Limit
+	// orders are priced in whole ticks
+	TickSize float64 `json:"tickSize,omitempty"`
+	// LotSize is the quantity every hedge order must be a multiple of, and
+	// MinOrder the smallest hedge order. Exposure less than a lot is left
+	// unhedged until it adds up to one
+	LotSize  float64 `json:"lotSize,omitempty"`
+	MinOrder float64 `json:"minOrder,omitempty"`
+	// Venues is what the instrument is called on venues other than bitmex,
+	// e.g. {"deribit": "BTC-PERPETUAL"}. It can only be hedged on bitmex
+	// and the venues listed here
+	Venues map[string]string `json:"venues,omitempty"`
+	// VenueLotSizes are the lots venues trade the instrument in, if they
+	// differ from a single contract, e.g. {"deribit": 10}. Orders are only
+	// rounded once, to LotSize, so it must be a multiple of the lot of
+	// every venue the instrument is hedged on
+	VenueLotSizes map[string]float64 `json:"venueLotSizes,omitempty"`
+}
+
+// pricingMode is which price of an instrument assets are priced with
+type pricingMode string
+
+const (
+	// pricingLast prices with the last traded price
+	pricingLast pricingMode = "last"
+	// pricingMark prices with the mark price of the exchange
+	pricingMark pricingMode = "mark"
+	// pricingIndex prices with the index the instrument tracks
+	pricingIndex pricingMode = "index"
+	// pricingTWAP prices with the time-weighted average of the last traded
+	// price
+	pricingTWAP pricingMode = "twap"
+)
+
+// defaultTWAPWindow is the window prices are averaged over in the twap
+// pricing mode, if the asset does not set one
+const defaultTWAPWindow = 5 * time.Minute
+
+// assetDefinition defines how an asset is priced and hedged
+type assetDefinition struct {
+	Name string `json:"name"`
+
+	// Price is the formula for the price of one bitcoin in the asset, as
+	// instruments multiplied or divided with each other, e.g.
+	// "XBTEUR * EURUSD". It must price bitcoin in the quote currency of the
+	// hedge instrument, as that is the only exposure the hedge covers. If
+	// empty, the bitcoin price is converted to the asset through our
+	// exchange rates
+	Price string `json:"price,omitempty"`
+
+	// Hedge is the instrument we trade to hedge contracts in the asset
+	Hedge string `json:"hedge"`
+	// Venues are the venues the hedge instrument is traded on, in order of
+	// preference. Orders fail over to the next venue when one is down.
+	// Defaults to the venues passed with --hedger
+	Venues []string `json:"venues,omitempty"`
+
+	// Pricing is which price of the instruments the asset is priced with,
+	// one of "last", "mark", "index" or "twap". Defaults to "last"
+	Pricing pricingMode `json:"pricing,omitempty"`
+	// TWAPWindow is the window prices are averaged over in the twap pricing
+	// mode
+	TWAPWindow duration `json:"twapWindow,omitempty"`
+
+	// RebalanceBps is how many basis points the price of the asset has to
+	// move before contracts in it are rebalanced
+	RebalanceBps float64 `json:"rebalanceBps,omitempty"`
+	// MinRebalanceInterval is the least time between two rebalances
+	MinRebalanceInterval duration `json:"minRebalanceInterval,omitempty"`
+	// ForceRebalanceInterval is the most time between two rebalances,
+	// regardless of how much the price moved. Zero never forces a rebalance
+	ForceRebalanceInterval duration `json:"forceRebalanceInterval,omitempty"`
+
+	formula priceFormula
+}
+
+// duration is a time.Duration read from JSON strings like "30s"
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+
+	d.Duration, err = time.ParseDuration(s)
+	return err
+}
+
+// defaultAssetConfig returns the config used when no asset config is
+// passed. It is built on every call, as parse writes into the config
+func defaultAssetConfig() assetConfig {
+	return assetConfig{
+		Instruments: map[string]instrumentConfig{
+			bitmex.XBTUSD: {Base: "BTC", Quote: "USD", TickSize: 0.5, LotSize: 1, MinOrder: 1,
+				Venues:        map[string]string{venueDeribit: deribit.BTCPerpetual},
+				VenueLotSizes: map[string]float64{venueDeribit: 10}},
+		},
+		Assets: []assetDefinition{
+			{Name: "USD", Hedge: bitmex.XBTUSD},
+			{Name: "NOK", Hedge: bitmex.XBTUSD},
+		},
+	}
+}
+
+// loadAssetConfig reads the asset config from path, or returns the default
+// config if path is empty
+func loadAssetConfig(path string) (assetConfig, error) {
+	config := defaultAssetConfig()
+
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return assetConfig{}, fmt.Errorf("could not read asset config: %w", err)
+		}
+
+		config = assetConfig{}
+		err = json.Unmarshal(content, &config)
+		if err != nil {
+			return assetConfig{}, fmt.Errorf("could not unmarshal asset config: %w", err)
+		}
+	}
+
+	err := config.parse()
+	if err != nil {
+		return assetConfig{}, fmt.Errorf("invalid asset config: %w", err)
+	}
+
+	return config, nil
+}
+
+// parse parses the price formula of every asset, and checks that they and
+// the hedge instruments only refer to instruments we know of
+func (c *assetConfig) parse() error {
+	if len(c.Assets) == 0 {
+		return fmt.Errorf("no assets defined")
+	}
+
+	for symbol, instrument := range c.Instruments {
+		if instrument.TickSize < 0 || instrument.LotSize < 0 || instrument.MinOrder < 0 {
+			return fmt.Errorf("tick size, lot size and min order of %s can not be negative", symbol)
+		}
+		for venue, lot := range instrument.VenueLotSizes {
+			if lot <= 0 {
+				return fmt.Errorf("lot size of %s on %s must be positive", symbol, venue)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, asset := range c.Assets {
+		if asset.Name == "" {
+			return fmt.Errorf("asset %d has no name", i)
+		}
+		if seen[asset.Name] {
+			return fmt.Errorf("asset %s is defined twice", asset.Name)
+		}
+		seen[asset.Name] = true
+
+		hedge, ok := c.Instruments[asset.Hedge]
+		if !ok {
+			return fmt.Errorf("%s is hedged with unknown instrument %q", asset.Name, asset.Hedge)
+		}
+		// hedge orders are sized as one contract per unit of the quote
+		// currency, which only holds for inverse bitcoin contracts like
+		// XBTUSD. Quanto and linear contracts like ETHUSD can price assets,
+		// but not hedge them
+		if hedge.Base != "BTC" {
+			return fmt.Errorf("%s is hedged with %s, which is not an inverse bitcoin contract",
+				asset.Name, asset.Hedge)
+		}
+
+		switch asset.Pricing {
+		case "":
+			c.Assets[i].Pricing = pricingLast
+		case pricingLast, pricingMark, pricingIndex, pricingTWAP:
+		default:
+			return fmt.Errorf("%s has unknown pricing mode %q", asset.Name, asset.Pricing)
+		}
+		if asset.TWAPWindow.Duration < 0 {
+			return fmt.Errorf("TWAP window of %s can not be negative", asset.Name)
+		}
+		if asset.TWAPWindow.Duration == 0 {
+			c.Assets[i].TWAPWindow.Duration = defaultTWAPWindow
+		}
+
+		for _, venue := range asset.Venues {
+			if _, ok := hedge.Venues[venue]; !ok && venue != venueBitmex && venue != venuePaper {
+				return fmt.Errorf("%s is hedged on %s, which does not trade %s", asset.Name, venue, asset.Hedge)
+			}
+		}
+
+		if asset.RebalanceBps < 0 || asset.MinRebalanceInterval.Duration < 0 ||
+			asset.ForceRebalanceInterval.Duration < 0 {
+			return fmt.Errorf("rebalance triggers of %s can not be negative", asset.Name)
+		}
+
+		// assets without a price formula are priced by converting the
+		// bitcoin price of the hedge instrument to the asset
+		if asset.Price != "" {
+			formula, err := parsePriceFormula(asset.Price)
+			if err != nil {
+				return fmt.Errorf("could not parse price of %s: %w", asset.Name, err)
+			}
+			for _, term := range formula {
+				if _, ok := c.Instruments[term.symbol]; !ok {
+					return fmt.Errorf("price of %s refers to unknown instrument %q", asset.Name, term.symbol)
+				}
+			}
+
+			// hedge orders cover the value of contracts in the quote
+			// currency of the hedge instrument. An asset priced in any
+			// other currency, like ETH, would leave its exposure to that
+			// currency unhedged
+			currency, err := formula.currency(c.Instruments)
+			if err != nil {
+				return fmt.Errorf("invalid price of %s: %w", asset.Name, err)
+			}
+			if currency != hedge.Quote {
+				return fmt.Errorf("%s is priced in %s, but %s only hedges exposure to %s",
+					asset.Name, currency, asset.Hedge, hedge.Quote)
+			}
+			c.Assets[i].formula = formula
+		}
+
+		if c.Assets[i].Pricing == pricingIndex {
+			for _, symbol := range c.Assets[i].instruments() {
+				if c.Instruments[symbol].Index == "" {
+					return fmt.Errorf("%s is priced with the index of %s, which has none", asset.Name, symbol)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// names returns the names of all assets, in the order they were defined
+func (c assetConfig) names() []string {
+	var names []string
+	for _, asset := range c.Assets {
+		names = append(names, asset.Name)
+	}
+
+	return names
+}
+
+// lookup returns the definition of the named asset
+func (c assetConfig) lookup(name string) (assetDefinition, bool) {
+	for _, asset := range c.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+
+	return assetDefinition{}, false
+}
+
+// symbols returns every instrument and index we need prices for
+func (c assetConfig) symbols() []string {
+	var symbols []string
+	for symbol, instrument := range c.Instruments {
+		symbols = append(symbols, symbol)
+		if instrument.Index != "" {
+			symbols = append(symbols, instrument.Index)
+		}
+	}
+
+	return symbols
+}
+
+// lotSizes returns the order quantities every instrument can be traded in
+func (c assetConfig) lotSizes() map[string]hedge.LotSize {
+	lotSizes := make(map[string]hedge.LotSize)
+	for symbol, instrument := range c.Instruments {
+		lotSizes[symbol] = hedge.LotSize{Lot: instrument.LotSize, MinOrder: instrument.MinOrder}
+	}
+
+	return lotSizes
+}
+
+// hedgeSymbols returns every instrument assets are hedged with, sorted
+func (c assetConfig) hedgeSymbols() []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, asset := range c.Assets {
+		if !seen[asset.Hedge] {
+			seen[asset.Hedge] = true
+			symbols = append(symbols, asset.Hedge)
+		}
+	}
+	sort.Strings(symbols)
+
+	return symbols
+}
+
+// knows returns whether symbol is an instrument, the mark price of one or
+// an index
+func (c assetConfig) knows(symbol string) bool {
+	for instrumentSymbol, instrument := range c.Instruments {
+		if symbol == instrumentSymbol || symbol == bitmex.MarkSymbol(instrumentSymbol) ||
+			(instrument.Index != "" && symbol == instrument.Index) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tickSizes returns the tick size of every instrument that has one
+func (c assetConfig) tickSizes() map[string]float64 {
+	tickSizes := make(map[string]float64)
+	for symbol, instrument := range c.Instruments {
+		if instrument.TickSize > 0 {
+			tickSizes[symbol] = instrument.TickSize
+		}
+	}
+
+	return tickSizes
+}
+
+// venueRoutes returns the venues every hedge instrument is traded on, in
+// order of preference. Assets that do not list their own venues are hedged
+// on defaultVenues. Assets hedged with the same instrument share a single
+// position, so they must be hedged on the same venues, and be ordered in
+// lots all of them accept
+func (c assetConfig) venueRoutes(defaultVenues []string) (map[string][]string, error) {
+	routes := make(map[string][]string)
+	for _, asset := range c.Assets {
+		venues := asset.Venues
+		if len(venues) == 0 {
+			venues = defaultVenues
+		}
+
+		route, ok := routes[asset.Hedge]
+		if ok && strings.Join(route, ",") != strings.Join(venues, ",") {
+			return nil, fmt.Errorf("assets hedged with %s are hedged on different venues, %v and %v",
+				asset.Hedge, route, venues)
+		}
+		routes[asset.Hedge] = venues
+
+		instrument := c.Instruments[asset.Hedge]
+		for _, venue := range venues {
+			lot, ok := instrument.VenueLotSizes[venue]
+			if ok && !isMultiple(instrument.LotSize, lot) {
+				return nil, fmt.Errorf("%s is traded in lots of %v on %s, so its lot size must be a multiple of it, got %v",
+					asset.Hedge, lot, venue, instrument.LotSize)
+			}
+		}
+	}
+
+	return routes, nil
+}
+
+// isMultiple returns whether qty is a whole, non-zero number of lots
+func isMultiple(qty, lot float64) bool {
+	lots := qty / lot
+	return lots >= 1 && math.Abs(lots-math.Round(lots)) < 1e-9
+}
+
+// venueSymbols returns what every instrument traded on the venue is called
+// there, keyed by our symbol
+func (c assetConfig) venueSymbols(venue string) map[string]string {
+	symbols := make(map[string]string)
+	for symbol, instrument := range c.Instruments {
+		if venueSymbol, ok := instrument.Venues[venue]; ok {
+			symbols[symbol] = venueSymbol
+		}
+	}
+
+	return symbols
+}
+
+// maxTWAPWindow returns the longest window any asset averages prices over
+func (c assetConfig) maxTWAPWindow() time.Duration {
+	longest := defaultTWAPWindow
+	for _, asset := range c.Assets {
+		if asset.TWAPWindow.Duration > longest {
+			longest = asset.TWAPWindow.Duration
+		}
+	}
+
+	return longest
+}
+
+// instruments returns the instruments the price of the asset depends on.
+// Assets priced through our exchange rates depend on their hedge instrument
+func (d assetDefinition) instruments() []string {
+	if d.formula == nil {
+		return []string{d.Hedge}
+	}
+
+	var symbols []string
+	for _, term := range d.formula {
+		symbols = append(symbols, term.symbol)
+	}
+
+	return symbols
+}
+
+// priceSymbol returns the symbol of the price we use for the instrument in
+// the pricing mode of the asset
+func (c assetConfig) priceSymbol(asset assetDefinition, instrument string) string {
+	switch asset.Pricing {
+	case pricingMark:
+		return bitmex.MarkSymbol(instrument)
+	case pricingIndex:
+		return c.Instruments[instrument].Index
+	default:
+		return instrument
+	}
+}
+
+// priceSymbols returns the symbols of the prices the price of the asset
+// depends on, in its pricing mode
+func (c assetConfig) priceSymbols(asset assetDefinition) []string {
+	var symbols []string
+	for _, instrument := range asset.instruments() {
+		symbols = append(symbols, c.priceSymbol(asset, instrument))
+	}
+
+	return symbols
+}
+
+// priceTerm is a single instrument in a price formula
+type priceTerm struct {
+	symbol string
+	// divide is set if the formula divides by the price of the instrument
+	divide bool
+}
+
+// priceFormula is a product of instrument prices, each of which is either
+// multiplied or divided with
+type priceFormula []priceTerm
+
+// parsePriceFormula parses a formula like "XBTEUR * EURUSD"
+func parsePriceFormula(formula string) (priceFormula, error) {
+	fields := strings.Fields(strings.NewReplacer("*", " * ", "/", " / ").Replace(formula))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty formula")
+	}
+
+	var terms priceFormula
+	divide := false
+	expectSymbol := true
+	for _, field := range fields {
+		switch {
+		case field == "*" || field == "/":
+			if expectSymbol {
+				return nil, fmt.Errorf("unexpected %q in %q", field, formula)
+			}
+			divide = field == "/"
+			expectSymbol = true
+
+		default:
+			if !expectSymbol {
+				return nil, fmt.Errorf("missing operator before %q in %q", field, formula)
+			}
+			terms = append(terms, priceTerm{symbol: field, divide: divide})
+			expectSymbol = false
+		}
+	}
+	if expectSymbol {
+		return nil, fmt.Errorf("formula %q ends with an operator", formula)
+	}
+
+	return terms, nil
+}
+
+// currency returns the currency the formula prices bitcoin in. Starting
+// from bitcoin, multiplying with an instrument converts from its base to
+// its quote currency, and dividing with it from its quote to its base
+func (f priceFormula) currency(instruments map[string]instrumentConfig) (string, error) {
+	currency := "BTC"
+	for _, term := range f {
+		instrument := instruments[term.symbol]
+		switch {
+		case !term.divide && currency == instrument.Base:
+			currency = instrument.Quote
+		case term.divide && currency == instrument.Quote:
+			currency = instrument.Base
+		default:
+			return "", fmt.Errorf("%s is a %s/%s rate, which does not apply to a price in %s",
+				term.symbol, instrument.Base, instrument.Quote, currency)
+		}
+	}
+
+	return currency, nil
+}
+
+// evaluate computes the formula from the prices of its instruments. Every
+// price must be positive, as a zero price would make the result zero or
+// infinite
+func (f priceFormula) evaluate(price func(symbol string) (float64, error)) (float64, error) {
+	result := 1.0
+	for _, term := range f {
+		rate, err := price(term.symbol)
+		if err != nil {
+			return 0, fmt.Errorf("no price for %s: %w", term.symbol, err)
+		}
+		if rate <= 0 {
+			return 0, fmt.Errorf("%s has invalid price %f", term.symbol, rate)
+		}
+
+		if term.divide {
+			result /= rate
+		} else {
+			result *= rate
+		}
+	}
+
+	return result, nil
+}
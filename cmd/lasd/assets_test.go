@@ -0,0 +1,328 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+func TestParsePriceFormula(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    priceFormula
+		invalid bool
+	}{
+		{formula: "XBTUSD", want: priceFormula{{symbol: "XBTUSD"}}},
+		{formula: "XBTUSD / ETHUSD", want: priceFormula{{symbol: "XBTUSD"}, {symbol: "ETHUSD", divide: true}}},
+		{formula: "XBTUSD*ETHUSD", want: priceFormula{{symbol: "XBTUSD"}, {symbol: "ETHUSD"}}},
+		{formula: "XBTUSD / ETHUSD * LTCUSD", want: priceFormula{
+			{symbol: "XBTUSD"}, {symbol: "ETHUSD", divide: true}, {symbol: "LTCUSD"},
+		}},
+		{formula: "", invalid: true},
+		{formula: "/ XBTUSD", invalid: true},
+		{formula: "XBTUSD /", invalid: true},
+		{formula: "XBTUSD ETHUSD", invalid: true},
+		{formula: "XBTUSD * / ETHUSD", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.formula, func(t *testing.T) {
+			formula, err := parsePriceFormula(test.formula)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("parsed invalid formula as %v", formula)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(formula) != len(test.want) {
+				t.Fatalf("formula = %v, want %v", formula, test.want)
+			}
+			for i := range formula {
+				if formula[i] != test.want[i] {
+					t.Fatalf("formula = %v, want %v", formula, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPriceFormulaEvaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+		prices  map[string]float64
+		want    float64
+		invalid bool
+	}{
+		{
+			name:    "single instrument",
+			formula: "XBTUSD",
+			prices:  map[string]float64{"XBTUSD": 10000},
+			want:    10000,
+		},
+		{
+			name:    "divided",
+			formula: "XBTUSD / ETHUSD",
+			prices:  map[string]float64{"XBTUSD": 10000, "ETHUSD": 250},
+			want:    40,
+		},
+		{
+			name:    "multiplied",
+			formula: "XBTUSD * USDNOK",
+			prices:  map[string]float64{"XBTUSD": 10000, "USDNOK": 10},
+			want:    100000,
+		},
+		{
+			name:    "division by zero price",
+			formula: "XBTUSD / ETHUSD",
+			prices:  map[string]float64{"XBTUSD": 10000, "ETHUSD": 0},
+			invalid: true,
+		},
+		{
+			name:    "zero price",
+			formula: "XBTUSD * ETHUSD",
+			prices:  map[string]float64{"XBTUSD": 0, "ETHUSD": 250},
+			invalid: true,
+		},
+		{
+			name:    "negative price",
+			formula: "XBTUSD",
+			prices:  map[string]float64{"XBTUSD": -1},
+			invalid: true,
+		},
+		{
+			name:    "missing price",
+			formula: "XBTUSD / ETHUSD",
+			prices:  map[string]float64{"XBTUSD": 10000},
+			invalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formula, err := parsePriceFormula(test.formula)
+			if err != nil {
+				t.Fatalf("could not parse formula: %v", err)
+			}
+
+			price, err := formula.evaluate(func(symbol string) (float64, error) {
+				price, ok := test.prices[symbol]
+				if !ok {
+					return 0, oracle.ErrNoPrice
+				}
+				return price, nil
+			})
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("evaluated to %f, expected error", price)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(price-test.want) > 1e-9 {
+				t.Fatalf("price = %f, want %f", price, test.want)
+			}
+		})
+	}
+}
+
+func TestAssetConfigParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  assetConfig
+		invalid bool
+	}{
+		{
+			name: "default config",
+			config: assetConfig{
+				Instruments: map[string]instrumentConfig{"XBTUSD": {Base: "BTC", Quote: "USD"}},
+				Assets:      []assetDefinition{{Name: "USD", Hedge: "XBTUSD"}},
+			},
+		},
+		{
+			name: "priced by formula in the hedge currency",
+			config: assetConfig{
+				Instruments: map[string]instrumentConfig{
+					"XBTUSD": {Base: "BTC", Quote: "USD"},
+					"XBTEUR": {Base: "BTC", Quote: "EUR"},
+					"EURUSD": {Base: "EUR", Quote: "USD"},
+				},
+				Assets: []assetDefinition{{Name: "USD", Price: "XBTEUR * EURUSD", Hedge: "XBTUSD"}},
+			},
+		},
+		{
+			name: "priced in a currency the hedge does not cover",
+			config: assetConfig{
+				Instruments: map[string]instrumentConfig{
+					"XBTUSD": {Base: "BTC", Quote: "USD"},
+					"ETHUSD": {Base: "ETH", Quote: "USD"},
+				},
+				Assets: []assetDefinition{{Name: "ETH", Price: "XBTUSD / ETHUSD", Hedge: "XBTUSD"}},
+			},
+			invalid: true,
+		},
+		{
+			name: "hedged with instrument that is not inverse",
+			config: assetConfig{
+				Instruments: map[string]instrumentConfig{
+					"XBTUSD": {Base: "BTC", Quote: "USD"},
+					"ETHUSD": {Base: "ETH", Quote: "USD"},
+				},
+				Assets: []assetDefinition{{Name: "ETH", Price: "XBTUSD / ETHUSD", Hedge: "ETHUSD"}},
+			},
+			invalid: true,
+		},
+		{
+			name: "formula with unknown instrument",
+			config: assetConfig{
+				Instruments: map[string]instrumentConfig{"XBTUSD": {Base: "BTC", Quote: "USD"}},
+				Assets:      []assetDefinition{{Name: "ETH", Price: "XBTUSD / ETHUSD", Hedge: "XBTUSD"}},
+			},
+			invalid: true,
+		},
+		{
+			name: "asset defined twice",
+			config: assetConfig{
+				Instruments: map[string]instrumentConfig{"XBTUSD": {Base: "BTC", Quote: "USD"}},
+				Assets: []assetDefinition{
+					{Name: "USD", Hedge: "XBTUSD"},
+					{Name: "USD", Hedge: "XBTUSD"},
+				},
+			},
+			invalid: true,
+		},
+		{
+			name: "venue lot size not positive",
+			config: assetConfig{
+				Instruments: map[string]instrumentConfig{"XBTUSD": {Base: "BTC", Quote: "USD",
+					VenueLotSizes: map[string]float64{venueDeribit: 0}}},
+				Assets: []assetDefinition{{Name: "USD", Hedge: "XBTUSD"}},
+			},
+			invalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.parse()
+			if test.invalid && err == nil {
+				t.Fatal("parsed invalid config")
+			}
+			if !test.invalid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPriceFormulaCurrency(t *testing.T) {
+	instruments := map[string]instrumentConfig{
+		"XBTUSD": {Base: "BTC", Quote: "USD"},
+		"ETHUSD": {Base: "ETH", Quote: "USD"},
+		"EURUSD": {Base: "EUR", Quote: "USD"},
+	}
+
+	tests := []struct {
+		formula string
+		want    string
+		invalid bool
+	}{
+		{formula: "XBTUSD", want: "USD"},
+		{formula: "XBTUSD / ETHUSD", want: "ETH"},
+		{formula: "XBTUSD / EURUSD", want: "EUR"},
+		{formula: "XBTUSD * ETHUSD", invalid: true},
+		{formula: "ETHUSD", invalid: true},
+		{formula: "XBTUSD / ETHUSD / EURUSD", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.formula, func(t *testing.T) {
+			formula, err := parsePriceFormula(test.formula)
+			if err != nil {
+				t.Fatalf("could not parse formula: %v", err)
+			}
+
+			currency, err := formula.currency(instruments)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("formula priced in %s, want error", currency)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if currency != test.want {
+				t.Fatalf("currency = %s, want %s", currency, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadDefaultAssetConfig(t *testing.T) {
+	config, err := loadAssetConfig("")
+	if err != nil {
+		t.Fatalf("could not load default config: %v", err)
+	}
+
+	// changing the loaded config must not change the next default
+	config.Assets[0].Name = "EUR"
+	config.Instruments["ETHUSD"] = instrumentConfig{Base: "ETH", Quote: "USD"}
+
+	config, err = loadAssetConfig("")
+	if err != nil {
+		t.Fatalf("could not load default config: %v", err)
+	}
+	if config.Assets[0].Name != "USD" {
+		t.Fatalf("first default asset is %s, want USD", config.Assets[0].Name)
+	}
+	if _, ok := config.Instruments["ETHUSD"]; ok {
+		t.Fatal("default config kept an instrument added to an earlier copy")
+	}
+}
+
+func TestAssetConfigVenueRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		lotSize float64
+		venues  []string
+		invalid bool
+	}{
+		{name: "bitmex only", lotSize: 1, venues: []string{venueBitmex}},
+		{name: "lot size fits deribit", lotSize: 10, venues: []string{venueBitmex, venueDeribit}},
+		{name: "lot size is several deribit lots", lotSize: 20, venues: []string{venueBitmex, venueDeribit}},
+		{name: "lot size smaller than deribit lot", lotSize: 1, venues: []string{venueBitmex, venueDeribit}, invalid: true},
+		{name: "lot size not a multiple of deribit lot", lotSize: 15, venues: []string{venueDeribit}, invalid: true},
+		{name: "any quantity on deribit", lotSize: 0, venues: []string{venueDeribit}, invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := assetConfig{
+				Instruments: map[string]instrumentConfig{"XBTUSD": {
+					Base:          "BTC",
+					Quote:         "USD",
+					LotSize:       test.lotSize,
+					Venues:        map[string]string{venueDeribit: "BTC-PERPETUAL"},
+					VenueLotSizes: map[string]float64{venueDeribit: 10},
+				}},
+				Assets: []assetDefinition{{Name: "USD", Hedge: "XBTUSD"}},
+			}
+
+			_, err := config.venueRoutes(test.venues)
+			if test.invalid && err == nil {
+				t.Fatal("accepted lot size deribit does not accept")
+			}
+			if !test.invalid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
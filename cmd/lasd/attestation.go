@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/fx"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// quotePrices returns the price of each asset that depends on the given
+// price, keyed by asset. Assets we can not price yet are skipped. The
+// prices are not signed, as only the prices contracts are rebalanced on are
+// attested
+func (a AssetServer) quotePrices(price oracle.Price) (map[string]*larpc.PriceAttestation, error) {
+	quotes := make(map[string]*larpc.PriceAttestation)
+
+	for _, definition := range a.assets.Assets {
+		if !a.assets.dependsOn(definition, price.Symbol) {
+			continue
+		}
+
+		asset := definition.Name
+		assetPrice, inputs, rates, err := a.assetPriceInputs(asset)
+		if errors.Is(err, fx.ErrUnknownPair) || errors.Is(err, fx.ErrStaleRate) ||
+			errors.Is(err, oracle.ErrNoPrice) {
+			log.WithError(err).WithField("asset", asset).Warn("can not price asset, not quoting price")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		quote, err := a.newAttestation(price, definition, assetPrice, inputs, rates)
+		if err != nil {
+			return nil, fmt.Errorf("could not quote %s price: %w", asset, err)
+		}
+		quotes[asset] = quote
+	}
+
+	return quotes, nil
+}
+
+// dependsOn returns whether the price of the asset depends on the price of
+// the symbol, in the pricing mode of the asset
+func (c assetConfig) dependsOn(asset assetDefinition, symbol string) bool {
+	for _, priceSymbol := range c.priceSymbols(asset) {
+		if priceSymbol == symbol {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newAttestation returns the unsigned attestation of the asset price, and
+// the prices and exchange rates it was computed from
+func (a AssetServer) newAttestation(price oracle.Price, asset assetDefinition, assetPrice float64,
+	inputs []oracle.Price, rates []fx.Rate) (*larpc.PriceAttestation, error) {
+
+	timestamp, err := ptypes.TimestampProto(price.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert price timestamp: %w", err)
+	}
+
+	attestation := &larpc.PriceAttestation{
+		Symbol:     price.Symbol,
+		Price:      price.Value,
+		Source:     price.Source,
+		Timestamp:  timestamp,
+		Asset:      asset.Name,
+		AssetPrice: assetPrice,
+		Pubkey:     a.nodePubkey,
+		Pricing:    string(asset.Pricing),
+		Formula:    asset.Price,
+	}
+	if asset.Pricing == pricingTWAP {
+		attestation.TwapWindowSeconds = int64(asset.TWAPWindow.Seconds())
+	}
+
+	for _, input := range inputs {
+		inputTimestamp, err := ptypes.TimestampProto(input.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert input timestamp: %w", err)
+		}
+
+		attestation.Inputs = append(attestation.Inputs, &larpc.PriceInput{
+			Symbol:    input.Symbol,
+			Price:     input.Value,
+			Source:    input.Source,
+			Timestamp: inputTimestamp,
+		})
+	}
+
+	for _, rate := range rates {
+		rateTimestamp, err := ptypes.TimestampProto(rate.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert rate timestamp: %w", err)
+		}
+
+		attestation.FxRates = append(attestation.FxRates, &larpc.FxRate{
+			Base:      rate.Base,
+			Quote:     rate.Quote,
+			Rate:      rate.Value,
+			Source:    rate.Source,
+			Timestamp: rateTimestamp,
+		})
+	}
+
+	return attestation, nil
+}
+
+// attest signs a copy of the attestation with our lnd node key, and saves
+// it
+func (a AssetServer) attest(unsigned *larpc.PriceAttestation) (*larpc.PriceAttestation, error) {
+	attestation := proto.Clone(unsigned).(*larpc.PriceAttestation)
+
+	msg, err := attestation.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := a.lncli.SignMessage(context.Background(), &lnrpc.SignMessageRequest{
+		Msg: []byte(msg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not sign message: %w", err)
+	}
+	attestation.Signature = res.Signature
+
+	err = saveAttestation(a.db, attestation)
+	if err != nil {
+		return nil, fmt.Errorf("could not save attestation: %w", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"asset":      attestation.Asset,
+		"assetPrice": attestation.AssetPrice,
+		"source":     attestation.Source,
+	}).Debug("attested price")
+
+	return attestation, nil
+}
+
+// saveAttestation saves the attestation, keyed by the timestamp of the price
+// followed by the asset, so attestations are sorted by time
+func saveAttestation(db *bolt.DB, attestation *larpc.PriceAttestation) error {
+	timestamp, err := ptypes.Timestamp(attestation.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	key := make([]byte, 8, 8+len(attestation.Asset))
+	binary.BigEndian.PutUint64(key, uint64(timestamp.UnixNano()))
+	key = append(key, attestation.Asset...)
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(attestationsBucket)
+
+		asByte, err := json.Marshal(attestation)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(key, asByte)
+	})
+}
+
+// pruneAttestations deletes the attestations of prices older than the
+// given time
+func pruneAttestations(db *bolt.DB, before time.Time) (int, error) {
+	var pruned int
+	err := db.Update(func(tx *bolt.Tx) error {
+		var err error
+		pruned, err = deleteBefore(tx.Bucket(attestationsBucket), before)
+		return err
+	})
+
+	return pruned, err
+}
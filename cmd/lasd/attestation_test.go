@@ -0,0 +1,213 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/fx"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+func TestQuotePrices(t *testing.T) {
+	instruments := map[string]instrumentConfig{
+		"XBTUSD": {Base: "BTC", Quote: "USD"},
+		"XBTEUR": {Base: "BTC", Quote: "EUR"},
+		"EURUSD": {Base: "EUR", Quote: "USD"},
+	}
+
+	tests := []struct {
+		name  string
+		asset assetDefinition
+		// prices are the prices of each instrument, the last one is the
+		// price that is quoted
+		prices []oracle.Price
+		want   float64
+		// rates is how many exchange rates the price is converted with
+		rates  int
+		window int64
+	}{
+		{
+			name:   "same currency as the hedge",
+			asset:  assetDefinition{Name: "USD", Hedge: "XBTUSD"},
+			prices: []oracle.Price{{Symbol: "XBTUSD", Value: 10000}},
+			want:   10000,
+		},
+		{
+			name:   "converted through one rate",
+			asset:  assetDefinition{Name: "NOK", Hedge: "XBTUSD"},
+			prices: []oracle.Price{{Symbol: "XBTUSD", Value: 10000}},
+			want:   100000,
+			rates:  1,
+		},
+		{
+			name:   "converted through two rates",
+			asset:  assetDefinition{Name: "SEK", Hedge: "XBTUSD"},
+			prices: []oracle.Price{{Symbol: "XBTUSD", Value: 10000}},
+			want:   95000,
+			rates:  2,
+		},
+		{
+			name:  "priced by formula",
+			asset: assetDefinition{Name: "USD", Price: "XBTEUR * EURUSD", Hedge: "XBTUSD"},
+			prices: []oracle.Price{
+				{Symbol: "EURUSD", Value: 1.1},
+				{Symbol: "XBTEUR", Value: 9000},
+			},
+			want: 9900,
+		},
+		{
+			name:   "twap window",
+			asset:  assetDefinition{Name: "USD", Hedge: "XBTUSD", Pricing: pricingTWAP},
+			prices: []oracle.Price{{Symbol: "XBTUSD", Value: 10000}},
+			want:   10000,
+			window: int64(defaultTWAPWindow / time.Second),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := assetConfig{Instruments: instruments, Assets: []assetDefinition{test.asset}}
+			err := config.parse()
+			if err != nil {
+				t.Fatalf("invalid config: %v", err)
+			}
+
+			converter := fx.NewConverter(time.Hour)
+			for _, rate := range []fx.Rate{
+				{Base: "USD", Quote: "NOK", Value: 10, Source: "test", Timestamp: time.Now()},
+				{Base: "NOK", Quote: "SEK", Value: 0.95, Source: "test", Timestamp: time.Now()},
+			} {
+				err = converter.SetRate(rate)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			a := AssetServer{
+				assets:    config,
+				converter: converter,
+				window:    oracle.NewPriceWindow(config.maxTWAPWindow()),
+			}
+			for _, price := range test.prices {
+				price.Timestamp = time.Now().Add(-time.Minute)
+				price.Source = "test"
+				a.window.Add(price)
+			}
+			quoted := test.prices[len(test.prices)-1]
+			quoted.Timestamp = time.Now()
+
+			quotes, err := a.quotePrices(quoted)
+			if err != nil {
+				t.Fatalf("could not quote prices: %v", err)
+			}
+			quote, ok := quotes[test.asset.Name]
+			if !ok {
+				t.Fatalf("%s was not quoted", test.asset.Name)
+			}
+
+			if math.Abs(quote.AssetPrice-test.want) > 1e-6 {
+				t.Fatalf("asset price = %f, want %f", quote.AssetPrice, test.want)
+			}
+			if len(quote.FxRates) != test.rates {
+				t.Fatalf("got %d fx rates, want %d", len(quote.FxRates), test.rates)
+			}
+			if quote.TwapWindowSeconds != test.window {
+				t.Fatalf("twap window = %d, want %d", quote.TwapWindowSeconds, test.window)
+			}
+
+			// the asset price can be computed from the attested inputs
+			// and rates alone
+			var computed float64
+			if quote.Formula != "" {
+				formula, err := parsePriceFormula(quote.Formula)
+				if err != nil {
+					t.Fatalf("could not parse attested formula: %v", err)
+				}
+
+				i := 0
+				computed, err = formula.evaluate(func(symbol string) (float64, error) {
+					input := quote.Inputs[i]
+					i++
+					if input.Symbol != symbol {
+						t.Fatalf("input %d is %s, want %s", i, input.Symbol, symbol)
+					}
+					return input.Price, nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				if len(quote.Inputs) != 1 || quote.Inputs[0].Symbol != test.asset.Hedge {
+					t.Fatalf("inputs = %v, want the price of %s", quote.Inputs, test.asset.Hedge)
+				}
+				computed = quote.Inputs[0].Price
+				for _, rate := range quote.FxRates {
+					computed *= rate.Rate
+				}
+			}
+			if math.Abs(computed-quote.AssetPrice) > 1e-6 {
+				t.Fatalf("attested fields give price %f, but asset price is %f", computed, quote.AssetPrice)
+			}
+		})
+	}
+}
+
+func TestPruneAttestations(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		for _, asset := range []string{"USD", "NOK"} {
+			timestamp, err := ptypes.TimestampProto(start.Add(time.Duration(i) * 24 * time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = saveAttestation(db, &larpc.PriceAttestation{
+				Symbol: "XBTUSD", Price: 10000, Timestamp: timestamp, Asset: asset,
+			})
+			if err != nil {
+				t.Fatalf("could not save attestation: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		before time.Time
+		pruned int
+		left   int
+	}{
+		{before: start, pruned: 0, left: 8},
+		{before: start.Add(time.Nanosecond), pruned: 2, left: 6},
+		{before: start.Add(48 * time.Hour), pruned: 2, left: 4},
+		{before: start.Add(30 * 24 * time.Hour), pruned: 4, left: 0},
+	}
+
+	for _, test := range tests {
+		pruned, err := pruneAttestations(db, test.before)
+		if err != nil {
+			t.Fatalf("could not prune attestations: %v", err)
+		}
+		if pruned != test.pruned {
+			t.Fatalf("pruned %d attestations before %s, want %d", pruned, test.before, test.pruned)
+		}
+
+		var left int
+		err = db.View(func(tx *bolt.Tx) error {
+			left = tx.Bucket(attestationsBucket).Stats().KeyN
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if left != test.left {
+			t.Fatalf("%d attestations left, want %d", left, test.left)
+		}
+	}
+}
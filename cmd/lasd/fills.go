@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// trackFills records the fills of our hedge orders as our hedger reports
+// them
+// NOTE: MUST be run in a goroutine
+func (a AssetServer) trackFills() {
+	fillHedger, ok := a.hedger.(hedge.FillHedger)
+	if !ok {
+		log.WithField("hedger", a.hedger.Name()).Info("hedger does not report fills, not tracking them")
+		return
+	}
+
+	for fill := range fillHedger.Fills() {
+		err := a.recordFill(fill)
+		if err != nil {
+			log.WithError(err).WithField("orderID", fill.OrderID).Error("could not record hedge fill")
+		}
+	}
+}
+
+// recordFill saves the fill, and applies it to the hedge order it belongs
+// to. Fills can arrive before we have saved their order, in which case they
+// are applied when the order is saved
+func (a AssetServer) recordFill(fill hedge.Fill) error {
+	// fills routed to one of several venues are recorded with the venue
+	// they were placed on, like orders
+	venue := a.hedger.Name()
+	if fill.Venue != "" {
+		venue = fill.Venue
+	}
+
+	record, err := newHedgeFill(fill, venue)
+	if err != nil {
+		return err
+	}
+
+	var order *larpc.HedgeOrder
+	duplicate := false
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		key := append(hedgeOrderID(venue, fill.OrderID), ':')
+		key = append(key, fill.ID...)
+		if tx.Bucket(hedgeFillsBucket).Get(key) != nil {
+			// we have seen this fill before
+			duplicate = true
+			return nil
+		}
+
+		asByte, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		err = tx.Bucket(hedgeFillsBucket).Put(key, asByte)
+		if err != nil {
+			return err
+		}
+
+		orderKey := tx.Bucket(hedgeOrderKeysBucket).Get(hedgeOrderID(venue, fill.OrderID))
+		if orderKey == nil {
+			return nil
+		}
+
+		order = &larpc.HedgeOrder{}
+		err = json.Unmarshal(tx.Bucket(hedgeOrdersBucket).Get(orderKey), order)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal hedge order: %w", err)
+		}
+
+		return putHedgeOrder(tx, order)
+	})
+	if err != nil {
+		return fmt.Errorf("could not save hedge fill: %w", err)
+	}
+	if duplicate {
+		return nil
+	}
+
+	logger := log.WithFields(logrus.Fields{
+		"hedger":  venue,
+		"orderID": fill.OrderID,
+		"symbol":  fill.Symbol,
+		"side":    fill.Side,
+		"status":  fill.Status,
+	})
+	if order != nil {
+		logger = logger.WithFields(logrus.Fields{
+			"uuid":        order.ContractUuid,
+			"filledQty":   order.FilledQty,
+			"fillPrice":   order.FillPrice,
+			"openPrice":   order.OpenPrice,
+			"slippageBps": order.SlippageBps,
+		})
+	}
+
+	switch fill.Status {
+	case hedge.StatusCanceled, hedge.StatusRejected:
+		// reconciliation corrects the exposure the order did not get us
+		logger.WithField("reason", fill.Reason).Warn("hedge order was not filled")
+	default:
+		logger.WithFields(logrus.Fields{
+			"qty":   fill.Qty,
+			"price": fill.Price,
+		}).Info("hedge order filled")
+	}
+
+	return nil
+}
+
+// newHedgeFill converts a fill reported by our hedger to the record we save
+func newHedgeFill(fill hedge.Fill, venue string) (*larpc.HedgeFill, error) {
+	timestamp, err := ptypes.TimestampProto(fill.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	return &larpc.HedgeFill{
+		Id:            fill.ID,
+		OrderId:       fill.OrderID,
+		ClientOrderId: fill.ClientID,
+		Hedger:        venue,
+		Symbol:        fill.Symbol,
+		Side:          string(fill.Side),
+		Qty:           fill.Qty,
+		Price:         fill.Price,
+		FilledQty:     fill.FilledQty,
+		AvgPrice:      fill.AvgPrice,
+		FeeSats:       fill.Fee,
+		Status:        string(fill.Status),
+		Reason:        fill.Reason,
+		Timestamp:     timestamp,
+	}, nil
+}
+
+// hedgeOrderID identifies a hedge order across venues
+func hedgeOrderID(venue, orderID string) []byte {
+	return []byte(venue + ":" + orderID)
+}
+
+// applyFills updates the order with every fill we have saved of it, and
+// with how much it slipped against the price its contract was opened at
+func applyFills(tx *bolt.Tx, order *larpc.HedgeOrder) error {
+	prefix := append(hedgeOrderID(order.Hedger, order.OrderId), ':')
+
+	var fees int64
+	var filled bool
+	c := tx.Bucket(hedgeFillsBucket).Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var fill larpc.HedgeFill
+		err := json.Unmarshal(v, &fill)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal hedge fill: %w", err)
+		}
+
+		// fills may arrive out of order, so the one that filled the most
+		// of the order is the latest
+		if fill.FilledQty >= order.FilledQty {
+			order.FilledQty = fill.FilledQty
+			if fill.AvgPrice > 0 {
+				order.FillPrice = fill.AvgPrice
+			}
+		}
+		if !hedge.OrderStatus(order.Status).Done() {
+			order.Status = fill.Status
+		}
+		if fill.Reason != "" {
+			order.ReasonText = fill.Reason
+		}
+
+		fees += fill.FeeSats
+		filled = true
+	}
+	if filled {
+		order.FeeSats = fees
+	}
+
+	if order.ContractUuid != "" && order.OpenPrice == 0 {
+		asByte := tx.Bucket(contractsBucket).Get([]byte(order.ContractUuid))
+		if asByte != nil {
+			var contract larpc.ServerContract
+			err := json.Unmarshal(asByte, &contract)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal contract: %w", err)
+			}
+			order.OpenPrice = contract.HedgePrice
+		}
+	}
+
+	if order.OpenPrice > 0 && order.FillPrice > 0 {
+		order.SlippageBps = slippageBps(hedge.Side(order.Side), order.OpenPrice, order.FillPrice)
+	}
+
+	return nil
+}
+
+// slippageBps returns how many basis points worse than the open price an
+// order on the side filled at. Buying higher or selling lower is worse
+func slippageBps(side hedge.Side, openPrice, fillPrice float64) float64 {
+	slippage := (fillPrice - openPrice) / openPrice * 10000
+	if side == hedge.Sell {
+		return -slippage
+	}
+
+	return slippage
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+func TestSlippageBps(t *testing.T) {
+	tests := []struct {
+		name      string
+		side      hedge.Side
+		openPrice float64
+		fillPrice float64
+		want      float64
+	}{
+		{name: "buy at the open price", side: hedge.Buy, openPrice: 10000, fillPrice: 10000, want: 0},
+		{name: "buy higher", side: hedge.Buy, openPrice: 10000, fillPrice: 10010, want: 10},
+		{name: "buy lower", side: hedge.Buy, openPrice: 10000, fillPrice: 9990, want: -10},
+		{name: "sell lower", side: hedge.Sell, openPrice: 10000, fillPrice: 9950, want: 50},
+		{name: "sell higher", side: hedge.Sell, openPrice: 10000, fillPrice: 10100, want: -100},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := slippageBps(test.side, test.openPrice, test.fillPrice)
+			if math.Abs(got-test.want) > 1e-9 {
+				t.Fatalf("slippage = %f bps, want %f", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecordFill(t *testing.T) {
+	fill := func(id string, filledQty, avgPrice float64, fee int64, status hedge.OrderStatus) hedge.Fill {
+		return hedge.Fill{
+			ID:        id,
+			OrderID:   "order",
+			Symbol:    "XBTUSD",
+			Side:      hedge.Buy,
+			FilledQty: filledQty,
+			AvgPrice:  avgPrice,
+			Fee:       fee,
+			Status:    status,
+			Time:      time.Now(),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		fills []hedge.Fill
+		// orderLast saves the order after its fills arrived
+		orderLast bool
+		filledQty float64
+		fillPrice float64
+		fee       int64
+		status    hedge.OrderStatus
+		slippage  float64
+	}{
+		{
+			name:      "filled",
+			fills:     []hedge.Fill{fill("1", 100, 10100, 5, hedge.StatusFilled)},
+			filledQty: 100,
+			fillPrice: 10100,
+			fee:       5,
+			status:    hedge.StatusFilled,
+			slippage:  100,
+		},
+		{
+			name: "same fill reported twice",
+			fills: []hedge.Fill{
+				fill("1", 100, 10100, 5, hedge.StatusFilled),
+				fill("1", 100, 10100, 5, hedge.StatusFilled),
+			},
+			filledQty: 100,
+			fillPrice: 10100,
+			fee:       5,
+			status:    hedge.StatusFilled,
+			slippage:  100,
+		},
+		{
+			name: "partial fills",
+			fills: []hedge.Fill{
+				fill("1", 40, 10000, 2, hedge.StatusPartiallyFilled),
+				fill("2", 100, 10050, 3, hedge.StatusFilled),
+			},
+			filledQty: 100,
+			fillPrice: 10050,
+			fee:       5,
+			status:    hedge.StatusFilled,
+			slippage:  50,
+		},
+		{
+			name: "partial fills out of order",
+			fills: []hedge.Fill{
+				fill("2", 100, 10050, 3, hedge.StatusFilled),
+				fill("1", 40, 10000, 2, hedge.StatusPartiallyFilled),
+			},
+			filledQty: 100,
+			fillPrice: 10050,
+			fee:       5,
+			status:    hedge.StatusFilled,
+			slippage:  50,
+		},
+		{
+			name: "fills before the order is saved",
+			fills: []hedge.Fill{
+				fill("1", 40, 10000, 2, hedge.StatusPartiallyFilled),
+				fill("2", 100, 10050, 3, hedge.StatusFilled),
+				fill("2", 100, 10050, 3, hedge.StatusFilled),
+			},
+			orderLast: true,
+			filledQty: 100,
+			fillPrice: 10050,
+			fee:       5,
+			status:    hedge.StatusFilled,
+			slippage:  50,
+		},
+		{
+			name: "same execution on another venue",
+			fills: []hedge.Fill{
+				fill("1", 100, 10100, 5, hedge.StatusFilled),
+				func() hedge.Fill {
+					f := fill("1", 100, 9000, 7, hedge.StatusFilled)
+					f.Venue = "other"
+					return f
+				}(),
+			},
+			filledQty: 100,
+			fillPrice: 10100,
+			fee:       5,
+			status:    hedge.StatusFilled,
+			slippage:  100,
+		},
+		{
+			name: "cancelled",
+			fills: []hedge.Fill{
+				fill("1", 40, 10000, 2, hedge.StatusPartiallyFilled),
+				func() hedge.Fill {
+					f := fill("2", 40, 10000, 0, hedge.StatusCanceled)
+					f.Reason = "no liquidity"
+					return f
+				}(),
+			},
+			filledQty: 40,
+			fillPrice: 10000,
+			fee:       2,
+			status:    hedge.StatusCanceled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, cleanup := newTestDB(t)
+			defer cleanup()
+
+			// the contract was hedged at 10000 dollars
+			err := db.Update(func(tx *bolt.Tx) error {
+				asByte, err := json.Marshal(larpc.ServerContract{Uuid: "contract", HedgePrice: 10000})
+				if err != nil {
+					return err
+				}
+				return tx.Bucket(contractsBucket).Put([]byte("contract"), asByte)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a := AssetServer{db: db, hedger: &stubHedger{}}
+			saveOrder := func() {
+				err := a.saveHedgeOrders([]hedge.Order{{
+					ID:     "order",
+					Symbol: "XBTUSD",
+					Side:   hedge.Buy,
+					Qty:    100,
+				}}, "contract", "open")
+				if err != nil {
+					t.Fatalf("could not save hedge order: %v", err)
+				}
+			}
+
+			if !test.orderLast {
+				saveOrder()
+			}
+			for _, fill := range test.fills {
+				err := a.recordFill(fill)
+				if err != nil {
+					t.Fatalf("could not record fill: %v", err)
+				}
+			}
+			if test.orderLast {
+				saveOrder()
+			}
+
+			res, err := AdminServer{assets: a}.ListHedgeOrders(nil, &larpc.AdminListHedgeOrdersRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Orders) != 1 {
+				t.Fatalf("got %d orders, want 1", len(res.Orders))
+			}
+			order := res.Orders[0]
+
+			if order.FilledQty != test.filledQty || order.FillPrice != test.fillPrice || order.FeeSats != test.fee {
+				t.Fatalf("filled %f at %f for %d sats, want %f at %f for %d sats", order.FilledQty,
+					order.FillPrice, order.FeeSats, test.filledQty, test.fillPrice, test.fee)
+			}
+			if order.Status != string(test.status) {
+				t.Fatalf("status = %s, want %s", order.Status, test.status)
+			}
+			if order.OpenPrice != 10000 || math.Abs(order.SlippageBps-test.slippage) > 1e-9 {
+				t.Fatalf("slipped %f bps from %f, want %f bps from 10000", order.SlippageBps,
+					order.OpenPrice, test.slippage)
+			}
+		})
+	}
+}
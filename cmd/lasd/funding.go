@@ -0,0 +1,530 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// how funding paid on our hedges is passed on to clients
+const (
+	// fundingAbsorb records funding, but does not pass it on
+	fundingAbsorb = "absorb"
+	// fundingCharge settles the funding allocated to a contract with the
+	// client when it is rebalanced
+	fundingCharge = "charge"
+	// fundingMargin adds the funding we expect to pay to the margin quoted
+	// for new contracts
+	fundingMargin = "margin"
+)
+
+var (
+	defaultFundingPolicy   = fundingAbsorb
+	defaultFundingInterval = 10 * time.Minute
+	// defaultFundingMarginPeriods is how many funding periods of the
+	// latest rate are added to the margin of new contracts
+	defaultFundingMarginPeriods = 3.0
+)
+
+// fundingLookback is how far back we ask venues for funding payments. Any
+// payment we miss for longer than this is lost
+const fundingLookback = 24 * time.Hour
+
+const defaultFundingPaymentsLimit = 100
+
+// fundingTracker records the funding paid on our hedge positions, and
+// decides how it is passed on to clients
+type fundingTracker struct {
+	policy   string
+	interval time.Duration
+	// marginPeriods is how many funding periods of the latest rate are
+	// added to the margin of new contracts, with the margin policy
+	marginPeriods float64
+
+	mu sync.Mutex
+	// rates are the latest funding rates of every hedge instrument
+	rates map[string]float64
+}
+
+func newFundingTracker(policy string, interval time.Duration, marginPeriods float64) (*fundingTracker, error) {
+	switch policy {
+	case fundingAbsorb, fundingCharge, fundingMargin:
+	default:
+		return nil, fmt.Errorf("unknown funding policy %q", policy)
+	}
+
+	return &fundingTracker{
+		policy:        policy,
+		interval:      interval,
+		marginPeriods: marginPeriods,
+		rates:         make(map[string]float64),
+	}, nil
+}
+
+// rate returns the latest funding rate of the instrument, and false if we
+// have not recorded any funding for it
+func (f *fundingTracker) rate(symbol string) (float64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rate, ok := f.rates[symbol]
+	return rate, ok
+}
+
+// percentMargin returns the margin quoted for new contracts hedged with the
+// instrument. With the margin policy, the funding we expect to pay over
+// the next few periods is added to the base margin. Funding we expect to
+// receive is never subtracted
+func (f *fundingTracker) percentMargin(base float64, symbol string) float64 {
+	if f.policy != fundingMargin {
+		return base
+	}
+
+	rate, _ := f.rate(symbol)
+	return base + math.Max(rate, 0)*100*f.marginPeriods
+}
+
+// trackFunding records new funding payments on every hedge instrument
+// right away, and then every interval
+// NOTE: MUST be run in a goroutine
+func (a AssetServer) trackFunding() {
+	fundingHedger, ok := a.hedger.(hedge.FundingHedger)
+	if !ok {
+		log.WithField("hedger", a.hedger.Name()).Info("hedger pays no funding, not tracking it")
+		return
+	}
+
+	ticker := time.NewTicker(a.funding.interval)
+	defer ticker.Stop()
+
+	for {
+		err := a.recordFunding(fundingHedger)
+		if err != nil {
+			log.WithError(err).Error("could not record funding")
+		}
+
+		<-ticker.C
+	}
+}
+
+// recordFunding records funding paid on every hedge instrument since our
+// lookback, and allocates new payments to the contracts that were open when
+// they were paid
+func (a AssetServer) recordFunding(hedger hedge.FundingHedger) error {
+	var payments []hedge.FundingPayment
+	for _, symbol := range a.assets.hedgeSymbols() {
+		symbolPayments, err := hedger.FundingPayments(symbol, time.Now().Add(-fundingLookback))
+		if err != nil {
+			return fmt.Errorf("could not get %s funding: %w", symbol, err)
+		}
+
+		if len(symbolPayments) > 0 {
+			a.funding.mu.Lock()
+			a.funding.rates[symbol] = symbolPayments[len(symbolPayments)-1].Rate
+			a.funding.mu.Unlock()
+		}
+
+		payments = append(payments, symbolPayments...)
+	}
+	if len(payments) == 0 {
+		return nil
+	}
+
+	hedges, err := a.contractHedges()
+	if err != nil {
+		return err
+	}
+
+	return a.db.Update(func(tx *bolt.Tx) error {
+		for _, payment := range payments {
+			key := fundingKey(payment)
+			if tx.Bucket(fundingBucket).Get(key) != nil {
+				continue
+			}
+
+			record, err := newFundingPayment(payment, exposureAt(hedges, payment.Symbol, payment.Time))
+			if err != nil {
+				return err
+			}
+
+			err = putFundingPayment(tx, key, record)
+			if err != nil {
+				return err
+			}
+
+			log.WithFields(logrus.Fields{
+				"symbol":    payment.Symbol,
+				"venue":     payment.Venue,
+				"rate":      payment.Rate,
+				"amount":    payment.Amount,
+				"contracts": len(record.Allocations),
+			}).Info("recorded funding payment")
+		}
+
+		return pruneClosedContracts(tx, time.Now().Add(-fundingLookback))
+	})
+}
+
+// contractHedge is the hedge exposure of a contract, and when we held it
+type contractHedge struct {
+	uuid   string
+	symbol string
+	qty    float64
+	// openedAt is zero for contracts opened before we recorded it, and
+	// closedAt is zero for contracts that are still open
+	openedAt time.Time
+	closedAt time.Time
+}
+
+// heldAt returns whether we held the hedge at the given time
+func (c contractHedge) heldAt(t time.Time) bool {
+	return !c.openedAt.After(t) && (c.closedAt.IsZero() || c.closedAt.After(t))
+}
+
+// contractHedges returns the hedge exposure of every open contract, and of
+// contracts closed within our funding lookback. Contracts we can not size
+// the hedge of are left out, and get no funding
+func (a AssetServer) contractHedges() ([]contractHedge, error) {
+	contracts, err := listContracts(a.db)
+	if err != nil {
+		return nil, fmt.Errorf("could not extract contracts from db: %w", err)
+	}
+
+	err = a.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(closedContractsBucket).ForEach(func(k, v []byte) error {
+			var contract larpc.ServerContract
+			err := json.Unmarshal(v, &contract)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal closed contract: %w", err)
+			}
+
+			contracts = append(contracts, contract)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hedges []contractHedge
+	for _, contract := range contracts {
+		if !contractIsOpen(contract) {
+			continue
+		}
+
+		symbol, amount, err := a.hedgeOrder(contract)
+		if err != nil {
+			log.WithError(err).WithField("uuid", contract.Uuid).
+				Warn("could not size hedge of contract, not allocating funding to it")
+			continue
+		}
+
+		held := contractHedge{
+			uuid:   contract.Uuid,
+			symbol: symbol,
+			qty:    math.Abs(amount),
+		}
+		if contract.OpenedAt != nil {
+			held.openedAt, err = ptypes.Timestamp(contract.OpenedAt)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if contract.ClosedAt != nil {
+			held.closedAt, err = ptypes.Timestamp(contract.ClosedAt)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		hedges = append(hedges, held)
+	}
+
+	return hedges, nil
+}
+
+// exposureAt returns the exposure of every contract we held a hedge of in
+// the instrument at the given time, by contract uuid
+func exposureAt(hedges []contractHedge, symbol string, t time.Time) map[string]float64 {
+	exposure := make(map[string]float64)
+	for _, held := range hedges {
+		if held.symbol == symbol && held.heldAt(t) {
+			exposure[held.uuid] = held.qty
+		}
+	}
+
+	return exposure
+}
+
+// putClosedContract saves a contract that is being closed, so funding paid
+// while it was open is allocated to it even if we record it afterwards.
+// Nothing is saved when our hedger pays no funding
+func (a AssetServer) putClosedContract(tx *bolt.Tx, contract larpc.ServerContract) error {
+	if _, ok := a.hedger.(hedge.FundingHedger); !ok {
+		return nil
+	}
+
+	var err error
+	contract.ClosedAt, err = ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return err
+	}
+
+	asByte, err := json.Marshal(contract)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(closedContractsBucket).Put([]byte(contract.Uuid), asByte)
+}
+
+// pruneClosedContracts deletes the contracts closed before the given time,
+// as we do not record funding that old
+func pruneClosedContracts(tx *bolt.Tx, before time.Time) error {
+	b := tx.Bucket(closedContractsBucket)
+
+	var expired [][]byte
+	err := b.ForEach(func(k, v []byte) error {
+		var contract larpc.ServerContract
+		err := json.Unmarshal(v, &contract)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal closed contract: %w", err)
+		}
+
+		closedAt, err := ptypes.Timestamp(contract.ClosedAt)
+		if err != nil || closedAt.Before(before) {
+			expired = append(expired, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range expired {
+		err = b.Delete(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newFundingPayment converts a funding payment to the record we save, with
+// the payment allocated to the contracts pro rata to their exposure.
+// Rounding is settled with the largest contract, so the allocations always
+// add up to the payment
+func newFundingPayment(payment hedge.FundingPayment, exposure map[string]float64) (*larpc.FundingPayment, error) {
+	timestamp, err := ptypes.TimestampProto(payment.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &larpc.FundingPayment{
+		Id:          payment.ID,
+		Venue:       payment.Venue,
+		Symbol:      payment.Symbol,
+		Timestamp:   timestamp,
+		Rate:        payment.Rate,
+		PositionQty: payment.PositionQty,
+		AmountSats:  payment.Amount,
+	}
+
+	var uuids []string
+	var total float64
+	for uuid, amount := range exposure {
+		uuids = append(uuids, uuid)
+		total += amount
+	}
+	if total == 0 {
+		return record, nil
+	}
+
+	// largest contracts first, so the remainder goes to the largest
+	sort.Slice(uuids, func(i, j int) bool {
+		if exposure[uuids[i]] != exposure[uuids[j]] {
+			return exposure[uuids[i]] > exposure[uuids[j]]
+		}
+		return uuids[i] < uuids[j]
+	})
+
+	var allocated int64
+	for _, uuid := range uuids {
+		amount := int64(math.Round(float64(payment.Amount) * exposure[uuid] / total))
+		record.Allocations = append(record.Allocations, &larpc.FundingAllocation{
+			ContractUuid: uuid,
+			AmountSats:   amount,
+		})
+		allocated += amount
+	}
+	record.Allocations[0].AmountSats += payment.Amount - allocated
+
+	return record, nil
+}
+
+// fundingKey is the key of a funding payment, sorted by time and unique per
+// venue
+func fundingKey(payment hedge.FundingPayment) []byte {
+	key := timeKey(payment.Time)
+	key = append(key, payment.Venue...)
+	key = append(key, ':')
+	return append(key, payment.ID...)
+}
+
+// putFundingPayment saves the payment, and adds its allocations to the
+// funding of every contract
+func putFundingPayment(tx *bolt.Tx, key []byte, payment *larpc.FundingPayment) error {
+	asByte, err := json.Marshal(payment)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Bucket(fundingBucket).Put(key, asByte)
+	if err != nil {
+		return err
+	}
+
+	for _, allocation := range payment.Allocations {
+		funding, err := getContractFunding(tx, allocation.ContractUuid)
+		if err != nil {
+			return err
+		}
+
+		funding.FundingSats += allocation.AmountSats
+		err = putContractFunding(tx, funding)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getContractFunding returns the funding allocated to the contract
+func getContractFunding(tx *bolt.Tx, contractUUID string) (*larpc.ContractFunding, error) {
+	funding := &larpc.ContractFunding{ContractUuid: contractUUID}
+
+	asByte := tx.Bucket(contractFundingBucket).Get([]byte(contractUUID))
+	if asByte == nil {
+		return funding, nil
+	}
+
+	err := json.Unmarshal(asByte, funding)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal contract funding: %w", err)
+	}
+
+	return funding, nil
+}
+
+func putContractFunding(tx *bolt.Tx, funding *larpc.ContractFunding) error {
+	asByte, err := json.Marshal(funding)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(contractFundingBucket).Put([]byte(funding.ContractUuid), asByte)
+}
+
+// fundingCharge returns how many satoshis of funding allocated to the
+// contract are yet to be charged to the client, negative if we owe the
+// client funding we received. It is always zero unless funding is charged
+// when rebalancing
+func (a AssetServer) fundingCharge(contractUUID string) (int64, error) {
+	if a.funding.policy != fundingCharge {
+		return 0, nil
+	}
+
+	var charge int64
+	err := a.db.View(func(tx *bolt.Tx) error {
+		funding, err := getContractFunding(tx, contractUUID)
+		if err != nil {
+			return err
+		}
+
+		charge = funding.FundingSats - funding.ChargedSats
+		return nil
+	})
+
+	return charge, err
+}
+
+// markFundingCharged records that funding was charged to the client of the
+// contract
+func (a AssetServer) markFundingCharged(contractUUID string, charged int64) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		funding, err := getContractFunding(tx, contractUUID)
+		if err != nil {
+			return err
+		}
+
+		funding.ChargedSats += charged
+		return putContractFunding(tx, funding)
+	})
+}
+
+func (a AdminServer) ListFundingPayments(ctx context.Context, req *larpc.AdminListFundingPaymentsRequest) (*larpc.AdminListFundingPaymentsResponse, error) {
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultFundingPaymentsLimit
+	}
+
+	res := &larpc.AdminListFundingPaymentsResponse{}
+	err := a.assets.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(fundingBucket).Cursor()
+
+		for k, v := c.Last(); k != nil && len(res.Payments) < limit; k, v = c.Prev() {
+			var payment larpc.FundingPayment
+			err := json.Unmarshal(v, &payment)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal funding payment: %w", err)
+			}
+
+			if req.Symbol != "" && payment.Symbol != req.Symbol {
+				continue
+			}
+			if req.ContractUuid != "" && !allocatedTo(&payment, req.ContractUuid) {
+				continue
+			}
+
+			res.Payments = append(res.Payments, &payment)
+		}
+
+		if req.ContractUuid == "" {
+			return nil
+		}
+
+		var err error
+		res.Contract, err = getContractFunding(tx, req.ContractUuid)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// allocatedTo returns whether any of the payment was allocated to the
+// contract
+func allocatedTo(payment *larpc.FundingPayment, contractUUID string) bool {
+	for _, allocation := range payment.Allocations {
+		if allocation.ContractUuid == contractUUID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+)
+
+func TestNewFundingPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		exposure map[string]float64
+		want     map[string]int64
+	}{
+		{
+			name:     "no contracts",
+			amount:   100,
+			exposure: map[string]float64{},
+			want:     map[string]int64{},
+		},
+		{
+			name:     "single contract",
+			amount:   100,
+			exposure: map[string]float64{"a": 500},
+			want:     map[string]int64{"a": 100},
+		},
+		{
+			name:     "pro rata",
+			amount:   100,
+			exposure: map[string]float64{"a": 200, "b": 100},
+			want:     map[string]int64{"a": 67, "b": 33},
+		},
+		{
+			name:     "remainder goes to the largest contract",
+			amount:   100,
+			exposure: map[string]float64{"a": 100, "b": 300, "c": 100},
+			want:     map[string]int64{"a": 20, "b": 60, "c": 20},
+		},
+		{
+			name:     "remainder of equal contracts goes to the first",
+			amount:   100,
+			exposure: map[string]float64{"c": 100, "b": 100, "a": 100},
+			want:     map[string]int64{"a": 34, "b": 33, "c": 33},
+		},
+		{
+			name:     "rounding up is settled too",
+			amount:   101,
+			exposure: map[string]float64{"a": 100, "b": 100},
+			want:     map[string]int64{"a": 50, "b": 51},
+		},
+		{
+			name:     "received funding",
+			amount:   -100,
+			exposure: map[string]float64{"a": 100, "b": 100, "c": 100},
+			want:     map[string]int64{"a": -34, "b": -33, "c": -33},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payment := hedge.FundingPayment{
+				ID:     "1",
+				Symbol: "XBTUSD",
+				Venue:  "bitmex",
+				Time:   time.Unix(1000, 0),
+				Amount: test.amount,
+			}
+
+			record, err := newFundingPayment(payment, test.exposure)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(record.Allocations) != len(test.want) {
+				t.Fatalf("%d allocations, want %d", len(record.Allocations), len(test.want))
+			}
+
+			var total int64
+			for _, allocation := range record.Allocations {
+				want, ok := test.want[allocation.ContractUuid]
+				if !ok {
+					t.Fatalf("allocated to unknown contract %s", allocation.ContractUuid)
+				}
+				if allocation.AmountSats != want {
+					t.Fatalf("allocated %d to %s, want %d", allocation.AmountSats, allocation.ContractUuid, want)
+				}
+				total += allocation.AmountSats
+			}
+			if len(test.want) > 0 && total != test.amount {
+				t.Fatalf("allocations add up to %d, want %d", total, test.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// Hedge orders for single contracts are not placed while the contract is
+// updated, as a failing exchange would then roll back the payment of the
+// client. Instead a job to place the order is saved in the same transaction,
+// and a worker places it afterwards, retrying until it succeeds.
+var (
+	// hedgeJobInterval is how often the worker looks for jobs to retry,
+	// when it is not told about new ones
+	hedgeJobInterval = time.Second
+	// hedgeJobMaxBackoff is the most time between two attempts at a job
+	hedgeJobMaxBackoff = 5 * time.Minute
+	// hedgeJobAlertAttempts is how many times a job can fail before we
+	// alert the operator
+	hedgeJobAlertAttempts int64 = 5
+	// hedgeJobMaxAttempts is how many times a job can fail before we give
+	// up on it. Jobs failing permanently are given up on right away
+	hedgeJobMaxAttempts int64 = 20
+)
+
+// newHedgeJob creates a job placing the hedge order of the contract. It is
+// sized with the hedge quantity fixed when the contract was quoted, so it
+// never depends on market data. Contracts quoted before that get a job
+// with no qty, which the worker sizes
+func (a AssetServer) newHedgeJob(contract larpc.ServerContract, side hedge.Side, reason string) (*larpc.HedgeJob, error) {
+	asset, ok := a.assets.lookup(contract.Asset)
+	if !ok {
+		return nil, fmt.Errorf("asset %s not supported", contract.Asset)
+	}
+
+	now, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	job := &larpc.HedgeJob{
+		ClientOrderId: uuid.New().String(),
+		ContractUuid:  contract.Uuid,
+		Symbol:        asset.Hedge,
+		Side:          string(side),
+		Qty:           contract.HedgeQty,
+		Reason:        reason,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+	if contract.HedgeQty == 0 {
+		job.Asset = contract.Asset
+		job.AssetAmount = contract.Amount
+	}
+
+	return job, nil
+}
+
+// putContractHedgeJob saves a job placing the hedge order of the contract
+// in the transaction
+func (a AssetServer) putContractHedgeJob(tx *bolt.Tx, contract larpc.ServerContract, side hedge.Side,
+	reason string) error {
+
+	job, err := a.newHedgeJob(contract, side, reason)
+	if err != nil {
+		return err
+	}
+
+	if job.Qty != 0 {
+		placed, err := a.roundHedgeJob(tx, job, signedQty(hedge.Side(job.Side), job.Qty))
+		if err != nil || !placed {
+			return err
+		}
+	}
+
+	return putHedgeJob(tx, job)
+}
+
+// roundHedgeJob sizes the job in whole lots of the instrument, from qty
+// together with the residual left unhedged by earlier contracts. Whatever
+// does not add up to a lot is kept as the new residual. It returns false
+// if nothing does, and there is no order to place
+func (a AssetServer) roundHedgeJob(tx *bolt.Tx, job *larpc.HedgeJob, qty float64) (bool, error) {
+	residual, err := getHedgeResidual(tx, job.Symbol)
+	if err != nil {
+		return false, err
+	}
+
+	order, rest := a.assets.lotSizes()[job.Symbol].Round(residual.Qty + qty)
+
+	logger := log.WithFields(logrus.Fields{
+		"uuid":     job.ContractUuid,
+		"symbol":   job.Symbol,
+		"qty":      qty,
+		"order":    order,
+		"residual": rest,
+	})
+
+	residual.Qty = rest
+	err = putHedgeResidual(tx, residual)
+	if err != nil {
+		return false, err
+	}
+
+	if order == 0 {
+		logger.Info("hedge of contract is less than a lot, adding it to the residual")
+		return false, nil
+	}
+	if order != qty {
+		logger.Debug("sized hedge of contract to whole lots")
+	}
+
+	job.Qty = math.Abs(order)
+	job.Side = string(hedge.Buy)
+	if order < 0 {
+		job.Side = string(hedge.Sell)
+	}
+
+	return true, nil
+}
+
+// sizeHedgeJob sizes a job saved with no qty from the asset amount of its
+// contract, at our current exchange rates. It returns false if the job was
+// less than a lot, and is done
+func (a AssetServer) sizeHedgeJob(job *larpc.HedgeJob) (bool, error) {
+	_, qty, err := a.hedgeOrder(larpc.ServerContract{
+		Asset:  job.Asset,
+		Amount: job.AssetAmount,
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not size hedge of contract: %w", err)
+	}
+
+	key, err := hedgeJobKey(job)
+	if err != nil {
+		return false, err
+	}
+
+	side := job.Side
+	var placed bool
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		placed, err = a.roundHedgeJob(tx, job, signedQty(hedge.Side(side), qty))
+		if err != nil {
+			return err
+		}
+		if !placed {
+			return tx.Bucket(hedgeJobsBucket).Delete(key)
+		}
+
+		return putHedgeJob(tx, job)
+	})
+	if err != nil {
+		// the job is retried unsized
+		job.Qty = 0
+		job.Side = side
+		return false, fmt.Errorf("could not save sized hedge job: %w", err)
+	}
+
+	return placed, nil
+}
+
+// signedQty returns qty as a change in exposure, negative if it sells
+func signedQty(side hedge.Side, qty float64) float64 {
+	if side == hedge.Sell {
+		return -qty
+	}
+
+	return qty
+}
+
+// getHedgeResidual returns the exposure to the instrument left unhedged by
+// contracts hedged on their own
+func getHedgeResidual(tx *bolt.Tx, symbol string) (*larpc.HedgeResidual, error) {
+	residual := &larpc.HedgeResidual{Symbol: symbol}
+
+	asByte := tx.Bucket(hedgeResidualsBucket).Get([]byte(symbol))
+	if asByte == nil {
+		return residual, nil
+	}
+
+	err := json.Unmarshal(asByte, residual)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal hedge residual: %w", err)
+	}
+
+	return residual, nil
+}
+
+func putHedgeResidual(tx *bolt.Tx, residual *larpc.HedgeResidual) error {
+	asByte, err := json.Marshal(residual)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(hedgeResidualsBucket).Put([]byte(residual.Symbol), asByte)
+}
+
+// hedgeResiduals returns the exposure to every hedge instrument left
+// unhedged because it is less than a lot, sorted by symbol. When hedging
+// net exposure, it is what the net hedger left unhedged when it last
+// adjusted our positions
+func (a AssetServer) hedgeResiduals() ([]*larpc.HedgeResidual, error) {
+	var residuals []*larpc.HedgeResidual
+	for _, symbol := range a.assets.hedgeSymbols() {
+		residual := &larpc.HedgeResidual{Symbol: symbol}
+
+		if a.netHedger != nil {
+			residual.Qty = a.netHedger.engine.Residuals()[symbol]
+		} else {
+			err := a.db.View(func(tx *bolt.Tx) error {
+				var err error
+				residual, err = getHedgeResidual(tx, symbol)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		residuals = append(residuals, residual)
+	}
+
+	return residuals, nil
+}
+
+// hedgeJobKey returns the key of the job, which sorts jobs by when they
+// were created
+func hedgeJobKey(job *larpc.HedgeJob) ([]byte, error) {
+	createdAt, err := ptypes.Timestamp(job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(timeKey(createdAt), job.ClientOrderId...), nil
+}
+
+// putHedgeJob saves the job in the transaction
+func putHedgeJob(tx *bolt.Tx, job *larpc.HedgeJob) error {
+	key, err := hedgeJobKey(job)
+	if err != nil {
+		return err
+	}
+
+	asByte, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(hedgeJobsBucket).Put(key, asByte)
+}
+
+// listHedgeJobs returns all jobs in the bucket, oldest first. Jobs that have
+// not completed are in the hedge jobs bucket, and those we gave up on in the
+// failed hedge jobs bucket
+func listHedgeJobs(db *bolt.DB, bucket []byte) ([]*larpc.HedgeJob, error) {
+	var jobs []*larpc.HedgeJob
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+			var job larpc.HedgeJob
+			if err := json.Unmarshal(v, &job); err != nil {
+				return fmt.Errorf("could not unmarshal hedge job %q: %w", string(v), err)
+			}
+
+			jobs = append(jobs, &job)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// pendingExposure returns how many contracts of each instrument the jobs
+// that have not completed yet will buy, negative if they sell
+func pendingExposure(jobs []*larpc.HedgeJob) map[string]float64 {
+	pending := make(map[string]float64)
+	for _, job := range jobs {
+		if job.Side == string(hedge.Sell) {
+			pending[job.Symbol] -= job.Qty
+		} else {
+			pending[job.Symbol] += job.Qty
+		}
+	}
+
+	return pending
+}
+
+// notifyHedgeJobs tells the worker that new jobs were saved. It never blocks
+func (a AssetServer) notifyHedgeJobs() {
+	select {
+	case a.hedgeJobsCh <- struct{}{}:
+	default:
+	}
+}
+
+// runHedgeJobs places the orders of all jobs that are due, when told about
+// new jobs and every hedgeJobInterval. Jobs are run one at a time, oldest
+// first
+// NOTE: MUST be run in a goroutine
+func (a AssetServer) runHedgeJobs() {
+	ticker := time.NewTicker(hedgeJobInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.hedgeJobsCh:
+		case <-ticker.C:
+		}
+
+		jobs, err := listHedgeJobs(a.db, hedgeJobsBucket)
+		if err != nil {
+			log.WithError(err).Error("could not list hedge jobs")
+			continue
+		}
+
+		now := time.Now()
+		for _, job := range jobs {
+			nextAttemptAt, err := ptypes.Timestamp(job.NextAttemptAt)
+			if err == nil && nextAttemptAt.After(now) {
+				continue
+			}
+
+			a.hedgeJobsMu.Lock()
+			err = a.runHedgeJob(job)
+			a.hedgeJobsMu.Unlock()
+			if err != nil {
+				log.WithError(err).WithField("clientOrderID", job.ClientOrderId).
+					Error("could not update hedge job")
+			}
+		}
+	}
+}
+
+// runHedgeJob places the order of the job, unless an earlier attempt
+// already placed it. When the order is placed the job is replaced by the
+// order, otherwise the job is scheduled for another attempt
+func (a AssetServer) runHedgeJob(job *larpc.HedgeJob) error {
+	logger := log.WithFields(logrus.Fields{
+		"uuid":          job.ContractUuid,
+		"clientOrderID": job.ClientOrderId,
+		"symbol":        job.Symbol,
+		"side":          job.Side,
+		"qty":           job.Qty,
+	})
+
+	if job.Qty == 0 {
+		placed, err := a.sizeHedgeJob(job)
+		if err != nil {
+			return a.retryHedgeJob(job, err)
+		}
+		if !placed {
+			return nil
+		}
+		logger = logger.WithField("qty", job.Qty)
+	}
+
+	// an earlier attempt may have placed the order without us learning it
+	order, found, err := a.hedger.LookupOrder(job.ClientOrderId, job.Symbol)
+	if err == nil && !found {
+		order, err = a.hedger.PlaceOrder(job.ClientOrderId, job.Symbol, hedge.Side(job.Side), job.Qty)
+	}
+	if err != nil {
+		return a.retryHedgeJob(job, err)
+	}
+
+	record, err := a.newHedgeOrder(order, job.ContractUuid, job.Reason)
+	if err != nil {
+		return err
+	}
+
+	key, err := hedgeJobKey(job)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		err := putHedgeOrder(tx, record)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(hedgeJobsBucket).Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("could not complete hedge job: %w", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"hedger":   record.Hedger,
+		"orderID":  order.ID,
+		"attempts": job.Attempts + 1,
+	}).Info("placed hedge order for contract")
+
+	return nil
+}
+
+// retryHedgeJob records that an attempt at the job failed, and schedules
+// the next attempt with exponential backoff. If the venue rate limited us,
+// we wait at least as long as it asked. Jobs that failed permanently, or
+// too many times, are given up on instead
+func (a AssetServer) retryHedgeJob(job *larpc.HedgeJob, cause error) error {
+	job.Attempts++
+	job.LastError = cause.Error()
+
+	if hedge.Permanent(cause) || job.Attempts >= hedgeJobMaxAttempts {
+		return a.failHedgeJob(job, cause)
+	}
+
+	backoff := time.Duration(math.Min(
+		float64(time.Second)*math.Pow(2, float64(job.Attempts-1)),
+		float64(hedgeJobMaxBackoff)))
+	if retryAfter := hedge.RetryAfter(cause); retryAfter > backoff {
+		backoff = retryAfter
+	}
+	next, err := ptypes.TimestampProto(time.Now().Add(backoff))
+	if err != nil {
+		return err
+	}
+	job.NextAttemptAt = next
+
+	logger := log.WithError(cause).WithFields(logrus.Fields{
+		"uuid":          job.ContractUuid,
+		"clientOrderID": job.ClientOrderId,
+		"attempts":      job.Attempts,
+		"retryIn":       backoff,
+	})
+	if job.Attempts == hedgeJobAlertAttempts {
+		logger.Error("ALERT: hedge order keeps failing, contract is not hedged")
+	} else {
+		logger.Warn("could not place hedge order, retrying")
+	}
+
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return putHedgeJob(tx, job)
+	})
+}
+
+// failHedgeJob gives up on the job, moving it to the failed hedge jobs
+// bucket where the operator can find it
+func (a AssetServer) failHedgeJob(job *larpc.HedgeJob, cause error) error {
+	key, err := hedgeJobKey(job)
+	if err != nil {
+		return err
+	}
+
+	job.FailedAt, err = ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return err
+	}
+	job.NextAttemptAt = nil
+
+	asByte, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(failedHedgeJobsBucket).Put(key, asByte)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(hedgeJobsBucket).Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("could not save failed hedge job: %w", err)
+	}
+
+	log.WithError(cause).WithFields(logrus.Fields{
+		"uuid":          job.ContractUuid,
+		"clientOrderID": job.ClientOrderId,
+		"symbol":        job.Symbol,
+		"side":          job.Side,
+		"qty":           job.Qty,
+		"attempts":      job.Attempts,
+		"permanent":     hedge.Permanent(cause),
+	}).Error("ALERT: gave up placing hedge order, contract is not hedged")
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// rateLimitedError is a venue error asking us to wait before retrying
+type rateLimitedError struct {
+	retryAfter time.Duration
+}
+
+func (e rateLimitedError) Error() string {
+	return "rate limited"
+}
+
+func (e rateLimitedError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// stubHedger fails every order with err, and reports the orders it placed
+type stubHedger struct {
+	hedge.Hedger
+
+	err    error
+	found  bool
+	placed int
+}
+
+func (h *stubHedger) Name() string {
+	return "stub"
+}
+
+func (h *stubHedger) PlaceOrder(clientID, symbol string, side hedge.Side, qty float64) (hedge.Order, error) {
+	if h.err != nil {
+		return hedge.Order{}, h.err
+	}
+	h.placed++
+
+	return hedge.Order{ID: "placed", ClientID: clientID, Symbol: symbol, Side: side, Qty: qty}, nil
+}
+
+func (h *stubHedger) LookupOrder(clientID, symbol string) (hedge.Order, bool, error) {
+	if !h.found {
+		return hedge.Order{}, false, nil
+	}
+
+	return hedge.Order{ID: "found", ClientID: clientID, Symbol: symbol, Side: hedge.Buy, Qty: 100}, true, nil
+}
+
+// newTestHedgeJob saves a job buying 100 contracts of XBTUSD, which failed
+// the given number of times
+func newTestHedgeJob(t *testing.T, db *bolt.DB, attempts int64) *larpc.HedgeJob {
+	now, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := &larpc.HedgeJob{
+		ClientOrderId: "job",
+		ContractUuid:  "contract",
+		Symbol:        "XBTUSD",
+		Side:          string(hedge.Buy),
+		Qty:           100,
+		Reason:        "open",
+		Attempts:      attempts,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		return putHedgeJob(tx, job)
+	})
+	if err != nil {
+		t.Fatalf("could not save hedge job: %v", err)
+	}
+
+	return job
+}
+
+func TestRetryHedgeJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int64
+		cause    error
+		backoff  time.Duration
+		failed   bool
+	}{
+		{name: "first failure", cause: errors.New("timeout"), backoff: time.Second},
+		{name: "backoff doubles", attempts: 3, cause: errors.New("timeout"), backoff: 8 * time.Second},
+		{name: "backoff is capped", attempts: 15, cause: errors.New("timeout"), backoff: hedgeJobMaxBackoff},
+		{
+			name:    "venue asks us to wait longer",
+			cause:   fmt.Errorf("could not place order: %w", rateLimitedError{retryAfter: 30 * time.Second}),
+			backoff: 30 * time.Second,
+		},
+		{
+			name:     "venue asks us to wait less than the backoff",
+			attempts: 4,
+			cause:    rateLimitedError{retryAfter: time.Second},
+			backoff:  16 * time.Second,
+		},
+		{
+			name:   "permanent failure",
+			cause:  fmt.Errorf("could not place order: %w", bitmex.ErrInsufficientFunds),
+			failed: true,
+		},
+		{name: "too many attempts", attempts: hedgeJobMaxAttempts - 1, cause: errors.New("timeout"), failed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, cleanup := newTestDB(t)
+			defer cleanup()
+
+			a := AssetServer{db: db}
+			job := newTestHedgeJob(t, db, test.attempts)
+
+			start := time.Now()
+			err := a.retryHedgeJob(job, test.cause)
+			if err != nil {
+				t.Fatalf("could not retry hedge job: %v", err)
+			}
+
+			jobs, err := listHedgeJobs(db, hedgeJobsBucket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			failed, err := listHedgeJobs(db, failedHedgeJobsBucket)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.failed {
+				if len(jobs) != 0 || len(failed) != 1 {
+					t.Fatalf("got %d jobs and %d failed jobs, want the job to fail", len(jobs), len(failed))
+				}
+				if failed[0].FailedAt == nil || failed[0].NextAttemptAt != nil {
+					t.Fatalf("failed job is still scheduled: %+v", failed[0])
+				}
+				if failed[0].Attempts != test.attempts+1 || failed[0].LastError != test.cause.Error() {
+					t.Fatalf("failed job has %d attempts and error %q", failed[0].Attempts, failed[0].LastError)
+				}
+				return
+			}
+
+			if len(jobs) != 1 || len(failed) != 0 {
+				t.Fatalf("got %d jobs and %d failed jobs, want the job to be retried", len(jobs), len(failed))
+			}
+			if jobs[0].Attempts != test.attempts+1 || jobs[0].LastError != test.cause.Error() {
+				t.Fatalf("job has %d attempts and error %q", jobs[0].Attempts, jobs[0].LastError)
+			}
+
+			next, err := ptypes.Timestamp(jobs[0].NextAttemptAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.Before(start.Add(test.backoff)) || next.After(time.Now().Add(test.backoff)) {
+				t.Fatalf("next attempt in %v, want %v", next.Sub(start), test.backoff)
+			}
+		})
+	}
+}
+
+func TestRunHedgeJob(t *testing.T) {
+	tests := []struct {
+		name   string
+		hedger *stubHedger
+		// order is the id of the order the job completes with, empty if
+		// it is retried
+		order  string
+		placed int
+	}{
+		{name: "placed", hedger: &stubHedger{}, order: "placed", placed: 1},
+		{name: "placed by an earlier attempt", hedger: &stubHedger{found: true}, order: "found"},
+		{name: "failed", hedger: &stubHedger{err: errors.New("timeout")}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, cleanup := newTestDB(t)
+			defer cleanup()
+
+			a := AssetServer{db: db, hedger: test.hedger}
+			job := newTestHedgeJob(t, db, 0)
+
+			err := a.runHedgeJob(job)
+			if err != nil {
+				t.Fatalf("could not run hedge job: %v", err)
+			}
+			if test.hedger.placed != test.placed {
+				t.Fatalf("placed %d orders, want %d", test.hedger.placed, test.placed)
+			}
+
+			jobs, err := listHedgeJobs(db, hedgeJobsBucket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := AdminServer{assets: a}.ListHedgeOrders(nil, &larpc.AdminListHedgeOrdersRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			orders := res.Orders
+
+			if test.order == "" {
+				if len(jobs) != 1 || jobs[0].Attempts != 1 || len(orders) != 0 {
+					t.Fatalf("got jobs %v and orders %v, want the job to be retried", jobs, orders)
+				}
+				return
+			}
+
+			if len(jobs) != 0 {
+				t.Fatalf("job was not completed: %v", jobs)
+			}
+			if len(orders) != 1 {
+				t.Fatalf("got %d orders, want 1", len(orders))
+			}
+			order := orders[0]
+			if order.OrderId != test.order || order.Hedger != "stub" || order.ContractUuid != "contract" ||
+				order.Reason != "open" || order.Qty != 100 {
+				t.Fatalf("unexpected order %+v", order)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// why hedge orders were placed
+const (
+	hedgeReasonOpen  = "open"
+	hedgeReasonClose = "close"
+	hedgeReasonNet   = "net"
+	hedgeReasonDrift = "drift"
+)
+
+const defaultHedgeOrdersLimit = 100
+
+// newHedgeOrder converts an order placed by our hedger to the record we
+// save. contractUUID is empty for orders not placed for a single contract
+func (a AssetServer) newHedgeOrder(order hedge.Order, contractUUID, reason string) (*larpc.HedgeOrder, error) {
+	timestamp, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// orders routed to one of several venues are recorded with the venue
+	// they were placed on
+	hedger := a.hedger.Name()
+	if order.Venue != "" {
+		hedger = order.Venue
+	}
+
+	return &larpc.HedgeOrder{
+		OrderId:      order.ID,
+		Hedger:       hedger,
+		Symbol:       order.Symbol,
+		Side:         string(order.Side),
+		Qty:          order.Qty,
+		FillPrice:    order.Price,
+		FeeSats:      order.Fee,
+		ContractUuid: contractUUID,
+		Reason:       reason,
+		Timestamp:    timestamp,
+	}, nil
+}
+
+// putHedgeOrder saves the order in the transaction, keyed by its timestamp
+// followed by the order id, so orders are sorted by time. Fills of the
+// order we have already saved are applied to it first
+func putHedgeOrder(tx *bolt.Tx, order *larpc.HedgeOrder) error {
+	timestamp, err := ptypes.Timestamp(order.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	err = applyFills(tx, order)
+	if err != nil {
+		return err
+	}
+
+	asByte, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+
+	key := append(timeKey(timestamp), order.OrderId...)
+	err = tx.Bucket(hedgeOrdersBucket).Put(key, asByte)
+	if err != nil {
+		return err
+	}
+
+	if order.OrderId == "" {
+		return nil
+	}
+
+	// fills are reported by order id, so we can find the order they belong
+	// to
+	return tx.Bucket(hedgeOrderKeysBucket).Put(hedgeOrderID(order.Hedger, order.OrderId), key)
+}
+
+// saveHedgeOrders saves orders placed by our hedger
+func (a AssetServer) saveHedgeOrders(orders []hedge.Order, contractUUID, reason string) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	return a.db.Update(func(tx *bolt.Tx) error {
+		for _, order := range orders {
+			record, err := a.newHedgeOrder(order, contractUUID, reason)
+			if err != nil {
+				return err
+			}
+
+			err = putHedgeOrder(tx, record)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (a AdminServer) ListHedgeOrders(ctx context.Context, req *larpc.AdminListHedgeOrdersRequest) (*larpc.AdminListHedgeOrdersResponse, error) {
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultHedgeOrdersLimit
+	}
+
+	res := &larpc.AdminListHedgeOrdersResponse{}
+	err := a.assets.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(hedgeOrdersBucket).Cursor()
+
+		for k, v := c.Last(); k != nil && len(res.Orders) < limit; k, v = c.Prev() {
+			var order larpc.HedgeOrder
+			err := json.Unmarshal(v, &order)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal hedge order: %w", err)
+			}
+
+			if req.ContractUuid == "" || order.ContractUuid == req.ContractUuid {
+				res.Orders = append(res.Orders, &order)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
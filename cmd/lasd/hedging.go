@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+const (
+	// hedgeModeContract hedges every contract with its own orders when it
+	// is opened and closed
+	hedgeModeContract = "contract"
+	// hedgeModeNet keeps a single position per instrument matching the net
+	// exposure of all open contracts
+	hedgeModeNet = "net"
+)
+
+// netHedger adjusts our positions to the net exposure of all open contracts
+type netHedger struct {
+	engine *hedge.Engine
+	// interval is how often positions are adjusted, regardless of how far
+	// off they are
+	interval time.Duration
+	// threshold is how many contracts a position can be off before it is
+	// adjusted right away when contracts are opened or closed
+	threshold float64
+	// changed is signalled when contracts are opened or closed
+	changed chan struct{}
+}
+
+func newNetHedger(engine *hedge.Engine, interval time.Duration, threshold float64) *netHedger {
+	return &netHedger{
+		engine:    engine,
+		interval:  interval,
+		threshold: threshold,
+		changed:   make(chan struct{}, 1),
+	}
+}
+
+// contractsChanged tells the net hedger that contracts were opened or
+// closed. It never blocks
+func (n *netHedger) contractsChanged() {
+	select {
+	case n.changed <- struct{}{}:
+	default:
+	}
+}
+
+// openHedge saves a job buying the exposure of a contract that was just
+// opened, in the transaction the contract is opened in. The order is placed
+// by the hedge job worker. When hedging net exposure, nothing is traded
+// until the net hedger runs
+func (a AssetServer) openHedge(tx *bolt.Tx, contract larpc.ServerContract) error {
+	if a.netHedger != nil {
+		return nil
+	}
+
+	return a.putContractHedgeJob(tx, contract, hedge.Buy, hedgeReasonOpen)
+}
+
+// closeHedge saves a job selling the exposure of a contract that is being
+// closed, in the transaction the contract is closed in. When hedging net
+// exposure, nothing is traded until the net hedger runs
+func (a AssetServer) closeHedge(tx *bolt.Tx, contract larpc.ServerContract) error {
+	if a.netHedger != nil {
+		return nil
+	}
+
+	return a.putContractHedgeJob(tx, contract, hedge.Sell, hedgeReasonClose)
+}
+
+// netExposure returns how many contracts of each hedge instrument we should
+// hold to hedge all open contracts. Every hedge instrument is included, so
+// positions are closed when no contracts are left
+func (a AssetServer) netExposure() (map[string]float64, error) {
+	exposure := make(map[string]float64)
+	for _, asset := range a.assets.Assets {
+		exposure[asset.Hedge] = 0
+	}
+
+	contracts, err := listContracts(a.db)
+	if err != nil {
+		return nil, fmt.Errorf("could not extract contracts from db: %w", err)
+	}
+
+	for _, contract := range contracts {
+		if !contractIsOpen(contract) {
+			continue
+		}
+
+		symbol, amount, err := a.hedgeOrder(contract)
+		if err != nil {
+			return nil, fmt.Errorf("could not size hedge of contract %s: %w", contract.Uuid, err)
+		}
+		exposure[symbol] += amount
+	}
+
+	return exposure, nil
+}
+
+// hedgeNetExposure adjusts our positions to the net exposure of all open
+// contracts every interval, and right away when contracts are opened or
+// closed and a position is more than the threshold off
+// NOTE: MUST be run in a goroutine
+func (a AssetServer) hedgeNetExposure() {
+	ticker := time.NewTicker(a.netHedger.interval)
+	defer ticker.Stop()
+
+	for {
+		var threshold float64
+		select {
+		case <-a.netHedger.changed:
+			threshold = a.netHedger.threshold
+		case <-ticker.C:
+		}
+
+		exposure, err := a.netExposure()
+		if err != nil {
+			log.WithError(err).Error("could not compute net exposure")
+			continue
+		}
+
+		// orders placed before an error are saved as well
+		orders, err := a.netHedger.engine.Adjust(exposure, threshold)
+		if err != nil {
+			log.WithError(err).Error("could not hedge net exposure")
+		}
+
+		err = a.saveHedgeOrders(orders, "", hedgeReasonNet)
+		if err != nil {
+			log.WithError(err).Error("could not save hedge orders")
+		}
+	}
+}
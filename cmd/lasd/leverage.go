@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// what happens to contracts that would take our hedge past its leverage
+// limit
+const (
+	leverageReject = "reject"
+	// leverageCap opens the contract for as much as fits within the limit
+	leverageCap = "cap"
+)
+
+var (
+	// defaultMaxLeverage of zero does not limit our leverage
+	defaultMaxLeverage    = 0.0
+	defaultLeverageAction = leverageReject
+)
+
+var ErrMaxLeverage = errors.New("not enough exchange margin to hedge contract")
+
+// leverageLimit keeps the exposure of our hedges within a multiple of the
+// balance of our exchange accounts
+type leverageLimit struct {
+	// max is the most our hedge exposure can be, as a multiple of our
+	// wallet balance. Zero is no limit
+	max    float64
+	action string
+}
+
+func newLeverageLimit(max float64, action string) (leverageLimit, error) {
+	switch action {
+	case leverageReject, leverageCap:
+	default:
+		return leverageLimit{}, fmt.Errorf("unknown leverage action %q", action)
+	}
+	if max < 0 {
+		return leverageLimit{}, fmt.Errorf("max leverage can not be negative, was %f", max)
+	}
+
+	return leverageLimit{max: max, action: action}, nil
+}
+
+// checkLeverage returns how much of the asset the contract can be opened
+// for, without taking our hedge leverage past its limit or hedging it with
+// more margin than we have available. Contracts that do not fit are
+// rejected with ErrMaxLeverage, or capped to what fits. Only open
+// contracts count towards our exposure, and every hedge instrument is
+// treated as an inverse contract
+func (a AssetServer) checkLeverage(contract larpc.ServerContract) (float64, error) {
+	if a.leverage.max == 0 {
+		return contract.Amount, nil
+	}
+
+	exposure, err := a.netExposure()
+	if err != nil {
+		return 0, err
+	}
+
+	symbol, amount, err := a.hedgeOrder(contract)
+	if err != nil {
+		return 0, fmt.Errorf("could not size hedge of contract: %w", err)
+	}
+
+	// the value of our hedges in satoshis, before and after the contract
+	var current float64
+	for exposureSymbol, qty := range exposure {
+		value, err := a.hedgeValueSats(exposureSymbol, qty)
+		if err != nil {
+			return 0, err
+		}
+		current += value
+	}
+	before, err := a.hedgeValueSats(symbol, exposure[symbol])
+	if err != nil {
+		return 0, err
+	}
+	after, err := a.hedgeValueSats(symbol, exposure[symbol]+amount)
+	if err != nil {
+		return 0, err
+	}
+	added := after - before
+	if added <= 0 {
+		// the contract reduces our exposure
+		return contract.Amount, nil
+	}
+
+	margin, err := a.hedger.Margin()
+	if err != nil {
+		return 0, fmt.Errorf("could not get exchange margin: %w", err)
+	}
+
+	// we can add as much exposure as keeps us within the leverage limit,
+	// and as our available margin covers at that leverage
+	room := math.Min(
+		a.leverage.max*float64(margin.WalletBalance)-current,
+		a.leverage.max*float64(margin.AvailableMargin),
+	)
+
+	logger := log.WithFields(logrus.Fields{
+		"uuid":            contract.Uuid,
+		"symbol":          symbol,
+		"exposureSats":    int64(current),
+		"addedSats":       int64(added),
+		"walletBalance":   margin.WalletBalance,
+		"availableMargin": margin.AvailableMargin,
+		"maxLeverage":     a.leverage.max,
+	})
+
+	if added <= room {
+		return contract.Amount, nil
+	}
+
+	if a.leverage.action == leverageReject || room <= 0 {
+		logger.Warn("rejecting contract, not enough exchange margin to hedge it")
+		return 0, fmt.Errorf("%s contract would take our hedge leverage to %.2fx: %w",
+			contract.Asset, (current+added)/float64(margin.WalletBalance), ErrMaxLeverage)
+	}
+
+	capped := contract.Amount * room / added
+	logger.WithField("capped", capped).Warn("capping contract, not enough exchange margin to hedge all of it")
+
+	return capped, nil
+}
+
+// hedgeValueSats returns the value in satoshis of qty contracts of the
+// instrument. Our config only allows inverse hedge instruments, whose
+// contracts are worth one unit of their quote currency
+func (a AssetServer) hedgeValueSats(symbol string, qty float64) (float64, error) {
+	if qty == 0 {
+		return 0, nil
+	}
+
+	price, err := a.priceOracle.LatestPrice(symbol)
+	if err != nil {
+		return 0, fmt.Errorf("could not get %s price: %w", symbol, err)
+	}
+	if price.Value <= 0 {
+		return 0, fmt.Errorf("%s has invalid price %f", symbol, price.Value)
+	}
+
+	return math.Abs(qty) / price.Value * btcutil.SatoshiPerBitcoin, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/ArcaneCryptoAS/lassets-server/fx"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// staticOracle always reports the same prices
+type staticOracle struct {
+	oracle.Notifier
+	prices map[string]float64
+}
+
+func (o *staticOracle) LatestPrice(symbol string) (oracle.Price, error) {
+	value, ok := o.prices[symbol]
+	if !ok {
+		return oracle.Price{}, oracle.ErrNoPrice
+	}
+
+	return oracle.Price{Symbol: symbol, Value: value, Timestamp: time.Now(), Source: "static"}, nil
+}
+
+// newTestDB opens a database in a temporary directory with all our
+// buckets, holding the open contracts with the given hedge quantities. The
+// returned function closes and deletes it
+func newTestDB(t *testing.T, hedgeQtys ...float64) (*bolt.DB, func()) {
+	dir, err := ioutil.TempDir("", "lasd")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, "test.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("could not open db: %v", err)
+	}
+	cleanup := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	err = createBucketsIfNotExist(db)
+	if err != nil {
+		cleanup()
+		t.Fatalf("could not create buckets: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(contractsBucket)
+
+		for i, qty := range hedgeQtys {
+			contract := larpc.ServerContract{
+				Uuid:         string(rune('a' + i)),
+				Asset:        "USD",
+				Amount:       qty,
+				HedgeQty:     qty,
+				ContractType: larpc.ContractType_UNFUNDED,
+				MarginPaid:   true,
+			}
+			asByte, err := json.Marshal(contract)
+			if err != nil {
+				return err
+			}
+			err = b.Put([]byte(contract.Uuid), asByte)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		t.Fatalf("could not save contracts: %v", err)
+	}
+
+	return db, cleanup
+}
+
+func TestCheckLeverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    float64
+		action string
+		// open are the hedge quantities of our open contracts
+		open   []float64
+		amount float64
+		want   float64
+		reject bool
+	}{
+		{
+			name:   "no limit",
+			max:    0,
+			action: leverageReject,
+			open:   []float64{100000},
+			amount: 100000,
+			want:   100000,
+		},
+		{
+			name:   "fits",
+			max:    2,
+			action: leverageReject,
+			open:   []float64{10000},
+			amount: 5000,
+			want:   5000,
+		},
+		{
+			name:   "fits exactly",
+			max:    2,
+			action: leverageReject,
+			open:   []float64{10000},
+			amount: 10000,
+			want:   10000,
+		},
+		{
+			name:   "rejected",
+			max:    2,
+			action: leverageReject,
+			open:   []float64{10000},
+			amount: 20000,
+			reject: true,
+		},
+		{
+			name:   "capped",
+			max:    2,
+			action: leverageCap,
+			open:   []float64{10000},
+			amount: 20000,
+			want:   10000,
+		},
+		{
+			name:   "no room to cap",
+			max:    2,
+			action: leverageCap,
+			open:   []float64{20000},
+			amount: 1000,
+			reject: true,
+		},
+		{
+			name:   "reducing exposure always fits",
+			max:    2,
+			action: leverageReject,
+			open:   []float64{30000},
+			amount: -5000,
+			want:   -5000,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leverage, err := newLeverageLimit(test.max, test.action)
+			if err != nil {
+				t.Fatalf("could not create leverage limit: %v", err)
+			}
+
+			assets := assetConfig{
+				Instruments: map[string]instrumentConfig{"XBTUSD": {Base: "BTC", Quote: "USD"}},
+				Assets:      []assetDefinition{{Name: "USD", Hedge: "XBTUSD"}},
+			}
+			if err := assets.parse(); err != nil {
+				t.Fatalf("could not parse assets: %v", err)
+			}
+
+			db, cleanup := newTestDB(t, test.open...)
+			defer cleanup()
+
+			// one bitcoin of margin, at a price of 10000 dollars
+			prices := &staticOracle{prices: map[string]float64{"XBTUSD": 10000}}
+			a := AssetServer{
+				db:          db,
+				assets:      assets,
+				priceOracle: prices,
+				converter:   fx.NewConverter(0),
+				hedger:      hedge.NewPaperHedger(prices, 100000000),
+				leverage:    leverage,
+			}
+
+			amount, err := a.checkLeverage(larpc.ServerContract{Uuid: "new", Asset: "USD", Amount: test.amount})
+			if test.reject {
+				if !errors.Is(err, ErrMaxLeverage) {
+					t.Fatalf("expected ErrMaxLeverage, got %v, %v", amount, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(amount-test.want) > 1e-6 {
+				t.Fatalf("contract opened for %f, want %f", amount, test.want)
+			}
+		})
+	}
+}
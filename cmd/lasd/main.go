@@ -12,11 +12,16 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
 	"github.com/ArcaneCryptoAS/lassets-server/build"
+	"github.com/ArcaneCryptoAS/lassets-server/deribit"
+	"github.com/ArcaneCryptoAS/lassets-server/fx"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
 	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
 	"github.com/ArcaneCryptoAS/lndutil"
 	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
@@ -30,26 +35,64 @@ import (
 
 var (
 	ErrContractNotOpen = errors.New("contract not open yet")
+	ErrStalePrice      = errors.New("price is too old")
 )
 
 var (
 	contractsBucket = []byte("contracts")
 	paymentsBucket  = []byte("payments")
-	defaultDBName   = "laserver.db"
+	// attestationsBucket holds every price we have signed, which are the
+	// prices contracts were rebalanced on
+	attestationsBucket = []byte("attestations")
+	// priceHistoryBucket holds every price we have accepted, and candles
+	// aggregated from them
+	priceHistoryBucket = []byte("pricehistory")
+	// hedgeDriftBucket holds every reconciliation of our hedges against our
+	// open contracts
+	hedgeDriftBucket = []byte("hedgedrift")
+	// hedgeOrdersBucket holds every order we placed to hedge contracts
+	hedgeOrdersBucket = []byte("hedgeorders")
+	// hedgeJobsBucket holds hedge orders we have yet to place
+	hedgeJobsBucket = []byte("hedgejobs")
+	// failedHedgeJobsBucket holds hedge orders we gave up placing
+	failedHedgeJobsBucket = []byte("failedhedgejobs")
+	// fundingBucket holds every funding payment on our hedge positions
+	fundingBucket = []byte("funding")
+	// contractFundingBucket holds the funding allocated to every contract
+	contractFundingBucket = []byte("contractfunding")
+	// closedContractsBucket holds contracts closed within our funding
+	// lookback, so funding paid before they were closed is allocated to
+	// them
+	closedContractsBucket = []byte("closedcontracts")
+	// hedgeResidualsBucket holds the exposure to every hedge instrument
+	// left unhedged because it is less than a lot
+	hedgeResidualsBucket = []byte("hedgeresiduals")
+	// hedgeFillsBucket holds every update on the execution of our hedge
+	// orders, keyed by venue and order id
+	hedgeFillsBucket = []byte("hedgefills")
+	// hedgeOrderKeysBucket maps the venue and id of every hedge order to
+	// its key in the hedge orders bucket
+	hedgeOrderKeysBucket = []byte("hedgeorderkeys")
+	defaultDBName        = "laserver.db"
 )
 
-var prices = map[string]float64{
-	"USD": 0.00,
-	"NOK": 0.00,
-}
-
 var (
 	defaultLadPort       = 10455
 	defaultRestPort      = 8080
+	defaultAdminListen   = "localhost:10456"
 	defaultLadDir        = cleanAndExpandPath("~/.las")
 	defaultNetwork       = "regtest"
 	defaultPercentMargin = 1.0
 
+	defaultPriceSources      = "bitmex"
+	defaultPriceQuorum       = 1
+	defaultPriceMaxDeviation = 1.0
+	defaultReplaySpeed       = 1.0
+	defaultMaxPriceAge       = time.Minute
+	// a 10% move in a single tick is far more likely to be a bad print
+	// than a real move
+	defaultCircuitBreakerPercent = 10.0
+
 	// this should be changed to lnd-path when we start deploying it to servers
 	defaultLndDir     = cleanAndExpandPath("~/.lnd")
 	defaultLndRpcPort = "localhost:10009"
@@ -57,18 +100,66 @@ var (
 
 // define possible flag names here
 const (
-	flag_port               = "port"
-	flag_rest_port          = "restport"
-	flag_laddir             = "laddir"
-	flag_network            = "network"
-	flag_lnddir             = "lnddir"
-	flag_lndrpchost         = "lndrpchost"
-	flag_percentmargin      = "percentmargin"
-	flag_insecure           = "insecure"
-	flag_breakafter         = "breakafter"
+	flag_port          = "port"
+	flag_rest_port     = "restport"
+	flag_adminlisten   = "adminlisten"
+	flag_laddir        = "laddir"
+	flag_network       = "network"
+	flag_lnddir        = "lnddir"
+	flag_lndrpchost    = "lndrpchost"
+	flag_percentmargin = "percentmargin"
+	flag_insecure      = "insecure"
+	flag_breakafter    = "breakafter"
+
+	flag_pricesources      = "pricesources"
+	flag_pricequorum       = "pricequorum"
+	flag_pricemaxdeviation = "pricemaxdeviation"
+	flag_pricefile         = "pricefile"
+	flag_replayfile        = "replayfile"
+	flag_replayspeed       = "replayspeed"
+	flag_replayloop        = "replayloop"
+	flag_fxurl             = "fxurl"
+	flag_fxmaxage          = "fxmaxage"
+	flag_maxpriceage       = "maxpriceage"
+	flag_circuitbreaker    = "circuitbreakerpercent"
+	flag_feedalertafter    = "feedalertafter"
+	flag_assetsconfig      = "assetsconfig"
+
+	flag_attestationretention = "attestationretention"
+	flag_tickretention        = "tickretention"
+
+	flag_rebalancebps           = "rebalancebps"
+	flag_minrebalanceinterval   = "minrebalanceinterval"
+	flag_forcerebalanceinterval = "forcerebalanceinterval"
 
 	flag_bitmexapikey    = "bitmexapikey"
 	flag_bitmexsecretkey = "bitmexsecretkey"
+	flag_bitmexresturl   = "bitmexresturl"
+	flag_bitmexwsurl     = "bitmexwsurl"
+
+	flag_deribitclientid     = "deribitclientid"
+	flag_deribitclientsecret = "deribitclientsecret"
+	flag_deribitresturl      = "deribitresturl"
+	flag_deribitwsurl        = "deribitwsurl"
+
+	flag_hedger           = "hedger"
+	flag_paperbalance     = "paperbalance"
+	flag_hedgemode        = "hedgemode"
+	flag_hedgeinterval    = "hedgeinterval"
+	flag_hedgethreshold   = "hedgethreshold"
+	flag_hedgeslippagebps = "hedgeslippagebps"
+	flag_venuecooldown    = "venuecooldown"
+
+	flag_reconcileinterval = "reconcileinterval"
+	flag_driftalert        = "driftalert"
+	flag_driftautocorrect  = "driftautocorrect"
+
+	flag_fundingpolicy        = "fundingpolicy"
+	flag_fundinginterval      = "fundinginterval"
+	flag_fundingmarginperiods = "fundingmarginperiods"
+
+	flag_maxleverage    = "maxleverage"
+	flag_leverageaction = "leverageaction"
 )
 
 var log = logrus.New()
@@ -90,6 +181,11 @@ func main() {
 			Value: defaultRestPort,
 			Usage: "port to run lightning asset rest server",
 		},
+		cli.StringFlag{
+			Name:  flag_adminlisten,
+			Value: defaultAdminListen,
+			Usage: "address to serve the admin grpc service on, separately from the public one. Anyone who can reach it can confirm prices and look at our hedges",
+		},
 		cli.StringFlag{
 			Name:  flag_laddir,
 			Usage: "the location of lad dir",
@@ -106,6 +202,100 @@ func main() {
 			Value: defaultPercentMargin,
 		},
 
+		// flags specific to pricing
+		cli.StringFlag{
+			Name: flag_assetsconfig,
+			Usage: "path to a JSON file defining the assets contracts can be opened in, and the instruments " +
+				"they are priced and hedged with. Defaults to USD and NOK, hedged with XBTUSD",
+		},
+		cli.StringFlag{
+			Name:  flag_pricesources,
+			Usage: "comma separated list of price sources to aggregate, bitmex | deribit | coinbase | bitstamp | kraken | file | replay | http(s) url",
+			Value: defaultPriceSources,
+		},
+		cli.IntFlag{
+			Name:  flag_pricequorum,
+			Usage: "how many price sources have to agree on a price before contracts are rebalanced",
+			Value: defaultPriceQuorum,
+		},
+		cli.Float64Flag{
+			Name:  flag_pricemaxdeviation,
+			Usage: "how many percent a price source can deviate from the median before it is discarded",
+			Value: defaultPriceMaxDeviation,
+		},
+		cli.StringFlag{
+			Name:  flag_pricefile,
+			Usage: "path to a JSON file on the form {\"XBTUSD\": 7350.5}, used by the file price source",
+		},
+		cli.StringFlag{
+			Name:  flag_replayfile,
+			Usage: "path to a CSV or JSON lines file of recorded ticks, used by the replay price source",
+		},
+		cli.Float64Flag{
+			Name:  flag_replayspeed,
+			Usage: "how many times faster than real time the replay price source replays ticks",
+			Value: defaultReplaySpeed,
+		},
+		cli.BoolFlag{
+			Name:  flag_replayloop,
+			Usage: "start over from the first tick when the replay price source reaches the end of the file",
+		},
+
+		cli.DurationFlag{
+			Name:  flag_attestationretention,
+			Usage: "how long to keep the prices we signed and rebalanced contracts on. 0 keeps them forever",
+			Value: defaultAttestationRetention,
+		},
+		cli.DurationFlag{
+			Name:  flag_tickretention,
+			Usage: "how long to keep every price tick. Candles are kept forever. 0 keeps ticks forever",
+			Value: defaultTickRetention,
+		},
+		cli.DurationFlag{
+			Name:  flag_maxpriceage,
+			Usage: "how old a price can be before no new contracts are accepted and rebalancing pauses",
+			Value: defaultMaxPriceAge,
+		},
+		cli.Float64Flag{
+			Name: flag_circuitbreaker,
+			Usage: "halt rebalancing when the price moves more than this many percent in a single tick, " +
+				"until confirmed by another price source or `lascli confirmprice`. 0 disables the circuit breaker",
+			Value: defaultCircuitBreakerPercent,
+		},
+		cli.DurationFlag{
+			Name:  flag_feedalertafter,
+			Usage: "how long a realtime price feed can be disconnected before the operator is alerted",
+			Value: defaultFeedAlertAfter,
+		},
+		cli.StringFlag{
+			Name:  flag_fxurl,
+			Value: fx.DefaultFeedURL,
+			Usage: "url of an exchangeratesapi.io compatible endpoint, used to convert between fiat currencies. " +
+				"Endpoints needing an api key take it in the url",
+		},
+		cli.DurationFlag{
+			Name:  flag_fxmaxage,
+			Usage: "how old an exchange rate can be before we stop converting with it",
+			Value: fx.DefaultMaxAge,
+		},
+
+		// flags specific to rebalancing, can be overridden per asset in --assetsconfig
+		cli.Float64Flag{
+			Name:  flag_rebalancebps,
+			Usage: "how many basis points the price of an asset has to move before contracts in it are rebalanced",
+			Value: defaultRebalanceBps,
+		},
+		cli.DurationFlag{
+			Name:  flag_minrebalanceinterval,
+			Usage: "the least time between two rebalances of contracts in an asset",
+			Value: defaultMinRebalanceInterval,
+		},
+		cli.DurationFlag{
+			Name:  flag_forcerebalanceinterval,
+			Usage: "rebalance contracts in an asset at least this often, even if the price did not move. 0 disables it",
+			Value: defaultForceRebalanceInterval,
+		},
+
 		// flags specific to connecting to lnd
 		cli.StringFlag{
 			Name:  flag_lnddir,
@@ -132,6 +322,118 @@ func main() {
 			Usage:  "secret key for bitmex user. This should not be passed as cli flag, but as an environment variable",
 			EnvVar: "BITMEX_SECRET_KEY",
 		},
+		cli.StringFlag{
+			Name: flag_bitmexresturl,
+			Usage: "url of the bitmex REST api used for trading. Defaults to mainnet or testnet bitmex " +
+				"depending on --network, and a local mockexchange on regtest",
+		},
+		cli.StringFlag{
+			Name: flag_bitmexwsurl,
+			Usage: "url of the bitmex realtime api used for prices. Defaults to mainnet or testnet bitmex " +
+				"depending on --network, and a local mockexchange on regtest",
+		},
+
+		cli.StringFlag{
+			Name:   flag_deribitclientid,
+			Usage:  "client id of the deribit api key. This should not be passed as cli flag, but as an environment variable",
+			EnvVar: "DERIBIT_CLIENT_ID",
+		},
+		cli.StringFlag{
+			Name:   flag_deribitclientsecret,
+			Usage:  "client secret of the deribit api key. This should not be passed as cli flag, but as an environment variable",
+			EnvVar: "DERIBIT_CLIENT_SECRET",
+		},
+		cli.StringFlag{
+			Name: flag_deribitresturl,
+			Usage: "url of the deribit REST api used for trading. Defaults to mainnet or testnet deribit " +
+				"depending on --network, and a local mockderibit on regtest",
+		},
+		cli.StringFlag{
+			Name: flag_deribitwsurl,
+			Usage: "url of the deribit websocket api used for prices. Defaults to mainnet or testnet deribit " +
+				"depending on --network, and a local mockderibit on regtest",
+		},
+
+		cli.StringFlag{
+			Name:  flag_hedger,
+			Value: venueBitmex,
+			Usage: "comma separated list of venues contracts are hedged on, in order of preference, for assets " +
+				"that do not list their own. bitmex | deribit | paper to simulate fills at the oracle price",
+		},
+		cli.DurationFlag{
+			Name:  flag_venuecooldown,
+			Value: time.Minute,
+			Usage: "how long orders fail over to other venues after a venue fails",
+		},
+		cli.Int64Flag{
+			Name:  flag_paperbalance,
+			Value: 100000000,
+			Usage: "balance the paper hedger starts with, in satoshis",
+		},
+		cli.StringFlag{
+			Name:  flag_hedgemode,
+			Value: hedgeModeContract,
+			Usage: "how contracts are hedged. contract trades once for every contract opened and closed, " +
+				"net keeps a single position per instrument matching the net exposure of all open contracts",
+		},
+		cli.DurationFlag{
+			Name:  flag_hedgeinterval,
+			Value: time.Minute,
+			Usage: "with --hedgemode=net, how often positions are adjusted to the net exposure",
+		},
+		cli.Float64Flag{
+			Name:  flag_hedgethreshold,
+			Value: 100,
+			Usage: "with --hedgemode=net, how many contracts a position can be off before it is adjusted " +
+				"right away when contracts are opened or closed",
+		},
+		cli.Float64Flag{
+			Name:  flag_hedgeslippagebps,
+			Value: 10,
+			Usage: "with --hedgemode=net, how many basis points through the oracle price limit orders are placed",
+		},
+		cli.DurationFlag{
+			Name:  flag_reconcileinterval,
+			Value: defaultReconcileInterval,
+			Usage: "how often our exchange positions are compared to the exposure of our open contracts",
+		},
+		cli.Float64Flag{
+			Name:  flag_driftalert,
+			Value: defaultDriftAlert,
+			Usage: "how many contracts a position can drift from the exposure of our open contracts before we alert",
+		},
+		cli.BoolFlag{
+			Name: flag_driftautocorrect,
+			Usage: "correct positions that drift past --driftalert with an order. With --hedgemode=net " +
+				"positions are corrected by the net hedger instead",
+		},
+		cli.StringFlag{
+			Name:  flag_fundingpolicy,
+			Value: defaultFundingPolicy,
+			Usage: "how funding paid on our hedges is passed on to clients. One of absorb, charge " +
+				"(settled with the client when contracts are rebalanced) or margin (added to the margin of new contracts)",
+		},
+		cli.DurationFlag{
+			Name:  flag_fundinginterval,
+			Value: defaultFundingInterval,
+			Usage: "how often funding payments are fetched from our hedge venues",
+		},
+		cli.Float64Flag{
+			Name:  flag_fundingmarginperiods,
+			Value: defaultFundingMarginPeriods,
+			Usage: "how many funding periods of the latest rate are added to the margin of new contracts, with --fundingpolicy=margin",
+		},
+		cli.Float64Flag{
+			Name:  flag_maxleverage,
+			Value: defaultMaxLeverage,
+			Usage: "the most our hedge exposure can be as a multiple of our exchange wallet balance. " +
+				"New contracts that would exceed it are handled by --leverageaction. 0 is no limit",
+		},
+		cli.StringFlag{
+			Name:  flag_leverageaction,
+			Value: defaultLeverageAction,
+			Usage: "what to do with new contracts exceeding --maxleverage, reject them or cap them to what fits",
+		},
 	}
 	app.Action = runLightningAssetDaemon
 
@@ -170,6 +472,12 @@ func runLightningAssetDaemon(c *cli.Context) error {
 		return fmt.Errorf("could not connect to lnd: %w", err)
 	}
 
+	// our node pubkey is attached to every price we sign
+	info, err := lncli.GetInfo(context.Background(), &lnrpc.GetInfoRequest{})
+	if err != nil {
+		return fmt.Errorf("could not get lnd info: %w", err)
+	}
+
 	// create connection for daemon to listen on
 	port := c.Int(flag_port)
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
@@ -177,23 +485,113 @@ func runLightningAssetDaemon(c *cli.Context) error {
 		return fmt.Errorf("could not listen: %w", err)
 	}
 
-	bitmexApi := bitmex.New(c.String(flag_bitmexapikey), c.String(flag_bitmexsecretkey))
+	// we trade and get prices from the same venue, so our hedge follows
+	// the price contracts are rebalanced by
+	endpoints, err := bitmexEndpoints(c)
+	if err != nil {
+		return err
+	}
+	log.WithFields(logrus.Fields{
+		"rest":     endpoints.REST,
+		"realtime": endpoints.Realtime,
+	}).Info("using bitmex endpoints")
+
+	assets, err := loadAssetConfig(cleanAndExpandPath(c.String(flag_assetsconfig)))
+	if err != nil {
+		return err
+	}
+	log.WithField("assets", assets.names()).Info("loaded assets")
+
+	feeds := newFeedMonitor(c.Duration(flag_feedalertafter))
+	go feeds.Watch()
+
+	priceOracle, err := newPriceOracle(c, endpoints, assets, feeds)
+	if err != nil {
+		return fmt.Errorf("could not create price oracle: %w", err)
+	}
+
+	hedger, err := newHedger(c, endpoints, assets, priceOracle, feeds)
+	if err != nil {
+		return err
+	}
+	engine := hedge.NewEngine(hedger, priceOracle, c.Float64(flag_hedgeslippagebps), assets.tickSizes(),
+		assets.lotSizes())
+
+	var netHedger *netHedger
+	switch c.String(flag_hedgemode) {
+	case hedgeModeContract:
+	case hedgeModeNet:
+		netHedger = newNetHedger(engine, c.Duration(flag_hedgeinterval), c.Float64(flag_hedgethreshold))
+	default:
+		return fmt.Errorf("unknown hedge mode %q", c.String(flag_hedgemode))
+	}
+
+	// the net hedger already keeps positions in line with our contracts, so
+	// the reconciler only corrects positions hedged per contract
+	var correctingEngine *hedge.Engine
+	if c.Bool(flag_driftautocorrect) && netHedger == nil {
+		correctingEngine = engine
+	}
+	reconciler := newHedgeReconciler(c.Duration(flag_reconcileinterval), c.Float64(flag_driftalert),
+		correctingEngine)
+	log.WithFields(logrus.Fields{
+		"hedger": hedger.Name(),
+		"mode":   c.String(flag_hedgemode),
+	}).Info("hedging contracts")
+
+	funding, err := newFundingTracker(c.String(flag_fundingpolicy), c.Duration(flag_fundinginterval),
+		c.Float64(flag_fundingmarginperiods))
+	if err != nil {
+		return err
+	}
+
+	leverage, err := newLeverageLimit(c.Float64(flag_maxleverage), c.String(flag_leverageaction))
+	if err != nil {
+		return err
+	}
+
+	// the converter holds both the bitcoin prices from our price oracle,
+	// and fiat exchange rates from the fx feed
+	converter := fx.NewConverter(c.Duration(flag_fxmaxage))
+	fxFeed := fx.NewFeed(c.String(flag_fxurl), fx.DefaultFeedInterval, converter)
 
 	// create channel that new contracts and new payments are sent to
 	contractCh := make(chan larpc.ServerContract)
 	paymentCh := make(chan larpc.Payment)
 
 	assetServer := AssetServer{
-		lncli:              lncli,
-		db:                 db,
-		insecure:           c.Bool(flag_insecure),
-		port:               c.Int(flag_port),
-		percentMargin:      c.Float64(flag_percentmargin),
-		bitmexApi:          bitmexApi,
+		lncli:         lncli,
+		db:            db,
+		insecure:      c.Bool(flag_insecure),
+		port:          c.Int(flag_port),
+		percentMargin: c.Float64(flag_percentmargin),
+		hedger:        hedger,
+		netHedger:     netHedger,
+		reconciler:    reconciler,
+		funding:       funding,
+		leverage:      leverage,
+		priceOracle:   priceOracle,
+		converter:     converter,
+		maxPriceAge:   c.Duration(flag_maxpriceage),
+		breaker: oracle.NewCircuitBreaker(c.Float64(flag_circuitbreaker),
+			c.Float64(flag_pricemaxdeviation)),
+		nodePubkey:           info.IdentityPubkey,
+		attestationRetention: c.Duration(flag_attestationretention),
+		tickRetention:        c.Duration(flag_tickretention),
+		prices:               NewPriceBook(),
+		assets:               assets,
+		rebalancer: newRebalanceScheduler(assets, rebalanceTrigger{
+			bps:           c.Float64(flag_rebalancebps),
+			minInterval:   c.Duration(flag_minrebalanceinterval),
+			forceInterval: c.Duration(flag_forcerebalanceinterval),
+		}, c.Duration(flag_maxpriceage)),
+		window:             oracle.NewPriceWindow(assets.maxTWAPWindow()),
 		breakContractAfter: c.Int64(flag_breakafter),
 
-		contractCh: contractCh,
-		paymentsCh: paymentCh,
+		contractCh:  contractCh,
+		paymentsCh:  paymentCh,
+		hedgeJobsCh: make(chan struct{}, 1),
+		hedgeJobsMu: &sync.Mutex{},
 	}
 
 	// create channel that listens to lnd invoices
@@ -204,20 +602,49 @@ func runLightningAssetDaemon(c *cli.Context) error {
 	go func() {
 		err = assetServer.handleInvoices(db, invoiceSubscription)
 		if err != nil {
-			log.Fatalf("could not handle invoices: %w", err)
+			log.Fatalf("could not handle invoices: %v", err)
 		}
 	}()
 
-	getPrice := func(asset string) float64 {
-		return prices[asset]
-	}
-	// this go func connects to a bitmex websocket and updates
-	// our saved price for any changes > 1 dollar compared to our saved price.
-	// As a result of calling SetPrice it also rebalances all contracts on a price change
+	// this go func keeps our fiat exchange rates up to date
 	go func() {
-		err = bitmex.ListenToPrice(getPrice, assetServer.SetPrice)
+		err := fxFeed.Listen()
 		if err != nil {
-			log.Fatalf("could not listen to bitmex price: %w", err)
+			log.Fatalf("could not listen to exchange rates: %v", err)
+		}
+	}()
+
+	// this go func connects to all our price sources, and updates our
+	// saved price whenever a quorum of them agree on a new price
+	go func() {
+		err = priceOracle.Listen()
+		if err != nil {
+			log.Fatalf("could not listen to prices: %v", err)
+		}
+	}()
+
+	// SetPrice puts every price we are willing to act on in our price book,
+	// and we rebalance contracts when the prices of their assets move enough
+	go assetServer.rebalanceOnPriceUpdates()
+
+	if netHedger != nil {
+		go assetServer.hedgeNetExposure()
+	}
+	go assetServer.runHedgeJobs()
+	go assetServer.reconcileHedges()
+	go assetServer.trackFunding()
+	go assetServer.trackFills()
+	go assetServer.pruneHistory()
+
+	go func() {
+		priceUpdates, cancel := priceOracle.Subscribe()
+		defer cancel()
+
+		for price := range priceUpdates {
+			err := assetServer.SetPrice(price)
+			if err != nil {
+				log.WithError(err).Error("could not set new price")
+			}
 		}
 	}()
 
@@ -225,8 +652,29 @@ func runLightningAssetDaemon(c *cli.Context) error {
 	grpcServer := grpc.NewServer()
 	larpc.RegisterAssetServerServer(grpcServer, assetServer)
 
-	// start webserver that uses normal http / http2, used for communicating with front-end
-	wrappedGrpc := grpcweb.WrapServer(grpcServer)
+	// the admin service gets its own grpc server and listener, so it is
+	// not exposed to clients of the public one
+	adminLis, err := net.Listen("tcp", c.String(flag_adminlisten))
+	if err != nil {
+		return fmt.Errorf("could not listen for admin rpcs: %w", err)
+	}
+	adminGrpcServer := grpc.NewServer()
+	larpc.RegisterAdminServerServer(adminGrpcServer, AdminServer{
+		assets: assetServer,
+		feeds:  feeds,
+	})
+
+	go func() {
+		log.Infof("admin grpc server listening on %s", adminLis.Addr())
+		err := adminGrpcServer.Serve(adminLis)
+		log.Fatalf("could not serve admin rpcs: %v", err)
+	}()
+
+	// start webserver that uses normal http / http2, used for communicating with front-end.
+	// It gets its own grpc server, as only the AssetServer should be exposed to front-ends
+	webGrpcServer := grpc.NewServer()
+	larpc.RegisterAssetServerServer(webGrpcServer, assetServer)
+	wrappedGrpc := grpcweb.WrapServer(webGrpcServer)
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		wrappedGrpc.ServeHTTP(w, r)
@@ -324,6 +772,11 @@ func (a AssetServer) handleInvoices(db *bolt.DB, subscription lnrpc.Lightning_Su
 					return b.Put([]byte(contract.Uuid), asByte)
 				}
 
+				openedAt, err := ptypes.TimestampProto(time.Now())
+				if err != nil {
+					return err
+				}
+
 				// based on the contract type, we require either both margin and
 				// initiating paymentrequests to be paid, or just the margin
 				// if the contract
@@ -331,35 +784,25 @@ func (a AssetServer) handleInvoices(db *bolt.DB, subscription lnrpc.Lightning_Su
 
 				case larpc.ContractType_FUNDED:
 					if contract.InitiatingPaid && contract.MarginPaid {
-						// contract is now open. To lock the price for the client, hedge position on bitmex
-
-						// always convert to USD
-						buyAmount := convertAssetAmount(contract.Asset, contract.Amount, "USD")
-						resp, orderID, err := a.bitmexApi.MarketBuy(buyAmount)
+						// contract is now open. To lock the price for the client, hedge its position
+						contract.OpenedAt = openedAt
+						err = a.openHedge(tx, contract)
 						if err != nil {
-							return fmt.Errorf("could not market buy: %w", err)
+							return err
 						}
-						logger.WithFields(logrus.Fields{
-							"status":  resp.Status,
-							"orderID": orderID,
-						}).Info("opened position on bitmex for funded contract")
 					}
 
 				case larpc.ContractType_UNFUNDED:
 					if contract.MarginPaid {
-						buyAmount := convertAssetAmount(contract.Asset, contract.Amount, "USD")
-						resp, orderID, err := a.bitmexApi.MarketBuy(buyAmount)
+						contract.OpenedAt = openedAt
+						err = a.openHedge(tx, contract)
 						if err != nil {
-							return fmt.Errorf("could not market buy: %w", err)
+							return err
 						}
-						logger.WithFields(logrus.Fields{
-							"status":  resp.Status,
-							"orderID": orderID,
-						}).Info("opened position on bitmex for unfunded contract")
 					}
 
 				default:
-					log.Info("received unexpected contract type %d", contract.ContractType)
+					log.Infof("received unexpected contract type %d", contract.ContractType)
 				}
 
 				asByte, err = json.Marshal(contract)
@@ -374,14 +817,55 @@ func (a AssetServer) handleInvoices(db *bolt.DB, subscription lnrpc.Lightning_Su
 				logger.WithError(err).Error("could not update contract")
 			} else {
 				logger.Trace("successfully updated contract")
+
+				a.notifyHedgeJobs()
+				if a.netHedger != nil {
+					a.netHedger.contractsChanged()
+				}
 			}
 
 		default:
-			logger.Tracef("not handling invoice with state %s")
+			logger.Tracef("not handling invoice with state %s", inv.State)
 		}
 	}
 }
 
+// bitmexEndpoints returns the bitmex endpoints for our network, with any
+// endpoints passed as flags taking precedence
+func bitmexEndpoints(c *cli.Context) (bitmex.Endpoints, error) {
+	endpoints, err := bitmex.EndpointsForNetwork(c.String(flag_network))
+	if err != nil {
+		return bitmex.Endpoints{}, err
+	}
+
+	if c.IsSet(flag_bitmexresturl) {
+		endpoints.REST = c.String(flag_bitmexresturl)
+	}
+	if c.IsSet(flag_bitmexwsurl) {
+		endpoints.Realtime = c.String(flag_bitmexwsurl)
+	}
+
+	return endpoints, nil
+}
+
+// deribitEndpoints returns the deribit endpoints for our network, with any
+// endpoints passed as flags taking precedence
+func deribitEndpoints(c *cli.Context) (deribit.Endpoints, error) {
+	endpoints, err := deribit.EndpointsForNetwork(c.String(flag_network))
+	if err != nil {
+		return deribit.Endpoints{}, err
+	}
+
+	if c.IsSet(flag_deribitresturl) {
+		endpoints.REST = c.String(flag_deribitresturl)
+	}
+	if c.IsSet(flag_deribitwsurl) {
+		endpoints.Realtime = c.String(flag_deribitwsurl)
+	}
+
+	return endpoints, nil
+}
+
 func headerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "application/json")
@@ -403,6 +887,54 @@ func createBucketsIfNotExist(db *bolt.DB) error {
 		if err != nil {
 			return fmt.Errorf("could not create bucket: %w", err)
 		}
+		_, err = tx.CreateBucketIfNotExists(attestationsBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(priceHistoryBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(hedgeDriftBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(hedgeOrdersBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(hedgeJobsBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(failedHedgeJobsBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(fundingBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(closedContractsBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(contractFundingBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(hedgeResidualsBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(hedgeFillsBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists(hedgeOrderKeysBucket)
+		if err != nil {
+			return fmt.Errorf("could not create bucket: %w", err)
+		}
 		// add additional buckets here
 		return nil
 	})
@@ -413,38 +945,47 @@ type rebalanceType string
 const SEND rebalanceType = "SEND"
 const RECEIVE rebalanceType = "RECEIVE"
 
-func (a AssetServer) rebalanceContracts() error {
-
-	var contracts []larpc.ServerContract
+// rebalanceContracts rebalances all contracts, using the quoted price for
+// the asset of each contract. The price of an asset is only attested once a
+// contract is rebalanced on it
+func (a AssetServer) rebalanceContracts(quotes map[string]*larpc.PriceAttestation) error {
 
 	// extract all contracts from database
-	err := a.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(contractsBucket)
-
-		// rebalance each contract
-		return b.ForEach(func(k, v []byte) error {
-			var contract larpc.ServerContract
+	contracts, err := listContracts(a.db)
+	if err != nil {
+		return fmt.Errorf("could not extract contracts from db: %w", err)
+	}
 
-			if err := json.Unmarshal(v, &contract); err != nil {
-				return fmt.Errorf("could not unmarshal contract %q: %w", string(v), err)
-			}
+	// every asset price is attested at most once, when the first payment
+	// is made on it
+	attestations := make(map[string]*larpc.PriceAttestation)
+	attest := func(asset string) (*larpc.PriceAttestation, error) {
+		if attestation, ok := attestations[asset]; ok {
+			return attestation, nil
+		}
 
-			contracts = append(contracts, contract)
+		attestation, err := a.attest(quotes[asset])
+		if err != nil {
+			return nil, fmt.Errorf("could not attest %s price: %w", asset, err)
+		}
+		attestations[asset] = attestation
 
-			return nil
-		})
-	})
-	if err != nil {
-		return fmt.Errorf("could not extract contracts from db: %w", err)
+		return attestation, nil
 	}
 
 	// rebalance all contracts
 	for _, contract := range contracts {
-		err = a.rebalanceContract(contract)
+		// the price update does not affect the price of this contract
+		quote, ok := quotes[contract.Asset]
+		if !ok {
+			continue
+		}
+
+		err = a.rebalanceContract(contract, quote, attest)
 		if err != nil {
 			if !errors.Is(err, ErrContractNotOpen) {
-			log.WithError(err).WithField("uuid", contract.Uuid).
-				Error("could not rebalance contract")
+				log.WithError(err).WithField("uuid", contract.Uuid).
+					Error("could not rebalance contract")
 			}
 		}
 	}
@@ -452,7 +993,12 @@ func (a AssetServer) rebalanceContracts() error {
 	return nil
 }
 
-func (a AssetServer) rebalanceContract(contract larpc.ServerContract) error {
+// rebalanceContract rebalances the contract on the quoted price of its
+// asset. The price is attested with attest before it is sent to the client
+// along with a payment
+func (a AssetServer) rebalanceContract(contract larpc.ServerContract, quote *larpc.PriceAttestation,
+	attest func(asset string) (*larpc.PriceAttestation, error)) error {
+
 	if !contract.MarginPaid {
 		return fmt.Errorf("margin not paid: %w", ErrContractNotOpen)
 	}
@@ -479,7 +1025,7 @@ func (a AssetServer) rebalanceContract(contract larpc.ServerContract) error {
 	if time.Now().Add(time.Second * 30).Before(lastRebalancedAt) {
 
 		log.WithFields(logrus.Fields{
-			"now": time.Now().UTC(),
+			"now":              time.Now().UTC(),
 			"lastRebalancedAt": lastRebalancedAt.UTC(),
 		}).Info("closing inactive contract ")
 
@@ -492,12 +1038,72 @@ func (a AssetServer) rebalanceContract(contract larpc.ServerContract) error {
 		return nil
 	}
 
-	direction, rebalanceAmountSat := calculateRebalanceAmount(contract)
+	direction, rebalanceAmountSat := calculateRebalanceAmount(contract, quote.AssetPrice)
 
-	if rebalanceAmountSat == 0 {
+	// funding allocated to the contract is settled along with the
+	// rebalance, by sending the client less or requesting more
+	charge, err := a.fundingCharge(contract.Uuid)
+	if err != nil {
+		return fmt.Errorf("could not get funding of contract: %w", err)
+	}
+
+	if rebalanceAmountSat == 0 && charge == 0 {
 		return nil
 	}
 
+	// the contract is worth the same after the funding is settled, so only
+	// the payment itself includes the charge
+	transferSat := rebalanceAmountSat
+	if direction == RECEIVE {
+		transferSat = -transferSat
+	}
+	transferSat -= charge
+
+	if transferSat != 0 {
+		attestation, err := attest(contract.Asset)
+		if err != nil {
+			return err
+		}
+
+		err = a.rebalancePayment(contract, attestation, transferSat)
+		if err != nil {
+			return err
+		}
+	}
+
+	if direction == SEND {
+		contract.AmountSats -= rebalanceAmountSat
+	} else {
+		contract.AmountSats += rebalanceAmountSat
+	}
+
+	if charge != 0 {
+		log.WithFields(logrus.Fields{
+			"uuid":   contract.Uuid,
+			"charge": charge,
+		}).Info("charged funding")
+
+		err = a.markFundingCharged(contract.Uuid, charge)
+		if err != nil {
+			return fmt.Errorf("could not save funding charge: %w", err)
+		}
+	}
+
+	// save contract
+	contract.NumUpdates++
+	err = saveContract(a.db, a.contractCh, contract)
+	if err != nil {
+		return fmt.Errorf("could not save contract: %w", err)
+	}
+
+	return nil
+}
+
+// rebalancePayment sends the client of the contract transferSat satoshis,
+// or requests them if negative
+func (a AssetServer) rebalancePayment(contract larpc.ServerContract, attestation *larpc.PriceAttestation,
+	transferSat int64) error {
+
 	client, cleanup, err := connectToLaClient(contract.ClientHost,
 		a.insecure, "")
 	if err != nil {
@@ -505,10 +1111,11 @@ func (a AssetServer) rebalanceContract(contract larpc.ServerContract) error {
 	}
 	defer cleanup()
 
-	if direction == SEND {
+	if transferSat > 0 {
 		// we need to send sats
 		res, err := client.RequestPaymentRequest(context.Background(), &larpc.ClientRequestPaymentRequestRequest{
-			AmountSat: rebalanceAmountSat,
+			AmountSat:   transferSat,
+			Attestation: attestation,
 		})
 		if err != nil {
 			return fmt.Errorf("could not request payment request: %w", err)
@@ -519,32 +1126,22 @@ func (a AssetServer) rebalanceContract(contract larpc.ServerContract) error {
 		if err != nil {
 			return fmt.Errorf("could not pay invoice: %w", err)
 		}
-
-		contract.AmountSats -= rebalanceAmountSat
 	} else {
 		// we need to request sats
 		inv, err := a.AddInvoice(contract.Uuid, lnrpc.Invoice{
-			Value: rebalanceAmountSat,
+			Value: -transferSat,
 		})
 		if err != nil {
 			return fmt.Errorf("could not add invoice: %w", err)
 		}
 
 		_, err = client.RequestPayment(context.Background(), &larpc.ClientRequestPaymentRequest{
-			PayReq: inv.PaymentRequest,
+			PayReq:      inv.PaymentRequest,
+			Attestation: attestation,
 		})
 		if err != nil {
 			return fmt.Errorf("could not request payment: %w", err)
 		}
-
-		contract.AmountSats += rebalanceAmountSat
-	}
-
-	// save contract
-	contract.NumUpdates++
-	err = saveContract(a.db, a.contractCh, contract)
-	if err != nil {
-		return fmt.Errorf("could not save contract: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/urfave/cli"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+)
+
+func TestBitmexEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    bitmex.Endpoints
+		invalid bool
+	}{
+		{
+			name: "mainnet",
+			args: []string{"--network=mainnet"},
+			want: bitmex.MainnetEndpoints,
+		},
+		{
+			name: "regtest uses the mock exchange",
+			args: []string{"--network=regtest"},
+			want: bitmex.LocalEndpoints,
+		},
+		{
+			name: "flags take precedence",
+			args: []string{"--network=testnet", "--bitmexresturl=http://proxy/api/v1"},
+			want: bitmex.Endpoints{REST: "http://proxy/api/v1", Realtime: bitmex.TestnetEndpoints.Realtime},
+		},
+		{
+			name: "both endpoints set",
+			args: []string{"--network=mainnet", "--bitmexresturl=http://a", "--bitmexwsurl=ws://b"},
+			want: bitmex.Endpoints{REST: "http://a", Realtime: "ws://b"},
+		},
+		{
+			name:    "unknown network",
+			args:    []string{"--network=bogus", "--bitmexresturl=http://a", "--bitmexwsurl=ws://b"},
+			invalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set := flag.NewFlagSet("lasd", flag.ContinueOnError)
+			set.String(flag_network, "", "")
+			set.String(flag_bitmexresturl, "", "")
+			set.String(flag_bitmexwsurl, "", "")
+			err := set.Parse(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			endpoints, err := bitmexEndpoints(cli.NewContext(cli.NewApp(), set, nil))
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("got endpoints %v for unknown network", endpoints)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoints != test.want {
+				t.Fatalf("endpoints = %v, want %v", endpoints, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// priceBookBuffer is how many updates can be queued up for a slow
+// subscriber before its oldest updates are dropped
+const priceBookBuffer = 16
+
+// PriceUpdate is a price accepted into the PriceBook, along with the asset
+// prices derived from it
+type PriceUpdate struct {
+	oracle.Price
+
+	// Version increases by one for every update to the book, so a
+	// subscriber can tell if it missed an update
+	Version uint64
+
+	// Attestations are the asset prices derived from the price, keyed by
+	// asset. They are not signed until contracts are rebalanced on them
+	Attestations map[string]*larpc.PriceAttestation
+}
+
+// PriceBook holds the latest accepted price of every symbol, and lets any
+// number of consumers subscribe to changes independently of each other.
+// It is safe for concurrent use.
+type PriceBook struct {
+	mu          sync.RWMutex
+	version     uint64
+	prices      map[string]PriceUpdate
+	nextID      int
+	subscribers map[int]chan PriceUpdate
+}
+
+// NewPriceBook creates an empty PriceBook
+func NewPriceBook() *PriceBook {
+	return &PriceBook{
+		prices:      make(map[string]PriceUpdate),
+		subscribers: make(map[int]chan PriceUpdate),
+	}
+}
+
+// Set makes the price the latest price of its symbol, and passes the
+// update on to all subscribers
+func (b *PriceBook) Set(price oracle.Price, attestations map[string]*larpc.PriceAttestation) PriceUpdate {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.version++
+	update := PriceUpdate{
+		Price:        price,
+		Version:      b.version,
+		Attestations: attestations,
+	}
+	b.prices[price.Symbol] = update
+
+	for _, sub := range b.subscribers {
+		// a subscriber that is not keeping up only cares about the most
+		// recent prices, so we make room by dropping the oldest update
+		select {
+		case sub <- update:
+		default:
+			select {
+			case <-sub:
+			default:
+			}
+			select {
+			case sub <- update:
+			default:
+			}
+		}
+	}
+
+	return update
+}
+
+// Latest returns the latest update for the symbol, if there is one
+func (b *PriceBook) Latest(symbol string) (PriceUpdate, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	update, ok := b.prices[symbol]
+	return update, ok
+}
+
+// All returns the latest update of every symbol, sorted by symbol
+func (b *PriceBook) All() []PriceUpdate {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	updates := make([]PriceUpdate, 0, len(b.prices))
+	for _, update := range b.prices {
+		updates = append(updates, update)
+	}
+
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].Symbol < updates[j].Symbol
+	})
+
+	return updates
+}
+
+// Subscribe returns a channel that receives every update to the book, and a
+// function that cancels the subscription
+func (b *PriceBook) Subscribe() (<-chan PriceUpdate, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+
+	ch := make(chan PriceUpdate, priceBookBuffer)
+	b.subscribers[id] = ch
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if sub, ok := b.subscribers[id]; ok {
+			delete(b.subscribers, id)
+			close(sub)
+		}
+	}
+
+	return ch, cancel
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// The price history bucket has one nested bucket per symbol. Each symbol
+// bucket holds a bucket with every tick, and one bucket per candle interval.
+var (
+	ticksBucket = []byte("ticks")
+
+	candleBuckets = map[larpc.CandleInterval][]byte{
+		larpc.CandleInterval_MINUTE: []byte("candles_1m"),
+		larpc.CandleInterval_HOUR:   []byte("candles_1h"),
+		larpc.CandleInterval_DAY:    []byte("candles_1d"),
+	}
+
+	candleDurations = map[larpc.CandleInterval]time.Duration{
+		larpc.CandleInterval_MINUTE: time.Minute,
+		larpc.CandleInterval_HOUR:   time.Hour,
+		larpc.CandleInterval_DAY:    24 * time.Hour,
+	}
+)
+
+const (
+	defaultPriceHistoryPeriod = 24 * time.Hour
+	defaultPriceHistoryLimit  = 1000
+)
+
+var (
+	// defaultAttestationRetention is how long attestations are kept
+	defaultAttestationRetention = 90 * 24 * time.Hour
+	// defaultTickRetention is how long price ticks are kept. Candles are
+	// kept forever
+	defaultTickRetention = 30 * 24 * time.Hour
+	// historyPruneInterval is how often history older than its retention
+	// is deleted
+	historyPruneInterval = time.Hour
+)
+
+// timeKey returns a key that sorts in chronological order
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// deleteBefore deletes every value in the bucket with a time key before the
+// given time, and returns how many were deleted
+func deleteBefore(b *bolt.Bucket, before time.Time) (int, error) {
+	if b == nil {
+		return 0, nil
+	}
+
+	end := timeKey(before)
+	c := b.Cursor()
+
+	deleted := 0
+	// deleting moves the cursor, so we start over from the first key
+	for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.First() {
+		err := c.Delete()
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// pruneHistory deletes the history we keep for longer than its retention,
+// right away and then every historyPruneInterval. A retention of zero keeps
+// history forever
+// NOTE: MUST be run in a goroutine
+func (a AssetServer) pruneHistory() {
+	ticker := time.NewTicker(historyPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if a.attestationRetention > 0 {
+			pruned, err := pruneAttestations(a.db, time.Now().Add(-a.attestationRetention))
+			if err != nil {
+				log.WithError(err).Error("could not prune attestations")
+			} else if pruned > 0 {
+				log.WithField("pruned", pruned).Info("pruned old attestations")
+			}
+		}
+
+		if a.tickRetention > 0 {
+			pruned, err := pruneTicks(a.db, time.Now().Add(-a.tickRetention))
+			if err != nil {
+				log.WithError(err).Error("could not prune price ticks")
+			} else if pruned > 0 {
+				log.WithField("pruned", pruned).Info("pruned old price ticks")
+			}
+		}
+
+		<-ticker.C
+	}
+}
+
+// pruneTicks deletes the ticks of every symbol from before the given time,
+// and returns how many were deleted
+func pruneTicks(db *bolt.DB, before time.Time) (int, error) {
+	pruned := 0
+	err := db.Update(func(tx *bolt.Tx) error {
+		history := tx.Bucket(priceHistoryBucket)
+
+		// buckets can not be written to while we iterate over them, so
+		// the symbols are collected first
+		var symbols [][]byte
+		err := history.ForEach(func(k, v []byte) error {
+			if v == nil {
+				symbols = append(symbols, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, symbol := range symbols {
+			deleted, err := deleteBefore(history.Bucket(symbol).Bucket(ticksBucket), before)
+			pruned += deleted
+			if err != nil {
+				return fmt.Errorf("could not prune %s ticks: %w", symbol, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return pruned, nil
+}
+
+// savePriceTick saves the price as a tick, and updates all the candles the
+// tick belongs to. Every accepted price is saved, so the ticks of a candle
+// add up to its tick count
+func savePriceTick(db *bolt.DB, price oracle.Price) error {
+	timestamp, err := ptypes.TimestampProto(price.Timestamp)
+	if err != nil {
+		return fmt.Errorf("could not convert price timestamp: %w", err)
+	}
+
+	tick := larpc.PriceTick{
+		Symbol:    price.Symbol,
+		Price:     price.Value,
+		Source:    price.Source,
+		Timestamp: timestamp,
+	}
+
+	asByte, err := json.Marshal(tick)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		symbolBucket, err := tx.Bucket(priceHistoryBucket).CreateBucketIfNotExists([]byte(price.Symbol))
+		if err != nil {
+			return fmt.Errorf("could not create symbol bucket: %w", err)
+		}
+
+		ticks, err := symbolBucket.CreateBucketIfNotExists(ticksBucket)
+		if err != nil {
+			return fmt.Errorf("could not create ticks bucket: %w", err)
+		}
+
+		// a tick with the same timestamp as one we have replaces it, and
+		// is not counted again in the candles
+		key := timeKey(price.Timestamp)
+		replaced := ticks.Get(key) != nil
+
+		err = ticks.Put(key, asByte)
+		if err != nil {
+			return err
+		}
+
+		for interval, name := range candleBuckets {
+			candles, err := symbolBucket.CreateBucketIfNotExists(name)
+			if err != nil {
+				return fmt.Errorf("could not create candle bucket: %w", err)
+			}
+
+			err = updateCandle(candles, interval, price, !replaced)
+			if err != nil {
+				return fmt.Errorf("could not update %s candle: %w", interval, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// updateCandle adds the price to the candle of the given interval it
+// belongs to, creating the candle if it does not exist. newTick is whether
+// the price is a tick the candle has not counted yet
+func updateCandle(b *bolt.Bucket, interval larpc.CandleInterval, price oracle.Price, newTick bool) error {
+	start := price.Timestamp.UTC().Truncate(candleDurations[interval])
+	key := timeKey(start)
+
+	var candle larpc.PriceCandle
+	if existing := b.Get(key); existing != nil {
+		err := json.Unmarshal(existing, &candle)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal candle: %w", err)
+		}
+
+		if price.Value > candle.High {
+			candle.High = price.Value
+		}
+		if price.Value < candle.Low {
+			candle.Low = price.Value
+		}
+		candle.Close = price.Value
+		if newTick {
+			candle.NumTicks++
+		}
+	} else {
+		startProto, err := ptypes.TimestampProto(start)
+		if err != nil {
+			return err
+		}
+
+		candle = larpc.PriceCandle{
+			Symbol:   price.Symbol,
+			Start:    startProto,
+			Open:     price.Value,
+			High:     price.Value,
+			Low:      price.Value,
+			Close:    price.Value,
+			NumTicks: 1,
+		}
+	}
+
+	asByte, err := json.Marshal(candle)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(key, asByte)
+}
+
+// forEachInRange calls fn for every value in the bucket with a time key in
+// the range [start, end], stopping after fn included limit values
+func forEachInRange(b *bolt.Bucket, start, end time.Time, limit int,
+	fn func(v []byte) (bool, error)) error {
+	if b == nil {
+		return nil
+	}
+
+	endKey := timeKey(end)
+	c := b.Cursor()
+
+	count := 0
+	for k, v := c.Seek(timeKey(start)); k != nil && bytes.Compare(k, endKey) <= 0; k, v = c.Next() {
+		if count >= limit {
+			break
+		}
+
+		included, err := fn(v)
+		if err != nil {
+			return err
+		}
+		if included {
+			count++
+		}
+	}
+
+	return nil
+}
+
+func (a AssetServer) GetPriceHistory(ctx context.Context, req *larpc.ServerGetPriceHistoryRequest) (*larpc.ServerGetPriceHistoryResponse, error) {
+	symbol := req.Symbol
+	if symbol == "" {
+		symbol = bitmex.XBTUSD
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultPriceHistoryLimit
+	}
+
+	end := time.Now()
+	if req.End != nil {
+		var err error
+		end, err = ptypes.Timestamp(req.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end: %w", err)
+		}
+	}
+
+	start := end.Add(-defaultPriceHistoryPeriod)
+	if req.Start != nil {
+		var err error
+		start, err = ptypes.Timestamp(req.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start: %w", err)
+		}
+	}
+
+	if start.After(end) {
+		return nil, fmt.Errorf("start can not be after end")
+	}
+
+	res := &larpc.ServerGetPriceHistoryResponse{}
+	err := a.db.View(func(tx *bolt.Tx) error {
+		symbolBucket := tx.Bucket(priceHistoryBucket).Bucket([]byte(symbol))
+		if symbolBucket == nil {
+			return fmt.Errorf("no price history for %s", symbol)
+		}
+
+		if req.Interval == larpc.CandleInterval_TICK {
+			err := forEachInRange(symbolBucket.Bucket(ticksBucket), start, end, limit, func(v []byte) (bool, error) {
+				var tick larpc.PriceTick
+				err := json.Unmarshal(v, &tick)
+				if err != nil {
+					return false, fmt.Errorf("could not unmarshal tick: %w", err)
+				}
+
+				res.Ticks = append(res.Ticks, &tick)
+				return true, nil
+			})
+			if err != nil {
+				return err
+			}
+		} else {
+			name, ok := candleBuckets[req.Interval]
+			if !ok {
+				return fmt.Errorf("unknown interval %s", req.Interval)
+			}
+
+			// include the candle the start of the period is in
+			candleStart := start.UTC().Truncate(candleDurations[req.Interval])
+			err := forEachInRange(symbolBucket.Bucket(name), candleStart, end, limit, func(v []byte) (bool, error) {
+				var candle larpc.PriceCandle
+				err := json.Unmarshal(v, &candle)
+				if err != nil {
+					return false, fmt.Errorf("could not unmarshal candle: %w", err)
+				}
+
+				res.Candles = append(res.Candles, &candle)
+				return true, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// attestations are keyed by timestamp followed by the asset, so
+		// the time key of start and end works as bounds. Attestations of
+		// other symbols do not count towards the limit
+		return forEachInRange(tx.Bucket(attestationsBucket), start, end.Add(time.Nanosecond), limit,
+			func(v []byte) (bool, error) {
+				var attestation larpc.PriceAttestation
+				err := json.Unmarshal(v, &attestation)
+				if err != nil {
+					return false, fmt.Errorf("could not unmarshal attestation: %w", err)
+				}
+
+				if attestation.Symbol != symbol {
+					return false, nil
+				}
+				res.Attestations = append(res.Attestations, &attestation)
+				return true, nil
+			})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// historyStart is an hour boundary, so the ticks of the tests fall in
+// known candles
+var historyStart = time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// tickAt returns a price of XBTUSD the given time after historyStart
+func tickAt(offset time.Duration, value float64) oracle.Price {
+	return oracle.Price{Symbol: "XBTUSD", Value: value, Timestamp: historyStart.Add(offset), Source: "test"}
+}
+
+func TestSavePriceTick(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []oracle.Price
+		// ticks is how many ticks we should have stored
+		ticks int
+		// minute is the first minute candle we should have
+		minute larpc.PriceCandle
+		// minutes is how many minute candles we should have
+		minutes int
+	}{
+		{
+			name:    "single tick",
+			prices:  []oracle.Price{tickAt(0, 100)},
+			ticks:   1,
+			minute:  larpc.PriceCandle{Open: 100, High: 100, Low: 100, Close: 100, NumTicks: 1},
+			minutes: 1,
+		},
+		{
+			name: "ticks in one minute",
+			prices: []oracle.Price{
+				tickAt(0, 100), tickAt(10*time.Second, 120), tickAt(20*time.Second, 90),
+				tickAt(30*time.Second, 110),
+			},
+			ticks:   4,
+			minute:  larpc.PriceCandle{Open: 100, High: 120, Low: 90, Close: 110, NumTicks: 4},
+			minutes: 1,
+		},
+		{
+			name:    "unchanged price is stored",
+			prices:  []oracle.Price{tickAt(0, 100), tickAt(time.Second, 100), tickAt(2*time.Second, 100)},
+			ticks:   3,
+			minute:  larpc.PriceCandle{Open: 100, High: 100, Low: 100, Close: 100, NumTicks: 3},
+			minutes: 1,
+		},
+		{
+			name:    "tick with the same timestamp replaces the earlier one",
+			prices:  []oracle.Price{tickAt(0, 100), tickAt(0, 101)},
+			ticks:   1,
+			minute:  larpc.PriceCandle{Open: 100, High: 101, Low: 100, Close: 101, NumTicks: 1},
+			minutes: 1,
+		},
+		{
+			name:    "ticks in two minutes",
+			prices:  []oracle.Price{tickAt(0, 100), tickAt(59*time.Second, 105), tickAt(time.Minute, 95)},
+			ticks:   3,
+			minute:  larpc.PriceCandle{Open: 100, High: 105, Low: 100, Close: 105, NumTicks: 2},
+			minutes: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, cleanup := newTestDB(t)
+			defer cleanup()
+
+			for _, price := range test.prices {
+				err := savePriceTick(db, price)
+				if err != nil {
+					t.Fatalf("could not save tick: %v", err)
+				}
+			}
+
+			var candles []larpc.PriceCandle
+			var ticks int
+			err := db.View(func(tx *bolt.Tx) error {
+				symbol := tx.Bucket(priceHistoryBucket).Bucket([]byte("XBTUSD"))
+				ticks = symbol.Bucket(ticksBucket).Stats().KeyN
+
+				return forEachInRange(symbol.Bucket(candleBuckets[larpc.CandleInterval_MINUTE]),
+					historyStart, historyStart.Add(time.Hour), 100, func(v []byte) (bool, error) {
+						var candle larpc.PriceCandle
+						err := json.Unmarshal(v, &candle)
+						candles = append(candles, candle)
+						return true, err
+					})
+			})
+			if err != nil {
+				t.Fatalf("could not read history: %v", err)
+			}
+
+			if ticks != test.ticks {
+				t.Fatalf("stored %d ticks, want %d", ticks, test.ticks)
+			}
+			if len(candles) != test.minutes {
+				t.Fatalf("got %d minute candles, want %d", len(candles), test.minutes)
+			}
+
+			got := candles[0]
+			want := test.minute
+			if got.Open != want.Open || got.High != want.High || got.Low != want.Low ||
+				got.Close != want.Close || got.NumTicks != want.NumTicks {
+				t.Fatalf("minute candle = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetPriceHistory(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < 5; i++ {
+		err := savePriceTick(db, tickAt(time.Duration(i)*time.Minute, float64(100+i)))
+		if err != nil {
+			t.Fatalf("could not save tick: %v", err)
+		}
+	}
+
+	// attestations of XBTUSD are interleaved with those of another symbol
+	for i, symbol := range []string{"ETHUSD", "XBTUSD", "ETHUSD", "XBTUSD", "XBTUSD"} {
+		timestamp, err := ptypes.TimestampProto(historyStart.Add(time.Duration(i) * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = saveAttestation(db, &larpc.PriceAttestation{
+			Symbol: symbol, Price: 100, Timestamp: timestamp, Asset: symbol + "-asset",
+		})
+		if err != nil {
+			t.Fatalf("could not save attestation: %v", err)
+		}
+	}
+
+	start, _ := ptypes.TimestampProto(historyStart)
+	end, _ := ptypes.TimestampProto(historyStart.Add(time.Hour))
+
+	tests := []struct {
+		name         string
+		interval     larpc.CandleInterval
+		limit        uint32
+		ticks        int
+		candles      int
+		attestations int
+	}{
+		{name: "ticks", interval: larpc.CandleInterval_TICK, ticks: 5, attestations: 3},
+		{name: "limited ticks", interval: larpc.CandleInterval_TICK, limit: 2, ticks: 2, attestations: 2},
+		{name: "minute candles", interval: larpc.CandleInterval_MINUTE, candles: 5, attestations: 3},
+		{name: "hour candles", interval: larpc.CandleInterval_HOUR, candles: 1, attestations: 3},
+		{name: "limit counts only the symbol", interval: larpc.CandleInterval_HOUR, limit: 1, candles: 1, attestations: 1},
+	}
+
+	a := AssetServer{db: db}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := a.GetPriceHistory(context.Background(), &larpc.ServerGetPriceHistoryRequest{
+				Symbol:   "XBTUSD",
+				Interval: test.interval,
+				Start:    start,
+				End:      end,
+				Limit:    test.limit,
+			})
+			if err != nil {
+				t.Fatalf("could not get history: %v", err)
+			}
+
+			if len(res.Ticks) != test.ticks || len(res.Candles) != test.candles ||
+				len(res.Attestations) != test.attestations {
+				t.Fatalf("got %d ticks, %d candles and %d attestations, want %d, %d and %d",
+					len(res.Ticks), len(res.Candles), len(res.Attestations),
+					test.ticks, test.candles, test.attestations)
+			}
+			for _, attestation := range res.Attestations {
+				if attestation.Symbol != "XBTUSD" {
+					t.Fatalf("got attestation of %s", attestation.Symbol)
+				}
+			}
+		})
+	}
+
+	_, err := a.GetPriceHistory(context.Background(), &larpc.ServerGetPriceHistoryRequest{Symbol: "LTCUSD"})
+	if err == nil {
+		t.Fatal("got history of a symbol without any")
+	}
+}
+
+func TestPruneTicks(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < 4; i++ {
+		err := savePriceTick(db, tickAt(time.Duration(i)*time.Hour, float64(100+i)))
+		if err != nil {
+			t.Fatalf("could not save tick: %v", err)
+		}
+	}
+
+	pruned, err := pruneTicks(db, historyStart.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("could not prune ticks: %v", err)
+	}
+	if pruned != 2 {
+		t.Fatalf("pruned %d ticks, want 2", pruned)
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		symbol := tx.Bucket(priceHistoryBucket).Bucket([]byte("XBTUSD"))
+		if n := symbol.Bucket(ticksBucket).Stats().KeyN; n != 2 {
+			t.Errorf("%d ticks left, want 2", n)
+		}
+		// candles are kept
+		if n := symbol.Bucket(candleBuckets[larpc.CandleInterval_HOUR]).Stats().KeyN; n != 4 {
+			t.Errorf("%d hour candles left, want 4", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/deribit"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// names of the venues contracts can be hedged on
+const (
+	venueBitmex  = "bitmex"
+	venueDeribit = "deribit"
+	venuePaper   = "paper"
+)
+
+// newPriceOracle creates the price oracle contracts are priced and
+// rebalanced by, aggregating all the price sources passed as flags. The
+// bitmex feed gets prices for all our instruments, the deribit feed for
+// the instruments traded on deribit, while the other exchanges only
+// provide XBTUSD. The connection state of realtime sources is reported to
+// the monitor
+func newPriceOracle(c *cli.Context, endpoints bitmex.Endpoints, assets assetConfig,
+	monitor *feedMonitor) (*oracle.Aggregator, error) {
+
+	var sources []oracle.Source
+
+	for _, name := range strings.Split(c.String(flag_pricesources), ",") {
+		name = strings.TrimSpace(name)
+
+		switch {
+		case name == "bitmex":
+			feed := bitmex.NewPriceFeed(endpoints.Realtime, assets.symbols())
+			feed.OnStateChange(monitor.callback(feed.Name()))
+			sources = append(sources, feed)
+
+		case name == "deribit":
+			deribitEndpoints, err := deribitEndpoints(c)
+			if err != nil {
+				return nil, err
+			}
+			feed := deribit.NewPriceFeed(deribitEndpoints.Realtime, assets.venueSymbols(venueDeribit))
+			feed.OnStateChange(monitor.callback(feed.Name()))
+			sources = append(sources, feed)
+
+		case name == "coinbase":
+			sources = append(sources, oracle.NewCoinbaseSource(bitmex.XBTUSD,
+				"BTC-USD", oracle.DefaultPollInterval))
+
+		case name == "bitstamp":
+			sources = append(sources, oracle.NewBitstampSource(bitmex.XBTUSD,
+				"btcusd", oracle.DefaultPollInterval))
+
+		case name == "kraken":
+			sources = append(sources, oracle.NewKrakenSource(bitmex.XBTUSD,
+				"XBTUSD", oracle.DefaultPollInterval))
+
+		case name == "file":
+			path := c.String(flag_pricefile)
+			if path == "" {
+				return nil, fmt.Errorf("--%s is required for the file price source", flag_pricefile)
+			}
+			sources = append(sources, oracle.NewFileSource(cleanAndExpandPath(path),
+				oracle.DefaultPollInterval))
+
+		case name == "replay":
+			path := c.String(flag_replayfile)
+			if path == "" {
+				return nil, fmt.Errorf("--%s is required for the replay price source", flag_replayfile)
+			}
+			source, err := oracle.NewReplaySource(cleanAndExpandPath(path),
+				c.Float64(flag_replayspeed), c.Bool(flag_replayloop))
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+
+		case strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://"):
+			sources = append(sources, oracle.NewJSONSource(name, oracle.DefaultPollInterval))
+
+		default:
+			return nil, fmt.Errorf("unknown price source %q", name)
+		}
+	}
+
+	// sources that have not updated within the max price age do not count
+	// towards the quorum
+	return oracle.NewAggregator(sources, c.Int(flag_pricequorum),
+		c.Float64(flag_pricemaxdeviation), c.Duration(flag_maxpriceage))
+}
+
+// newHedger creates the hedger contracts are hedged with. Every hedge
+// instrument is traded on the venues listed for its assets, or on the
+// venues passed with --hedger, in order of preference. When more than one
+// venue is used, orders fail over between them. The paper venue fills
+// orders at the prices of our oracle. Our bitmex fills are followed on the
+// realtime api, and its connection state is reported to the monitor
+func newHedger(c *cli.Context, endpoints bitmex.Endpoints, assets assetConfig,
+	prices oracle.PriceOracle, monitor *feedMonitor) (hedge.Hedger, error) {
+
+	var defaultVenues []string
+	for _, name := range strings.Split(c.String(flag_hedger), ",") {
+		defaultVenues = append(defaultVenues, strings.TrimSpace(name))
+	}
+
+	routes, err := assets.venueRoutes(defaultVenues)
+	if err != nil {
+		return nil, err
+	}
+
+	// we only connect to the venues we trade on
+	var names []string
+	used := make(map[string]bool)
+	for symbol, route := range routes {
+		for _, name := range route {
+			if name == venueDeribit && assets.venueSymbols(venueDeribit)[symbol] == "" {
+				return nil, fmt.Errorf("%s is hedged on deribit, but has no deribit instrument", symbol)
+			}
+			if !used[name] {
+				used[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var venues []hedge.Hedger
+	for _, name := range names {
+		switch name {
+		case venueBitmex:
+			api := bitmex.New(c.String(flag_bitmexapikey), c.String(flag_bitmexsecretkey), endpoints.REST)
+			feed := bitmex.NewExecutionFeed(endpoints.Realtime, c.String(flag_bitmexapikey),
+				c.String(flag_bitmexsecretkey))
+			feed.OnStateChange(monitor.callback(feed.Name()))
+			venues = append(venues, hedge.NewBitmexHedger(api, feed))
+
+			// this go func follows our bitmex orders until lasd stops
+			go func() {
+				err := feed.Listen()
+				if err != nil {
+					log.Fatalf("could not listen to bitmex executions: %v", err)
+				}
+			}()
+
+		case venueDeribit:
+			deribitEndpoints, err := deribitEndpoints(c)
+			if err != nil {
+				return nil, err
+			}
+			api := deribit.New(c.String(flag_deribitclientid), c.String(flag_deribitclientsecret),
+				deribitEndpoints.REST)
+			venues = append(venues, hedge.NewDeribitHedger(api, assets.venueSymbols(venueDeribit)))
+
+		case venuePaper:
+			venues = append(venues, hedge.NewPaperHedger(prices, c.Int64(flag_paperbalance)))
+
+		default:
+			return nil, fmt.Errorf("unknown hedger %q", name)
+		}
+	}
+
+	if len(venues) == 1 {
+		return venues[0], nil
+	}
+
+	return hedge.NewFailoverHedger(venues, routes, c.Duration(flag_venuecooldown))
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// rebalanceCheckInterval is how often we check if contracts are due for a
+// rebalance, when no new prices arrive
+const rebalanceCheckInterval = time.Second
+
+var (
+	defaultRebalanceBps           = 1.0
+	defaultMinRebalanceInterval   = time.Duration(0)
+	defaultForceRebalanceInterval = time.Duration(0)
+)
+
+// rebalanceTrigger decides when contracts in an asset are rebalanced
+type rebalanceTrigger struct {
+	// bps is how many basis points the price has to move since contracts
+	// were last rebalanced before they are rebalanced again
+	bps float64
+	// minInterval is the least time between two rebalances
+	minInterval time.Duration
+	// forceInterval is the most time between two rebalances, regardless of
+	// how much the price moved. Zero never forces a rebalance
+	forceInterval time.Duration
+}
+
+// rebalanceTrigger returns the rebalance trigger of the asset, falling
+// back to the defaults for anything not set in its definition
+func (d assetDefinition) rebalanceTrigger(defaults rebalanceTrigger) rebalanceTrigger {
+	trigger := defaults
+	if d.RebalanceBps != 0 {
+		trigger.bps = d.RebalanceBps
+	}
+	if d.MinRebalanceInterval.Duration != 0 {
+		trigger.minInterval = d.MinRebalanceInterval.Duration
+	}
+	if d.ForceRebalanceInterval.Duration != 0 {
+		trigger.forceInterval = d.ForceRebalanceInterval.Duration
+	}
+
+	return trigger
+}
+
+// assetRebalanceState is what we know about the rebalancing of contracts
+// in a single asset
+type assetRebalanceState struct {
+	trigger rebalanceTrigger
+
+	// latest is the latest quoted price of the asset
+	latest *larpc.PriceAttestation
+	// lastPrice is the price contracts were last rebalanced on
+	lastPrice float64
+	// lastAt is when contracts were last rebalanced
+	lastAt time.Time
+}
+
+// rebalanceScheduler keeps track of the latest price of every asset, and
+// decides when contracts in each asset are due for a rebalance
+type rebalanceScheduler struct {
+	// maxPriceAge is how old a price can be before we stop rebalancing on it
+	maxPriceAge time.Duration
+
+	mu     sync.Mutex
+	assets map[string]*assetRebalanceState
+}
+
+func newRebalanceScheduler(assets assetConfig, defaults rebalanceTrigger,
+	maxPriceAge time.Duration) *rebalanceScheduler {
+
+	states := make(map[string]*assetRebalanceState)
+	for _, asset := range assets.Assets {
+		states[asset.Name] = &assetRebalanceState{
+			trigger: asset.rebalanceTrigger(defaults),
+		}
+	}
+
+	return &rebalanceScheduler{
+		maxPriceAge: maxPriceAge,
+		assets:      states,
+	}
+}
+
+// record records the latest quoted prices
+func (s *rebalanceScheduler) record(quotes map[string]*larpc.PriceAttestation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for asset, quote := range quotes {
+		if state, ok := s.assets[asset]; ok {
+			state.latest = quote
+		}
+	}
+}
+
+// due returns the latest quoted price of every asset whose contracts are
+// due for a rebalance, and marks them as rebalanced. Assets that are halted
+// are never due, not even when a rebalance is forced
+func (s *rebalanceScheduler) due(now time.Time, halted func(asset string) bool) map[string]*larpc.PriceAttestation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make(map[string]*larpc.PriceAttestation)
+	for asset, state := range s.assets {
+		reason := s.dueReason(state, now)
+		if reason == "" {
+			continue
+		}
+		if halted(asset) {
+			log.WithFields(logrus.Fields{
+				"asset":  asset,
+				"reason": reason,
+			}).Debug("not rebalancing contracts, circuit breaker is tripped")
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			"asset":  asset,
+			"price":  state.latest.AssetPrice,
+			"reason": reason,
+		}).Debug("contracts due for rebalance")
+
+		due[asset] = state.latest
+		state.lastPrice = state.latest.AssetPrice
+		state.lastAt = now
+	}
+
+	return due
+}
+
+// dueReason returns why contracts in the asset are due for a rebalance, or
+// an empty string if they are not
+func (s *rebalanceScheduler) dueReason(state *assetRebalanceState, now time.Time) string {
+	if state.latest == nil {
+		return ""
+	}
+
+	// we never rebalance on stale prices, not even when forced to
+	timestamp, err := ptypes.Timestamp(state.latest.Timestamp)
+	if err != nil || now.Sub(timestamp) > s.maxPriceAge {
+		return ""
+	}
+
+	if state.lastAt.IsZero() {
+		return "first price"
+	}
+
+	elapsed := now.Sub(state.lastAt)
+	if elapsed < state.trigger.minInterval {
+		return ""
+	}
+
+	if state.trigger.forceInterval > 0 && elapsed >= state.trigger.forceInterval {
+		return "forced"
+	}
+
+	moveBps := math.Abs(state.latest.AssetPrice-state.lastPrice) / state.lastPrice * 10000
+	if moveBps > 0 && moveBps >= state.trigger.bps {
+		return "price moved"
+	}
+
+	return ""
+}
+
+// status returns the rebalance trigger of the asset, and when contracts in
+// it were last rebalanced
+func (s *rebalanceScheduler) status(asset string) (rebalanceTrigger, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.assets[asset]
+	if !ok {
+		return rebalanceTrigger{}, time.Time{}
+	}
+
+	return state.trigger, state.lastAt
+}
+
+// rebalanceOnPriceUpdates rebalances contracts whenever our price book is
+// updated or time passes, if the rebalance trigger of their asset says so.
+// If several updates have queued up while rebalancing, only the most
+// recent one is acted on
+// NOTE: MUST be run in a goroutine
+func (a AssetServer) rebalanceOnPriceUpdates() {
+	updates, cancel := a.prices.Subscribe()
+	defer cancel()
+
+	ticker := time.NewTicker(rebalanceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			latest, ok := a.prices.Latest(update.Symbol)
+			if ok && latest.Version != update.Version {
+				log.WithField("version", update.Version).Debug("skipping outdated price update")
+				continue
+			}
+
+			a.rebalancer.record(update.Attestations)
+
+		case <-ticker.C:
+		}
+
+		due := a.rebalancer.due(time.Now(), a.breakerTripped)
+		if len(due) == 0 {
+			continue
+		}
+
+		var assets []string
+		for asset := range due {
+			assets = append(assets, asset)
+		}
+		sort.Strings(assets)
+		log.WithField("assets", assets).Info("rebalancing contracts")
+
+		err := a.rebalanceContracts(due)
+		if err != nil {
+			log.WithError(err).Error("could not rebalance contracts")
+		}
+	}
+}
+
+// breakerTripped returns whether the circuit breaker is tripped for any of
+// the prices the asset is priced with
+func (a AssetServer) breakerTripped(asset string) bool {
+	definition, ok := a.assets.lookup(asset)
+	if !ok {
+		return false
+	}
+
+	for _, symbol := range a.assets.priceSymbols(definition) {
+		if _, tripped := a.breaker.Tripped(symbol); tripped {
+			return true
+		}
+	}
+
+	return false
+}
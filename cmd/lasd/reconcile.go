@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+var (
+	defaultReconcileInterval = time.Minute
+	// defaultDriftAlert is how many contracts our position can be off
+	// before we alert the operator
+	defaultDriftAlert = 100.0
+)
+
+const defaultHedgeDriftLimit = 100
+
+// hedgeReconciler periodically compares our exchange positions to the
+// exposure of our open contracts, and alerts the operator when they drift
+// apart
+type hedgeReconciler struct {
+	interval time.Duration
+	// alertThreshold is how many contracts a position can drift before we
+	// alert the operator
+	alertThreshold float64
+	// engine corrects positions that drifted past the alert threshold. If
+	// nil, drift is only reported
+	engine *hedge.Engine
+
+	mu sync.Mutex
+	// latest is the latest drift of every hedge instrument
+	latest map[string]*larpc.HedgeDrift
+	// alerted is set for instruments the operator has been alerted about,
+	// so we only alert once until the drift is back within the threshold
+	alerted map[string]bool
+}
+
+func newHedgeReconciler(interval time.Duration, alertThreshold float64,
+	engine *hedge.Engine) *hedgeReconciler {
+
+	return &hedgeReconciler{
+		interval:       interval,
+		alertThreshold: alertThreshold,
+		engine:         engine,
+		latest:         make(map[string]*larpc.HedgeDrift),
+		alerted:        make(map[string]bool),
+	}
+}
+
+// status returns the latest drift of every hedge instrument, sorted by
+// symbol
+func (r *hedgeReconciler) status() []*larpc.HedgeDrift {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var drifts []*larpc.HedgeDrift
+	for _, drift := range r.latest {
+		drifts = append(drifts, drift)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Symbol < drifts[j].Symbol
+	})
+
+	return drifts
+}
+
+// reconcileHedges reconciles our hedges every interval
+// NOTE: MUST be run in a goroutine
+func (a AssetServer) reconcileHedges() {
+	ticker := time.NewTicker(a.reconciler.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := a.reconcile()
+		if err != nil {
+			log.WithError(err).Error("could not reconcile hedges")
+		}
+	}
+}
+
+// reconcile compares our position in every hedge instrument, including
+// orders we have yet to place, to the exposure of our open contracts,
+// records the drift between them, and alerts and possibly corrects drift
+// past the alert threshold
+func (a AssetServer) reconcile() error {
+	// jobs are saved in the same transaction as the contract changes they
+	// hedge, so the jobs from before we read our exposure tell us which
+	// jobs are newer than it
+	before, err := listHedgeJobs(a.db, hedgeJobsBucket)
+	if err != nil {
+		return fmt.Errorf("could not list hedge jobs: %w", err)
+	}
+
+	exposure, err := a.netExposure()
+	if err != nil {
+		return err
+	}
+
+	// contracts whose hedge orders have not been placed yet are not drift.
+	// The hedge job worker is held off while we read them and our
+	// positions, so an order placed in between is not counted twice
+	a.hedgeJobsMu.Lock()
+	jobs, positions, err := a.hedgeSnapshot(exposure)
+	a.hedgeJobsMu.Unlock()
+	if err != nil {
+		return err
+	}
+	pending := pendingExposure(jobs)
+	changed := newHedgeJobSymbols(before, jobs)
+
+	// neither is exposure we have chosen not to hedge, as it is less than a
+	// lot
+	residuals, err := a.hedgeResiduals()
+	if err != nil {
+		return fmt.Errorf("could not get hedge residuals: %w", err)
+	}
+	residual := make(map[string]float64)
+	for _, r := range residuals {
+		residual[r.Symbol] = r.Qty
+	}
+
+	var symbols []string
+	for symbol := range exposure {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		// contracts that changed while we read our exposure are not in
+		// it, so the instrument is reconciled on the next run instead
+		if changed[symbol] {
+			log.WithField("symbol", symbol).Debug("contracts changed while reconciling, skipping")
+			continue
+		}
+		position := positions[symbol]
+
+		now, err := ptypes.TimestampProto(time.Now())
+		if err != nil {
+			return err
+		}
+
+		drift := &larpc.HedgeDrift{
+			Symbol:    symbol,
+			Expected:  exposure[symbol],
+			Actual:    position.Qty,
+			Pending:   pending[symbol],
+			Residual:  residual[symbol],
+			Drift:     position.Qty + pending[symbol] + residual[symbol] - exposure[symbol],
+			Timestamp: now,
+		}
+
+		if a.checkDrift(drift) {
+			drift.CorrectionOrderId = a.correctDrift(drift)
+		}
+
+		err = saveHedgeDrift(a.db, drift)
+		if err != nil {
+			return fmt.Errorf("could not save hedge drift: %w", err)
+		}
+
+		a.reconciler.mu.Lock()
+		a.reconciler.latest[symbol] = drift
+		a.reconciler.mu.Unlock()
+	}
+
+	return nil
+}
+
+// hedgeSnapshot returns the hedge jobs we have yet to place, and our
+// position in every instrument we have exposure in
+func (a AssetServer) hedgeSnapshot(exposure map[string]float64) ([]*larpc.HedgeJob, map[string]hedge.Position, error) {
+	jobs, err := listHedgeJobs(a.db, hedgeJobsBucket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list hedge jobs: %w", err)
+	}
+
+	positions := make(map[string]hedge.Position)
+	for symbol := range exposure {
+		position, err := a.hedger.Position(symbol)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get %s position: %w", symbol, err)
+		}
+		positions[symbol] = position
+	}
+
+	return jobs, positions, nil
+}
+
+// newHedgeJobSymbols returns the instruments of the jobs that are not in
+// before
+func newHedgeJobSymbols(before, jobs []*larpc.HedgeJob) map[string]bool {
+	seen := make(map[string]bool)
+	for _, job := range before {
+		seen[job.ClientOrderId] = true
+	}
+
+	symbols := make(map[string]bool)
+	for _, job := range jobs {
+		if !seen[job.ClientOrderId] {
+			symbols[job.Symbol] = true
+		}
+	}
+
+	return symbols
+}
+
+// checkDrift alerts the operator the first time the drift of an instrument
+// exceeds the alert threshold, and returns whether it does
+func (a AssetServer) checkDrift(drift *larpc.HedgeDrift) bool {
+	r := a.reconciler
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger := log.WithFields(logrus.Fields{
+		"symbol":   drift.Symbol,
+		"expected": drift.Expected,
+		"actual":   drift.Actual,
+	})
+
+	if math.Abs(drift.Drift) < r.alertThreshold {
+		if r.alerted[drift.Symbol] {
+			logger.Info("hedge is back in line with open contracts")
+		}
+		r.alerted[drift.Symbol] = false
+		return false
+	}
+
+	if !r.alerted[drift.Symbol] {
+		logger.Errorf("ALERT: hedge position is off by %f contracts", drift.Drift)
+	}
+	r.alerted[drift.Symbol] = true
+
+	return true
+}
+
+// correctDrift places an order bringing our position back to the expected
+// exposure, if we are configured to. It returns the id of the order placed
+func (a AssetServer) correctDrift(drift *larpc.HedgeDrift) string {
+	if a.reconciler.engine == nil {
+		return ""
+	}
+
+	// orders we have yet to place will make up for part of the drift, and
+	// the residual is left for later contracts to make up a lot
+	orders, err := a.reconciler.engine.Adjust(map[string]float64{
+		drift.Symbol: drift.Expected - drift.Pending - drift.Residual,
+	}, 0)
+	if err != nil {
+		log.WithError(err).WithField("symbol", drift.Symbol).Error("could not correct hedge drift")
+		return ""
+	}
+	if len(orders) == 0 {
+		return ""
+	}
+
+	log.WithFields(logrus.Fields{
+		"symbol":  drift.Symbol,
+		"orderID": orders[0].ID,
+	}).Warn("corrected hedge drift")
+
+	err = a.saveHedgeOrders(orders, "", hedgeReasonDrift)
+	if err != nil {
+		log.WithError(err).Error("could not save hedge order")
+	}
+
+	return orders[0].ID
+}
+
+// saveHedgeDrift saves the drift, keyed by its timestamp followed by the
+// symbol, so drift is sorted by time
+func saveHedgeDrift(db *bolt.DB, drift *larpc.HedgeDrift) error {
+	timestamp, err := ptypes.Timestamp(drift.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	key := append(timeKey(timestamp), drift.Symbol...)
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(hedgeDriftBucket)
+
+		asByte, err := json.Marshal(drift)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(key, asByte)
+	})
+}
+
+func (a AdminServer) ListHedgeDrift(ctx context.Context, req *larpc.AdminListHedgeDriftRequest) (*larpc.AdminListHedgeDriftResponse, error) {
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultHedgeDriftLimit
+	}
+
+	res := &larpc.AdminListHedgeDriftResponse{}
+	err := a.assets.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(hedgeDriftBucket).Cursor()
+
+		for k, v := c.Last(); k != nil && len(res.Drifts) < limit; k, v = c.Prev() {
+			var drift larpc.HedgeDrift
+			err := json.Unmarshal(v, &drift)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal hedge drift: %w", err)
+			}
+
+			if req.Symbol == "" || drift.Symbol == req.Symbol {
+				res.Drifts = append(res.Drifts, &drift)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
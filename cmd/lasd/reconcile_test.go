@@ -0,0 +1,213 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/fx"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+func TestNewHedgeJobSymbols(t *testing.T) {
+	job := func(id, symbol string) *larpc.HedgeJob {
+		return &larpc.HedgeJob{ClientOrderId: id, Symbol: symbol}
+	}
+
+	tests := []struct {
+		name   string
+		before []*larpc.HedgeJob
+		jobs   []*larpc.HedgeJob
+		want   string
+	}{
+		{name: "no jobs"},
+		{
+			name:   "same jobs",
+			before: []*larpc.HedgeJob{job("a", "XBTUSD")},
+			jobs:   []*larpc.HedgeJob{job("a", "XBTUSD")},
+		},
+		{
+			name:   "job completed",
+			before: []*larpc.HedgeJob{job("a", "XBTUSD")},
+		},
+		{
+			name:   "new job",
+			before: []*larpc.HedgeJob{job("a", "XBTUSD")},
+			jobs:   []*larpc.HedgeJob{job("a", "XBTUSD"), job("b", "ETHUSD")},
+			want:   "ETHUSD",
+		},
+		{
+			name: "new jobs in several instruments",
+			jobs: []*larpc.HedgeJob{job("a", "XBTUSD"), job("b", "ETHUSD"), job("c", "XBTUSD")},
+			want: "ETHUSD,XBTUSD",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var symbols []string
+			for symbol := range newHedgeJobSymbols(test.before, test.jobs) {
+				symbols = append(symbols, symbol)
+			}
+			sort.Strings(symbols)
+
+			if strings.Join(symbols, ",") != test.want {
+				t.Fatalf("symbols = %v, want %s", symbols, test.want)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name string
+		// open are the hedge quantities of our open contracts
+		open []float64
+		// position is our position on the venue
+		position float64
+		// pending is the signed qty of a hedge job not placed yet
+		pending float64
+		// residual is the exposure left unhedged for being less than a lot
+		residual float64
+		drift    float64
+		alert    bool
+	}{
+		{name: "hedged", open: []float64{100, 50}, position: 150},
+		{name: "nothing open", position: 0},
+		{name: "under hedged", open: []float64{100}, position: 40, drift: -60},
+		{name: "over hedged past threshold", open: []float64{100}, position: 300, drift: 200, alert: true},
+		{name: "pending order is not drift", open: []float64{100, 50}, position: 100, pending: 50},
+		{name: "pending sell is not drift", open: []float64{100}, position: 150, pending: -50},
+		{name: "residual is not drift", open: []float64{100, 5}, position: 100, residual: 5},
+		{name: "short hedge", open: []float64{-100}, position: -100},
+		{name: "drift below zero past threshold", open: []float64{500}, position: 100, drift: -400, alert: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, cleanup := newTestDB(t, test.open...)
+			defer cleanup()
+
+			assets := assetConfig{
+				Instruments: map[string]instrumentConfig{"XBTUSD": {Base: "BTC", Quote: "USD"}},
+				Assets:      []assetDefinition{{Name: "USD", Hedge: "XBTUSD"}},
+			}
+			if err := assets.parse(); err != nil {
+				t.Fatalf("could not parse assets: %v", err)
+			}
+
+			prices := &staticOracle{prices: map[string]float64{"XBTUSD": 10000}}
+			hedger := hedge.NewPaperHedger(prices, 100000000)
+			if test.position > 0 {
+				_, err := hedger.OpenExposure("XBTUSD", test.position)
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if test.position < 0 {
+				_, err := hedger.CloseExposure("XBTUSD", -test.position)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := db.Update(func(tx *bolt.Tx) error {
+				if test.pending != 0 {
+					now, _ := ptypes.TimestampProto(time.Now())
+					side := hedge.Buy
+					if test.pending < 0 {
+						side = hedge.Sell
+					}
+					err := putHedgeJob(tx, &larpc.HedgeJob{
+						ClientOrderId: "pending",
+						Symbol:        "XBTUSD",
+						Side:          string(side),
+						Qty:           math.Abs(test.pending),
+						CreatedAt:     now,
+					})
+					if err != nil {
+						return err
+					}
+				}
+
+				return putHedgeResidual(tx, &larpc.HedgeResidual{Symbol: "XBTUSD", Qty: test.residual})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a := AssetServer{
+				db:          db,
+				assets:      assets,
+				priceOracle: prices,
+				converter:   fx.NewConverter(0),
+				hedger:      hedger,
+				reconciler:  newHedgeReconciler(time.Minute, 100, nil),
+				hedgeJobsMu: &sync.Mutex{},
+			}
+
+			err = a.reconcile()
+			if err != nil {
+				t.Fatalf("could not reconcile: %v", err)
+			}
+
+			drifts := a.reconciler.status()
+			if len(drifts) != 1 {
+				t.Fatalf("got %d drifts, want 1", len(drifts))
+			}
+			drift := drifts[0]
+			if math.Abs(drift.Drift-test.drift) > 1e-9 {
+				t.Fatalf("drift = %f, want %f (%+v)", drift.Drift, test.drift, drift)
+			}
+			if drift.Pending != test.pending || drift.Residual != test.residual {
+				t.Fatalf("pending %f and residual %f, want %f and %f",
+					drift.Pending, drift.Residual, test.pending, test.residual)
+			}
+			if a.reconciler.alerted["XBTUSD"] != test.alert {
+				t.Fatalf("alerted = %v, want %v", a.reconciler.alerted["XBTUSD"], test.alert)
+			}
+
+			// the drift is saved, so ListHedgeDrift returns it
+			res, err := AdminServer{assets: a}.ListHedgeDrift(nil, &larpc.AdminListHedgeDriftRequest{})
+			if err != nil {
+				t.Fatalf("could not list drift: %v", err)
+			}
+			if len(res.Drifts) != 1 || res.Drifts[0].Drift != drift.Drift {
+				t.Fatalf("listed %v, want the drift of the reconciliation", res.Drifts)
+			}
+		})
+	}
+}
+
+func TestCheckDriftAlertsOnce(t *testing.T) {
+	a := AssetServer{reconciler: newHedgeReconciler(time.Minute, 100, nil)}
+
+	tests := []struct {
+		drift   float64
+		correct bool
+	}{
+		{drift: 50},
+		{drift: 150, correct: true},
+		// still over the threshold, so still corrected, but we alerted
+		// already
+		{drift: -150, correct: true},
+		{drift: 99.9},
+		{drift: 100, correct: true},
+	}
+
+	for i, test := range tests {
+		correct := a.checkDrift(&larpc.HedgeDrift{Symbol: "XBTUSD", Drift: test.drift})
+		if correct != test.correct {
+			t.Fatalf("check %d: correct = %v, want %v", i, correct, test.correct)
+		}
+		if a.reconciler.alerted["XBTUSD"] != test.correct {
+			t.Fatalf("check %d: alerted = %v, want %v", i, a.reconciler.alerted["XBTUSD"], test.correct)
+		}
+	}
+}
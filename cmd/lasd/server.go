@@ -6,15 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
+	"time"
 
 	"github.com/boltdb/bolt"
 	"github.com/btcsuite/btcutil"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/uuid"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/sirupsen/logrus"
 
-	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/fx"
+	"github.com/ArcaneCryptoAS/lassets-server/hedge"
 	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
 )
 
 var _ larpc.AssetServerServer = &AssetServer{}
@@ -28,12 +33,52 @@ type AssetServer struct {
 	percentMargin      float64
 	priceServerURL     string
 	breakContractAfter int64
-	bitmexApi          *bitmex.Bitmex
+	hedger             hedge.Hedger
+	priceOracle        oracle.PriceOracle
+	converter          *fx.Converter
+	// maxPriceAge is how old a price can be before we stop acting on it
+	maxPriceAge time.Duration
+	breaker     *oracle.CircuitBreaker
+	// nodePubkey is the identity pubkey of our lnd node, used to sign prices
+	nodePubkey string
+	// attestationRetention is how long the prices we signed are kept. Zero
+	// keeps them forever
+	attestationRetention time.Duration
+	// tickRetention is how long price ticks are kept. Zero keeps them
+	// forever
+	tickRetention time.Duration
+	// prices holds every price we have accepted, and notifies consumers
+	// like the rebalancer of new ones
+	prices *PriceBook
+	// assets defines the assets contracts can be opened in, and the
+	// instruments they are priced and hedged with
+	assets assetConfig
+	// rebalancer decides when contracts in each asset are rebalanced
+	rebalancer *rebalanceScheduler
+	// window keeps recent prices of every symbol, to price assets in their
+	// pricing mode
+	window *oracle.PriceWindow
+	// netHedger hedges the net exposure of all contracts. If nil, every
+	// contract is hedged on its own
+	netHedger *netHedger
+	// reconciler checks that our hedges match our open contracts
+	reconciler *hedgeReconciler
+	// funding records the funding paid on our hedges, and how it is passed
+	// on to clients
+	funding *fundingTracker
+	// leverage limits the contracts we accept to what our exchange margin
+	// can hedge
+	leverage leverageLimit
 
 	// channels
 	paymentsCh          chan larpc.Payment
 	contractCh          chan larpc.ServerContract
 	invoiceSubscription lnrpc.Lightning_SubscribeInvoicesClient
+	// hedgeJobsCh wakes up the worker placing hedge orders
+	hedgeJobsCh chan struct{}
+	// hedgeJobsMu is held by the worker while it runs a job, so
+	// reconciliation never sees a job as both pending and placed
+	hedgeJobsMu *sync.Mutex
 }
 
 func (a AssetServer) NewContract(ctx context.Context, req *larpc.ServerNewContractRequest) (*larpc.ServerNewContractResponse, error) {
@@ -43,27 +88,71 @@ func (a AssetServer) NewContract(ctx context.Context, req *larpc.ServerNewContra
 	if req.Amount <= 0 {
 		return nil, fmt.Errorf("amount can not be 0")
 	}
-	ok := assetIsSupported(req.Asset)
+	asset, ok := a.assets.lookup(req.Asset)
 	if !ok {
-		var supported []string
-		for currency := range prices {
-			supported = append(supported, currency)
+		return nil, fmt.Errorf("asset %s not supported, try one of: %+v", req.Asset, a.assets.names())
+	}
+
+	// refuse to quote prices that are too old, or that we have stopped
+	// rebalancing on because they moved suspiciously much
+	for _, symbol := range a.assets.priceSymbols(asset) {
+		oraclePrice, err := a.priceOracle.LatestPrice(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("could not get price: %w", err)
+		}
+		err = a.checkPriceAge(oraclePrice)
+		if err != nil {
+			return nil, err
+		}
+		if tripped, ok := a.breaker.Tripped(oraclePrice.Symbol); ok {
+			return nil, fmt.Errorf("%s price of %f is awaiting confirmation: %w",
+				tripped.Symbol, tripped.Value, oracle.ErrCircuitBreakerTripped)
 		}
-		return nil, fmt.Errorf("asset %s not supported, try one of: %+v", req.Asset, supported)
+	}
+
+	price, err := a.assetPrice(req.Asset)
+	if err != nil {
+		return nil, fmt.Errorf("could not get price: %w", err)
+	}
+	hedgePrice, err := a.instrumentPrice(asset, asset.Hedge)
+	if err != nil {
+		return nil, fmt.Errorf("could not get %s price: %w", asset.Hedge, err)
 	}
 
 	contract := larpc.ServerContract{
 		Uuid:         uuid.New().String(),
 		Asset:        req.Asset,
 		Amount:       req.Amount,
-		AmountSats:   convertPercentOfAssetToSats(req.Amount, req.Asset, 100),
 		ClientHost:   req.Host,
 		ContractType: req.ContractType,
+		PricingMode:  string(asset.Pricing),
+		HedgePrice:   hedgePrice,
+	}
+
+	// we only issue invoices for contracts we have the exchange margin to
+	// hedge, as the hedge is placed after the client has paid
+	contract.Amount, err = a.checkLeverage(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	// the hedge is sized now, so placing it when the client has paid does
+	// not depend on our prices and exchange rates
+	_, contract.HedgeQty, err = a.hedgeOrder(contract)
+	if err != nil {
+		return nil, fmt.Errorf("could not size hedge of contract: %w", err)
+	}
+
+	contract.AmountSats, err = convertPercentOfAssetToSats(contract.Amount, price, 100)
+	if err != nil {
+		return nil, err
 	}
 
+	percentMargin := a.funding.percentMargin(a.percentMargin, asset.Hedge)
+
 	// all contract types has a margin invoice
 	marginInvoice, err := a.AddInvoice(contract.Uuid, lnrpc.Invoice{
-		Value: int64(math.Round(float64(contract.AmountSats) * a.percentMargin / 100)),
+		Value: int64(math.Round(float64(contract.AmountSats) * percentMargin / 100)),
 		Memo:  contract.Uuid,
 	})
 	if err != nil {
@@ -102,25 +191,136 @@ func (a AssetServer) NewContract(ctx context.Context, req *larpc.ServerNewContra
 		// which is the default value anyways
 		InitiatingPayReq: contract.InitiatingPayReq,
 
-		PercentMargin: a.percentMargin,
-		AssetPrice:    prices[req.Asset],
+		PercentMargin: percentMargin,
+		AssetPrice:    price,
+		PricingMode:   contract.PricingMode,
+		Amount:        contract.Amount,
 	}, nil
 }
 
-func assetIsSupported(asset string) bool {
-	for currency := range prices {
-		if currency == asset {
-			return true
+// assetPrice returns the price of one bitcoin denominated in the given asset,
+// in the pricing mode of the asset. Assets with a price formula are priced
+// from the prices of the instruments in it, all others convert the bitcoin
+// price of their hedge instrument to the asset through our exchange rates
+func (a AssetServer) assetPrice(asset string) (float64, error) {
+	price, _, _, err := a.assetPriceInputs(asset)
+	return price, err
+}
+
+// assetPriceInputs returns the price of the asset like assetPrice, the
+// instrument prices it was computed from, and the exchange rates they were
+// converted with. Assets with a formula have an input per instrument in it
+// and no rates, all others their hedge instrument as the only input
+func (a AssetServer) assetPriceInputs(asset string) (float64, []oracle.Price, []fx.Rate, error) {
+	definition, ok := a.assets.lookup(asset)
+	if !ok {
+		return 0, nil, nil, fmt.Errorf("asset %s not supported", asset)
+	}
+
+	if definition.formula != nil {
+		var inputs []oracle.Price
+		price, err := definition.formula.evaluate(func(symbol string) (float64, error) {
+			input, err := a.instrumentQuote(definition, symbol)
+			if err != nil {
+				return 0, err
+			}
+
+			inputs = append(inputs, input)
+			return input.Value, nil
+		})
+		if err != nil {
+			return 0, nil, nil, err
 		}
+
+		return price, inputs, nil, nil
 	}
-	return false
+
+	hedgePrice, err := a.instrumentQuote(definition, definition.Hedge)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("no price for %s: %w", definition.Hedge, err)
+	}
+	rates, err := a.converter.Path(a.assets.Instruments[definition.Hedge].Quote, asset)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	price := hedgePrice.Value
+	for _, rate := range rates {
+		price *= rate.Value
+	}
+
+	return price, []oracle.Price{hedgePrice}, rates, nil
 }
 
-// convertPercentOfAssetToSats converts a percentage of an amount of a given asset to satoshis
-func convertPercentOfAssetToSats(amount float64, asset string, percent float64) int64 {
-	price := prices[asset]
+// instrumentPrice returns the price of the instrument in the pricing mode
+// of the asset
+func (a AssetServer) instrumentPrice(asset assetDefinition, symbol string) (float64, error) {
+	price, err := a.instrumentQuote(asset, symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	return price.Value, nil
+}
+
+// instrumentQuote returns the price of the instrument in the pricing mode
+// of the asset, along with where and when it was observed
+func (a AssetServer) instrumentQuote(asset assetDefinition, symbol string) (oracle.Price, error) {
+	if asset.Pricing == pricingTWAP {
+		return a.window.TWAP(symbol, asset.TWAPWindow.Duration, time.Now())
+	}
+
+	return a.window.Latest(a.assets.priceSymbol(asset, symbol))
+}
+
+// hedgeOrder returns the instrument to hedge a contract with, and the
+// amount of it to trade. Hedge instruments are inverse contracts worth one
+// unit of their quote currency each, so the amount is the contract amount
+// in the quote currency of the instrument. It is fixed when the contract is quoted, and only
+// converted from the contract amount for contracts quoted before that
+func (a AssetServer) hedgeOrder(contract larpc.ServerContract) (string, float64, error) {
+	asset, ok := a.assets.lookup(contract.Asset)
+	if !ok {
+		return "", 0, fmt.Errorf("asset %s not supported", contract.Asset)
+	}
+	if contract.HedgeQty != 0 {
+		return asset.Hedge, contract.HedgeQty, nil
+	}
+
+	instrument := a.assets.Instruments[asset.Hedge]
+	amount, err := a.convertAssetAmount(contract.Asset, contract.Amount, instrument.Quote)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not convert contract amount: %w", err)
+	}
+
+	return asset.Hedge, amount, nil
+}
+
+// convertPercentOfAssetToSats converts a percentage of an amount of an asset
+// with the given price to satoshis
+func convertPercentOfAssetToSats(amount float64, price float64, percent float64) (int64, error) {
+	if price <= 0 {
+		return 0, fmt.Errorf("can not convert to sats with a price of %f", price)
+	}
+
 	amountSat := (amount / price) * btcutil.SatoshiPerBitcoin
-	return int64(math.Round(amountSat * percent / 100))
+	return int64(math.Round(amountSat * percent / 100)), nil
+}
+
+// checkPriceAge returns ErrStalePrice if the price is older than our max
+// price age
+func (a AssetServer) checkPriceAge(price oracle.Price) error {
+	if price.Value <= 0 {
+		return fmt.Errorf("%s has invalid price %f", price.Symbol, price.Value)
+	}
+
+	age := time.Since(price.Timestamp)
+	if age > a.maxPriceAge {
+		return fmt.Errorf("%s price is %s old: %w", price.Symbol,
+			age.Round(time.Second), ErrStalePrice)
+	}
+
+	return nil
 }
 
 func saveContract(db *bolt.DB, contractCh chan larpc.ServerContract, contract larpc.ServerContract) error {
@@ -180,102 +380,174 @@ func (a AssetServer) CloseContract(ctx context.Context, req *larpc.ServerCloseCo
 		return nil, fmt.Errorf("could not find or unmarshal contract: %w", err)
 	}
 
-	// if the contract is not open, we do not yet have long exposure for the contract on bitmex
-	if contractIsOpen(contract) {
-		// close position on equal size bitmex, always convert to USD
-		sellAmount := convertAssetAmount(contract.Asset, contract.Amount, "USD")
-		_, _, err = a.bitmexApi.MarketSell(sellAmount)
-		if err != nil {
-			return nil, fmt.Errorf("could not market sell: %w", err)
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		// if the contract is not open, we do not yet have long exposure for the contract
+		if contractIsOpen(contract) {
+			// close position of equal size
+			err := a.closeHedge(tx, contract)
+			if err != nil {
+				return err
+			}
+
+			err = a.putClosedContract(tx, contract)
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	err = deleteContract(a.db, req.Uuid)
+		return tx.Bucket(contractsBucket).Delete([]byte(req.Uuid))
+	})
 	if err != nil {
 		return nil, fmt.Errorf("closecontract could not delete contract: %w", err)
 	}
 
+	a.notifyHedgeJobs()
+	if a.netHedger != nil {
+		a.netHedger.contractsChanged()
+	}
+
 	return &larpc.ServerCloseContractResponse{}, nil
 }
 
-
 func (a AssetServer) ListAssets(ctx context.Context, req *larpc.ServerListAssetsRequest) (*larpc.ServerListAssetsResponse, error) {
 
-	supportedAssets := make([]string, len(prices))
+	var assets []*larpc.Asset
+	for _, definition := range a.assets.Assets {
+		asset := &larpc.Asset{
+			Name:            definition.Name,
+			PriceFormula:    definition.Price,
+			HedgeInstrument: definition.Hedge,
+			PricingMode:     string(definition.Pricing),
+		}
+		if definition.Pricing == pricingTWAP {
+			asset.TwapWindowSeconds = int64(definition.TWAPWindow.Seconds())
+		}
+
+		// assets we do not have a price for yet are still listed
+		price, err := a.assetPrice(definition.Name)
+		if err == nil {
+			asset.Price = price
+		}
+
+		trigger, lastRebalancedAt := a.rebalancer.status(definition.Name)
+		asset.RebalanceBps = trigger.bps
+		asset.MinRebalanceIntervalSeconds = int64(trigger.minInterval.Seconds())
+		asset.ForceRebalanceIntervalSeconds = int64(trigger.forceInterval.Seconds())
+		if !lastRebalancedAt.IsZero() {
+			asset.LastRebalancedAt, err = ptypes.TimestampProto(lastRebalancedAt)
+			if err != nil {
+				return nil, err
+			}
+		}
 
-	for asset, _ := range prices {
-		supportedAssets = append(supportedAssets, asset)
+		assets = append(assets, asset)
 	}
 
 	return &larpc.ServerListAssetsResponse{
-		SupportedAssets:      supportedAssets,
-		XXX_NoUnkeyedLiteral: struct{}{},
-		XXX_unrecognized:     nil,
-		XXX_sizecache:        0,
+		SupportedAssets: a.assets.names(),
+		Assets:          assets,
 	}, nil
 }
 
-func deleteContract(db *bolt.DB, uuid string) error {
-	return db.Update(func(tx *bolt.Tx) error {
+// listContracts returns every contract in the database
+func listContracts(db *bolt.DB) ([]larpc.ServerContract, error) {
+	var contracts []larpc.ServerContract
+	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(contractsBucket)
 
-		return b.Delete([]byte(uuid))
-	})
-}
+		return b.ForEach(func(k, v []byte) error {
+			var contract larpc.ServerContract
 
-func (a AssetServer) SetPrice(asset string, amount float64) error {
+			if err := json.Unmarshal(v, &contract); err != nil {
+				return fmt.Errorf("could not unmarshal contract %q: %w", string(v), err)
+			}
 
-	_, ok := prices[asset]
-	if !ok {
-		return fmt.Errorf("asset does not exist: %s", asset)
+			contracts = append(contracts, contract)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	prices[asset] = amount
+	return contracts, nil
+}
 
-	// set price for all supported currencies
-	for to := range prices {
-		// skip if equal, price is already set
-		if to == asset {
-			continue
-		}
-		prices[to] = convertAssetAmount(asset, amount, to)
-	}
+// SetPrice is called for every new price accepted by our price oracle. If
+// we are willing to act on the price, it is attested and put in our price
+// book, which contracts are rebalanced from
+func (a AssetServer) SetPrice(price oracle.Price) error {
 
 	log.WithFields(logrus.Fields{
-		"asset": asset,
-		"price": amount,
-	}).Info("saved new price")
-
-	err := a.rebalanceContracts()
+		"symbol": price.Symbol,
+		"price":  price.Value,
+		"source": price.Source,
+	}).Debug("received new price")
+
+	// only rebalance on prices our oracle currently stands behind. For an
+	// aggregated oracle, this means a quorum of sources agree on the price
+	current, err := a.priceOracle.LatestPrice(price.Symbol)
 	if err != nil {
-		return err
+		return fmt.Errorf("not rebalancing contracts: %w", err)
 	}
 
-	return nil
-}
+	// do not act on prices that are too old, or that moved so much in a
+	// single tick that they need to be confirmed first
+	err = a.checkPriceAge(current)
+	if err != nil {
+		return fmt.Errorf("not rebalancing contracts: %w", err)
+	}
+	err = a.breaker.Check(current)
+	if err != nil {
+		return fmt.Errorf("not rebalancing contracts: %w", err)
+	}
 
-// getExchangeRate gets and exchange rate for the given pair
-// TODO: Hook into an api here instead of hard-coding rates..
-func getExchangeRate(base, quote string) float64 {
-	const nokusd = 9.1
+	if !a.assets.knows(current.Symbol) {
+		return fmt.Errorf("symbol %s is not an instrument or index we know of", current.Symbol)
+	}
 
-	if base == "NOK" && quote == "USD" { // NOK/USD
-		// usd is at the bottom, and we divide whatever other price on usd
-		return 1 / nokusd
+	// only prices we accepted make it into our price history
+	err = savePriceTick(a.db, current)
+	if err != nil {
+		return fmt.Errorf("could not save price: %w", err)
+	}
+	a.window.Add(current)
+
+	// mark and index prices are only used for pricing assets, while the
+	// last price of instruments is also our exchange rate
+	if instrument, ok := a.assets.Instruments[current.Symbol]; ok {
+		err = a.converter.SetRate(fx.Rate{
+			Base:      instrument.Base,
+			Quote:     instrument.Quote,
+			Value:     current.Value,
+			Timestamp: current.Timestamp,
+			Source:    current.Source,
+		})
+		if err != nil {
+			return fmt.Errorf("could not set exchange rate: %w", err)
+		}
 	}
 
-	if base == "USD" && quote == "NOK" { // USD/NOK
-		return nokusd
+	// the asset prices are only signed if contracts are rebalanced on them
+	quotes, err := a.quotePrices(current)
+	if err != nil {
+		return fmt.Errorf("could not quote prices: %w", err)
 	}
 
-	// the pairs are the same, and we just return 1
-	return 1
-}
+	update := a.prices.Set(current, quotes)
+	log.WithFields(logrus.Fields{
+		"symbol":  update.Symbol,
+		"version": update.Version,
+	}).Debug("updated price book")
 
-func convertAssetAmount(from string, amount float64, to string) float64 {
-	rate := getExchangeRate(from, to)
+	return nil
+}
 
-	return amount * rate
+// convertAssetAmount converts an amount of one asset to another, using our
+// latest exchange rates. It fails if we do not know of a rate between them
+func (a AssetServer) convertAssetAmount(from string, amount float64, to string) (float64, error) {
+	return a.converter.Convert(from, amount, to)
 }
 
 func savePayment(db *bolt.DB, paymentCh chan larpc.Payment, payment larpc.Payment) error {
@@ -305,8 +577,7 @@ func savePayment(db *bolt.DB, paymentCh chan larpc.Payment, payment larpc.Paymen
 }
 
 // calculateRebalanceAmount calculates the amount needed to rebalance a channel
-func calculateRebalanceAmount(contract larpc.ServerContract) (rebalanceType, int64) {
-	price := prices[contract.Asset]
+func calculateRebalanceAmount(contract larpc.ServerContract, price float64) (rebalanceType, int64) {
 	if price == 0 {
 		return "", 0
 	}
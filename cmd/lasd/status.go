@@ -0,0 +1,140 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+// defaultFeedAlertAfter is how long a feed can be disconnected before we
+// alert the operator
+var defaultFeedAlertAfter = 2 * time.Minute
+
+// feedStatus is the connection state of a single realtime feed
+type feedStatus struct {
+	state     bitmex.ConnectionState
+	since     time.Time
+	lastError error
+	// alerted is set when the operator has been alerted that the feed is
+	// down, so we only alert once per outage
+	alerted bool
+}
+
+// feedMonitor keeps track of the connection state of our realtime feeds,
+// and alerts the operator when one of them has been down for too long
+type feedMonitor struct {
+	alertAfter time.Duration
+
+	mu    sync.Mutex
+	feeds map[string]*feedStatus
+}
+
+func newFeedMonitor(alertAfter time.Duration) *feedMonitor {
+	return &feedMonitor{
+		alertAfter: alertAfter,
+		feeds:      make(map[string]*feedStatus),
+	}
+}
+
+// callback returns a function that records state changes of the named feed,
+// suitable for passing to OnStateChange
+func (m *feedMonitor) callback(name string) func(bitmex.ConnectionState, error) {
+	m.mu.Lock()
+	m.feeds[name] = &feedStatus{
+		state: bitmex.Disconnected,
+		since: time.Now(),
+	}
+	m.mu.Unlock()
+
+	return func(state bitmex.ConnectionState, err error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		status := m.feeds[name]
+		logger := log.WithField("feed", name)
+
+		if err != nil {
+			status.lastError = err
+		}
+
+		switch {
+		case state == bitmex.Connected:
+			if status.alerted {
+				logger.Infof("feed reconnected after being down for %s",
+					time.Since(status.since).Round(time.Second))
+			}
+			status.alerted = false
+			status.state = state
+			status.since = time.Now()
+
+		// going from disconnected to connecting and back again is one
+		// outage, so we only reset the timer when we lose a connection
+		case status.state == bitmex.Connected:
+			logger.WithError(err).Warn("feed disconnected")
+			status.state = state
+			status.since = time.Now()
+
+		default:
+			status.state = state
+		}
+	}
+}
+
+// Watch alerts the operator every time a feed has been down for longer
+// than alertAfter
+// NOTE: MUST be run in a goroutine
+func (m *feedMonitor) Watch() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.mu.Lock()
+		for name, status := range m.feeds {
+			down := time.Since(status.since)
+			if status.state == bitmex.Connected || status.alerted || down < m.alertAfter {
+				continue
+			}
+
+			status.alerted = true
+			log.WithError(status.lastError).WithField("feed", name).
+				Errorf("ALERT: feed has been down for %s", down.Round(time.Second))
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Status returns the status of every feed, sorted by name
+func (m *feedMonitor) Status() ([]*larpc.FeedStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var statuses []*larpc.FeedStatus
+	for name, status := range m.feeds {
+		since, err := ptypes.TimestampProto(status.since)
+		if err != nil {
+			return nil, err
+		}
+
+		feed := &larpc.FeedStatus{
+			Name:  name,
+			State: status.state.String(),
+			Since: since,
+		}
+		if status.lastError != nil {
+			feed.LastError = status.lastError.Error()
+		}
+
+		statuses = append(statuses, feed)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+)
+
+func (a AssetServer) SubscribePrices(req *larpc.ServerSubscribePricesRequest, stream larpc.AssetServer_SubscribePricesServer) error {
+	for _, asset := range req.Assets {
+		if _, ok := a.assets.lookup(asset); !ok {
+			return fmt.Errorf("asset %s not supported, try one of: %+v", asset, a.assets.names())
+		}
+	}
+
+	// subscribe before sending the latest prices, so we do not miss any
+	// update in between
+	updates, cancel := a.prices.Subscribe()
+	defer cancel()
+
+	send := func(update PriceUpdate) error {
+		res, ok, err := priceUpdateToProto(update, req.Assets)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		err = stream.Send(res)
+		if err != nil {
+			return fmt.Errorf("could not send price update: %w", err)
+		}
+
+		return nil
+	}
+
+	// updates already in the latest prices may be in the subscription as well
+	var snapshot uint64
+	for _, update := range a.prices.All() {
+		err := send(update)
+		if err != nil {
+			return err
+		}
+
+		if update.Version > snapshot {
+			snapshot = update.Version
+		}
+	}
+
+	log.WithField("assets", req.Assets).Info("client subscribed to prices")
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if update.Version <= snapshot {
+				continue
+			}
+
+			err := send(update)
+			if err != nil {
+				return err
+			}
+
+		case <-stream.Context().Done():
+			log.WithField("assets", req.Assets).Info("client unsubscribed from prices")
+			return nil
+		}
+	}
+}
+
+// priceUpdateToProto converts a price update to the message we stream to
+// clients, keeping only the attestations of the given assets. If assets is
+// not empty and none of them are affected by the update, false is returned
+func priceUpdateToProto(update PriceUpdate, assets []string) (*larpc.ServerPriceUpdate, bool, error) {
+	timestamp, err := ptypes.TimestampProto(update.Timestamp)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not convert price timestamp: %w", err)
+	}
+
+	filtered := len(assets) > 0
+
+	res := &larpc.ServerPriceUpdate{
+		Symbol:    update.Symbol,
+		Price:     update.Value,
+		Source:    update.Source,
+		Timestamp: timestamp,
+		Version:   update.Version,
+	}
+
+	if len(assets) == 0 {
+		for asset := range update.Attestations {
+			assets = append(assets, asset)
+		}
+		sort.Strings(assets)
+	}
+	for _, asset := range assets {
+		if attestation, ok := update.Attestations[asset]; ok {
+			res.Attestations = append(res.Attestations, attestation)
+		}
+	}
+
+	if filtered && len(res.Attestations) == 0 {
+		return nil, false, nil
+	}
+
+	return res, true, nil
+}
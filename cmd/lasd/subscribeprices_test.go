@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArcaneCryptoAS/lassets-server/larpc"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+func TestPriceUpdateToProto(t *testing.T) {
+	update := PriceUpdate{
+		Price:   oracle.Price{Symbol: "XBTUSD", Value: 10000, Source: "bitmex", Timestamp: time.Now()},
+		Version: 7,
+		Attestations: map[string]*larpc.PriceAttestation{
+			"USD": {Asset: "USD", AssetPrice: 10000},
+			"NOK": {Asset: "NOK", AssetPrice: 100000},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		assets []string
+		// want are the assets of the attestations sent, in order. Empty
+		// means the update is not sent
+		want string
+	}{
+		{name: "all assets, sorted", want: "NOK,USD"},
+		{name: "one asset", assets: []string{"USD"}, want: "USD"},
+		{name: "in the requested order", assets: []string{"USD", "NOK"}, want: "USD,NOK"},
+		{name: "asset not affected", assets: []string{"EUR"}},
+		{name: "affected and unaffected asset", assets: []string{"EUR", "NOK"}, want: "NOK"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, ok, err := priceUpdateToProto(update, test.assets)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != (test.want != "") {
+				t.Fatalf("sent = %v, want %v", ok, test.want != "")
+			}
+			if !ok {
+				return
+			}
+
+			var assets []string
+			for _, attestation := range res.Attestations {
+				assets = append(assets, attestation.Asset)
+			}
+			if strings.Join(assets, ",") != test.want {
+				t.Fatalf("attestations of %v, want %s", assets, test.want)
+			}
+			if res.Symbol != "XBTUSD" || res.Price != 10000 || res.Version != 7 {
+				t.Fatalf("update = %v, want the price and version of the update", res)
+			}
+		})
+	}
+}
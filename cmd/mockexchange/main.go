@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex/mock"
+	"github.com/ArcaneCryptoAS/lassets-server/build"
+)
+
+var (
+	defaultPort         = 8090
+	defaultInstruments  = bitmex.XBTUSD + "=7500"
+	defaultVolatility   = 0.05
+	defaultTickInterval = 5 * time.Second
+	// bitmex charges funding every 8 hours
+	defaultFundingInterval = 8 * time.Hour
+	defaultFundingRate     = 0.0001
+)
+
+const (
+	flag_port            = "port"
+	flag_instruments     = "instruments"
+	flag_volatility      = "volatility"
+	flag_tickinterval    = "tickinterval"
+	flag_balance         = "balance"
+	flag_fundingrate     = "fundingrate"
+	flag_fundinginterval = "fundinginterval"
+	flag_ratelimit       = "ratelimit"
+)
+
+var log = logrus.New()
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "mockexchange"
+	app.Version = build.Version()
+	app.Usage = "mock bitmex exchange, used by lasd on regtest"
+	app.Flags = []cli.Flag{
+		cli.IntFlag{
+			Name:  flag_port,
+			Value: defaultPort,
+			Usage: "port to serve the REST and realtime api on",
+		},
+		cli.StringFlag{
+			Name:  flag_instruments,
+			Value: defaultInstruments,
+			Usage: "comma separated list of instruments to trade and the price they start at, e.g. XBTUSD=7500,ETHUSD=150",
+		},
+		cli.Float64Flag{
+			Name:  flag_volatility,
+			Value: defaultVolatility,
+			Usage: "standard deviation of each price move, in percent",
+		},
+		cli.DurationFlag{
+			Name:  flag_tickinterval,
+			Value: defaultTickInterval,
+			Usage: "how often the price moves",
+		},
+		cli.Int64Flag{
+			Name:  flag_balance,
+			Value: mock.DefaultBalance,
+			Usage: "wallet balance of the account, in satoshis",
+		},
+		cli.Float64Flag{
+			Name:  flag_fundingrate,
+			Value: defaultFundingRate,
+			Usage: "funding rate charged every funding interval, paid by longs to shorts when positive",
+		},
+		cli.DurationFlag{
+			Name:  flag_fundinginterval,
+			Value: defaultFundingInterval,
+			Usage: "how often funding is charged",
+		},
+		cli.IntFlag{
+			Name:  flag_ratelimit,
+			Usage: "how many REST requests are served every minute, zero is no limit",
+		},
+	}
+	app.Action = runMockExchange
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatalf("[mockexchange]: %v", err)
+	}
+}
+
+func runMockExchange(c *cli.Context) error {
+	prices, err := parseInstruments(c.String(flag_instruments))
+	if err != nil {
+		return err
+	}
+
+	exchange := mock.NewExchange(prices, c.Float64(flag_volatility))
+	exchange.SetBalance(c.Int64(flag_balance))
+	exchange.SetFundingRate(c.Float64(flag_fundingrate))
+	exchange.SetRateLimit(c.Int(flag_ratelimit))
+	go exchange.Run(c.Duration(flag_tickinterval))
+	go exchange.RunFunding(c.Duration(flag_fundinginterval))
+
+	log.Infof("mock exchange listening on port %d", c.Int(flag_port))
+	return http.ListenAndServe(fmt.Sprintf(":%d", c.Int(flag_port)), exchange.Handler())
+}
+
+// parseInstruments parses a list on the form XBTUSD=7500,ETHUSD=150
+func parseInstruments(instruments string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for _, instrument := range strings.Split(instruments, ",") {
+		parts := strings.Split(strings.TrimSpace(instrument), "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("instrument %q is not on the form SYMBOL=PRICE", instrument)
+		}
+
+		price, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("invalid price for %s: %q", parts[0], parts[1])
+		}
+		prices[parts[0]] = price
+	}
+
+	return prices, nil
+}
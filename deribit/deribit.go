@@ -0,0 +1,445 @@
+// Package deribit trades perpetual futures on deribit through its v2 api,
+// and streams their prices. Deribit perpetuals like BTC-PERPETUAL are
+// inverse contracts whose amount is denominated in dollars, just like
+// XBTUSD on bitmex, so they can hedge the same contracts.
+package deribit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// BTCPerpetual is the deribit perpetual bitcoin/dollar swap
+const BTCPerpetual = "BTC-PERPETUAL"
+
+// BTC is the currency deribit denominates margin in for bitcoin
+// instruments
+const BTC = "BTC"
+
+const (
+	OrderTypeMarket = "market"
+	OrderTypeLimit  = "limit"
+)
+
+// requestTimeout is how long we wait for deribit to answer a request
+const requestTimeout = 10 * time.Second
+
+// Errors returned by the deribit api are an *Error, which can be compared
+// to these with errors.Is
+var (
+	ErrRateLimited       = errors.New("rate limited")
+	ErrOverloaded        = errors.New("system overloaded")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidQty        = errors.New("invalid order quantity")
+	ErrAuth              = errors.New("authentication failed")
+)
+
+// error codes deribit returns
+const (
+	codeAuthorizationRequired = 10000
+	codeQtyTooLow             = 10002
+	codeNotEnoughFunds        = 10009
+	codeTooManyRequests       = 10028
+	codeSettlementInProgress  = 10040
+	codeSystemMaintenance     = 11051
+	codeInvalidCredentials    = 13004
+	codeUnauthorized          = 13009
+)
+
+// Error is an error returned by the deribit api
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("deribit error %d: %s", e.Code, e.Message)
+}
+
+// Unwrap returns which of our errors the error is, if any
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case codeTooManyRequests:
+		return ErrRateLimited
+	case codeSettlementInProgress, codeSystemMaintenance:
+		return ErrOverloaded
+	case codeAuthorizationRequired, codeInvalidCredentials, codeUnauthorized:
+		return ErrAuth
+	case codeNotEnoughFunds:
+		return ErrInsufficientFunds
+	case codeQtyTooLow:
+		return ErrInvalidQty
+	}
+
+	// amounts that are not a multiple of the contract size are rejected
+	// with several codes, but always mention it
+	if strings.Contains(strings.ToLower(e.Message), "contract size") {
+		return ErrInvalidQty
+	}
+
+	return nil
+}
+
+// Order is an order on deribit
+type Order struct {
+	OrderID        string `json:"order_id"`
+	Label          string `json:"label"`
+	InstrumentName string `json:"instrument_name"`
+	// Direction is buy or sell
+	Direction string `json:"direction"`
+	// Amount is the size of the order, in dollars for inverse contracts
+	Amount       float64 `json:"amount"`
+	FilledAmount float64 `json:"filled_amount"`
+	// AveragePrice is the average price the order was filled at
+	AveragePrice float64 `json:"average_price"`
+	OrderType    string  `json:"order_type"`
+	// OrderState is open, filled, rejected, cancelled or untriggered
+	OrderState string `json:"order_state"`
+}
+
+// Trade is a single fill of an order
+type Trade struct {
+	TradeID string  `json:"trade_id"`
+	Amount  float64 `json:"amount"`
+	Price   float64 `json:"price"`
+	// Fee is what we paid for the trade, in FeeCurrency
+	Fee         float64 `json:"fee"`
+	FeeCurrency string  `json:"fee_currency"`
+}
+
+// OrderResult is the order placed by a buy or sell, and the trades it
+// was filled with right away
+type OrderResult struct {
+	Order  Order   `json:"order"`
+	Trades []Trade `json:"trades"`
+}
+
+// Position is our position in an instrument
+type Position struct {
+	InstrumentName string `json:"instrument_name"`
+	// Size is the size of the position in dollars, negative for short
+	// positions
+	Size         float64 `json:"size"`
+	AveragePrice float64 `json:"average_price"`
+	// Direction is buy, sell or zero
+	Direction string `json:"direction"`
+}
+
+// AccountSummary is the balance of one currency of our account
+type AccountSummary struct {
+	Currency string `json:"currency"`
+	// Balance is the deposits and realised profit of the account
+	Balance float64 `json:"balance"`
+	Equity  float64 `json:"equity"`
+	// AvailableFunds is what is left to open new positions with
+	AvailableFunds float64 `json:"available_funds"`
+}
+
+// Instrument describes an instrument traded on deribit
+type Instrument struct {
+	InstrumentName string `json:"instrument_name"`
+	// ContractSize is the amount every order must be a multiple of
+	ContractSize   float64 `json:"contract_size"`
+	TickSize       float64 `json:"tick_size"`
+	MinTradeAmount float64 `json:"min_trade_amount"`
+}
+
+// TransactionTypeSettlement is the type of the transaction log entries
+// funding of perpetuals is settled in
+const TransactionTypeSettlement = "settlement"
+
+// Transaction is an entry of the transaction log of our account
+type Transaction struct {
+	ID             int64  `json:"id"`
+	Type           string `json:"type"`
+	InstrumentName string `json:"instrument_name"`
+	Currency       string `json:"currency"`
+	// InterestPL is the funding we received when settling perpetuals,
+	// negative if we paid it
+	InterestPL float64 `json:"interest_pl"`
+	// Position is the size of our position when the transaction happened
+	Position float64 `json:"position"`
+	Price    float64 `json:"price"`
+	// Timestamp is in milliseconds
+	Timestamp int64 `json:"timestamp"`
+}
+
+// Deribit is a client for the deribit v2 REST api. Private methods are
+// authenticated with an access token we request with our client
+// credentials, and renew before it expires
+type Deribit struct {
+	url          string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// New creates a deribit client sending requests to the REST api at
+// basePath, e.g. https://www.deribit.com/api/v2
+func New(clientID, clientSecret, basePath string) *Deribit {
+	return &Deribit{
+		url:          basePath,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Buy places an order buying amount of the instrument. price is ignored
+// for market orders. label is our own id of the order, which it can be
+// looked up by with OrderByLabel
+func (d *Deribit) Buy(instrument string, amount float64, orderType string, price float64,
+	label string) (OrderResult, error) {
+
+	return d.placeOrder("private/buy", instrument, amount, orderType, price, label)
+}
+
+// Sell places an order selling amount of the instrument. price is ignored
+// for market orders. label is our own id of the order, which it can be
+// looked up by with OrderByLabel
+func (d *Deribit) Sell(instrument string, amount float64, orderType string, price float64,
+	label string) (OrderResult, error) {
+
+	return d.placeOrder("private/sell", instrument, amount, orderType, price, label)
+}
+
+func (d *Deribit) placeOrder(method, instrument string, amount float64, orderType string,
+	price float64, label string) (OrderResult, error) {
+
+	if amount <= 0 {
+		return OrderResult{}, errors.New("amount must be positive")
+	}
+
+	params := url.Values{
+		"instrument_name": {instrument},
+		"amount":          {strconv.FormatFloat(amount, 'f', -1, 64)},
+		"type":            {orderType},
+	}
+	if orderType == OrderTypeLimit {
+		if price <= 0 {
+			return OrderResult{}, errors.New("price must be positive")
+		}
+		params.Set("price", strconv.FormatFloat(price, 'f', -1, 64))
+	}
+	if label != "" {
+		params.Set("label", label)
+	}
+
+	var result OrderResult
+	err := d.call(method, params, &result)
+	if err != nil {
+		return OrderResult{}, fmt.Errorf("could not place order: %w", err)
+	}
+
+	return result, nil
+}
+
+// OrderByLabel returns our order in the instrument with the given label,
+// and false if there is none. Both open orders and our order history are
+// searched
+func (d *Deribit) OrderByLabel(instrument, label string) (Order, bool, error) {
+	params := url.Values{"instrument_name": {instrument}}
+
+	var open []Order
+	err := d.call("private/get_open_orders_by_instrument", params, &open)
+	if err != nil {
+		return Order{}, false, fmt.Errorf("could not get open orders: %w", err)
+	}
+
+	history := url.Values{
+		"instrument_name": {instrument},
+		"count":           {"100"},
+	}
+	var closed []Order
+	err = d.call("private/get_order_history_by_instrument", history, &closed)
+	if err != nil {
+		return Order{}, false, fmt.Errorf("could not get order history: %w", err)
+	}
+
+	for _, order := range append(open, closed...) {
+		if order.Label == label {
+			return order, true, nil
+		}
+	}
+
+	return Order{}, false, nil
+}
+
+// CancelAll cancels all our open orders in the instrument
+func (d *Deribit) CancelAll(instrument string) error {
+	var cancelled int
+	err := d.call("private/cancel_all_by_instrument", url.Values{
+		"instrument_name": {instrument},
+	}, &cancelled)
+	if err != nil {
+		return fmt.Errorf("could not cancel orders: %w", err)
+	}
+
+	return nil
+}
+
+// Position returns our position in the instrument
+func (d *Deribit) Position(instrument string) (Position, error) {
+	var position Position
+	err := d.call("private/get_position", url.Values{
+		"instrument_name": {instrument},
+	}, &position)
+	if err != nil {
+		return Position{}, fmt.Errorf("could not get position: %w", err)
+	}
+
+	return position, nil
+}
+
+// AccountSummary returns the balance of the given currency of our account
+func (d *Deribit) AccountSummary(currency string) (AccountSummary, error) {
+	var summary AccountSummary
+	err := d.call("private/get_account_summary", url.Values{
+		"currency": {currency},
+	}, &summary)
+	if err != nil {
+		return AccountSummary{}, fmt.Errorf("could not get account summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// TransactionLog returns the entries of the transaction log of the given
+// currency between start and end
+func (d *Deribit) TransactionLog(currency string, start, end time.Time) ([]Transaction, error) {
+	var res struct {
+		Logs []Transaction `json:"logs"`
+	}
+	err := d.call("private/get_transaction_log", url.Values{
+		"currency":        {currency},
+		"start_timestamp": {strconv.FormatInt(millis(start), 10)},
+		"end_timestamp":   {strconv.FormatInt(millis(end), 10)},
+		"count":           {"1000"},
+	}, &res)
+	if err != nil {
+		return nil, fmt.Errorf("could not get transaction log: %w", err)
+	}
+
+	return res.Logs, nil
+}
+
+// millis returns t in milliseconds since the epoch
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// Instrument returns the description of the instrument
+func (d *Deribit) Instrument(instrument string) (Instrument, error) {
+	var res Instrument
+	err := d.call("public/get_instrument", url.Values{
+		"instrument_name": {instrument},
+	}, &res)
+	if err != nil {
+		return Instrument{}, fmt.Errorf("could not get instrument: %w", err)
+	}
+
+	return res, nil
+}
+
+// token returns our access token, requesting a new one if we have none or
+// it is about to expire
+func (d *Deribit) token() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.accessToken != "" && time.Now().Before(d.expiresAt) {
+		return d.accessToken, nil
+	}
+
+	var auth struct {
+		AccessToken string `json:"access_token"`
+		// ExpiresIn is how many seconds the token is valid for
+		ExpiresIn int64 `json:"expires_in"`
+	}
+	err := d.do("public/auth", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {d.clientID},
+		"client_secret": {d.clientSecret},
+	}, "", &auth)
+	if err != nil {
+		return "", fmt.Errorf("could not authenticate: %w", err)
+	}
+
+	// renew the token well before it expires, so it does not expire
+	// while a request is underway
+	d.accessToken = auth.AccessToken
+	d.expiresAt = time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second / 2)
+
+	return d.accessToken, nil
+}
+
+// call calls an api method, authenticating private methods
+func (d *Deribit) call(method string, params url.Values, result interface{}) error {
+	var token string
+	if strings.HasPrefix(method, "private/") {
+		var err error
+		token, err = d.token()
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.do(method, params, token, result)
+}
+
+// do sends a single request, and unmarshals the result of the response
+// into result
+func (d *Deribit) do(method string, params url.Values, token string, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, d.url+"/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil && res.StatusCode >= http.StatusInternalServerError {
+		// gateways in front of deribit answer without a json body when it
+		// is down
+		return fmt.Errorf("%s: %s: %w", method, res.Status, ErrOverloaded)
+	}
+	if err != nil {
+		return fmt.Errorf("could not decode %s response (%s): %w", method, res.Status, err)
+	}
+	if body.Error != nil {
+		return body.Error
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", method, res.Status)
+	}
+
+	log.WithField("method", method).Trace("called deribit")
+
+	return json.Unmarshal(body.Result, result)
+}
@@ -0,0 +1,45 @@
+package deribit
+
+import (
+	"fmt"
+)
+
+// Endpoints are the urls of the deribit REST and websocket apis
+type Endpoints struct {
+	REST     string
+	Realtime string
+}
+
+var (
+	MainnetEndpoints = Endpoints{
+		REST:     "https://www.deribit.com/api/v2",
+		Realtime: "wss://www.deribit.com/ws/api/v2",
+	}
+
+	TestnetEndpoints = Endpoints{
+		REST:     "https://test.deribit.com/api/v2",
+		Realtime: "wss://test.deribit.com/ws/api/v2",
+	}
+
+	// LocalEndpoints point to a mock exchange running on this machine,
+	// started with `mockderibit`
+	LocalEndpoints = Endpoints{
+		REST:     "http://localhost:8091/api/v2",
+		Realtime: "ws://localhost:8091/ws/api/v2",
+	}
+)
+
+// EndpointsForNetwork returns the endpoints that match the given bitcoin
+// network
+func EndpointsForNetwork(network string) (Endpoints, error) {
+	switch network {
+	case "mainnet":
+		return MainnetEndpoints, nil
+	case "testnet":
+		return TestnetEndpoints, nil
+	case "regtest", "simnet":
+		return LocalEndpoints, nil
+	}
+
+	return Endpoints{}, fmt.Errorf("unknown network %q", network)
+}
@@ -0,0 +1,722 @@
+// Package mock implements a small subset of the deribit REST and websocket
+// apis, so lasd can be run against a local deribit on regtest.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/deribit"
+)
+
+var log = logrus.New()
+
+// DefaultBalance is the balance the account starts with, in bitcoin
+const DefaultBalance = 1.0
+
+// takerFee is the fee charged for every order, as a fraction of its value
+const takerFee = 0.0005
+
+// tokenLifetime is how long access tokens are valid
+const tokenLifetime = 15 * time.Minute
+
+// error codes used by deribit
+const (
+	codeUnauthorized      = 13009
+	codeInvalidParams     = -32602
+	codeMethodNotFound    = -32601
+	codeInstrumentUnknown = 10020
+	codeInvalidAmount     = 10011
+)
+
+// Exchange is a mock deribit exchange. Market orders fill immediately at
+// the current price, and limit orders fill immediately at their limit
+// price. Every instrument is treated as an inverse contract like
+// BTC-PERPETUAL, held without leverage.
+type Exchange struct {
+	// volatility is the standard deviation of each price move, in percent
+	volatility float64
+
+	mu sync.Mutex
+	// balance is the balance of the account, in bitcoin
+	balance     float64
+	instruments map[string]*instrument
+	orders      []deribit.Order
+	tokens      map[string]time.Time
+	// fundingRate is the rate settled every funding interval, paid by
+	// longs to shorts when positive
+	fundingRate float64
+	// transactions are the funding settlements so far
+	transactions []deribit.Transaction
+	// subscribers are websocket connections, with the instruments they are
+	// subscribed to
+	subscribers map[*subscriber]map[string]bool
+
+	upgrader websocket.Upgrader
+}
+
+// instrument is the state of a single instrument on the exchange
+type instrument struct {
+	price        float64
+	contractSize float64
+	position     float64
+	avgPrice     float64
+}
+
+// subscriber is a websocket connection, with writes serialized
+type subscriber struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (s *subscriber) write(msg interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn.WriteJSON(msg)
+}
+
+// NewExchange creates a new mock exchange trading the given instruments,
+// starting at the given prices. BTC-PERPETUAL is traded in multiples of
+// 10 dollars, like on deribit, and other instruments in whole dollars
+func NewExchange(prices map[string]float64, volatilityPercent float64) *Exchange {
+	instruments := make(map[string]*instrument)
+	for name, price := range prices {
+		contractSize := 1.0
+		if name == deribit.BTCPerpetual {
+			contractSize = 10
+		}
+		instruments[name] = &instrument{price: price, contractSize: contractSize}
+	}
+
+	return &Exchange{
+		volatility:  volatilityPercent,
+		balance:     DefaultBalance,
+		instruments: instruments,
+		tokens:      make(map[string]time.Time),
+		subscribers: make(map[*subscriber]map[string]bool),
+	}
+}
+
+// Handler returns a http.Handler serving the REST api under /api/v2, and
+// the websocket api under /ws/api/v2
+func (e *Exchange) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v2/public/auth", e.handleAuth).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/public/get_instrument", e.handleGetInstrument).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/buy", e.private(e.handleOrder)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/sell", e.private(e.handleOrder)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/get_open_orders_by_instrument",
+		e.private(e.handleGetOpenOrders)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/get_order_history_by_instrument",
+		e.private(e.handleGetOrderHistory)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/cancel_all_by_instrument",
+		e.private(e.handleCancelAll)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/get_position", e.private(e.handleGetPosition)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/get_account_summary",
+		e.private(e.handleGetAccountSummary)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v2/private/get_transaction_log",
+		e.private(e.handleGetTransactionLog)).Methods(http.MethodGet)
+	router.HandleFunc("/ws/api/v2", e.handleWebsocket)
+
+	return router
+}
+
+// Run moves all prices randomly every interval, and pushes the new prices
+// to all subscribers
+// NOTE: MUST be run in a goroutine
+func (e *Exchange) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.mu.Lock()
+		prices := make(map[string]float64)
+		for name, instrument := range e.instruments {
+			instrument.price *= 1 + rand.NormFloat64()*e.volatility/100
+			prices[name] = instrument.price
+		}
+		e.mu.Unlock()
+
+		for name, price := range prices {
+			e.broadcast(name, price)
+		}
+	}
+}
+
+// SetPrice sets the current price of an instrument, and pushes it to all
+// subscribers
+func (e *Exchange) SetPrice(name string, price float64) error {
+	e.mu.Lock()
+	instrument, ok := e.instruments[name]
+	if !ok {
+		e.mu.Unlock()
+		return fmt.Errorf("unknown instrument %s", name)
+	}
+	instrument.price = price
+	e.mu.Unlock()
+
+	e.broadcast(name, price)
+	return nil
+}
+
+// SetBalance sets the balance of the account, in bitcoin
+func (e *Exchange) SetBalance(balance float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.balance = balance
+}
+
+// SetFundingRate sets the rate settled every funding interval
+func (e *Exchange) SetFundingRate(rate float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.fundingRate = rate
+}
+
+// RunFunding settles funding every interval. Deribit accrues funding
+// continuously, but settling it at intervals is close enough for a mock
+// NOTE: MUST be run in a goroutine
+func (e *Exchange) RunFunding(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.SettleFunding()
+	}
+}
+
+// SettleFunding settles funding on every open position at the current
+// funding rate, and records it in the transaction log
+func (e *Exchange) SettleFunding() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for name, instrument := range e.instruments {
+		if instrument.position == 0 {
+			continue
+		}
+
+		// longs pay shorts when the rate is positive
+		received := -instrument.position / instrument.price * e.fundingRate
+		e.balance += received
+
+		e.transactions = append(e.transactions, deribit.Transaction{
+			ID:             int64(len(e.transactions) + 1),
+			Type:           deribit.TransactionTypeSettlement,
+			InstrumentName: name,
+			Currency:       deribit.BTC,
+			InterestPL:     received,
+			Position:       instrument.position,
+			Price:          instrument.price,
+			Timestamp:      now,
+		})
+
+		log.WithFields(logrus.Fields{
+			"instrument": name,
+			"position":   instrument.position,
+			"rate":       e.fundingRate,
+			"received":   received,
+		}).Info("settled funding")
+	}
+}
+
+// handleAuth hands out an access token for any client credentials
+func (e *Exchange) handleAuth(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("grant_type") != "client_credentials" || query.Get("client_id") == "" {
+		writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params")
+		return
+	}
+
+	token := uuid.New().String()
+	e.mu.Lock()
+	e.tokens[token] = time.Now().Add(tokenLifetime)
+	e.mu.Unlock()
+
+	writeResult(w, map[string]interface{}{
+		"access_token":  token,
+		"refresh_token": uuid.New().String(),
+		"expires_in":    int64(tokenLifetime.Seconds()),
+		"token_type":    "bearer",
+		"scope":         "connection trade:read_write account:read",
+	})
+}
+
+// private only passes on requests carrying a valid access token
+func (e *Exchange) private(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		e.mu.Lock()
+		expiresAt, ok := e.tokens[token]
+		e.mu.Unlock()
+
+		if !ok || time.Now().After(expiresAt) {
+			writeError(w, http.StatusBadRequest, codeUnauthorized, "unauthorized")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (e *Exchange) handleGetInstrument(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("instrument_name")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instrument, ok := e.instruments[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, codeInstrumentUnknown, "instrument_not_found")
+		return
+	}
+
+	writeResult(w, deribit.Instrument{
+		InstrumentName: name,
+		ContractSize:   instrument.contractSize,
+		TickSize:       0.5,
+		MinTradeAmount: instrument.contractSize,
+	})
+}
+
+// handleOrder fills a buy or sell right away
+func (e *Exchange) handleOrder(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	direction := "buy"
+	if strings.HasSuffix(r.URL.Path, "/sell") {
+		direction = "sell"
+	}
+
+	amount, err := strconv.ParseFloat(query.Get("amount"), 64)
+	if err != nil || amount <= 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params: amount")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name := query.Get("instrument_name")
+	instrument, ok := e.instruments[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, codeInstrumentUnknown, "instrument_not_found")
+		return
+	}
+
+	if remainder := math.Mod(amount, instrument.contractSize); remainder != 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidAmount,
+			fmt.Sprintf("must be a multiple of contract size %v", instrument.contractSize))
+		return
+	}
+
+	fillPrice := instrument.price
+	orderType := query.Get("type")
+	switch orderType {
+	case "", deribit.OrderTypeMarket:
+		orderType = deribit.OrderTypeMarket
+	case deribit.OrderTypeLimit:
+		fillPrice, err = strconv.ParseFloat(query.Get("price"), 64)
+		if err != nil || fillPrice <= 0 {
+			writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params: price")
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params: type")
+		return
+	}
+
+	signed := amount
+	if direction == "sell" {
+		signed = -amount
+	}
+
+	// realise the profit of the part of the order reducing our position,
+	// and average the entry price of the part increasing it
+	position := instrument.position
+	if position != 0 && (position > 0) != (signed > 0) {
+		closed := math.Min(math.Abs(position), amount)
+		if position < 0 {
+			closed = -closed
+		}
+		e.balance += closed * (1/instrument.avgPrice - 1/fillPrice)
+	}
+	newPosition := position + signed
+	switch {
+	case newPosition == 0:
+		instrument.avgPrice = 0
+	case position == 0 || (position > 0) != (newPosition > 0):
+		instrument.avgPrice = fillPrice
+	case (position > 0) == (signed > 0):
+		instrument.avgPrice = newPosition / (position/instrument.avgPrice + signed/fillPrice)
+	}
+	instrument.position = newPosition
+
+	fee := amount / fillPrice * takerFee
+	e.balance -= fee
+
+	order := deribit.Order{
+		OrderID:        uuid.New().String(),
+		Label:          query.Get("label"),
+		InstrumentName: name,
+		Direction:      direction,
+		Amount:         amount,
+		FilledAmount:   amount,
+		AveragePrice:   fillPrice,
+		OrderType:      orderType,
+		OrderState:     "filled",
+	}
+	e.orders = append(e.orders, order)
+
+	log.WithFields(logrus.Fields{
+		"instrument": name,
+		"direction":  direction,
+		"amount":     amount,
+		"price":      fillPrice,
+		"position":   instrument.position,
+	}).Info("filled order")
+
+	writeResult(w, deribit.OrderResult{
+		Order: order,
+		Trades: []deribit.Trade{{
+			TradeID:     uuid.New().String(),
+			Amount:      amount,
+			Price:       fillPrice,
+			Fee:         fee,
+			FeeCurrency: deribit.BTC,
+		}},
+	})
+}
+
+// handleGetOpenOrders returns our open orders. As every order is filled
+// right away, there never are any
+func (e *Exchange) handleGetOpenOrders(w http.ResponseWriter, r *http.Request) {
+	writeResult(w, []deribit.Order{})
+}
+
+// handleGetOrderHistory returns our orders in the instrument, newest first
+func (e *Exchange) handleGetOrderHistory(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("instrument_name")
+	count := 20
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		var err error
+		count, err = strconv.Atoi(raw)
+		if err != nil || count <= 0 {
+			writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params: count")
+			return
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	orders := []deribit.Order{}
+	for i := len(e.orders) - 1; i >= 0 && len(orders) < count; i-- {
+		if e.orders[i].InstrumentName == name {
+			orders = append(orders, e.orders[i])
+		}
+	}
+
+	writeResult(w, orders)
+}
+
+// handleCancelAll cancels all open orders. As every order is filled right
+// away, there is never anything to cancel
+func (e *Exchange) handleCancelAll(w http.ResponseWriter, r *http.Request) {
+	writeResult(w, 0)
+}
+
+func (e *Exchange) handleGetPosition(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("instrument_name")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instrument, ok := e.instruments[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, codeInstrumentUnknown, "instrument_not_found")
+		return
+	}
+
+	direction := "zero"
+	switch {
+	case instrument.position > 0:
+		direction = "buy"
+	case instrument.position < 0:
+		direction = "sell"
+	}
+
+	writeResult(w, deribit.Position{
+		InstrumentName: name,
+		Size:           instrument.position,
+		AveragePrice:   instrument.avgPrice,
+		Direction:      direction,
+	})
+}
+
+// handleGetAccountSummary reports the balance of the account. The margin
+// used by every position is its value at the current price
+func (e *Exchange) handleGetAccountSummary(w http.ResponseWriter, r *http.Request) {
+	currency := r.URL.Query().Get("currency")
+	if currency != deribit.BTC {
+		writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params: currency")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var used float64
+	for _, instrument := range e.instruments {
+		used += math.Abs(instrument.position) / instrument.price
+	}
+
+	writeResult(w, deribit.AccountSummary{
+		Currency:       currency,
+		Balance:        e.balance,
+		Equity:         e.balance,
+		AvailableFunds: e.balance - used,
+	})
+}
+
+// handleGetTransactionLog returns the funding settlements between
+// start_timestamp and end_timestamp, newest first
+func (e *Exchange) handleGetTransactionLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, err := strconv.ParseInt(query.Get("start_timestamp"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params: start_timestamp")
+		return
+	}
+	end, err := strconv.ParseInt(query.Get("end_timestamp"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidParams, "Invalid params: end_timestamp")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	logs := []deribit.Transaction{}
+	for i := len(e.transactions) - 1; i >= 0; i-- {
+		transaction := e.transactions[i]
+		if transaction.Currency != query.Get("currency") ||
+			transaction.Timestamp < start || transaction.Timestamp > end {
+			continue
+		}
+		logs = append(logs, transaction)
+	}
+
+	writeResult(w, map[string]interface{}{"logs": logs})
+}
+
+// handleWebsocket serves the websocket api. Clients can subscribe to
+// ticker channels, and ask for heartbeats
+func (e *Exchange) handleWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := e.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Error("could not upgrade connection")
+		return
+	}
+	defer conn.Close()
+
+	sub := &subscriber{conn: conn}
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		e.mu.Lock()
+		delete(e.subscribers, sub)
+		e.mu.Unlock()
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Channels []string `json:"channels"`
+				Interval float64  `json:"interval"`
+			} `json:"params"`
+		}
+		err = json.Unmarshal(msg, &req)
+		if err != nil {
+			_ = sub.write(rpcError(0, codeInvalidParams, "invalid request"))
+			continue
+		}
+
+		switch req.Method {
+		case "public/subscribe":
+			var subscribed []string
+			for _, channel := range req.Params.Channels {
+				price, ok := e.subscribe(sub, channel)
+				if !ok {
+					continue
+				}
+				subscribed = append(subscribed, channel)
+				_ = sub.write(tickerNotification(channel, price))
+			}
+			_ = sub.write(rpcResult(req.ID, subscribed))
+
+		case "public/set_heartbeat":
+			if req.Params.Interval < 1 {
+				_ = sub.write(rpcError(req.ID, codeInvalidParams, "Invalid params: interval"))
+				continue
+			}
+			_ = sub.write(rpcResult(req.ID, "ok"))
+			go heartbeat(sub, time.Duration(req.Params.Interval*float64(time.Second)), done)
+
+		case "public/test":
+			_ = sub.write(rpcResult(req.ID, map[string]string{"version": "mock"}))
+
+		default:
+			_ = sub.write(rpcError(req.ID, codeMethodNotFound, "Method not found"))
+		}
+	}
+}
+
+// heartbeat asks the subscriber to prove it is alive every interval, until
+// done is closed
+func heartbeat(sub *subscriber, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := sub.write(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"method":  "heartbeat",
+				"params":  map[string]string{"type": "test_request"},
+			})
+			if err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// subscribe subscribes to a ticker channel, and returns the current price
+// of its instrument
+func (e *Exchange) subscribe(sub *subscriber, channel string) (float64, bool) {
+	parts := strings.Split(channel, ".")
+	if len(parts) != 3 || parts[0] != "ticker" {
+		return 0, false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instrument, ok := e.instruments[parts[1]]
+	if !ok {
+		return 0, false
+	}
+
+	if e.subscribers[sub] == nil {
+		e.subscribers[sub] = make(map[string]bool)
+	}
+	e.subscribers[sub][channel] = true
+
+	return instrument.price, true
+}
+
+// broadcast pushes the price of an instrument to all subscribers of its
+// ticker
+func (e *Exchange) broadcast(name string, price float64) {
+	type target struct {
+		sub     *subscriber
+		channel string
+	}
+
+	e.mu.Lock()
+	var targets []target
+	for sub, channels := range e.subscribers {
+		for channel := range channels {
+			if strings.HasPrefix(channel, "ticker."+name+".") {
+				targets = append(targets, target{sub: sub, channel: channel})
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	for _, t := range targets {
+		err := t.sub.write(tickerNotification(t.channel, price))
+		if err != nil {
+			log.WithError(err).Debug("could not push price to subscriber")
+		}
+	}
+}
+
+func tickerNotification(channel string, price float64) interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "subscription",
+		"params": map[string]interface{}{
+			"channel": channel,
+			"data": map[string]interface{}{
+				"instrument_name": strings.Split(channel, ".")[1],
+				"last_price":      price,
+				"mark_price":      price,
+				"index_price":     price,
+				"timestamp":       time.Now().UnixNano() / int64(time.Millisecond),
+			},
+		},
+	}
+}
+
+func rpcResult(id int, result interface{}) interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  result,
+	}
+}
+
+func rpcError(id, code int, message string) interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(rpcResult(0, result))
+	if err != nil {
+		log.WithError(err).Error("could not write response")
+	}
+}
+
+// writeError writes an error on the same form as deribit does
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(rpcError(0, code, message))
+}
@@ -0,0 +1,305 @@
+package deribit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// sourceName is the name we attach to prices originating from deribit
+const sourceName = "deribit"
+
+const (
+	// heartbeatInterval is how often we ask deribit to check that we are
+	// alive. If we hear nothing for two intervals, the connection is dead
+	heartbeatInterval = 10 * time.Second
+
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+var _ oracle.Source = &PriceFeed{}
+
+// PriceFeed is a price source backed by the ticker channels of the deribit
+// websocket api. Prices are reported under our own symbols of the
+// instruments, so they can be aggregated with prices from other venues
+type PriceFeed struct {
+	oracle.Notifier
+
+	url string
+	// symbols maps the deribit instruments we subscribe to to our symbols
+	symbols map[string]string
+
+	mu     sync.RWMutex
+	prices map[string]oracle.Price
+
+	onStateChange func(state bitmex.ConnectionState, err error)
+
+	quit chan struct{}
+	once sync.Once
+}
+
+// NewPriceFeed creates a feed that connects to the websocket api at url,
+// and receives the prices of the given instruments, keyed by our symbol
+// for them. It does not receive any prices before Listen is called
+func NewPriceFeed(url string, instruments map[string]string) *PriceFeed {
+	symbols := make(map[string]string)
+	for symbol, instrument := range instruments {
+		symbols[instrument] = symbol
+	}
+
+	return &PriceFeed{
+		url:           url,
+		symbols:       symbols,
+		prices:        make(map[string]oracle.Price),
+		onStateChange: func(bitmex.ConnectionState, error) {},
+		quit:          make(chan struct{}),
+	}
+}
+
+// Name returns the name of the feed
+func (f *PriceFeed) Name() string {
+	return sourceName
+}
+
+// LatestPrice returns the latest price received from deribit for the symbol
+func (f *PriceFeed) LatestPrice(symbol string) (oracle.Price, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	price, ok := f.prices[symbol]
+	if !ok {
+		return oracle.Price{}, fmt.Errorf("%s: %w", symbol, oracle.ErrNoPrice)
+	}
+
+	return price, nil
+}
+
+// OnStateChange sets a function that is called every time the connection
+// to deribit changes state. MUST be called before Listen.
+func (f *PriceFeed) OnStateChange(fn func(state bitmex.ConnectionState, err error)) {
+	f.onStateChange = fn
+}
+
+// Listen connects to deribit and updates the feed with new prices. Lost
+// connections are reopened with exponential backoff, so Listen only
+// returns if Close is called.
+// NOTE: MUST be run in a goroutine
+func (f *PriceFeed) Listen() error {
+	backoff := minBackoff
+
+	for {
+		f.onStateChange(bitmex.Connecting, nil)
+
+		connectedAt := time.Now()
+		err := f.connect()
+
+		select {
+		case <-f.quit:
+			f.onStateChange(bitmex.Disconnected, nil)
+			return nil
+		default:
+		}
+
+		f.onStateChange(bitmex.Disconnected, err)
+
+		// a connection that stayed up for a while was healthy, so we start
+		// over with a short backoff
+		if time.Since(connectedAt) > maxBackoff {
+			backoff = minBackoff
+		}
+
+		log.WithError(err).WithFields(logrus.Fields{
+			"url":     f.url,
+			"backoff": backoff,
+		}).Warn("deribit connection lost, reconnecting")
+
+		select {
+		case <-time.After(backoff):
+		case <-f.quit:
+			return nil
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// Close closes the connection to deribit, and stops reconnecting
+func (f *PriceFeed) Close() {
+	f.once.Do(func() {
+		close(f.quit)
+	})
+}
+
+// rpcRequest is a JSON-RPC request sent over the websocket
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// connect opens a single connection, subscribes to the tickers of our
+// instruments and reads from it until the connection fails
+func (f *PriceFeed) connect() error {
+	conn, _, err := websocket.DefaultDialer.Dial(f.url, nil)
+	if err != nil {
+		return fmt.Errorf("could not dial %s: %w", f.url, err)
+	}
+	defer conn.Close()
+
+	// closing the connection makes the read below fail, which is how we
+	// stop reading when Close is called
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-f.quit:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	var channels []string
+	for instrument := range f.symbols {
+		channels = append(channels, tickerChannel(instrument))
+	}
+
+	err = conn.WriteJSON(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "public/subscribe",
+		Params:  map[string]interface{}{"channels": channels},
+	})
+	if err != nil {
+		return fmt.Errorf("could not subscribe: %w", err)
+	}
+
+	err = conn.WriteJSON(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "public/set_heartbeat",
+		Params:  map[string]interface{}{"interval": heartbeatInterval.Seconds()},
+	})
+	if err != nil {
+		return fmt.Errorf("could not set heartbeat: %w", err)
+	}
+
+	f.onStateChange(bitmex.Connected, nil)
+
+	for {
+		err = conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+		if err != nil {
+			return err
+		}
+
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("could not read from deribit: %w", err)
+		}
+
+		err = f.handleMessage(conn, msg)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleMessage answers heartbeats, and extracts prices from ticker
+// notifications
+func (f *PriceFeed) handleMessage(conn *websocket.Conn, msg []byte) error {
+	var message struct {
+		ID     int    `json:"id"`
+		Method string `json:"method"`
+		Error  *Error `json:"error"`
+		Params struct {
+			// Type is set on heartbeats
+			Type    string `json:"type"`
+			Channel string `json:"channel"`
+			Data    struct {
+				InstrumentName string  `json:"instrument_name"`
+				LastPrice      float64 `json:"last_price"`
+				MarkPrice      float64 `json:"mark_price"`
+				// Timestamp is in milliseconds
+				Timestamp int64 `json:"timestamp"`
+			} `json:"data"`
+		} `json:"params"`
+	}
+	err := json.Unmarshal(msg, &message)
+	if err != nil {
+		log.WithError(err).WithField("msg", string(msg)).Error("could not unmarshal message")
+		return nil
+	}
+
+	switch {
+	case message.Error != nil:
+		log.WithError(message.Error).WithField("id", message.ID).Error("received error from deribit")
+
+	case message.Method == "heartbeat":
+		if message.Params.Type != "test_request" {
+			return nil
+		}
+		err := conn.WriteJSON(rpcRequest{JSONRPC: "2.0", ID: 3, Method: "public/test"})
+		if err != nil {
+			return fmt.Errorf("could not answer heartbeat: %w", err)
+		}
+
+	case message.Method == "subscription":
+		data := message.Params.Data
+		symbol, ok := f.symbols[data.InstrumentName]
+		if !ok {
+			return nil
+		}
+
+		timestamp := time.Now()
+		if data.Timestamp != 0 {
+			timestamp = time.Unix(0, data.Timestamp*int64(time.Millisecond))
+		}
+
+		if data.LastPrice != 0 {
+			f.setPrice(oracle.Price{
+				Symbol:    symbol,
+				Value:     data.LastPrice,
+				Timestamp: timestamp,
+				Source:    sourceName,
+			})
+		}
+		if data.MarkPrice != 0 {
+			f.setPrice(oracle.Price{
+				Symbol:    bitmex.MarkSymbol(symbol),
+				Value:     data.MarkPrice,
+				Timestamp: timestamp,
+				Source:    sourceName,
+			})
+		}
+	}
+
+	return nil
+}
+
+// setPrice updates the latest price, and notifies subscribers of it. Every
+// tick is passed on, even if the price did not change, so subscribers know
+// it is still fresh
+func (f *PriceFeed) setPrice(price oracle.Price) {
+	f.mu.Lock()
+	f.prices[price.Symbol] = price
+	f.mu.Unlock()
+
+	f.Notify(price)
+}
+
+// tickerChannel returns the channel the ticker of the instrument is
+// published on
+func tickerChannel(instrument string) string {
+	return "ticker." + instrument + ".100ms"
+}
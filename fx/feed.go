@@ -0,0 +1,108 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultFeedURL serves the latest fiat exchange rates with USD as base,
+// published by the European Central Bank. It does not need an api key
+const DefaultFeedURL = "https://api.frankfurter.app/latest?from=USD"
+
+// DefaultMaxAge is how old fiat exchange rates can get before they are not
+// used. Rates are fetched every DefaultFeedInterval, so this allows several
+// fetches in a row to fail
+const DefaultMaxAge = time.Hour
+
+// DefaultFeedInterval is how often we fetch new fiat exchange rates. Fiat
+// rates are only published a few times a day, so there is no need to fetch
+// them often
+const DefaultFeedInterval = 10 * time.Minute
+
+// Feed periodically fetches exchange rates from an exchangeratesapi.io
+// compatible endpoint, and stores them in a Converter
+type Feed struct {
+	url       string
+	interval  time.Duration
+	converter *Converter
+	client    *http.Client
+}
+
+// NewFeed creates a Feed that updates converter with rates from url
+func NewFeed(url string, interval time.Duration, converter *Converter) *Feed {
+	return &Feed{
+		url:       url,
+		interval:  interval,
+		converter: converter,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Listen fetches new rates every interval. Failed fetches are logged and
+// retried at the next interval, while the previous rates are kept
+// NOTE: MUST be run in a goroutine
+func (f *Feed) Listen() error {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		err := f.update()
+		if err != nil {
+			log.WithError(err).WithField("url", f.url).Error("could not update exchange rates")
+		}
+		<-ticker.C
+	}
+}
+
+func (f *Feed) update() error {
+	res, err := f.client.Get(f.url)
+	if err != nil {
+		return fmt.Errorf("could not get exchange rates: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not get exchange rates: %s", res.Status)
+	}
+
+	var body struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return fmt.Errorf("could not decode exchange rates: %w", err)
+	}
+
+	now := time.Now()
+	for quote, value := range body.Rates {
+		// the base is sometimes included in the rates, with a rate of 1
+		if quote == body.Base {
+			continue
+		}
+
+		err = f.converter.SetRate(Rate{
+			Base:      body.Base,
+			Quote:     quote,
+			Value:     value,
+			Timestamp: now,
+			Source:    f.url,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"base":  body.Base,
+		"rates": len(body.Rates),
+	}).Info("updated exchange rates")
+
+	return nil
+}
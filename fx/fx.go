@@ -0,0 +1,183 @@
+// Package fx converts amounts between currencies. It keeps a graph of
+// exchange rates, and routes conversions through intermediate currencies
+// when there is no direct rate, e.g. EUR -> USD -> BTC.
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+var (
+	// ErrUnknownPair is returned when there is no way to convert between two currencies
+	ErrUnknownPair = errors.New("no exchange rate between currencies")
+	// ErrStaleRate is returned when the only way to convert between two
+	// currencies is through rates older than the max age of the converter
+	ErrStaleRate = errors.New("exchange rate is too old")
+)
+
+// Rate is an exchange rate, meaning one unit of Base costs Value units of Quote
+type Rate struct {
+	Base      string
+	Quote     string
+	Value     float64
+	Timestamp time.Time
+	Source    string
+}
+
+// Converter holds the exchange rates we know about, and converts between
+// currencies using them. Rates older than its max age are not used, so a
+// rate is not used forever after its feed stops. It is safe for concurrent
+// use.
+type Converter struct {
+	// maxAge is how old a rate can be before it is not used. Zero means
+	// rates never get too old
+	maxAge time.Duration
+
+	mu sync.RWMutex
+	// rates holds every known rate in both directions, rates[base][quote]
+	rates map[string]map[string]Rate
+}
+
+// NewConverter creates a Converter without any rates, that does not use
+// rates older than maxAge
+func NewConverter(maxAge time.Duration) *Converter {
+	return &Converter{
+		maxAge: maxAge,
+		rates:  make(map[string]map[string]Rate),
+	}
+}
+
+// SetRate adds or updates the exchange rate between two currencies. The
+// inverse rate is added as well.
+func (c *Converter) SetRate(rate Rate) error {
+	if rate.Value <= 0 {
+		return fmt.Errorf("rate %s/%s must be positive, got %f", rate.Base, rate.Quote, rate.Value)
+	}
+	if rate.Base == rate.Quote {
+		return fmt.Errorf("base and quote can not both be %s", rate.Base)
+	}
+
+	inverse := rate
+	inverse.Base, inverse.Quote = rate.Quote, rate.Base
+	inverse.Value = 1 / rate.Value
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setEdge(rate)
+	c.setEdge(inverse)
+
+	return nil
+}
+
+// setEdge stores a single direction of a rate
+// NOTE: the caller must hold the write lock
+func (c *Converter) setEdge(rate Rate) {
+	quotes, ok := c.rates[rate.Base]
+	if !ok {
+		quotes = make(map[string]Rate)
+		c.rates[rate.Base] = quotes
+	}
+	quotes[rate.Quote] = rate
+}
+
+// Path returns the rates used to convert from one currency to another,
+// using as few intermediate currencies as possible. Of several paths that
+// are equally short, the one through the alphabetically first currencies
+// is used, so conversions do not change between calls
+func (c *Converter) Path(from, to string) ([]Rate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if from == to {
+		return nil, nil
+	}
+
+	// breadth first search from the currency we convert from, keeping track
+	// of which rate we used to reach each currency
+	reachedBy := map[string]Rate{}
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+	stale := false
+	now := time.Now()
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, quote := range sortedQuotes(c.rates[current]) {
+			if visited[quote] {
+				continue
+			}
+			rate := c.rates[current][quote]
+			if c.maxAge > 0 && now.Sub(rate.Timestamp) > c.maxAge {
+				stale = true
+				continue
+			}
+			visited[quote] = true
+			reachedBy[quote] = rate
+
+			if quote != to {
+				queue = append(queue, quote)
+				continue
+			}
+
+			// walk backwards from the target to build the path
+			var path []Rate
+			for currency := to; currency != from; currency = reachedBy[currency].Base {
+				path = append([]Rate{reachedBy[currency]}, path...)
+			}
+			return path, nil
+		}
+	}
+
+	if stale {
+		return nil, fmt.Errorf("%s/%s: %w", from, to, ErrStaleRate)
+	}
+
+	return nil, fmt.Errorf("%s/%s: %w", from, to, ErrUnknownPair)
+}
+
+// sortedQuotes returns the currencies there are rates to, sorted
+func sortedQuotes(rates map[string]Rate) []string {
+	var quotes []string
+	for quote := range rates {
+		quotes = append(quotes, quote)
+	}
+	sort.Strings(quotes)
+
+	return quotes
+}
+
+// Rate returns how many units of to one unit of from is worth
+func (c *Converter) Rate(from, to string) (float64, error) {
+	path, err := c.Path(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	rate := 1.0
+	for _, step := range path {
+		rate *= step.Value
+	}
+
+	return rate, nil
+}
+
+// Convert converts an amount of one currency to another
+func (c *Converter) Convert(from string, amount float64, to string) (float64, error) {
+	rate, err := c.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return amount * rate, nil
+}
@@ -0,0 +1,146 @@
+package fx
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConverterPath(t *testing.T) {
+	now := time.Now()
+	rates := []Rate{
+		{Base: "BTC", Quote: "USD", Value: 10000, Timestamp: now},
+		{Base: "USD", Quote: "NOK", Value: 10, Timestamp: now},
+		{Base: "USD", Quote: "EUR", Value: 0.9, Timestamp: now},
+		{Base: "EUR", Quote: "SEK", Value: 11, Timestamp: now},
+		{Base: "NOK", Quote: "SEK", Value: 1, Timestamp: now},
+	}
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		path    string
+		rate    float64
+		unknown bool
+	}{
+		{name: "same currency", from: "USD", to: "USD", path: "", rate: 1},
+		{name: "direct", from: "BTC", to: "USD", path: "BTC/USD", rate: 10000},
+		{name: "inverse", from: "USD", to: "BTC", path: "USD/BTC", rate: 0.0001},
+		{name: "through intermediate", from: "BTC", to: "NOK", path: "BTC/USD USD/NOK", rate: 100000},
+		// USD -> EUR -> SEK and USD -> NOK -> SEK are equally short, and
+		// EUR sorts before NOK
+		{name: "equally short paths", from: "USD", to: "SEK", path: "USD/EUR EUR/SEK", rate: 9.9},
+		{name: "unknown currency", from: "BTC", to: "JPY", unknown: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converter := NewConverter(time.Hour)
+			for _, rate := range rates {
+				if err := converter.SetRate(rate); err != nil {
+					t.Fatalf("could not set rate: %v", err)
+				}
+			}
+
+			// the path must not depend on map order
+			for i := 0; i < 20; i++ {
+				path, err := converter.Path(test.from, test.to)
+				if test.unknown {
+					if !errors.Is(err, ErrUnknownPair) {
+						t.Fatalf("expected ErrUnknownPair, got %v", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				var steps []string
+				for _, step := range path {
+					steps = append(steps, step.Base+"/"+step.Quote)
+				}
+				if got := strings.Join(steps, " "); got != test.path {
+					t.Fatalf("path = %q, want %q", got, test.path)
+				}
+			}
+
+			rate, err := converter.Rate(test.from, test.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(rate-test.rate) > 1e-9*test.rate {
+				t.Fatalf("rate = %f, want %f", rate, test.rate)
+			}
+		})
+	}
+}
+
+func TestConverterMaxAge(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		rates  []Rate
+		err    error
+	}{
+		{
+			name:   "fresh rate",
+			maxAge: time.Hour,
+			rates:  []Rate{{Base: "USD", Quote: "NOK", Value: 10, Timestamp: now}},
+		},
+		{
+			name:   "stale rate",
+			maxAge: time.Hour,
+			rates:  []Rate{{Base: "USD", Quote: "NOK", Value: 10, Timestamp: now.Add(-2 * time.Hour)}},
+			err:    ErrStaleRate,
+		},
+		{
+			name:   "zero max age keeps rates forever",
+			maxAge: 0,
+			rates:  []Rate{{Base: "USD", Quote: "NOK", Value: 10, Timestamp: now.Add(-2 * time.Hour)}},
+		},
+		{
+			name:   "fresh path around stale rate",
+			maxAge: time.Hour,
+			rates: []Rate{
+				{Base: "USD", Quote: "NOK", Value: 10, Timestamp: now.Add(-2 * time.Hour)},
+				{Base: "USD", Quote: "EUR", Value: 0.9, Timestamp: now},
+				{Base: "EUR", Quote: "NOK", Value: 11, Timestamp: now},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converter := NewConverter(test.maxAge)
+			for _, rate := range test.rates {
+				if err := converter.SetRate(rate); err != nil {
+					t.Fatalf("could not set rate: %v", err)
+				}
+			}
+
+			_, err := converter.Convert("USD", 1, "NOK")
+			if test.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestConverterSetRateValidates(t *testing.T) {
+	converter := NewConverter(0)
+
+	if err := converter.SetRate(Rate{Base: "USD", Quote: "NOK", Value: 0}); err == nil {
+		t.Error("accepted zero rate")
+	}
+	if err := converter.SetRate(Rate{Base: "USD", Quote: "USD", Value: 1}); err == nil {
+		t.Error("accepted rate between a currency and itself")
+	}
+}
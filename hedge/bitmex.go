@@ -0,0 +1,338 @@
+package hedge
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qct/bitmex-go/swagger"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+)
+
+var (
+	_ LimitHedger   = &BitmexHedger{}
+	_ FundingHedger = &BitmexHedger{}
+	_ FillHedger    = &BitmexHedger{}
+)
+
+// fillsBuffer is how many fills can be queued up before new fills are
+// dropped
+const fillsBuffer = 256
+
+// fillsResyncDelay is how long we wait after dropping fills before fetching
+// them again, so the queue has time to drain
+const fillsResyncDelay = 5 * time.Second
+
+// BitmexHedger hedges with market and limit orders on bitmex
+type BitmexHedger struct {
+	api   *bitmex.Bitmex
+	fills chan Fill
+
+	// dropped counts the fills we dropped because the queue was full
+	dropped uint64
+
+	mu sync.Mutex
+	// missedSince is the time of the oldest fill we dropped since we last
+	// fetched them again, zero if we have not dropped any
+	missedSince time.Time
+}
+
+// NewBitmexHedger creates a hedger trading through the given bitmex api.
+// If feed is not nil, our fills are read from it, and it must be listened
+// to after the hedger is created. Fills missed while the feed was
+// disconnected are fetched from the REST api when it reconnects
+func NewBitmexHedger(api *bitmex.Bitmex, feed *bitmex.ExecutionFeed) *BitmexHedger {
+	h := &BitmexHedger{
+		api:   api,
+		fills: make(chan Fill, fillsBuffer),
+	}
+
+	if feed != nil {
+		feed.OnFill(h.handleExecution)
+		feed.OnOrder(h.handleOrder)
+		feed.OnReconnect(h.fetchFills)
+		feed.OnPosition(func(position swagger.Position) {
+			log.WithFields(logrus.Fields{
+				"symbol":        position.Symbol,
+				"qty":           position.CurrentQty,
+				"avgEntryPrice": position.AvgEntryPrice,
+			}).Debug("bitmex position changed")
+		})
+	}
+
+	return h
+}
+
+// Name returns the name of the hedger
+func (h *BitmexHedger) Name() string {
+	return "bitmex"
+}
+
+// OpenExposure market buys qty contracts of the instrument
+func (h *BitmexHedger) OpenExposure(symbol string, qty float64) (Order, error) {
+	_, orderID, err := h.api.MarketBuy(symbol, qty)
+	if err != nil {
+		return Order{}, fmt.Errorf("could not market buy: %w", err)
+	}
+
+	return Order{ID: orderID, Symbol: symbol, Side: Buy, Qty: qty}, nil
+}
+
+// CloseExposure market sells qty contracts of the instrument
+func (h *BitmexHedger) CloseExposure(symbol string, qty float64) (Order, error) {
+	_, orderID, err := h.api.MarketSell(symbol, qty)
+	if err != nil {
+		return Order{}, fmt.Errorf("could not market sell: %w", err)
+	}
+
+	return Order{ID: orderID, Symbol: symbol, Side: Sell, Qty: qty}, nil
+}
+
+// PlaceOrder places a market order on bitmex with our client order id
+func (h *BitmexHedger) PlaceOrder(clientID, symbol string, side Side, qty float64) (Order, error) {
+	signed := qty
+	if side == Sell {
+		signed = -qty
+	}
+
+	order, err := h.api.MarketOrder(clientID, symbol, signed)
+	if err != nil {
+		return Order{}, err
+	}
+
+	return orderFromBitmex(order), nil
+}
+
+// LookupOrder returns our bitmex order with the client order id
+func (h *BitmexHedger) LookupOrder(clientID, symbol string) (Order, bool, error) {
+	order, ok, err := h.api.OrderByClientID(symbol, clientID)
+	if err != nil || !ok {
+		return Order{}, ok, err
+	}
+
+	return orderFromBitmex(order), true, nil
+}
+
+// orderFromBitmex converts an order returned by bitmex
+func orderFromBitmex(order swagger.Order) Order {
+	return Order{
+		ID:       order.OrderID,
+		ClientID: order.ClOrdID,
+		Symbol:   order.Symbol,
+		Side:     Side(order.Side),
+		Qty:      float64(order.OrderQty),
+		Price:    order.AvgPx,
+	}
+}
+
+// Fills returns a channel receiving our fills on bitmex, and our orders
+// being cancelled or rejected
+func (h *BitmexHedger) Fills() <-chan Fill {
+	return h.fills
+}
+
+// DroppedFills returns how many fills we have dropped because they were
+// not read fast enough. Dropped fills are fetched again from the REST api,
+// except cancellations and rejections
+func (h *BitmexHedger) DroppedFills() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// send queues the fill without blocking, as we are called from the
+// goroutine reading the realtime api. If the queue is full the fill is
+// dropped, and fetched again from the REST api a little later
+func (h *BitmexHedger) send(fill Fill) {
+	select {
+	case h.fills <- fill:
+		return
+	default:
+	}
+
+	dropped := atomic.AddUint64(&h.dropped, 1)
+
+	h.mu.Lock()
+	first := h.missedSince.IsZero()
+	if first || fill.Time.Before(h.missedSince) {
+		h.missedSince = fill.Time
+	}
+	h.mu.Unlock()
+
+	log.WithFields(logrus.Fields{
+		"orderID": fill.OrderID,
+		"status":  fill.Status,
+		"dropped": dropped,
+	}).Warn("fills are not read fast enough, dropped fill")
+
+	if first {
+		time.AfterFunc(fillsResyncDelay, h.fetchDroppedFills)
+	}
+}
+
+// fetchDroppedFills fetches the fills we dropped again
+func (h *BitmexHedger) fetchDroppedFills() {
+	h.mu.Lock()
+	since := h.missedSince
+	h.missedSince = time.Time{}
+	h.mu.Unlock()
+
+	h.fetchFills(since)
+}
+
+// fetchFills fetches our fills since the given time from the REST api, and
+// queues them like fills from the realtime api. Fills we have seen before
+// are queued again, so they must be told apart by their ID. We are not
+// called from the goroutine reading the realtime api, so we wait for the
+// queue to drain instead of dropping fills
+func (h *BitmexHedger) fetchFills(since time.Time) {
+	executions, err := h.api.TradeExecutions(since)
+	if err != nil {
+		log.WithError(err).WithField("since", since).Error("could not fetch bitmex fills, " +
+			"orders filled since then are not recorded")
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"since": since,
+		"fills": len(executions),
+	}).Info("fetched bitmex fills")
+
+	for _, execution := range executions {
+		h.fills <- fillFromBitmex(execution)
+	}
+}
+
+// handleExecution passes on an execution filling one of our orders
+func (h *BitmexHedger) handleExecution(execution swagger.Execution) {
+	h.send(fillFromBitmex(execution))
+}
+
+// fillFromBitmex converts an execution filling one of our orders
+func fillFromBitmex(execution swagger.Execution) Fill {
+	return Fill{
+		ID:        execution.ExecID,
+		OrderID:   execution.OrderID,
+		ClientID:  execution.ClOrdID,
+		Symbol:    execution.Symbol,
+		Side:      Side(execution.Side),
+		Qty:       float64(execution.LastQty),
+		Price:     execution.LastPx,
+		FilledQty: float64(execution.CumQty),
+		AvgPrice:  execution.AvgPx,
+		Fee:       int64(execution.ExecComm),
+		Status:    OrderStatus(execution.OrdStatus),
+		Time:      execution.TransactTime,
+	}
+}
+
+// handleOrder passes on our orders being cancelled or rejected. Fills are
+// passed on from executions, which tell us the price of every fill
+func (h *BitmexHedger) handleOrder(order swagger.Order) {
+	status := OrderStatus(order.OrdStatus)
+	if status != StatusCanceled && status != StatusRejected {
+		return
+	}
+
+	reason := order.OrdRejReason
+	if reason == "" {
+		reason = order.Text
+	}
+
+	h.send(Fill{
+		// bitmex does not send an execution id with order updates, and
+		// an order is only cancelled or rejected once
+		ID:        order.OrderID + ":" + order.OrdStatus,
+		OrderID:   order.OrderID,
+		ClientID:  order.ClOrdID,
+		Symbol:    order.Symbol,
+		Side:      Side(order.Side),
+		FilledQty: float64(order.CumQty),
+		AvgPrice:  order.AvgPx,
+		Status:    status,
+		Reason:    reason,
+		Time:      order.Timestamp,
+	})
+}
+
+// OpenExposureAt places a limit buy of qty contracts of the instrument
+func (h *BitmexHedger) OpenExposureAt(symbol string, qty, price float64) (Order, error) {
+	_, orderID, err := h.api.LimitBuy(symbol, qty, price)
+	if err != nil {
+		return Order{}, fmt.Errorf("could not limit buy: %w", err)
+	}
+
+	return Order{ID: orderID, Symbol: symbol, Side: Buy, Qty: qty}, nil
+}
+
+// CloseExposureAt places a limit sell of qty contracts of the instrument
+func (h *BitmexHedger) CloseExposureAt(symbol string, qty, price float64) (Order, error) {
+	_, orderID, err := h.api.LimitSell(symbol, qty, price)
+	if err != nil {
+		return Order{}, fmt.Errorf("could not limit sell: %w", err)
+	}
+
+	return Order{ID: orderID, Symbol: symbol, Side: Sell, Qty: qty}, nil
+}
+
+// CancelOrders cancels all our open bitmex orders in the instrument
+func (h *BitmexHedger) CancelOrders(symbol string) error {
+	return h.api.CancelAll(symbol)
+}
+
+// Position returns our bitmex position in the instrument
+func (h *BitmexHedger) Position(symbol string) (Position, error) {
+	position, err := h.api.Position(symbol)
+	if err != nil {
+		return Position{}, err
+	}
+
+	return Position{
+		Symbol:        symbol,
+		Qty:           float64(position.CurrentQty),
+		AvgEntryPrice: position.AvgEntryPrice,
+	}, nil
+}
+
+// Margin returns the margin status of our bitmex account
+func (h *BitmexHedger) Margin() (Margin, error) {
+	margin, err := h.api.Margin()
+	if err != nil {
+		return Margin{}, err
+	}
+
+	return Margin{
+		WalletBalance:   int64(margin.WalletBalance),
+		AvailableMargin: int64(margin.AvailableMargin),
+	}, nil
+}
+
+// FundingPayments returns the funding paid or received on our bitmex
+// position in the instrument since the given time
+func (h *BitmexHedger) FundingPayments(symbol string, since time.Time) ([]FundingPayment, error) {
+	executions, err := h.api.FundingExecutions(symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []FundingPayment
+	for _, execution := range executions {
+		qty := float64(execution.LastQty)
+		if execution.Side == string(Sell) {
+			qty = -qty
+		}
+
+		payments = append(payments, FundingPayment{
+			ID:          execution.ExecID,
+			Symbol:      symbol,
+			Venue:       h.Name(),
+			Time:        execution.TransactTime,
+			Rate:        execution.Commission,
+			PositionQty: qty,
+			Amount:      int64(execution.ExecComm),
+		})
+	}
+
+	return payments, nil
+}
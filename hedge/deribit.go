@@ -0,0 +1,281 @@
+package hedge
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+
+	"github.com/ArcaneCryptoAS/lassets-server/deribit"
+)
+
+var (
+	_ LimitHedger   = &DeribitHedger{}
+	_ FundingHedger = &DeribitHedger{}
+)
+
+// DeribitHedger hedges with market and limit orders on deribit. Deribit
+// names instruments differently than we do, so every instrument we hedge
+// on deribit must be mapped to a deribit instrument, e.g. XBTUSD to
+// BTC-PERPETUAL.
+//
+// Orders on deribit must be a multiple of the contract size of the
+// instrument. Quantities are rounded to lots before they reach the hedger,
+// so any other quantity is refused rather than rounded again.
+type DeribitHedger struct {
+	api *deribit.Deribit
+	// instruments maps our symbols to deribit instruments
+	instruments map[string]string
+
+	mu sync.Mutex
+	// contractSizes are the contract sizes of deribit instruments, fetched
+	// the first time we trade them
+	contractSizes map[string]float64
+}
+
+// NewDeribitHedger creates a hedger trading through the given deribit api.
+// instruments maps our symbols to the deribit instruments they are traded
+// as
+func NewDeribitHedger(api *deribit.Deribit, instruments map[string]string) *DeribitHedger {
+	return &DeribitHedger{
+		api:           api,
+		instruments:   instruments,
+		contractSizes: make(map[string]float64),
+	}
+}
+
+// Name returns the name of the hedger
+func (h *DeribitHedger) Name() string {
+	return "deribit"
+}
+
+// OpenExposure market buys qty contracts of the instrument
+func (h *DeribitHedger) OpenExposure(symbol string, qty float64) (Order, error) {
+	return h.order(symbol, Buy, qty, deribit.OrderTypeMarket, 0, "")
+}
+
+// CloseExposure market sells qty contracts of the instrument
+func (h *DeribitHedger) CloseExposure(symbol string, qty float64) (Order, error) {
+	return h.order(symbol, Sell, qty, deribit.OrderTypeMarket, 0, "")
+}
+
+// OpenExposureAt places a limit buy of qty contracts of the instrument
+func (h *DeribitHedger) OpenExposureAt(symbol string, qty, price float64) (Order, error) {
+	return h.order(symbol, Buy, qty, deribit.OrderTypeLimit, price, "")
+}
+
+// CloseExposureAt places a limit sell of qty contracts of the instrument
+func (h *DeribitHedger) CloseExposureAt(symbol string, qty, price float64) (Order, error) {
+	return h.order(symbol, Sell, qty, deribit.OrderTypeLimit, price, "")
+}
+
+// PlaceOrder places a market order on deribit, labelled with our client id
+func (h *DeribitHedger) PlaceOrder(clientID, symbol string, side Side, qty float64) (Order, error) {
+	return h.order(symbol, side, qty, deribit.OrderTypeMarket, 0, clientID)
+}
+
+// LookupOrder returns our deribit order labelled with the client id
+func (h *DeribitHedger) LookupOrder(clientID, symbol string) (Order, bool, error) {
+	instrument, err := h.instrument(symbol)
+	if err != nil {
+		return Order{}, false, err
+	}
+
+	order, ok, err := h.api.OrderByLabel(instrument, clientID)
+	if err != nil || !ok {
+		return Order{}, ok, err
+	}
+
+	return orderFromDeribit(symbol, order, nil), true, nil
+}
+
+// order places an order of qty contracts, which must be a multiple of the
+// contract size of the instrument
+func (h *DeribitHedger) order(symbol string, side Side, qty float64, orderType string,
+	price float64, label string) (Order, error) {
+
+	instrument, err := h.instrument(symbol)
+	if err != nil {
+		return Order{}, err
+	}
+
+	err = h.checkContracts(instrument, qty)
+	if err != nil {
+		return Order{}, err
+	}
+
+	var result deribit.OrderResult
+	if side == Buy {
+		result, err = h.api.Buy(instrument, qty, orderType, price, label)
+	} else {
+		result, err = h.api.Sell(instrument, qty, orderType, price, label)
+	}
+	if err != nil {
+		return Order{}, err
+	}
+
+	return orderFromDeribit(symbol, result.Order, result.Trades), nil
+}
+
+// checkContracts checks that qty is a whole number of contracts of the
+// instrument. Rounding it here would leave what was rounded away unhedged
+// without anyone knowing, so quantities that are not are refused
+func (h *DeribitHedger) checkContracts(instrument string, qty float64) error {
+	h.mu.Lock()
+	contractSize, ok := h.contractSizes[instrument]
+	h.mu.Unlock()
+
+	if !ok {
+		details, err := h.api.Instrument(instrument)
+		if err != nil {
+			return err
+		}
+		contractSize = details.ContractSize
+
+		h.mu.Lock()
+		h.contractSizes[instrument] = contractSize
+		h.mu.Unlock()
+	}
+
+	if contractSize <= 0 {
+		return nil
+	}
+
+	contracts := qty / contractSize
+	if contracts < 1 || math.Abs(contracts-math.Round(contracts)) > 1e-9 {
+		return fmt.Errorf("%v is not a multiple of the %v contract size of %s: %w",
+			qty, contractSize, instrument, deribit.ErrInvalidQty)
+	}
+
+	return nil
+}
+
+// instrument returns the deribit instrument our symbol is traded as
+func (h *DeribitHedger) instrument(symbol string) (string, error) {
+	instrument, ok := h.instruments[symbol]
+	if !ok {
+		return "", fmt.Errorf("%s is not traded on deribit", symbol)
+	}
+
+	return instrument, nil
+}
+
+// orderFromDeribit converts an order returned by deribit, summing the fees
+// of the trades it was filled with
+func orderFromDeribit(symbol string, order deribit.Order, trades []deribit.Trade) Order {
+	var fee float64
+	for _, trade := range trades {
+		if trade.FeeCurrency == deribit.BTC {
+			fee += trade.Fee
+		}
+	}
+
+	side := Buy
+	if order.Direction == "sell" {
+		side = Sell
+	}
+
+	return Order{
+		ID:       order.OrderID,
+		ClientID: order.Label,
+		Symbol:   symbol,
+		Side:     side,
+		Qty:      order.Amount,
+		Price:    order.AveragePrice,
+		Fee:      int64(math.Round(fee * btcutil.SatoshiPerBitcoin)),
+	}
+}
+
+// CancelOrders cancels all our open deribit orders in the instrument
+func (h *DeribitHedger) CancelOrders(symbol string) error {
+	instrument, err := h.instrument(symbol)
+	if err != nil {
+		return err
+	}
+
+	return h.api.CancelAll(instrument)
+}
+
+// Position returns our deribit position in the instrument
+func (h *DeribitHedger) Position(symbol string) (Position, error) {
+	instrument, err := h.instrument(symbol)
+	if err != nil {
+		return Position{}, err
+	}
+
+	position, err := h.api.Position(instrument)
+	if err != nil {
+		return Position{}, err
+	}
+
+	return Position{
+		Symbol:        symbol,
+		Qty:           position.Size,
+		AvgEntryPrice: position.AveragePrice,
+	}, nil
+}
+
+// Margin returns the margin status of the bitcoin balance of our deribit
+// account
+func (h *DeribitHedger) Margin() (Margin, error) {
+	summary, err := h.api.AccountSummary(deribit.BTC)
+	if err != nil {
+		return Margin{}, err
+	}
+
+	return Margin{
+		WalletBalance:   int64(math.Round(summary.Balance * btcutil.SatoshiPerBitcoin)),
+		AvailableMargin: int64(math.Round(summary.AvailableFunds * btcutil.SatoshiPerBitcoin)),
+	}, nil
+}
+
+// FundingPayments returns the funding settled on our deribit position in
+// the instrument since the given time. Deribit does not report the funding
+// rate, so it is derived from what was paid on the value of the position
+func (h *DeribitHedger) FundingPayments(symbol string, since time.Time) ([]FundingPayment, error) {
+	instrument, err := h.instrument(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := h.api.TransactionLog(deribit.BTC, since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []FundingPayment
+	for _, transaction := range transactions {
+		if transaction.Type != deribit.TransactionTypeSettlement ||
+			transaction.InstrumentName != instrument || transaction.InterestPL == 0 {
+			continue
+		}
+
+		// deribit reports what we received, we record what we paid
+		paid := -transaction.InterestPL
+
+		var rate float64
+		if transaction.Position != 0 && transaction.Price != 0 {
+			rate = paid / (transaction.Position / transaction.Price)
+		}
+
+		payments = append(payments, FundingPayment{
+			ID:          strconv.FormatInt(transaction.ID, 10),
+			Symbol:      symbol,
+			Venue:       h.Name(),
+			Time:        time.Unix(0, transaction.Timestamp*int64(time.Millisecond)),
+			Rate:        rate,
+			PositionQty: transaction.Position,
+			Amount:      int64(math.Round(paid * btcutil.SatoshiPerBitcoin)),
+		})
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].Time.Before(payments[j].Time)
+	})
+
+	return payments, nil
+}
@@ -0,0 +1,196 @@
+package hedge
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+// Engine keeps a single position in each instrument matching the net
+// exposure of all contracts hedged with it, instead of trading once per
+// contract. If the hedger can place limit orders, positions are adjusted
+// with limit orders priced slightly through the oracle price, so they fill
+// right away without paying more than the allowed slippage.
+//
+// Positions are adjusted in whole lots of each instrument, so a position
+// can be off its target by up to half a lot. What is left unhedged is kept
+// as the residual of the instrument.
+type Engine struct {
+	hedger Hedger
+	prices oracle.PriceOracle
+
+	// slippageBps is how many basis points worse than the oracle price we
+	// are willing to fill limit orders at
+	slippageBps float64
+	// tickSizes is the smallest price increment of each instrument. Limit
+	// prices are rounded to them
+	tickSizes map[string]float64
+	// lotSizes are the order quantities each instrument can be traded in
+	lotSizes map[string]LotSize
+
+	mu sync.Mutex
+	// residuals are how many contracts of each instrument our position was
+	// left short of its target by rounding to whole lots when it was last
+	// adjusted, negative if it was left over
+	residuals map[string]float64
+}
+
+// NewEngine creates a hedging engine trading through the hedger
+func NewEngine(hedger Hedger, prices oracle.PriceOracle, slippageBps float64,
+	tickSizes map[string]float64, lotSizes map[string]LotSize) *Engine {
+
+	return &Engine{
+		hedger:      hedger,
+		prices:      prices,
+		slippageBps: slippageBps,
+		tickSizes:   tickSizes,
+		lotSizes:    lotSizes,
+		residuals:   make(map[string]float64),
+	}
+}
+
+// Residuals returns how many contracts of each instrument our position was
+// left short of its target by rounding to whole lots when it was last
+// adjusted, negative if it was left over
+func (e *Engine) Residuals() map[string]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	residuals := make(map[string]float64)
+	for symbol, residual := range e.residuals {
+		residuals[symbol] = residual
+	}
+
+	return residuals
+}
+
+func (e *Engine) setResidual(symbol string, residual float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.residuals[symbol] = residual
+}
+
+// Adjust brings our position in every instrument to its target, if it is
+// at least threshold contracts off. It returns the orders placed. All
+// instruments are adjusted even if some of them fail, and the first error
+// is returned
+func (e *Engine) Adjust(targets map[string]float64, threshold float64) ([]Order, error) {
+	var symbols []string
+	for symbol := range targets {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var orders []Order
+	var firstErr error
+	for _, symbol := range symbols {
+		order, ok, err := e.adjust(symbol, targets[symbol], threshold)
+		if err != nil {
+			log.WithError(err).WithField("symbol", symbol).Error("could not adjust position")
+			if firstErr == nil {
+				firstErr = fmt.Errorf("could not adjust %s position: %w", symbol, err)
+			}
+			continue
+		}
+		if ok {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders, firstErr
+}
+
+// adjust brings our position in the instrument as close to target as whole
+// lots allow. Any of our limit orders still resting from the last
+// adjustment are cancelled first, so they are not counted twice
+func (e *Engine) adjust(symbol string, target, threshold float64) (Order, bool, error) {
+	limitHedger, canLimit := e.hedger.(LimitHedger)
+	if canLimit {
+		err := limitHedger.CancelOrders(symbol)
+		if err != nil {
+			return Order{}, false, err
+		}
+	}
+
+	position, err := e.hedger.Position(symbol)
+	if err != nil {
+		return Order{}, false, err
+	}
+
+	diff := target - position.Qty
+	if diff == 0 || math.Abs(diff) < threshold {
+		return Order{}, false, nil
+	}
+
+	diff, residual := e.lotSizes[symbol].Round(diff)
+	e.setResidual(symbol, residual)
+	if diff == 0 {
+		return Order{}, false, nil
+	}
+
+	logger := log.WithFields(logrus.Fields{
+		"symbol":   symbol,
+		"position": position.Qty,
+		"target":   target,
+	})
+
+	var order Order
+	price, priceErr := e.prices.LatestPrice(symbol)
+	switch {
+	case canLimit && priceErr == nil && price.Value > 0:
+		if diff > 0 {
+			order, err = limitHedger.OpenExposureAt(symbol, diff, e.limitPrice(symbol, price.Value, Buy))
+		} else {
+			order, err = limitHedger.CloseExposureAt(symbol, -diff, e.limitPrice(symbol, price.Value, Sell))
+		}
+
+	default:
+		if canLimit {
+			logger.WithError(priceErr).Warn("no price to place limit order at, using market order")
+		}
+		if diff > 0 {
+			order, err = e.hedger.OpenExposure(symbol, diff)
+		} else {
+			order, err = e.hedger.CloseExposure(symbol, -diff)
+		}
+	}
+	if err != nil {
+		return Order{}, false, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"orderID": order.ID,
+		"side":    order.Side,
+		"qty":     order.Qty,
+	}).Info("adjusted hedge position")
+
+	return order, true, nil
+}
+
+// limitPrice returns the price to place a limit order at, which is the
+// price moved by our slippage allowance in the direction of the order, and
+// rounded to the tick size of the instrument in our favour
+func (e *Engine) limitPrice(symbol string, price float64, side Side) float64 {
+	slippage := price * e.slippageBps / 10000
+	tick := e.tickSizes[symbol]
+
+	if side == Buy {
+		limit := price + slippage
+		if tick > 0 {
+			limit = math.Floor(limit/tick) * tick
+		}
+		return limit
+	}
+
+	limit := price - slippage
+	if tick > 0 {
+		limit = math.Ceil(limit/tick) * tick
+	}
+	return limit
+}
@@ -0,0 +1,75 @@
+package hedge
+
+import (
+	"errors"
+	"net"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/deribit"
+)
+
+// permanentErrors are the errors of venues that sending the same request
+// again will not fix
+var permanentErrors = []error{
+	bitmex.ErrInvalidQty,
+	bitmex.ErrInsufficientFunds,
+	bitmex.ErrAuth,
+	deribit.ErrInvalidQty,
+	deribit.ErrInsufficientFunds,
+	deribit.ErrAuth,
+}
+
+// Permanent returns whether an order failed for a reason retrying it will
+// not fix: the venue refused its quantity, we do not have the margin for
+// it, or the venue refused our credentials
+func Permanent(err error) bool {
+	for _, permanent := range permanentErrors {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// busyErrors are the errors of venues that refused a request because they
+// are too busy to take it right now
+var busyErrors = []error{
+	bitmex.ErrRateLimited,
+	bitmex.ErrOverloaded,
+	deribit.ErrRateLimited,
+	deribit.ErrOverloaded,
+}
+
+// busy returns whether the venue refused the request because it is rate
+// limiting us or overloaded. The request was not carried out
+func busy(err error) bool {
+	for _, b := range busyErrors {
+		if errors.Is(err, b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// transportError returns whether the request never got an answer from the
+// venue, e.g. because it timed out or the connection failed. Whether it was
+// carried out is unknown. Errors the venue answered with are wrapped in
+// network errors by the http client, so they are ruled out first
+func transportError(err error) bool {
+	var bitmexErr *bitmex.APIError
+	var deribitErr *deribit.Error
+	if errors.As(err, &bitmexErr) || errors.As(err, &deribitErr) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// unavailable returns whether a request failed because the venue is down
+// or busy, rather than because of the request itself
+func unavailable(err error) bool {
+	return busy(err) || transportError(err)
+}
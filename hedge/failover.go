@@ -0,0 +1,524 @@
+package hedge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ LimitHedger   = &FailoverHedger{}
+	_ FundingHedger = &FailoverHedger{}
+	_ FillHedger    = &FailoverHedger{}
+)
+
+// FailoverHedger hedges every instrument on the first of its venues that
+// is up, so an outage of one venue does not stop us from hedging. A venue
+// that is unreachable, rate limits us or is overloaded is considered down
+// for a cooldown, during which orders fail over to the next venue of the
+// instrument. If all venues of an instrument are down, they are all tried
+// again in order. Orders a venue refuses for any other reason, like their
+// quantity or our margin, would fail the same way elsewhere, so they are
+// not failed over and do not take the venue down.
+//
+// After failing over, an instrument may be held on several venues, so our
+// position is the sum of our positions on all of its venues. Venues that
+// are down are counted with the last position we got from them.
+//
+// An order that fails without us learning whether it was placed, e.g. on
+// a timeout, is only failed over if it has a client id we can look it up
+// with on the venue first, so it is not placed twice.
+type FailoverHedger struct {
+	venues map[string]Hedger
+	// routes are the names of the venues each instrument is hedged on, in
+	// order of preference
+	routes   map[string][]string
+	cooldown time.Duration
+
+	mu     sync.Mutex
+	health map[string]*venueHealth
+	// positions are the last positions we got from each venue, by venue
+	// and symbol
+	positions map[string]map[string]Position
+	// margins are the last margin statuses we got from each venue
+	margins map[string]Margin
+
+	// fills are the fills of all venues that report them
+	fills chan Fill
+}
+
+// venueHealth is whether a venue is up
+type venueHealth struct {
+	// downUntil is when orders can be routed to the venue again after it
+	// failed
+	downUntil time.Time
+	lastError error
+}
+
+// VenueStatus is the health of a single venue
+type VenueStatus struct {
+	Name string
+	Up   bool
+	// DownUntil is when the venue is tried again, if it is down
+	DownUntil time.Time
+	// LastError is the last error the venue failed with
+	LastError error
+}
+
+// NewFailoverHedger creates a hedger routing orders in every instrument to
+// the venues of its route, in order of preference. Venues that are down
+// are not routed to for the cooldown
+func NewFailoverHedger(venues []Hedger, routes map[string][]string,
+	cooldown time.Duration) (*FailoverHedger, error) {
+
+	if len(venues) == 0 {
+		return nil, errors.New("no venues to hedge on")
+	}
+
+	h := &FailoverHedger{
+		venues:    make(map[string]Hedger),
+		routes:    routes,
+		cooldown:  cooldown,
+		health:    make(map[string]*venueHealth),
+		positions: make(map[string]map[string]Position),
+		margins:   make(map[string]Margin),
+		fills:     make(chan Fill, fillsBuffer),
+	}
+
+	for _, venue := range venues {
+		h.venues[venue.Name()] = venue
+		h.health[venue.Name()] = &venueHealth{}
+		h.positions[venue.Name()] = make(map[string]Position)
+	}
+
+	for symbol, route := range routes {
+		if len(route) == 0 {
+			return nil, fmt.Errorf("%s has no venues", symbol)
+		}
+		for _, name := range route {
+			if _, ok := h.venues[name]; !ok {
+				return nil, fmt.Errorf("%s is hedged on unknown venue %q", symbol, name)
+			}
+		}
+	}
+
+	// fills are passed on from every venue as they arrive, labelled with
+	// the venue the order was placed on. Waiting for our queue to drain
+	// only holds up the goroutine forwarding them, as venues queue fills
+	// without blocking their feeds, and fetch fills they had to drop again
+	for name, venue := range h.venues {
+		fillVenue, ok := venue.(FillHedger)
+		if !ok {
+			continue
+		}
+
+		go func(name string, fills <-chan Fill) {
+			for fill := range fills {
+				fill.Venue = name
+				h.fills <- fill
+			}
+		}(name, fillVenue.Fills())
+	}
+
+	return h, nil
+}
+
+// Name returns the name of the hedger
+func (h *FailoverHedger) Name() string {
+	return "failover"
+}
+
+// Status returns the health of every venue, sorted by name
+func (h *FailoverHedger) Status() []VenueStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	var statuses []VenueStatus
+	for name, health := range h.health {
+		status := VenueStatus{
+			Name:      name,
+			Up:        !now.Before(health.downUntil),
+			LastError: health.lastError,
+		}
+		if !status.Up {
+			status.DownUntil = health.downUntil
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// candidates returns the venues of the instrument in the order they should
+// be tried: venues that are up in order of preference, followed by those
+// that are down
+func (h *FailoverHedger) candidates(symbol string) ([]string, error) {
+	route, ok := h.routes[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no venue hedges %s", symbol)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	var up, down []string
+	for _, name := range route {
+		if now.Before(h.health[name].downUntil) {
+			down = append(down, name)
+		} else {
+			up = append(up, name)
+		}
+	}
+
+	return append(up, down...), nil
+}
+
+// succeeded records that a request to the venue succeeded
+func (h *FailoverHedger) succeeded(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health := h.health[name]
+	if !health.downUntil.IsZero() {
+		log.WithField("venue", name).Info("venue is back up")
+	}
+	health.downUntil = time.Time{}
+	health.lastError = nil
+}
+
+// failed records that a request to the venue failed. If it failed because
+// the venue is unavailable, the venue is taken down for the cooldown, or
+// for as long as the venue asked us to back off if that is longer
+func (h *FailoverHedger) failed(name string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health := h.health[name]
+	health.lastError = err
+	if !unavailable(err) {
+		return
+	}
+
+	if health.downUntil.IsZero() {
+		log.WithError(err).WithFields(logrus.Fields{
+			"venue":    name,
+			"cooldown": h.cooldown,
+		}).Error("ALERT: venue failed, failing over to other venues")
+	}
+	cooldown := h.cooldown
+	if retryAfter := RetryAfter(err); retryAfter > cooldown {
+		cooldown = retryAfter
+	}
+	health.downUntil = time.Now().Add(cooldown)
+}
+
+// route places an order in the instrument with the first venue that
+// accepts it. Orders are only passed on to the next venue if the venue is
+// unavailable. If we do not know whether the venue placed the order, it is
+// looked up with lookup first, and not passed on if lookup is nil
+func (h *FailoverHedger) route(symbol string, place func(venue Hedger) (Order, error),
+	lookup func(venue Hedger) (Order, bool, error)) (Order, error) {
+
+	names, err := h.candidates(symbol)
+	if err != nil {
+		return Order{}, err
+	}
+
+	var lastErr error
+	for i, name := range names {
+		order, err := place(h.venues[name])
+		if err != nil {
+			h.failed(name, err)
+			lastErr = fmt.Errorf("%s: %w", name, err)
+
+			switch {
+			case busy(err):
+				continue
+
+			case transportError(err):
+				if lookup == nil {
+					return Order{}, fmt.Errorf("%s order may have been placed on %s: %w", symbol, name, err)
+				}
+
+				var found bool
+				var lookupErr error
+				order, found, lookupErr = lookup(h.venues[name])
+				if lookupErr != nil {
+					return Order{}, fmt.Errorf("could not find out whether %s placed %s order: %v: %w",
+						name, symbol, lookupErr, err)
+				}
+				if !found {
+					continue
+				}
+
+			default:
+				return Order{}, lastErr
+			}
+		} else {
+			h.succeeded(name)
+		}
+
+		if i > 0 {
+			log.WithFields(logrus.Fields{
+				"symbol":  symbol,
+				"venue":   name,
+				"orderID": order.ID,
+			}).Warn("placed order on failover venue")
+		}
+
+		order.Venue = name
+		return order, nil
+	}
+
+	return Order{}, fmt.Errorf("no venue could place %s order: %w", symbol, lastErr)
+}
+
+// OpenExposure market buys qty contracts of the instrument
+func (h *FailoverHedger) OpenExposure(symbol string, qty float64) (Order, error) {
+	return h.route(symbol, func(venue Hedger) (Order, error) {
+		return venue.OpenExposure(symbol, qty)
+	}, nil)
+}
+
+// CloseExposure market sells qty contracts of the instrument
+func (h *FailoverHedger) CloseExposure(symbol string, qty float64) (Order, error) {
+	return h.route(symbol, func(venue Hedger) (Order, error) {
+		return venue.CloseExposure(symbol, qty)
+	}, nil)
+}
+
+// OpenExposureAt places a limit buy of qty contracts of the instrument.
+// Venues that can not place limit orders market buy instead
+func (h *FailoverHedger) OpenExposureAt(symbol string, qty, price float64) (Order, error) {
+	return h.route(symbol, func(venue Hedger) (Order, error) {
+		if limitVenue, ok := venue.(LimitHedger); ok {
+			return limitVenue.OpenExposureAt(symbol, qty, price)
+		}
+		return venue.OpenExposure(symbol, qty)
+	}, nil)
+}
+
+// CloseExposureAt places a limit sell of qty contracts of the instrument.
+// Venues that can not place limit orders market sell instead
+func (h *FailoverHedger) CloseExposureAt(symbol string, qty, price float64) (Order, error) {
+	return h.route(symbol, func(venue Hedger) (Order, error) {
+		if limitVenue, ok := venue.(LimitHedger); ok {
+			return limitVenue.CloseExposureAt(symbol, qty, price)
+		}
+		return venue.CloseExposure(symbol, qty)
+	}, nil)
+}
+
+// PlaceOrder places a market order with our client id on the first venue
+// that accepts it. If a venue does not answer, the order is looked up there
+// by its client id before it is passed on to the next venue
+func (h *FailoverHedger) PlaceOrder(clientID, symbol string, side Side, qty float64) (Order, error) {
+	return h.route(symbol, func(venue Hedger) (Order, error) {
+		return venue.PlaceOrder(clientID, symbol, side, qty)
+	}, func(venue Hedger) (Order, bool, error) {
+		return venue.LookupOrder(clientID, symbol)
+	})
+}
+
+// LookupOrder looks for the order with our client id on every venue of the
+// instrument, as it may have been placed on any of them. Venues we can not
+// reach are skipped, unless we can reach none of them
+func (h *FailoverHedger) LookupOrder(clientID, symbol string) (Order, bool, error) {
+	route, ok := h.routes[symbol]
+	if !ok {
+		return Order{}, false, fmt.Errorf("no venue hedges %s", symbol)
+	}
+
+	var lastErr error
+	reached := false
+	for _, name := range route {
+		order, found, err := h.venues[name].LookupOrder(clientID, symbol)
+		if err != nil {
+			h.failed(name, err)
+			lastErr = fmt.Errorf("%s: %w", name, err)
+			continue
+		}
+		reached = true
+
+		if found {
+			order.Venue = name
+			return order, true, nil
+		}
+	}
+
+	if !reached {
+		return Order{}, false, fmt.Errorf("could not look up order on any venue: %w", lastErr)
+	}
+
+	return Order{}, false, nil
+}
+
+// CancelOrders cancels all our open orders in the instrument on every
+// venue. Venues we can not reach are skipped
+func (h *FailoverHedger) CancelOrders(symbol string) error {
+	route, ok := h.routes[symbol]
+	if !ok {
+		return fmt.Errorf("no venue hedges %s", symbol)
+	}
+
+	for _, name := range route {
+		limitVenue, ok := h.venues[name].(LimitHedger)
+		if !ok {
+			continue
+		}
+
+		err := limitVenue.CancelOrders(symbol)
+		if err != nil {
+			h.failed(name, err)
+			log.WithError(err).WithFields(logrus.Fields{
+				"venue":  name,
+				"symbol": symbol,
+			}).Warn("could not cancel orders")
+		}
+	}
+
+	return nil
+}
+
+// Position returns the sum of our positions in the instrument on all its
+// venues. The entry price is the average of the venues, weighted
+// harmonically by position size like for inverse contracts
+func (h *FailoverHedger) Position(symbol string) (Position, error) {
+	route, ok := h.routes[symbol]
+	if !ok {
+		return Position{}, fmt.Errorf("no venue hedges %s", symbol)
+	}
+
+	total := Position{Symbol: symbol}
+	var value float64
+	for _, name := range route {
+		position, err := h.venues[name].Position(symbol)
+		if err != nil {
+			h.failed(name, err)
+
+			var ok bool
+			h.mu.Lock()
+			position, ok = h.positions[name][symbol]
+			h.mu.Unlock()
+			if !ok {
+				return Position{}, fmt.Errorf("%s: %w", name, err)
+			}
+
+			log.WithError(err).WithFields(logrus.Fields{
+				"venue":    name,
+				"symbol":   symbol,
+				"position": position.Qty,
+			}).Warn("could not get position, using the last one we know of")
+		} else {
+			h.mu.Lock()
+			h.positions[name][symbol] = position
+			h.mu.Unlock()
+		}
+
+		total.Qty += position.Qty
+		if position.AvgEntryPrice > 0 {
+			value += position.Qty / position.AvgEntryPrice
+		}
+	}
+
+	if value != 0 {
+		total.AvgEntryPrice = total.Qty / value
+	}
+
+	return total, nil
+}
+
+// FundingPayments returns the funding paid or received on our positions in
+// the instrument on all its venues, oldest first. Venues that do not pay
+// funding are skipped, and so are venues we can not reach, unless we can
+// reach none of them
+func (h *FailoverHedger) FundingPayments(symbol string, since time.Time) ([]FundingPayment, error) {
+	route, ok := h.routes[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no venue hedges %s", symbol)
+	}
+
+	var payments []FundingPayment
+	var lastErr error
+	reached := false
+	for _, name := range route {
+		fundingVenue, ok := h.venues[name].(FundingHedger)
+		if !ok {
+			continue
+		}
+
+		venuePayments, err := fundingVenue.FundingPayments(symbol, since)
+		if err != nil {
+			h.failed(name, err)
+			lastErr = fmt.Errorf("%s: %w", name, err)
+			continue
+		}
+		reached = true
+
+		for _, payment := range venuePayments {
+			payment.Venue = name
+			payments = append(payments, payment)
+		}
+	}
+
+	if !reached && lastErr != nil {
+		return nil, fmt.Errorf("could not get funding from any venue: %w", lastErr)
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].Time.Before(payments[j].Time)
+	})
+
+	return payments, nil
+}
+
+// Margin returns the sum of the margin of all venues. Venues that are down
+// are counted with the last margin we got from them
+func (h *FailoverHedger) Margin() (Margin, error) {
+	var names []string
+	for name := range h.venues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var total Margin
+	for _, name := range names {
+		margin, err := h.venues[name].Margin()
+		if err != nil {
+			h.failed(name, err)
+
+			var ok bool
+			h.mu.Lock()
+			margin, ok = h.margins[name]
+			h.mu.Unlock()
+			if !ok {
+				return Margin{}, fmt.Errorf("%s: %w", name, err)
+			}
+		} else {
+			h.mu.Lock()
+			h.margins[name] = margin
+			h.mu.Unlock()
+		}
+
+		total.WalletBalance += margin.WalletBalance
+		total.AvailableMargin += margin.AvailableMargin
+	}
+
+	return total, nil
+}
+
+// Fills returns a channel receiving the fills of all venues that report
+// them
+func (h *FailoverHedger) Fills() <-chan Fill {
+	return h.fills
+}
@@ -0,0 +1,226 @@
+package hedge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ArcaneCryptoAS/lassets-server/bitmex"
+	"github.com/ArcaneCryptoAS/lassets-server/deribit"
+)
+
+// timeoutError is a network error for a request that got no answer
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// stubVenue fails every order with err, and reports the orders it placed
+// and those found when looked up
+type stubVenue struct {
+	name   string
+	err    error
+	placed int
+	// found is whether LookupOrder finds the order, and lookupErr what it
+	// fails with
+	found     bool
+	lookupErr error
+	lookups   int
+}
+
+func (v *stubVenue) Name() string {
+	return v.name
+}
+
+func (v *stubVenue) OpenExposure(symbol string, qty float64) (Order, error) {
+	return v.PlaceOrder("", symbol, Buy, qty)
+}
+
+func (v *stubVenue) CloseExposure(symbol string, qty float64) (Order, error) {
+	return v.PlaceOrder("", symbol, Sell, qty)
+}
+
+func (v *stubVenue) PlaceOrder(clientID, symbol string, side Side, qty float64) (Order, error) {
+	if v.err != nil {
+		return Order{}, v.err
+	}
+	v.placed++
+
+	return Order{ID: v.name + "-order", ClientID: clientID, Symbol: symbol, Side: side, Qty: qty}, nil
+}
+
+func (v *stubVenue) LookupOrder(clientID, symbol string) (Order, bool, error) {
+	v.lookups++
+	if v.lookupErr != nil || !v.found {
+		return Order{}, false, v.lookupErr
+	}
+
+	return Order{ID: v.name + "-found", ClientID: clientID, Symbol: symbol}, true, nil
+}
+
+func (v *stubVenue) Position(symbol string) (Position, error) {
+	return Position{Symbol: symbol}, nil
+}
+
+func (v *stubVenue) Margin() (Margin, error) {
+	return Margin{}, nil
+}
+
+func TestFailoverHedgerRoute(t *testing.T) {
+	transportErr := &url.Error{Op: "Post", URL: "https://venue", Err: timeoutError{}}
+	// the http client wraps errors the venue answered with in network
+	// errors as well
+	answeredErr := &url.Error{Op: "Post", URL: "https://venue", Err: &bitmex.APIError{StatusCode: 400}}
+
+	tests := []struct {
+		name string
+		// first is how the preferred venue fails
+		first     *stubVenue
+		clientID  string
+		wantVenue string
+		// firstDown is whether the preferred venue is taken down
+		firstDown bool
+		wantErr   bool
+	}{
+		{
+			name:      "preferred venue places order",
+			first:     &stubVenue{},
+			wantVenue: "first",
+		},
+		{
+			name:      "rate limited venue fails over",
+			first:     &stubVenue{err: fmt.Errorf("placing: %w", bitmex.ErrRateLimited)},
+			wantVenue: "second",
+			firstDown: true,
+		},
+		{
+			name:      "overloaded venue fails over",
+			first:     &stubVenue{err: &deribit.Error{Code: 11051}},
+			wantVenue: "second",
+			firstDown: true,
+		},
+		{
+			name:    "invalid quantity does not fail over",
+			first:   &stubVenue{err: fmt.Errorf("placing: %w", bitmex.ErrInvalidQty)},
+			wantErr: true,
+		},
+		{
+			name:    "insufficient funds does not fail over",
+			first:   &stubVenue{err: &deribit.Error{Code: 10009}},
+			wantErr: true,
+		},
+		{
+			name:    "answered error does not fail over",
+			first:   &stubVenue{err: answeredErr},
+			wantErr: true,
+		},
+		{
+			name:      "unanswered order without client id is not placed again",
+			first:     &stubVenue{err: transportErr},
+			firstDown: true,
+			wantErr:   true,
+		},
+		{
+			name:      "unanswered order not found fails over",
+			first:     &stubVenue{err: transportErr},
+			clientID:  "client",
+			wantVenue: "second",
+			firstDown: true,
+		},
+		{
+			name:      "unanswered order that was placed is not placed again",
+			first:     &stubVenue{err: transportErr, found: true},
+			clientID:  "client",
+			wantVenue: "first",
+			firstDown: true,
+		},
+		{
+			name:      "unanswered order that can not be looked up is not placed again",
+			first:     &stubVenue{err: transportErr, lookupErr: transportErr},
+			clientID:  "client",
+			firstDown: true,
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			first := test.first
+			first.name = "first"
+			second := &stubVenue{name: "second"}
+
+			h, err := NewFailoverHedger([]Hedger{first, second},
+				map[string][]string{"XBTUSD": {"first", "second"}}, time.Minute)
+			if err != nil {
+				t.Fatalf("could not create hedger: %v", err)
+			}
+
+			var order Order
+			if test.clientID != "" {
+				order, err = h.PlaceOrder(test.clientID, "XBTUSD", Buy, 10)
+			} else {
+				order, err = h.OpenExposure("XBTUSD", 10)
+			}
+
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, placed order on %s", order.Venue)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if order.Venue != test.wantVenue {
+					t.Fatalf("order placed on %s, want %s", order.Venue, test.wantVenue)
+				}
+			}
+
+			if test.wantVenue != "second" && second.placed > 0 {
+				t.Fatal("order was also placed on the second venue")
+			}
+
+			down := false
+			for _, status := range h.Status() {
+				if status.Name == "first" {
+					down = !status.Up
+				}
+				if status.Name == "second" && !status.Up {
+					t.Fatal("second venue was taken down")
+				}
+			}
+			if down != test.firstDown {
+				t.Fatalf("first venue down = %v, want %v", down, test.firstDown)
+			}
+		})
+	}
+}
+
+func TestFailoverHedgerAllVenuesDown(t *testing.T) {
+	limited := fmt.Errorf("placing: %w", bitmex.ErrRateLimited)
+	first := &stubVenue{name: "first", err: limited}
+	second := &stubVenue{name: "second", err: limited}
+
+	h, err := NewFailoverHedger([]Hedger{first, second},
+		map[string][]string{"XBTUSD": {"first", "second"}}, time.Minute)
+	if err != nil {
+		t.Fatalf("could not create hedger: %v", err)
+	}
+
+	_, err = h.OpenExposure("XBTUSD", 10)
+	if !errors.Is(err, bitmex.ErrRateLimited) {
+		t.Fatalf("expected the last venue error, got %v", err)
+	}
+
+	// venues that are down are still tried, in order, when all are down
+	first.err = nil
+	order, err := h.OpenExposure("XBTUSD", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order.Venue != "first" {
+		t.Fatalf("order placed on %s, want first", order.Venue)
+	}
+}
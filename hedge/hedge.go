@@ -0,0 +1,197 @@
+// Package hedge defines how lasd offsets the exposure it takes on when
+// contracts are opened. Contract code only depends on the Hedger interface,
+// so it can run against a real exchange, or against a paper-trading hedger
+// on regtest.
+package hedge
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// Side is the side of an order
+type Side string
+
+const (
+	Buy  Side = "Buy"
+	Sell Side = "Sell"
+)
+
+// Order is an order placed to open or close exposure
+type Order struct {
+	// ID is the id the venue assigned the order
+	ID string
+	// ClientID is our own id of the order, if we gave it one
+	ClientID string
+	Symbol   string
+	Side     Side
+	// Qty is the number of contracts of the instrument, always positive
+	Qty float64
+	// Price is the average price the order was filled at, zero if unknown
+	Price float64
+	// Fee is what we paid the venue for the order in satoshis, zero if
+	// unknown
+	Fee int64
+	// Venue is the name of the hedger the order was placed with, set when
+	// orders are routed to one of several venues
+	Venue string
+}
+
+// OrderStatus is the status of an order after an update
+type OrderStatus string
+
+const (
+	StatusPartiallyFilled OrderStatus = "PartiallyFilled"
+	StatusFilled          OrderStatus = "Filled"
+	StatusCanceled        OrderStatus = "Canceled"
+	StatusRejected        OrderStatus = "Rejected"
+)
+
+// Done returns whether orders with the status will not change anymore
+func (s OrderStatus) Done() bool {
+	return s == StatusFilled || s == StatusCanceled || s == StatusRejected
+}
+
+// Fill is an update on the execution of one of our orders, sent when it is
+// filled in full or in part, and when it is cancelled or rejected
+type Fill struct {
+	// ID is the id the venue assigned the execution, unique per venue
+	ID       string
+	OrderID  string
+	ClientID string
+	Symbol   string
+	Side     Side
+	// Qty is the number of contracts this execution filled, at Price. Zero
+	// when the order was cancelled or rejected
+	Qty   float64
+	Price float64
+	// FilledQty is how much of the order has been filled so far, at the
+	// average price AvgPrice
+	FilledQty float64
+	AvgPrice  float64
+	// Fee is what we paid the venue for this execution in satoshis
+	Fee    int64
+	Status OrderStatus
+	// Reason is why the order was cancelled or rejected
+	Reason string
+	Time   time.Time
+	// Venue is the name of the hedger the order was placed with, set when
+	// orders are routed to one of several venues
+	Venue string
+}
+
+// Position is our position in a single instrument
+type Position struct {
+	Symbol string
+	// Qty is the number of contracts we hold, negative for short positions
+	Qty float64
+	// AvgEntryPrice is the average price the position was opened at
+	AvgEntryPrice float64
+}
+
+// Margin is the margin status of our account, in satoshis
+type Margin struct {
+	// WalletBalance is the deposits and realised profit of the account
+	WalletBalance int64
+	// AvailableMargin is what is left to open new positions with
+	AvailableMargin int64
+}
+
+// Hedger is anything that can take on and give up exposure to instruments,
+// and tell us what exposure we currently hold
+type Hedger interface {
+	// Name returns the name of the hedger, used in logs
+	Name() string
+
+	// OpenExposure buys qty contracts of the instrument
+	OpenExposure(symbol string, qty float64) (Order, error)
+
+	// CloseExposure sells qty contracts of the instrument
+	CloseExposure(symbol string, qty float64) (Order, error)
+
+	// PlaceOrder places a market order tagged with our own client id, so
+	// it can be looked up with LookupOrder if we do not learn whether it
+	// was placed
+	PlaceOrder(clientID, symbol string, side Side, qty float64) (Order, error)
+
+	// LookupOrder returns the order we placed with the client id, and false
+	// if there is none
+	LookupOrder(clientID, symbol string) (Order, bool, error)
+
+	// Position returns our current position in the instrument
+	Position(symbol string) (Position, error)
+
+	// Margin returns the margin status of our account
+	Margin() (Margin, error)
+}
+
+// LimitHedger is a Hedger that can also place limit orders, which rest on
+// the venue until filled or cancelled
+type LimitHedger interface {
+	Hedger
+
+	// OpenExposureAt buys qty contracts of the instrument at price or lower
+	OpenExposureAt(symbol string, qty, price float64) (Order, error)
+
+	// CloseExposureAt sells qty contracts of the instrument at price or
+	// higher
+	CloseExposureAt(symbol string, qty, price float64) (Order, error)
+
+	// CancelOrders cancels all our open orders in the instrument
+	CancelOrders(symbol string) error
+}
+
+// FillHedger is a Hedger that tells us when our orders are filled, instead
+// of only what we learn when placing them
+type FillHedger interface {
+	Hedger
+
+	// Fills returns a channel receiving updates on the execution of our
+	// orders. Updates may be sent more than once, so they must be told
+	// apart by their ID
+	Fills() <-chan Fill
+}
+
+// FundingPayment is funding paid or received on our position in a
+// perpetual instrument
+type FundingPayment struct {
+	// ID is the id the venue assigned the payment
+	ID     string
+	Symbol string
+	// Venue is the name of the hedger the position is held with
+	Venue string
+	Time  time.Time
+	// Rate is the funding rate, paid by longs to shorts when positive
+	Rate float64
+	// PositionQty is the position funding was paid on, negative for short
+	// positions
+	PositionQty float64
+	// Amount is what we paid in satoshis, negative if we received funding
+	Amount int64
+}
+
+// FundingHedger is a Hedger holding positions in perpetual instruments,
+// which pay or receive funding at regular intervals
+type FundingHedger interface {
+	Hedger
+
+	// FundingPayments returns the funding paid or received on our position
+	// in the instrument since the given time, oldest first
+	FundingPayments(symbol string, since time.Time) ([]FundingPayment, error)
+}
+
+// RetryAfter returns how long the venue asked us to wait before sending it
+// another request, if the error is a rate limit or overload error saying
+// so. Zero if it did not
+func RetryAfter(err error) time.Duration {
+	var limited interface{ RetryAfter() time.Duration }
+	if errors.As(err, &limited) {
+		return limited.RetryAfter()
+	}
+
+	return 0
+}
@@ -0,0 +1,29 @@
+package hedge
+
+import (
+	"math"
+)
+
+// LotSize is what order quantities an instrument can be traded in
+type LotSize struct {
+	// Lot is the size every order must be a multiple of, e.g. 1 contract
+	// for XBTUSD. Zero allows any quantity
+	Lot float64
+	// MinOrder is the smallest order the venue accepts
+	MinOrder float64
+}
+
+// Round splits a signed quantity into the part that can be ordered, which
+// is the nearest whole number of lots if it is at least the minimum order,
+// and the residual that can not
+func (l LotSize) Round(qty float64) (order, residual float64) {
+	order = qty
+	if l.Lot > 0 {
+		order = math.Round(qty/l.Lot) * l.Lot
+	}
+	if math.Abs(order) < l.MinOrder {
+		order = 0
+	}
+
+	return order, qty - order
+}
@@ -0,0 +1,38 @@
+package hedge
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLotSizeRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		lot      LotSize
+		qty      float64
+		order    float64
+		residual float64
+	}{
+		{name: "any quantity", lot: LotSize{}, qty: 12.3, order: 12.3},
+		{name: "whole lots", lot: LotSize{Lot: 1, MinOrder: 1}, qty: 12, order: 12},
+		{name: "rounds down", lot: LotSize{Lot: 1, MinOrder: 1}, qty: 12.3, order: 12, residual: 0.3},
+		{name: "rounds up", lot: LotSize{Lot: 1, MinOrder: 1}, qty: 12.7, order: 13, residual: -0.3},
+		{name: "negative", lot: LotSize{Lot: 1, MinOrder: 1}, qty: -12.7, order: -13, residual: 0.3},
+		{name: "larger lots", lot: LotSize{Lot: 10, MinOrder: 10}, qty: 37, order: 40, residual: -3},
+		{name: "below half a lot", lot: LotSize{Lot: 10, MinOrder: 10}, qty: 4, order: 0, residual: 4},
+		{name: "below min order", lot: LotSize{Lot: 1, MinOrder: 25}, qty: 20, order: 0, residual: 20},
+		{name: "negative below min order", lot: LotSize{Lot: 1, MinOrder: 25}, qty: -20, order: 0, residual: -20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			order, residual := test.lot.Round(test.qty)
+			if math.Abs(order-test.order) > 1e-9 || math.Abs(residual-test.residual) > 1e-9 {
+				t.Fatalf("Round(%f) = %f, %f, want %f, %f", test.qty, order, residual, test.order, test.residual)
+			}
+			if math.Abs(order+residual-test.qty) > 1e-9 {
+				t.Fatalf("order %f and residual %f do not add up to %f", order, residual, test.qty)
+			}
+		})
+	}
+}
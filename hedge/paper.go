@@ -0,0 +1,208 @@
+package hedge
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ArcaneCryptoAS/lassets-server/oracle"
+)
+
+var _ Hedger = &PaperHedger{}
+
+// paperTakerFeeBps is the fee charged for every paper order, in basis
+// points of its value, the same as the taker fee of bitmex
+const paperTakerFeeBps = 7.5
+
+// PaperHedger simulates a venue in memory, filling every order right away
+// at the latest price of our oracle. It is used on regtest, and wherever we
+// can not trade with real money.
+//
+// Every instrument is treated as an inverse contract like XBTUSD, where
+// one contract is worth one unit of the quote currency, and positions are
+// held without leverage.
+type PaperHedger struct {
+	prices oracle.PriceOracle
+
+	mu sync.Mutex
+	// balance is the deposits and realised profit of the account, in
+	// satoshis
+	balance   int64
+	positions map[string]*Position
+	// orders are the orders placed with a client id, by that id
+	orders map[string]Order
+}
+
+// NewPaperHedger creates a paper-trading hedger filling orders at the
+// prices of the oracle, starting with the given balance in satoshis
+func NewPaperHedger(prices oracle.PriceOracle, balance int64) *PaperHedger {
+	return &PaperHedger{
+		prices:    prices,
+		balance:   balance,
+		positions: make(map[string]*Position),
+		orders:    make(map[string]Order),
+	}
+}
+
+// Name returns the name of the hedger
+func (h *PaperHedger) Name() string {
+	return "paper"
+}
+
+// OpenExposure buys qty contracts of the instrument at the oracle price
+func (h *PaperHedger) OpenExposure(symbol string, qty float64) (Order, error) {
+	return h.fill(symbol, Buy, qty)
+}
+
+// CloseExposure sells qty contracts of the instrument at the oracle price
+func (h *PaperHedger) CloseExposure(symbol string, qty float64) (Order, error) {
+	return h.fill(symbol, Sell, qty)
+}
+
+// PlaceOrder fills an order at the oracle price, remembering it by its
+// client id. Like on bitmex, a client id can only be used once
+func (h *PaperHedger) PlaceOrder(clientID, symbol string, side Side, qty float64) (Order, error) {
+	h.mu.Lock()
+	_, exists := h.orders[clientID]
+	h.mu.Unlock()
+	if exists {
+		return Order{}, fmt.Errorf("duplicate client id %s", clientID)
+	}
+
+	order, err := h.fill(symbol, side, qty)
+	if err != nil {
+		return Order{}, err
+	}
+	order.ClientID = clientID
+
+	h.mu.Lock()
+	h.orders[clientID] = order
+	h.mu.Unlock()
+
+	return order, nil
+}
+
+// LookupOrder returns the paper order placed with the client id
+func (h *PaperHedger) LookupOrder(clientID, symbol string) (Order, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	order, ok := h.orders[clientID]
+	return order, ok, nil
+}
+
+// fill fills an order at the oracle price, realising the profit of the
+// part of it that reduces our position
+func (h *PaperHedger) fill(symbol string, side Side, qty float64) (Order, error) {
+	if qty <= 0 {
+		return Order{}, fmt.Errorf("order quantity must be positive, got %f", qty)
+	}
+
+	price, err := h.prices.LatestPrice(symbol)
+	if err != nil {
+		return Order{}, fmt.Errorf("could not get price to fill order at: %w", err)
+	}
+	if price.Value <= 0 {
+		return Order{}, errors.New("can not fill order at a price of zero")
+	}
+
+	signed := qty
+	if side == Sell {
+		signed = -qty
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	position, ok := h.positions[symbol]
+	if !ok {
+		position = &Position{Symbol: symbol}
+		h.positions[symbol] = position
+	}
+
+	reducing := position.Qty != 0 && (position.Qty > 0) != (signed > 0)
+	if reducing {
+		closed := math.Min(math.Abs(position.Qty), qty)
+		if position.Qty < 0 {
+			closed = -closed
+		}
+		profit := closed * (1/position.AvgEntryPrice - 1/price.Value) * btcutil.SatoshiPerBitcoin
+		h.balance += int64(math.Round(profit))
+	}
+
+	newQty := position.Qty + signed
+	switch {
+	case newQty == 0:
+		position.AvgEntryPrice = 0
+
+	case position.Qty == 0 || (position.Qty > 0) != (newQty > 0):
+		// the position was opened, or flipped to the other side
+		position.AvgEntryPrice = price.Value
+
+	case !reducing:
+		// the entry price of inverse contracts is averaged harmonically
+		position.AvgEntryPrice = newQty / (position.Qty/position.AvgEntryPrice + signed/price.Value)
+	}
+	position.Qty = newQty
+
+	fee := int64(math.Round(qty / price.Value * btcutil.SatoshiPerBitcoin * paperTakerFeeBps / 10000))
+	h.balance -= fee
+
+	order := Order{
+		ID:     uuid.New().String(),
+		Symbol: symbol,
+		Side:   side,
+		Qty:    qty,
+		Price:  price.Value,
+		Fee:    fee,
+	}
+
+	log.WithFields(logrus.Fields{
+		"symbol":   symbol,
+		"side":     side,
+		"qty":      qty,
+		"price":    price.Value,
+		"fee":      fee,
+		"position": position.Qty,
+		"balance":  h.balance,
+	}).Info("filled paper order")
+
+	return order, nil
+}
+
+// Position returns our paper position in the instrument
+func (h *PaperHedger) Position(symbol string) (Position, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	position, ok := h.positions[symbol]
+	if !ok {
+		return Position{Symbol: symbol}, nil
+	}
+
+	return *position, nil
+}
+
+// Margin returns the margin status of our paper account. The margin used
+// by every position is its value at its entry price
+func (h *PaperHedger) Margin() (Margin, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var used float64
+	for _, position := range h.positions {
+		if position.Qty != 0 {
+			used += math.Abs(position.Qty) / position.AvgEntryPrice * btcutil.SatoshiPerBitcoin
+		}
+	}
+
+	return Margin{
+		WalletBalance:   h.balance,
+		AvailableMargin: h.balance - int64(math.Round(used)),
+	}, nil
+}
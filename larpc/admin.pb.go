@@ -0,0 +1,1853 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: admin.proto
+
+package larpc
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type AdminConfirmPriceRequest struct {
+	// the symbol to confirm the price of, defaults to XBTUSD
+	Symbol               string   `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AdminConfirmPriceRequest) Reset()         { *m = AdminConfirmPriceRequest{} }
+func (m *AdminConfirmPriceRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminConfirmPriceRequest) ProtoMessage()    {}
+func (*AdminConfirmPriceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{0}
+}
+
+func (m *AdminConfirmPriceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminConfirmPriceRequest.Unmarshal(m, b)
+}
+func (m *AdminConfirmPriceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminConfirmPriceRequest.Marshal(b, m, deterministic)
+}
+func (m *AdminConfirmPriceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminConfirmPriceRequest.Merge(m, src)
+}
+func (m *AdminConfirmPriceRequest) XXX_Size() int {
+	return xxx_messageInfo_AdminConfirmPriceRequest.Size(m)
+}
+func (m *AdminConfirmPriceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminConfirmPriceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminConfirmPriceRequest proto.InternalMessageInfo
+
+func (m *AdminConfirmPriceRequest) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+type AdminConfirmPriceResponse struct {
+	// the price that was confirmed
+	Price                float64              `protobuf:"fixed64,1,opt,name=price,proto3" json:"price,omitempty"`
+	Source               string               `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Timestamp            *timestamp.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AdminConfirmPriceResponse) Reset()         { *m = AdminConfirmPriceResponse{} }
+func (m *AdminConfirmPriceResponse) String() string { return proto.CompactTextString(m) }
+func (*AdminConfirmPriceResponse) ProtoMessage()    {}
+func (*AdminConfirmPriceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{1}
+}
+
+func (m *AdminConfirmPriceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminConfirmPriceResponse.Unmarshal(m, b)
+}
+func (m *AdminConfirmPriceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminConfirmPriceResponse.Marshal(b, m, deterministic)
+}
+func (m *AdminConfirmPriceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminConfirmPriceResponse.Merge(m, src)
+}
+func (m *AdminConfirmPriceResponse) XXX_Size() int {
+	return xxx_messageInfo_AdminConfirmPriceResponse.Size(m)
+}
+func (m *AdminConfirmPriceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminConfirmPriceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminConfirmPriceResponse proto.InternalMessageInfo
+
+func (m *AdminConfirmPriceResponse) GetPrice() float64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *AdminConfirmPriceResponse) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *AdminConfirmPriceResponse) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+type AdminGetStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AdminGetStatusRequest) Reset()         { *m = AdminGetStatusRequest{} }
+func (m *AdminGetStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminGetStatusRequest) ProtoMessage()    {}
+func (*AdminGetStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{2}
+}
+
+func (m *AdminGetStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminGetStatusRequest.Unmarshal(m, b)
+}
+func (m *AdminGetStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminGetStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *AdminGetStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminGetStatusRequest.Merge(m, src)
+}
+func (m *AdminGetStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_AdminGetStatusRequest.Size(m)
+}
+func (m *AdminGetStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminGetStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminGetStatusRequest proto.InternalMessageInfo
+
+type AdminGetStatusResponse struct {
+	Feeds []*FeedStatus `protobuf:"bytes,1,rep,name=feeds,proto3" json:"feeds,omitempty"`
+	// the latest reconciliation of every hedge instrument
+	Hedges []*HedgeDrift `protobuf:"bytes,2,rep,name=hedges,proto3" json:"hedges,omitempty"`
+	// hedge orders waiting to be placed, oldest first
+	PendingHedgeJobs []*HedgeJob `protobuf:"bytes,3,rep,name=pending_hedge_jobs,json=pendingHedgeJobs,proto3" json:"pending_hedge_jobs,omitempty"`
+	// the venues we hedge on, when orders fail over between several
+	Venues []*VenueStatus `protobuf:"bytes,4,rep,name=venues,proto3" json:"venues,omitempty"`
+	// exposure left unhedged because it is less than a lot of its hedge
+	// instrument
+	Residuals []*HedgeResidual `protobuf:"bytes,5,rep,name=residuals,proto3" json:"residuals,omitempty"`
+	// hedge orders we gave up placing, oldest first. Their contracts are
+	// not hedged until the orders are placed by hand, or reconciliation
+	// corrects the drift they cause
+	FailedHedgeJobs      []*HedgeJob `protobuf:"bytes,6,rep,name=failed_hedge_jobs,json=failedHedgeJobs,proto3" json:"failed_hedge_jobs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *AdminGetStatusResponse) Reset()         { *m = AdminGetStatusResponse{} }
+func (m *AdminGetStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*AdminGetStatusResponse) ProtoMessage()    {}
+func (*AdminGetStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{3}
+}
+
+func (m *AdminGetStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminGetStatusResponse.Unmarshal(m, b)
+}
+func (m *AdminGetStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminGetStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *AdminGetStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminGetStatusResponse.Merge(m, src)
+}
+func (m *AdminGetStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_AdminGetStatusResponse.Size(m)
+}
+func (m *AdminGetStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminGetStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminGetStatusResponse proto.InternalMessageInfo
+
+func (m *AdminGetStatusResponse) GetFeeds() []*FeedStatus {
+	if m != nil {
+		return m.Feeds
+	}
+	return nil
+}
+
+func (m *AdminGetStatusResponse) GetHedges() []*HedgeDrift {
+	if m != nil {
+		return m.Hedges
+	}
+	return nil
+}
+
+func (m *AdminGetStatusResponse) GetPendingHedgeJobs() []*HedgeJob {
+	if m != nil {
+		return m.PendingHedgeJobs
+	}
+	return nil
+}
+
+func (m *AdminGetStatusResponse) GetVenues() []*VenueStatus {
+	if m != nil {
+		return m.Venues
+	}
+	return nil
+}
+
+func (m *AdminGetStatusResponse) GetResiduals() []*HedgeResidual {
+	if m != nil {
+		return m.Residuals
+	}
+	return nil
+}
+
+func (m *AdminGetStatusResponse) GetFailedHedgeJobs() []*HedgeJob {
+	if m != nil {
+		return m.FailedHedgeJobs
+	}
+	return nil
+}
+
+type FeedStatus struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// disconnected | connecting | connected
+	State string `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	// when the feed entered its current state. Reconnect attempts do not
+	// reset this while the feed is down
+	Since *timestamp.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	// the last error that caused the feed to disconnect
+	LastError            string   `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FeedStatus) Reset()         { *m = FeedStatus{} }
+func (m *FeedStatus) String() string { return proto.CompactTextString(m) }
+func (*FeedStatus) ProtoMessage()    {}
+func (*FeedStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{4}
+}
+
+func (m *FeedStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FeedStatus.Unmarshal(m, b)
+}
+func (m *FeedStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FeedStatus.Marshal(b, m, deterministic)
+}
+func (m *FeedStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FeedStatus.Merge(m, src)
+}
+func (m *FeedStatus) XXX_Size() int {
+	return xxx_messageInfo_FeedStatus.Size(m)
+}
+func (m *FeedStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_FeedStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FeedStatus proto.InternalMessageInfo
+
+func (m *FeedStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *FeedStatus) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *FeedStatus) GetSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+func (m *FeedStatus) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+// VenueStatus is the health of a venue we hedge on
+type VenueStatus struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// false while orders fail over to other venues, after the venue failed
+	Up bool `protobuf:"varint,2,opt,name=up,proto3" json:"up,omitempty"`
+	// when orders are routed to the venue again, if it is down
+	DownUntil *timestamp.Timestamp `protobuf:"bytes,3,opt,name=down_until,json=downUntil,proto3" json:"down_until,omitempty"`
+	// the last error the venue failed with
+	LastError            string   `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VenueStatus) Reset()         { *m = VenueStatus{} }
+func (m *VenueStatus) String() string { return proto.CompactTextString(m) }
+func (*VenueStatus) ProtoMessage()    {}
+func (*VenueStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{5}
+}
+
+func (m *VenueStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VenueStatus.Unmarshal(m, b)
+}
+func (m *VenueStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VenueStatus.Marshal(b, m, deterministic)
+}
+func (m *VenueStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VenueStatus.Merge(m, src)
+}
+func (m *VenueStatus) XXX_Size() int {
+	return xxx_messageInfo_VenueStatus.Size(m)
+}
+func (m *VenueStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_VenueStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VenueStatus proto.InternalMessageInfo
+
+func (m *VenueStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *VenueStatus) GetUp() bool {
+	if m != nil {
+		return m.Up
+	}
+	return false
+}
+
+func (m *VenueStatus) GetDownUntil() *timestamp.Timestamp {
+	if m != nil {
+		return m.DownUntil
+	}
+	return nil
+}
+
+func (m *VenueStatus) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+// HedgeDrift is the result of comparing our position in a hedge instrument
+// to the exposure of the open contracts hedged with it
+type HedgeDrift struct {
+	Symbol string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// contracts of the instrument we should hold to hedge all open contracts
+	Expected float64 `protobuf:"fixed64,2,opt,name=expected,proto3" json:"expected,omitempty"`
+	// contracts of the instrument we hold on the exchange
+	Actual float64 `protobuf:"fixed64,3,opt,name=actual,proto3" json:"actual,omitempty"`
+	// actual + pending + residual - expected, positive if we are overhedged
+	Drift     float64              `protobuf:"fixed64,4,opt,name=drift,proto3" json:"drift,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// the id of the order placed to correct the drift, if any
+	CorrectionOrderId string `protobuf:"bytes,6,opt,name=correction_order_id,json=correctionOrderId,proto3" json:"correction_order_id,omitempty"`
+	// contracts of the instrument bought, or sold if negative, by hedge
+	// orders waiting to be placed
+	Pending float64 `protobuf:"fixed64,7,opt,name=pending,proto3" json:"pending,omitempty"`
+	// contracts of the instrument left unhedged because they are less than
+	// a lot, negative if we are overhedged
+	Residual             float64  `protobuf:"fixed64,8,opt,name=residual,proto3" json:"residual,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HedgeDrift) Reset()         { *m = HedgeDrift{} }
+func (m *HedgeDrift) String() string { return proto.CompactTextString(m) }
+func (*HedgeDrift) ProtoMessage()    {}
+func (*HedgeDrift) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{6}
+}
+
+func (m *HedgeDrift) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HedgeDrift.Unmarshal(m, b)
+}
+func (m *HedgeDrift) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HedgeDrift.Marshal(b, m, deterministic)
+}
+func (m *HedgeDrift) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HedgeDrift.Merge(m, src)
+}
+func (m *HedgeDrift) XXX_Size() int {
+	return xxx_messageInfo_HedgeDrift.Size(m)
+}
+func (m *HedgeDrift) XXX_DiscardUnknown() {
+	xxx_messageInfo_HedgeDrift.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HedgeDrift proto.InternalMessageInfo
+
+func (m *HedgeDrift) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *HedgeDrift) GetExpected() float64 {
+	if m != nil {
+		return m.Expected
+	}
+	return 0
+}
+
+func (m *HedgeDrift) GetActual() float64 {
+	if m != nil {
+		return m.Actual
+	}
+	return 0
+}
+
+func (m *HedgeDrift) GetDrift() float64 {
+	if m != nil {
+		return m.Drift
+	}
+	return 0
+}
+
+func (m *HedgeDrift) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *HedgeDrift) GetCorrectionOrderId() string {
+	if m != nil {
+		return m.CorrectionOrderId
+	}
+	return ""
+}
+
+func (m *HedgeDrift) GetPending() float64 {
+	if m != nil {
+		return m.Pending
+	}
+	return 0
+}
+
+func (m *HedgeDrift) GetResidual() float64 {
+	if m != nil {
+		return m.Residual
+	}
+	return 0
+}
+
+// HedgeResidual is the exposure to a hedge instrument we have not hedged,
+// because it does not add up to a whole lot. It is hedged once later
+// contracts make it add up to one
+type HedgeResidual struct {
+	Symbol string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// contracts of the instrument we should buy, or sell if negative
+	Qty                  float64  `protobuf:"fixed64,2,opt,name=qty,proto3" json:"qty,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HedgeResidual) Reset()         { *m = HedgeResidual{} }
+func (m *HedgeResidual) String() string { return proto.CompactTextString(m) }
+func (*HedgeResidual) ProtoMessage()    {}
+func (*HedgeResidual) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{7}
+}
+
+func (m *HedgeResidual) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HedgeResidual.Unmarshal(m, b)
+}
+func (m *HedgeResidual) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HedgeResidual.Marshal(b, m, deterministic)
+}
+func (m *HedgeResidual) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HedgeResidual.Merge(m, src)
+}
+func (m *HedgeResidual) XXX_Size() int {
+	return xxx_messageInfo_HedgeResidual.Size(m)
+}
+func (m *HedgeResidual) XXX_DiscardUnknown() {
+	xxx_messageInfo_HedgeResidual.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HedgeResidual proto.InternalMessageInfo
+
+func (m *HedgeResidual) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *HedgeResidual) GetQty() float64 {
+	if m != nil {
+		return m.Qty
+	}
+	return 0
+}
+
+type AdminListHedgeDriftRequest struct {
+	// the instrument to list drift for, defaults to all
+	Symbol string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// max number of records to return, defaults to 100
+	Limit                int64    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AdminListHedgeDriftRequest) Reset()         { *m = AdminListHedgeDriftRequest{} }
+func (m *AdminListHedgeDriftRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminListHedgeDriftRequest) ProtoMessage()    {}
+func (*AdminListHedgeDriftRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{8}
+}
+
+func (m *AdminListHedgeDriftRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminListHedgeDriftRequest.Unmarshal(m, b)
+}
+func (m *AdminListHedgeDriftRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminListHedgeDriftRequest.Marshal(b, m, deterministic)
+}
+func (m *AdminListHedgeDriftRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListHedgeDriftRequest.Merge(m, src)
+}
+func (m *AdminListHedgeDriftRequest) XXX_Size() int {
+	return xxx_messageInfo_AdminListHedgeDriftRequest.Size(m)
+}
+func (m *AdminListHedgeDriftRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListHedgeDriftRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminListHedgeDriftRequest proto.InternalMessageInfo
+
+func (m *AdminListHedgeDriftRequest) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *AdminListHedgeDriftRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type AdminListHedgeDriftResponse struct {
+	Drifts               []*HedgeDrift `protobuf:"bytes,1,rep,name=drifts,proto3" json:"drifts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *AdminListHedgeDriftResponse) Reset()         { *m = AdminListHedgeDriftResponse{} }
+func (m *AdminListHedgeDriftResponse) String() string { return proto.CompactTextString(m) }
+func (*AdminListHedgeDriftResponse) ProtoMessage()    {}
+func (*AdminListHedgeDriftResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{9}
+}
+
+func (m *AdminListHedgeDriftResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminListHedgeDriftResponse.Unmarshal(m, b)
+}
+func (m *AdminListHedgeDriftResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminListHedgeDriftResponse.Marshal(b, m, deterministic)
+}
+func (m *AdminListHedgeDriftResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListHedgeDriftResponse.Merge(m, src)
+}
+func (m *AdminListHedgeDriftResponse) XXX_Size() int {
+	return xxx_messageInfo_AdminListHedgeDriftResponse.Size(m)
+}
+func (m *AdminListHedgeDriftResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListHedgeDriftResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminListHedgeDriftResponse proto.InternalMessageInfo
+
+func (m *AdminListHedgeDriftResponse) GetDrifts() []*HedgeDrift {
+	if m != nil {
+		return m.Drifts
+	}
+	return nil
+}
+
+// HedgeOrder is an order we placed to hedge contracts
+type HedgeOrder struct {
+	// the id the venue assigned the order
+	OrderId string `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// the venue the order was placed with
+	Hedger string `protobuf:"bytes,2,opt,name=hedger,proto3" json:"hedger,omitempty"`
+	Symbol string `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Buy | Sell
+	Side string `protobuf:"bytes,4,opt,name=side,proto3" json:"side,omitempty"`
+	// number of contracts of the instrument
+	Qty float64 `protobuf:"fixed64,5,opt,name=qty,proto3" json:"qty,omitempty"`
+	// average price the order was filled at, 0 if unknown
+	FillPrice float64 `protobuf:"fixed64,6,opt,name=fill_price,json=fillPrice,proto3" json:"fill_price,omitempty"`
+	// fees paid for the order in satoshis, 0 if unknown
+	FeeSats int64 `protobuf:"varint,7,opt,name=fee_sats,json=feeSats,proto3" json:"fee_sats,omitempty"`
+	// the contract the order hedges. Empty for orders hedging the net
+	// exposure of several contracts
+	ContractUuid string `protobuf:"bytes,8,opt,name=contract_uuid,json=contractUuid,proto3" json:"contract_uuid,omitempty"`
+	// why the order was placed: open | close | net | drift
+	Reason    string               `protobuf:"bytes,9,opt,name=reason,proto3" json:"reason,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,10,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// contracts filled so far, 0 if we have not heard of any fills
+	FilledQty float64 `protobuf:"fixed64,11,opt,name=filled_qty,json=filledQty,proto3" json:"filled_qty,omitempty"`
+	// PartiallyFilled | Filled | Canceled | Rejected, empty if we have not
+	// heard of any fills
+	Status string `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	// why the order was cancelled or rejected
+	ReasonText string `protobuf:"bytes,13,opt,name=reason_text,json=reasonText,proto3" json:"reason_text,omitempty"`
+	// the price of the hedge instrument when the contract was opened, 0 for
+	// orders not placed for a single contract
+	OpenPrice float64 `protobuf:"fixed64,14,opt,name=open_price,json=openPrice,proto3" json:"open_price,omitempty"`
+	// how many basis points worse than the open price the order filled at,
+	// negative if it filled at a better price
+	SlippageBps          float64  `protobuf:"fixed64,15,opt,name=slippage_bps,json=slippageBps,proto3" json:"slippage_bps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HedgeOrder) Reset()         { *m = HedgeOrder{} }
+func (m *HedgeOrder) String() string { return proto.CompactTextString(m) }
+func (*HedgeOrder) ProtoMessage()    {}
+func (*HedgeOrder) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{10}
+}
+
+func (m *HedgeOrder) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HedgeOrder.Unmarshal(m, b)
+}
+func (m *HedgeOrder) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HedgeOrder.Marshal(b, m, deterministic)
+}
+func (m *HedgeOrder) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HedgeOrder.Merge(m, src)
+}
+func (m *HedgeOrder) XXX_Size() int {
+	return xxx_messageInfo_HedgeOrder.Size(m)
+}
+func (m *HedgeOrder) XXX_DiscardUnknown() {
+	xxx_messageInfo_HedgeOrder.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HedgeOrder proto.InternalMessageInfo
+
+func (m *HedgeOrder) GetOrderId() string {
+	if m != nil {
+		return m.OrderId
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetHedger() string {
+	if m != nil {
+		return m.Hedger
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetSide() string {
+	if m != nil {
+		return m.Side
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetQty() float64 {
+	if m != nil {
+		return m.Qty
+	}
+	return 0
+}
+
+func (m *HedgeOrder) GetFillPrice() float64 {
+	if m != nil {
+		return m.FillPrice
+	}
+	return 0
+}
+
+func (m *HedgeOrder) GetFeeSats() int64 {
+	if m != nil {
+		return m.FeeSats
+	}
+	return 0
+}
+
+func (m *HedgeOrder) GetContractUuid() string {
+	if m != nil {
+		return m.ContractUuid
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *HedgeOrder) GetFilledQty() float64 {
+	if m != nil {
+		return m.FilledQty
+	}
+	return 0
+}
+
+func (m *HedgeOrder) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetReasonText() string {
+	if m != nil {
+		return m.ReasonText
+	}
+	return ""
+}
+
+func (m *HedgeOrder) GetOpenPrice() float64 {
+	if m != nil {
+		return m.OpenPrice
+	}
+	return 0
+}
+
+func (m *HedgeOrder) GetSlippageBps() float64 {
+	if m != nil {
+		return m.SlippageBps
+	}
+	return 0
+}
+
+// HedgeFill is an update on the execution of a hedge order, reported by the
+// venue it was placed with
+type HedgeFill struct {
+	// the id the venue assigned the execution
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrderId       string `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ClientOrderId string `protobuf:"bytes,3,opt,name=client_order_id,json=clientOrderId,proto3" json:"client_order_id,omitempty"`
+	// the venue the order was placed with
+	Hedger string `protobuf:"bytes,4,opt,name=hedger,proto3" json:"hedger,omitempty"`
+	Symbol string `protobuf:"bytes,5,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Buy | Sell
+	Side string `protobuf:"bytes,6,opt,name=side,proto3" json:"side,omitempty"`
+	// contracts filled by this execution, and the price they filled at
+	Qty   float64 `protobuf:"fixed64,7,opt,name=qty,proto3" json:"qty,omitempty"`
+	Price float64 `protobuf:"fixed64,8,opt,name=price,proto3" json:"price,omitempty"`
+	// contracts of the order filled so far, and their average price
+	FilledQty float64 `protobuf:"fixed64,9,opt,name=filled_qty,json=filledQty,proto3" json:"filled_qty,omitempty"`
+	AvgPrice  float64 `protobuf:"fixed64,10,opt,name=avg_price,json=avgPrice,proto3" json:"avg_price,omitempty"`
+	// fees paid for this execution in satoshis
+	FeeSats int64 `protobuf:"varint,11,opt,name=fee_sats,json=feeSats,proto3" json:"fee_sats,omitempty"`
+	// PartiallyFilled | Filled | Canceled | Rejected
+	Status string `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	// why the order was cancelled or rejected
+	Reason               string               `protobuf:"bytes,13,opt,name=reason,proto3" json:"reason,omitempty"`
+	Timestamp            *timestamp.Timestamp `protobuf:"bytes,14,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *HedgeFill) Reset()         { *m = HedgeFill{} }
+func (m *HedgeFill) String() string { return proto.CompactTextString(m) }
+func (*HedgeFill) ProtoMessage()    {}
+func (*HedgeFill) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{11}
+}
+
+func (m *HedgeFill) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HedgeFill.Unmarshal(m, b)
+}
+func (m *HedgeFill) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HedgeFill.Marshal(b, m, deterministic)
+}
+func (m *HedgeFill) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HedgeFill.Merge(m, src)
+}
+func (m *HedgeFill) XXX_Size() int {
+	return xxx_messageInfo_HedgeFill.Size(m)
+}
+func (m *HedgeFill) XXX_DiscardUnknown() {
+	xxx_messageInfo_HedgeFill.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HedgeFill proto.InternalMessageInfo
+
+func (m *HedgeFill) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetOrderId() string {
+	if m != nil {
+		return m.OrderId
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetClientOrderId() string {
+	if m != nil {
+		return m.ClientOrderId
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetHedger() string {
+	if m != nil {
+		return m.Hedger
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetSide() string {
+	if m != nil {
+		return m.Side
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetQty() float64 {
+	if m != nil {
+		return m.Qty
+	}
+	return 0
+}
+
+func (m *HedgeFill) GetPrice() float64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *HedgeFill) GetFilledQty() float64 {
+	if m != nil {
+		return m.FilledQty
+	}
+	return 0
+}
+
+func (m *HedgeFill) GetAvgPrice() float64 {
+	if m != nil {
+		return m.AvgPrice
+	}
+	return 0
+}
+
+func (m *HedgeFill) GetFeeSats() int64 {
+	if m != nil {
+		return m.FeeSats
+	}
+	return 0
+}
+
+func (m *HedgeFill) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *HedgeFill) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+type AdminListHedgeOrdersRequest struct {
+	// only list orders hedging this contract, defaults to all
+	ContractUuid string `protobuf:"bytes,1,opt,name=contract_uuid,json=contractUuid,proto3" json:"contract_uuid,omitempty"`
+	// max number of orders to return, defaults to 100
+	Limit                int64    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AdminListHedgeOrdersRequest) Reset()         { *m = AdminListHedgeOrdersRequest{} }
+func (m *AdminListHedgeOrdersRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminListHedgeOrdersRequest) ProtoMessage()    {}
+func (*AdminListHedgeOrdersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{12}
+}
+
+func (m *AdminListHedgeOrdersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminListHedgeOrdersRequest.Unmarshal(m, b)
+}
+func (m *AdminListHedgeOrdersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminListHedgeOrdersRequest.Marshal(b, m, deterministic)
+}
+func (m *AdminListHedgeOrdersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListHedgeOrdersRequest.Merge(m, src)
+}
+func (m *AdminListHedgeOrdersRequest) XXX_Size() int {
+	return xxx_messageInfo_AdminListHedgeOrdersRequest.Size(m)
+}
+func (m *AdminListHedgeOrdersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListHedgeOrdersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminListHedgeOrdersRequest proto.InternalMessageInfo
+
+func (m *AdminListHedgeOrdersRequest) GetContractUuid() string {
+	if m != nil {
+		return m.ContractUuid
+	}
+	return ""
+}
+
+func (m *AdminListHedgeOrdersRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type AdminListHedgeOrdersResponse struct {
+	Orders               []*HedgeOrder `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *AdminListHedgeOrdersResponse) Reset()         { *m = AdminListHedgeOrdersResponse{} }
+func (m *AdminListHedgeOrdersResponse) String() string { return proto.CompactTextString(m) }
+func (*AdminListHedgeOrdersResponse) ProtoMessage()    {}
+func (*AdminListHedgeOrdersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{13}
+}
+
+func (m *AdminListHedgeOrdersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminListHedgeOrdersResponse.Unmarshal(m, b)
+}
+func (m *AdminListHedgeOrdersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminListHedgeOrdersResponse.Marshal(b, m, deterministic)
+}
+func (m *AdminListHedgeOrdersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListHedgeOrdersResponse.Merge(m, src)
+}
+func (m *AdminListHedgeOrdersResponse) XXX_Size() int {
+	return xxx_messageInfo_AdminListHedgeOrdersResponse.Size(m)
+}
+func (m *AdminListHedgeOrdersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListHedgeOrdersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminListHedgeOrdersResponse proto.InternalMessageInfo
+
+func (m *AdminListHedgeOrdersResponse) GetOrders() []*HedgeOrder {
+	if m != nil {
+		return m.Orders
+	}
+	return nil
+}
+
+// HedgeJob is a hedge order we have committed to placing, saved in the same
+// transaction as the contract change that caused it. Jobs are retried until
+// the order is placed
+type HedgeJob struct {
+	// our own id of the order, sent to the venue so retries never place
+	// the same order twice
+	ClientOrderId string `protobuf:"bytes,1,opt,name=client_order_id,json=clientOrderId,proto3" json:"client_order_id,omitempty"`
+	ContractUuid  string `protobuf:"bytes,2,opt,name=contract_uuid,json=contractUuid,proto3" json:"contract_uuid,omitempty"`
+	Symbol        string `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Buy | Sell
+	Side string  `protobuf:"bytes,4,opt,name=side,proto3" json:"side,omitempty"`
+	Qty  float64 `protobuf:"fixed64,5,opt,name=qty,proto3" json:"qty,omitempty"`
+	// open | close
+	Reason string `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	// how many times we have tried to place the order
+	Attempts int64 `protobuf:"varint,7,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// why the last attempt failed
+	LastError     string               `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedAt     *timestamp.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	NextAttemptAt *timestamp.Timestamp `protobuf:"bytes,10,opt,name=next_attempt_at,json=nextAttemptAt,proto3" json:"next_attempt_at,omitempty"`
+	// the asset and amount of contracts quoted before their hedge quantity
+	// was fixed. Their jobs are saved with no qty, and sized by the worker
+	Asset       string  `protobuf:"bytes,11,opt,name=asset,proto3" json:"asset,omitempty"`
+	AssetAmount float64 `protobuf:"fixed64,12,opt,name=asset_amount,json=assetAmount,proto3" json:"asset_amount,omitempty"`
+	// when we gave up placing the order, because it failed permanently or
+	// too many times
+	FailedAt             *timestamp.Timestamp `protobuf:"bytes,13,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *HedgeJob) Reset()         { *m = HedgeJob{} }
+func (m *HedgeJob) String() string { return proto.CompactTextString(m) }
+func (*HedgeJob) ProtoMessage()    {}
+func (*HedgeJob) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{14}
+}
+
+func (m *HedgeJob) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HedgeJob.Unmarshal(m, b)
+}
+func (m *HedgeJob) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HedgeJob.Marshal(b, m, deterministic)
+}
+func (m *HedgeJob) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HedgeJob.Merge(m, src)
+}
+func (m *HedgeJob) XXX_Size() int {
+	return xxx_messageInfo_HedgeJob.Size(m)
+}
+func (m *HedgeJob) XXX_DiscardUnknown() {
+	xxx_messageInfo_HedgeJob.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HedgeJob proto.InternalMessageInfo
+
+func (m *HedgeJob) GetClientOrderId() string {
+	if m != nil {
+		return m.ClientOrderId
+	}
+	return ""
+}
+
+func (m *HedgeJob) GetContractUuid() string {
+	if m != nil {
+		return m.ContractUuid
+	}
+	return ""
+}
+
+func (m *HedgeJob) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *HedgeJob) GetSide() string {
+	if m != nil {
+		return m.Side
+	}
+	return ""
+}
+
+func (m *HedgeJob) GetQty() float64 {
+	if m != nil {
+		return m.Qty
+	}
+	return 0
+}
+
+func (m *HedgeJob) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *HedgeJob) GetAttempts() int64 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *HedgeJob) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+func (m *HedgeJob) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *HedgeJob) GetNextAttemptAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.NextAttemptAt
+	}
+	return nil
+}
+
+func (m *HedgeJob) GetAsset() string {
+	if m != nil {
+		return m.Asset
+	}
+	return ""
+}
+
+func (m *HedgeJob) GetAssetAmount() float64 {
+	if m != nil {
+		return m.AssetAmount
+	}
+	return 0
+}
+
+func (m *HedgeJob) GetFailedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.FailedAt
+	}
+	return nil
+}
+
+// FundingPayment is funding paid or received on one of our hedge positions,
+// allocated to the contracts open when it was paid
+type FundingPayment struct {
+	// the id the venue assigned the payment
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// the venue the position is held with
+	Venue     string               `protobuf:"bytes,2,opt,name=venue,proto3" json:"venue,omitempty"`
+	Symbol    string               `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// the funding rate, paid by longs to shorts when positive
+	Rate float64 `protobuf:"fixed64,5,opt,name=rate,proto3" json:"rate,omitempty"`
+	// contracts of the instrument funding was paid on, negative for short
+	// positions
+	PositionQty float64 `protobuf:"fixed64,6,opt,name=position_qty,json=positionQty,proto3" json:"position_qty,omitempty"`
+	// what we paid in satoshis, negative if we received funding
+	AmountSats           int64                `protobuf:"varint,7,opt,name=amount_sats,json=amountSats,proto3" json:"amount_sats,omitempty"`
+	Allocations          []*FundingAllocation `protobuf:"bytes,8,rep,name=allocations,proto3" json:"allocations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *FundingPayment) Reset()         { *m = FundingPayment{} }
+func (m *FundingPayment) String() string { return proto.CompactTextString(m) }
+func (*FundingPayment) ProtoMessage()    {}
+func (*FundingPayment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{15}
+}
+
+func (m *FundingPayment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FundingPayment.Unmarshal(m, b)
+}
+func (m *FundingPayment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FundingPayment.Marshal(b, m, deterministic)
+}
+func (m *FundingPayment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FundingPayment.Merge(m, src)
+}
+func (m *FundingPayment) XXX_Size() int {
+	return xxx_messageInfo_FundingPayment.Size(m)
+}
+func (m *FundingPayment) XXX_DiscardUnknown() {
+	xxx_messageInfo_FundingPayment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FundingPayment proto.InternalMessageInfo
+
+func (m *FundingPayment) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *FundingPayment) GetVenue() string {
+	if m != nil {
+		return m.Venue
+	}
+	return ""
+}
+
+func (m *FundingPayment) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *FundingPayment) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *FundingPayment) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *FundingPayment) GetPositionQty() float64 {
+	if m != nil {
+		return m.PositionQty
+	}
+	return 0
+}
+
+func (m *FundingPayment) GetAmountSats() int64 {
+	if m != nil {
+		return m.AmountSats
+	}
+	return 0
+}
+
+func (m *FundingPayment) GetAllocations() []*FundingAllocation {
+	if m != nil {
+		return m.Allocations
+	}
+	return nil
+}
+
+// FundingAllocation is the share of a funding payment allocated to a
+// contract, pro rata to the exposure it is hedged with
+type FundingAllocation struct {
+	ContractUuid string `protobuf:"bytes,1,opt,name=contract_uuid,json=contractUuid,proto3" json:"contract_uuid,omitempty"`
+	// negative if the contract was allocated funding we received
+	AmountSats           int64    `protobuf:"varint,2,opt,name=amount_sats,json=amountSats,proto3" json:"amount_sats,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FundingAllocation) Reset()         { *m = FundingAllocation{} }
+func (m *FundingAllocation) String() string { return proto.CompactTextString(m) }
+func (*FundingAllocation) ProtoMessage()    {}
+func (*FundingAllocation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{16}
+}
+
+func (m *FundingAllocation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FundingAllocation.Unmarshal(m, b)
+}
+func (m *FundingAllocation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FundingAllocation.Marshal(b, m, deterministic)
+}
+func (m *FundingAllocation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FundingAllocation.Merge(m, src)
+}
+func (m *FundingAllocation) XXX_Size() int {
+	return xxx_messageInfo_FundingAllocation.Size(m)
+}
+func (m *FundingAllocation) XXX_DiscardUnknown() {
+	xxx_messageInfo_FundingAllocation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FundingAllocation proto.InternalMessageInfo
+
+func (m *FundingAllocation) GetContractUuid() string {
+	if m != nil {
+		return m.ContractUuid
+	}
+	return ""
+}
+
+func (m *FundingAllocation) GetAmountSats() int64 {
+	if m != nil {
+		return m.AmountSats
+	}
+	return 0
+}
+
+// ContractFunding is the funding allocated to a single contract
+type ContractFunding struct {
+	ContractUuid string `protobuf:"bytes,1,opt,name=contract_uuid,json=contractUuid,proto3" json:"contract_uuid,omitempty"`
+	// the sum of all funding allocated to the contract
+	FundingSats int64 `protobuf:"varint,2,opt,name=funding_sats,json=fundingSats,proto3" json:"funding_sats,omitempty"`
+	// how much of it has been charged to the client when rebalancing
+	ChargedSats          int64    `protobuf:"varint,3,opt,name=charged_sats,json=chargedSats,proto3" json:"charged_sats,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContractFunding) Reset()         { *m = ContractFunding{} }
+func (m *ContractFunding) String() string { return proto.CompactTextString(m) }
+func (*ContractFunding) ProtoMessage()    {}
+func (*ContractFunding) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{17}
+}
+
+func (m *ContractFunding) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContractFunding.Unmarshal(m, b)
+}
+func (m *ContractFunding) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContractFunding.Marshal(b, m, deterministic)
+}
+func (m *ContractFunding) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContractFunding.Merge(m, src)
+}
+func (m *ContractFunding) XXX_Size() int {
+	return xxx_messageInfo_ContractFunding.Size(m)
+}
+func (m *ContractFunding) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContractFunding.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContractFunding proto.InternalMessageInfo
+
+func (m *ContractFunding) GetContractUuid() string {
+	if m != nil {
+		return m.ContractUuid
+	}
+	return ""
+}
+
+func (m *ContractFunding) GetFundingSats() int64 {
+	if m != nil {
+		return m.FundingSats
+	}
+	return 0
+}
+
+func (m *ContractFunding) GetChargedSats() int64 {
+	if m != nil {
+		return m.ChargedSats
+	}
+	return 0
+}
+
+type AdminListFundingPaymentsRequest struct {
+	// only list payments on this instrument, defaults to all
+	Symbol string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// only list payments allocated to this contract, defaults to all
+	ContractUuid string `protobuf:"bytes,2,opt,name=contract_uuid,json=contractUuid,proto3" json:"contract_uuid,omitempty"`
+	// max number of payments to return, defaults to 100
+	Limit                int64    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AdminListFundingPaymentsRequest) Reset()         { *m = AdminListFundingPaymentsRequest{} }
+func (m *AdminListFundingPaymentsRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminListFundingPaymentsRequest) ProtoMessage()    {}
+func (*AdminListFundingPaymentsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{18}
+}
+
+func (m *AdminListFundingPaymentsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminListFundingPaymentsRequest.Unmarshal(m, b)
+}
+func (m *AdminListFundingPaymentsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminListFundingPaymentsRequest.Marshal(b, m, deterministic)
+}
+func (m *AdminListFundingPaymentsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListFundingPaymentsRequest.Merge(m, src)
+}
+func (m *AdminListFundingPaymentsRequest) XXX_Size() int {
+	return xxx_messageInfo_AdminListFundingPaymentsRequest.Size(m)
+}
+func (m *AdminListFundingPaymentsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListFundingPaymentsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminListFundingPaymentsRequest proto.InternalMessageInfo
+
+func (m *AdminListFundingPaymentsRequest) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *AdminListFundingPaymentsRequest) GetContractUuid() string {
+	if m != nil {
+		return m.ContractUuid
+	}
+	return ""
+}
+
+func (m *AdminListFundingPaymentsRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type AdminListFundingPaymentsResponse struct {
+	Payments []*FundingPayment `protobuf:"bytes,1,rep,name=payments,proto3" json:"payments,omitempty"`
+	// the funding allocated to the contract, if one was given
+	Contract             *ContractFunding `protobuf:"bytes,2,opt,name=contract,proto3" json:"contract,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *AdminListFundingPaymentsResponse) Reset()         { *m = AdminListFundingPaymentsResponse{} }
+func (m *AdminListFundingPaymentsResponse) String() string { return proto.CompactTextString(m) }
+func (*AdminListFundingPaymentsResponse) ProtoMessage()    {}
+func (*AdminListFundingPaymentsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{19}
+}
+
+func (m *AdminListFundingPaymentsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AdminListFundingPaymentsResponse.Unmarshal(m, b)
+}
+func (m *AdminListFundingPaymentsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AdminListFundingPaymentsResponse.Marshal(b, m, deterministic)
+}
+func (m *AdminListFundingPaymentsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListFundingPaymentsResponse.Merge(m, src)
+}
+func (m *AdminListFundingPaymentsResponse) XXX_Size() int {
+	return xxx_messageInfo_AdminListFundingPaymentsResponse.Size(m)
+}
+func (m *AdminListFundingPaymentsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListFundingPaymentsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminListFundingPaymentsResponse proto.InternalMessageInfo
+
+func (m *AdminListFundingPaymentsResponse) GetPayments() []*FundingPayment {
+	if m != nil {
+		return m.Payments
+	}
+	return nil
+}
+
+func (m *AdminListFundingPaymentsResponse) GetContract() *ContractFunding {
+	if m != nil {
+		return m.Contract
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*AdminConfirmPriceRequest)(nil), "ladrpc.AdminConfirmPriceRequest")
+	proto.RegisterType((*AdminConfirmPriceResponse)(nil), "ladrpc.AdminConfirmPriceResponse")
+	proto.RegisterType((*AdminGetStatusRequest)(nil), "ladrpc.AdminGetStatusRequest")
+	proto.RegisterType((*AdminGetStatusResponse)(nil), "ladrpc.AdminGetStatusResponse")
+	proto.RegisterType((*FeedStatus)(nil), "ladrpc.FeedStatus")
+	proto.RegisterType((*VenueStatus)(nil), "ladrpc.VenueStatus")
+	proto.RegisterType((*HedgeDrift)(nil), "ladrpc.HedgeDrift")
+	proto.RegisterType((*HedgeResidual)(nil), "ladrpc.HedgeResidual")
+	proto.RegisterType((*AdminListHedgeDriftRequest)(nil), "ladrpc.AdminListHedgeDriftRequest")
+	proto.RegisterType((*AdminListHedgeDriftResponse)(nil), "ladrpc.AdminListHedgeDriftResponse")
+	proto.RegisterType((*HedgeOrder)(nil), "ladrpc.HedgeOrder")
+	proto.RegisterType((*HedgeFill)(nil), "ladrpc.HedgeFill")
+	proto.RegisterType((*AdminListHedgeOrdersRequest)(nil), "ladrpc.AdminListHedgeOrdersRequest")
+	proto.RegisterType((*AdminListHedgeOrdersResponse)(nil), "ladrpc.AdminListHedgeOrdersResponse")
+	proto.RegisterType((*HedgeJob)(nil), "ladrpc.HedgeJob")
+	proto.RegisterType((*FundingPayment)(nil), "ladrpc.FundingPayment")
+	proto.RegisterType((*FundingAllocation)(nil), "ladrpc.FundingAllocation")
+	proto.RegisterType((*ContractFunding)(nil), "ladrpc.ContractFunding")
+	proto.RegisterType((*AdminListFundingPaymentsRequest)(nil), "ladrpc.AdminListFundingPaymentsRequest")
+	proto.RegisterType((*AdminListFundingPaymentsResponse)(nil), "ladrpc.AdminListFundingPaymentsResponse")
+}
+
+func init() { proto.RegisterFile("admin.proto", fileDescriptor_73a7fc70dcc2027c) }
+
+var fileDescriptor_73a7fc70dcc2027c = []byte{
+	// 1382 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0x5d, 0x6f, 0xdc, 0x44,
+	0x17, 0x96, 0xd7, 0xbb, 0x9b, 0xf5, 0x71, 0x92, 0x6d, 0xa7, 0x6d, 0xea, 0x6e, 0xdf, 0xbe, 0x4d,
+	0xdc, 0x0a, 0xa2, 0x22, 0x52, 0x94, 0x5e, 0x40, 0x05, 0x42, 0xda, 0x16, 0x0a, 0x8d, 0x90, 0x5a,
+	0x9c, 0x16, 0x51, 0x84, 0x64, 0xcd, 0xda, 0xb3, 0x5b, 0x23, 0xaf, 0xed, 0x7a, 0xc6, 0x21, 0x91,
+	0xb8, 0xe3, 0x82, 0x8b, 0x48, 0xfc, 0x03, 0xfe, 0x0f, 0x57, 0x48, 0x5c, 0xf2, 0x6f, 0xd0, 0x9c,
+	0x19, 0x7b, 0xd7, 0xbb, 0xde, 0x7c, 0x88, 0x3b, 0x9f, 0x33, 0xcf, 0x39, 0xf3, 0xcc, 0xf9, 0x9a,
+	0x31, 0xd8, 0x34, 0x9c, 0x46, 0xc9, 0x5e, 0x96, 0xa7, 0x22, 0x25, 0xdd, 0x98, 0x86, 0x79, 0x16,
+	0x0c, 0xfa, 0x22, 0x9a, 0x32, 0x2e, 0xe8, 0x34, 0x53, 0x0b, 0xee, 0x3e, 0x38, 0x43, 0x89, 0x7b,
+	0x9a, 0x26, 0xe3, 0x28, 0x9f, 0xbe, 0xcc, 0xa3, 0x80, 0x79, 0xec, 0x5d, 0xc1, 0xb8, 0x20, 0x5b,
+	0xd0, 0xe5, 0x27, 0xd3, 0x51, 0x1a, 0x3b, 0xc6, 0xb6, 0xb1, 0x6b, 0x79, 0x5a, 0x72, 0x7f, 0x35,
+	0xe0, 0x56, 0x83, 0x11, 0xcf, 0xd2, 0x84, 0x33, 0x72, 0x1d, 0x3a, 0x99, 0x54, 0xa0, 0x91, 0xe1,
+	0x29, 0x01, 0x7d, 0xa5, 0x45, 0x1e, 0x30, 0xa7, 0xa5, 0x7d, 0xa1, 0x44, 0x3e, 0x01, 0xab, 0xa2,
+	0xe4, 0x98, 0xdb, 0xc6, 0xae, 0xbd, 0x3f, 0xd8, 0x9b, 0xa4, 0xe9, 0x24, 0x66, 0x8a, 0xe1, 0xa8,
+	0x18, 0xef, 0xbd, 0x2a, 0x11, 0xde, 0x0c, 0xec, 0xde, 0x84, 0x1b, 0x48, 0xe2, 0x2b, 0x26, 0x0e,
+	0x05, 0x15, 0x05, 0xd7, 0xb4, 0xdd, 0xbf, 0x5a, 0xb0, 0xb5, 0xb8, 0xa2, 0xb9, 0xed, 0x42, 0x67,
+	0xcc, 0x58, 0xc8, 0x1d, 0x63, 0xdb, 0xdc, 0xb5, 0xf7, 0xc9, 0x9e, 0x0a, 0xcb, 0xde, 0x33, 0xc6,
+	0x42, 0x0d, 0x55, 0x00, 0xf2, 0x00, 0xba, 0x6f, 0x59, 0x38, 0x61, 0xdc, 0x69, 0xd5, 0xa1, 0x5f,
+	0x4b, 0xed, 0x17, 0x79, 0x34, 0x16, 0x9e, 0x46, 0x90, 0xcf, 0x81, 0x64, 0x2c, 0x09, 0xa3, 0x64,
+	0xe2, 0xa3, 0xc6, 0xff, 0x29, 0x1d, 0x71, 0xc7, 0x44, 0xbb, 0x2b, 0x35, 0xbb, 0x83, 0x74, 0xe4,
+	0x5d, 0xd1, 0xd8, 0x52, 0xc1, 0xc9, 0x07, 0xd0, 0x3d, 0x62, 0x49, 0xc1, 0xb8, 0xd3, 0x46, 0x9b,
+	0x6b, 0xa5, 0xcd, 0x77, 0x52, 0xab, 0x79, 0x69, 0x08, 0x79, 0x04, 0x56, 0xce, 0x78, 0x14, 0x16,
+	0x34, 0xe6, 0x4e, 0x07, 0xf1, 0x37, 0x6a, 0x7b, 0x78, 0x7a, 0xd5, 0x9b, 0xe1, 0xc8, 0x67, 0x70,
+	0x75, 0x4c, 0xa3, 0x98, 0x85, 0xf3, 0x04, 0xbb, 0x2b, 0x08, 0xf6, 0x15, 0xb4, 0xe2, 0xe7, 0xfe,
+	0x66, 0x00, 0xcc, 0x22, 0x44, 0x08, 0xb4, 0x13, 0x3a, 0x65, 0xba, 0x28, 0xf0, 0x5b, 0x26, 0x9d,
+	0x0b, 0x2a, 0xca, 0xec, 0x2a, 0x81, 0x7c, 0x04, 0x1d, 0x1e, 0x25, 0x01, 0xbb, 0x40, 0x62, 0x15,
+	0x90, 0xdc, 0x01, 0x88, 0x29, 0x17, 0x3e, 0xcb, 0xf3, 0x34, 0x77, 0xda, 0xe8, 0xcc, 0x92, 0x9a,
+	0x2f, 0xa5, 0xc2, 0x3d, 0x35, 0xc0, 0x9e, 0x0b, 0x4a, 0x23, 0x95, 0x4d, 0x68, 0x15, 0x19, 0xf2,
+	0xe8, 0x79, 0xad, 0x22, 0x23, 0x8f, 0x01, 0xc2, 0xf4, 0xe7, 0xc4, 0x2f, 0x12, 0x11, 0xc5, 0x17,
+	0x29, 0x31, 0x89, 0x7e, 0x2d, 0xc1, 0xe7, 0xb1, 0xf9, 0xbd, 0x05, 0x30, 0x2b, 0x87, 0x55, 0xed,
+	0x42, 0x06, 0xd0, 0x63, 0xc7, 0x19, 0x0b, 0x04, 0x0b, 0x91, 0x96, 0xe1, 0x55, 0xb2, 0xb4, 0xa1,
+	0x81, 0x28, 0xa8, 0x22, 0x66, 0x78, 0x5a, 0x92, 0xf1, 0x0c, 0xa5, 0x53, 0xdc, 0xd4, 0xf0, 0x94,
+	0x50, 0x6f, 0x96, 0xce, 0x25, 0x9a, 0x85, 0xec, 0xc1, 0xb5, 0x20, 0xcd, 0x73, 0x16, 0x88, 0x28,
+	0x4d, 0xfc, 0x34, 0x0f, 0x59, 0xee, 0x47, 0xa1, 0xd3, 0x45, 0xa2, 0x57, 0x67, 0x4b, 0x2f, 0xe4,
+	0xca, 0xf3, 0x90, 0x38, 0xb0, 0xa6, 0xcb, 0xd4, 0x59, 0x43, 0x06, 0xa5, 0x28, 0x4f, 0x53, 0xd6,
+	0x95, 0xd3, 0x53, 0xa7, 0x29, 0x65, 0xf7, 0x31, 0x6c, 0xd4, 0x4a, 0x70, 0x65, 0x48, 0xae, 0x80,
+	0xf9, 0x4e, 0x9c, 0xe8, 0x68, 0xc8, 0x4f, 0xf7, 0x00, 0x06, 0xd8, 0xb3, 0xdf, 0x44, 0x5c, 0xcc,
+	0xb5, 0xd8, 0xd9, 0x93, 0x48, 0x86, 0x29, 0x8e, 0xa6, 0x91, 0x40, 0x4f, 0xa6, 0xa7, 0x04, 0xf7,
+	0x39, 0xdc, 0x6e, 0xf4, 0xa5, 0x87, 0xc0, 0x03, 0xe8, 0x62, 0x38, 0x97, 0xa6, 0xc0, 0x7c, 0x6b,
+	0x2b, 0x84, 0xfb, 0xa7, 0xa9, 0x53, 0x8c, 0x81, 0x21, 0xb7, 0xa0, 0x57, 0xc5, 0x4e, 0x31, 0x59,
+	0x4b, 0x75, 0xc4, 0xb6, 0xf4, 0xc0, 0xc8, 0xcb, 0x01, 0xa7, 0xa4, 0x39, 0xea, 0x66, 0x8d, 0x3a,
+	0x81, 0x36, 0x8f, 0x42, 0xa6, 0xab, 0x0a, 0xbf, 0xcb, 0xb0, 0x74, 0xaa, 0xb0, 0xc8, 0x0a, 0x1c,
+	0x47, 0x71, 0xec, 0xab, 0x89, 0xda, 0xc5, 0x05, 0x4b, 0x6a, 0x70, 0xe6, 0x4a, 0x3e, 0x63, 0xc6,
+	0x7c, 0x4e, 0x05, 0xc7, 0x3c, 0x99, 0xde, 0xda, 0x98, 0xb1, 0x43, 0x2a, 0x38, 0xb9, 0x07, 0x1b,
+	0x41, 0x9a, 0x88, 0x9c, 0x06, 0xc2, 0x2f, 0x8a, 0x28, 0xc4, 0x64, 0x59, 0xde, 0x7a, 0xa9, 0x7c,
+	0x5d, 0x44, 0x48, 0x3a, 0x67, 0x94, 0xa7, 0x89, 0x63, 0x29, 0x72, 0x4a, 0xaa, 0x17, 0x1a, 0x5c,
+	0xa6, 0xd0, 0x34, 0x61, 0x16, 0xfa, 0xf2, 0x24, 0xf6, 0x8c, 0x30, 0x0b, 0xbf, 0x15, 0x27, 0x18,
+	0x0d, 0x6c, 0x5d, 0x67, 0x5d, 0x47, 0x03, 0x25, 0x72, 0x17, 0x6c, 0xb5, 0xb5, 0x2f, 0xd8, 0xb1,
+	0x70, 0x36, 0x70, 0x11, 0x94, 0xea, 0x15, 0x3b, 0x16, 0xd2, 0x6f, 0x9a, 0xb1, 0x44, 0x07, 0x62,
+	0x53, 0xf9, 0x95, 0x1a, 0x15, 0x88, 0x1d, 0x58, 0xe7, 0x71, 0x94, 0x65, 0x74, 0xc2, 0xfc, 0x51,
+	0xc6, 0x9d, 0x3e, 0x02, 0xec, 0x52, 0xf7, 0x24, 0xe3, 0xee, 0xa9, 0x09, 0x16, 0xa6, 0xf2, 0x59,
+	0x14, 0xc7, 0x72, 0x4a, 0x54, 0x39, 0x6c, 0x45, 0x61, 0x2d, 0xb3, 0xad, 0x7a, 0x66, 0xdf, 0x83,
+	0x7e, 0x10, 0x47, 0x2c, 0x11, 0xb3, 0xbe, 0x51, 0xa9, 0xdc, 0x50, 0xea, 0x17, 0x4b, 0x15, 0xd0,
+	0x5e, 0x51, 0x01, 0x9d, 0xc6, 0x0a, 0xe8, 0x2e, 0x57, 0xc0, 0xda, 0xac, 0x02, 0xaa, 0xeb, 0xb4,
+	0x37, 0x7f, 0x9d, 0xd6, 0xc3, 0x6c, 0x2d, 0x86, 0xf9, 0x36, 0x58, 0xf4, 0x68, 0xa2, 0x83, 0x05,
+	0xaa, 0x4b, 0xe9, 0xd1, 0x64, 0xb9, 0x68, 0xec, 0x7a, 0xd1, 0xac, 0x4a, 0xcf, 0xac, 0x4e, 0x36,
+	0x56, 0xd7, 0xc9, 0xe6, 0x65, 0x6e, 0xef, 0xef, 0x17, 0x7b, 0x14, 0xa3, 0x58, 0xde, 0xe1, 0xcb,
+	0xd5, 0x6b, 0x34, 0x54, 0x6f, 0x73, 0xf7, 0x1f, 0xc0, 0xff, 0x9a, 0x3d, 0xcf, 0xda, 0x1f, 0xf3,
+	0xd8, 0xdc, 0xfe, 0x08, 0xf6, 0x34, 0xc2, 0xfd, 0xc7, 0x84, 0x5e, 0x79, 0x0f, 0x36, 0xd5, 0x81,
+	0xd1, 0x54, 0x07, 0x4b, 0xdc, 0x5b, 0xcd, 0x9d, 0xf7, 0x1f, 0xc6, 0xc2, 0x2c, 0x1f, 0xdd, 0x5a,
+	0x3e, 0x06, 0xd0, 0xa3, 0x42, 0xb0, 0x69, 0x56, 0xcd, 0x83, 0x4a, 0x5e, 0xb8, 0xcc, 0x7a, 0x0b,
+	0x97, 0x99, 0xbc, 0x26, 0x83, 0x9c, 0x51, 0xc1, 0x42, 0x9f, 0x0a, 0xc7, 0x3a, 0x3f, 0x97, 0x1a,
+	0x3d, 0x14, 0xe4, 0x09, 0xf4, 0x13, 0x76, 0x2c, 0x7c, 0xbd, 0x95, 0xb4, 0x3f, 0x7f, 0x66, 0x6c,
+	0x48, 0x93, 0xa1, 0xb2, 0x18, 0x0a, 0x99, 0x4b, 0xca, 0x39, 0x13, 0x58, 0x91, 0x96, 0xa7, 0x04,
+	0xd9, 0xd6, 0xf8, 0xe1, 0xd3, 0x69, 0x5a, 0x24, 0x02, 0xab, 0xd2, 0xf0, 0x6c, 0xd4, 0x0d, 0x51,
+	0x45, 0x3e, 0x06, 0x4b, 0x3f, 0x6d, 0xa8, 0x9a, 0x1b, 0x67, 0x6f, 0xdb, 0x53, 0xe0, 0xa1, 0x70,
+	0xff, 0x68, 0xc1, 0xe6, 0xb3, 0x02, 0x2f, 0xb5, 0x97, 0xf4, 0x64, 0xca, 0x12, 0xb1, 0x34, 0x14,
+	0xae, 0x43, 0x07, 0x5f, 0x5d, 0xe5, 0xab, 0x06, 0x85, 0x95, 0xa9, 0xab, 0x35, 0x43, 0xfb, 0x32,
+	0x43, 0x93, 0x40, 0x3b, 0x97, 0x8f, 0x27, 0x95, 0x61, 0xfc, 0x96, 0x47, 0xcf, 0x52, 0x1e, 0xe1,
+	0x7d, 0x2d, 0xb3, 0xaf, 0x66, 0xbf, 0x5d, 0xea, 0x64, 0x97, 0xdf, 0x05, 0x5b, 0xc5, 0x65, 0xfe,
+	0x02, 0x00, 0xa5, 0xc2, 0x76, 0xfe, 0x14, 0x6c, 0x1a, 0xc7, 0x69, 0x40, 0xa5, 0x05, 0x77, 0x7a,
+	0x58, 0xef, 0xb7, 0xaa, 0x47, 0xaf, 0x3a, 0xfc, 0xb0, 0x42, 0x78, 0xf3, 0x68, 0xf7, 0x0d, 0x5c,
+	0x5d, 0x42, 0x5c, 0xac, 0x2f, 0x17, 0x78, 0xb5, 0x16, 0x79, 0xb9, 0xbf, 0x40, 0xff, 0xa9, 0x36,
+	0xd0, 0x5b, 0x5c, 0xcc, 0xf1, 0x0e, 0xac, 0x8f, 0x15, 0x7e, 0xde, 0xb3, 0xad, 0x75, 0x78, 0xe4,
+	0x1d, 0x58, 0x0f, 0xde, 0xd2, 0x7c, 0xc2, 0x42, 0x05, 0x31, 0x15, 0x44, 0xeb, 0x70, 0x77, 0x01,
+	0x77, 0xab, 0x01, 0x51, 0x2f, 0x00, 0x7e, 0xde, 0x7b, 0xe3, 0x42, 0xad, 0x5d, 0x8d, 0x25, 0x73,
+	0x7e, 0x2c, 0x9d, 0x1a, 0xb0, 0xbd, 0x7a, 0x5b, 0x3d, 0x9b, 0xf6, 0xa1, 0x97, 0x69, 0x9d, 0x9e,
+	0x4e, 0x5b, 0x0b, 0xd9, 0xd2, 0x26, 0x5e, 0x85, 0x23, 0x8f, 0xa0, 0x57, 0x6e, 0x8f, 0x74, 0xec,
+	0xfd, 0x9b, 0xa5, 0xcd, 0x42, 0x90, 0xbd, 0x0a, 0xb8, 0xff, 0xb7, 0x09, 0x36, 0xb2, 0x39, 0x64,
+	0xf9, 0x11, 0xcb, 0xc9, 0x21, 0xac, 0xcf, 0xff, 0xcc, 0x91, 0xed, 0xd2, 0xc5, 0xaa, 0x9f, 0xc3,
+	0xc1, 0xce, 0x19, 0x08, 0x7d, 0x9a, 0x03, 0xb0, 0xaa, 0x5f, 0x30, 0x72, 0xa7, 0x86, 0x5f, 0xfc,
+	0x69, 0x1b, 0xfc, 0x7f, 0xd5, 0xb2, 0xf6, 0xf5, 0x06, 0x36, 0xeb, 0xcf, 0x39, 0xe2, 0xd6, 0x2c,
+	0x1a, 0xdf, 0x8d, 0x83, 0x7b, 0x67, 0x62, 0xb4, 0xeb, 0x1f, 0xa1, 0xbf, 0x70, 0x57, 0x90, 0x15,
+	0x76, 0xb5, 0x3b, 0x6a, 0x70, 0xff, 0x6c, 0x90, 0xf6, 0xfe, 0x16, 0xae, 0x35, 0x64, 0x9c, 0xbc,
+	0xbf, 0x64, 0xdc, 0x5c, 0x8a, 0x83, 0xdd, 0xf3, 0x81, 0x6a, 0xa7, 0x27, 0xf7, 0x7f, 0x70, 0x69,
+	0x1e, 0xd0, 0x84, 0x05, 0xf9, 0x49, 0x26, 0xd2, 0x87, 0x71, 0x82, 0x73, 0x92, 0x7f, 0xa8, 0xae,
+	0xa7, 0x87, 0x31, 0xcd, 0xb3, 0x60, 0xd4, 0xc5, 0x51, 0xf4, 0xe8, 0xdf, 0x01, 0x00, 0xf9, 0x83,
+	0xfb, 0x91, 0x1f, 0x10, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AdminServerClient is the client API for AdminServer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminServerClient interface {
+	// ConfirmPrice confirms the price move that tripped the circuit breaker
+	// for a symbol, and resumes rebalancing contracts
+	ConfirmPrice(ctx context.Context, in *AdminConfirmPriceRequest, opts ...grpc.CallOption) (*AdminConfirmPriceResponse, error)
+	// GetStatus returns the connection state of the realtime feeds the
+	// server depends on
+	GetStatus(ctx context.Context, in *AdminGetStatusRequest, opts ...grpc.CallOption) (*AdminGetStatusResponse, error)
+	// ListHedgeDrift returns the recorded differences between our exchange
+	// positions and the exposure of our open contracts, newest first
+	ListHedgeDrift(ctx context.Context, in *AdminListHedgeDriftRequest, opts ...grpc.CallOption) (*AdminListHedgeDriftResponse, error)
+	// ListHedgeOrders returns the orders we placed to hedge contracts,
+	// newest first
+	ListHedgeOrders(ctx context.Context, in *AdminListHedgeOrdersRequest, opts ...grpc.CallOption) (*AdminListHedgeOrdersResponse, error)
+	// ListFundingPayments returns the funding we paid or received on our
+	// hedge positions, newest first, and what each contract was allocated
+	ListFundingPayments(ctx context.Context, in *AdminListFundingPaymentsRequest, opts ...grpc.CallOption) (*AdminListFundingPaymentsResponse, error)
+}
+
+type adminServerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminServerClient(cc *grpc.ClientConn) AdminServerClient {
+	return &adminServerClient{cc}
+}
+
+func (c *adminServerClient) ConfirmPrice(ctx context.Context, in *AdminConfirmPriceRequest, opts ...grpc.CallOption) (*AdminConfirmPriceResponse, error) {
+	out := new(AdminConfirmPriceResponse)
+	err := c.cc.Invoke(ctx, "/ladrpc.AdminServer/ConfirmPrice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServerClient) GetStatus(ctx context.Context, in *AdminGetStatusRequest, opts ...grpc.CallOption) (*AdminGetStatusResponse, error) {
+	out := new(AdminGetStatusResponse)
+	err := c.cc.Invoke(ctx, "/ladrpc.AdminServer/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServerClient) ListHedgeDrift(ctx context.Context, in *AdminListHedgeDriftRequest, opts ...grpc.CallOption) (*AdminListHedgeDriftResponse, error) {
+	out := new(AdminListHedgeDriftResponse)
+	err := c.cc.Invoke(ctx, "/ladrpc.AdminServer/ListHedgeDrift", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServerClient) ListHedgeOrders(ctx context.Context, in *AdminListHedgeOrdersRequest, opts ...grpc.CallOption) (*AdminListHedgeOrdersResponse, error) {
+	out := new(AdminListHedgeOrdersResponse)
+	err := c.cc.Invoke(ctx, "/ladrpc.AdminServer/ListHedgeOrders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServerClient) ListFundingPayments(ctx context.Context, in *AdminListFundingPaymentsRequest, opts ...grpc.CallOption) (*AdminListFundingPaymentsResponse, error) {
+	out := new(AdminListFundingPaymentsResponse)
+	err := c.cc.Invoke(ctx, "/ladrpc.AdminServer/ListFundingPayments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServerServer is the server API for AdminServer service.
+type AdminServerServer interface {
+	// ConfirmPrice confirms the price move that tripped the circuit breaker
+	// for a symbol, and resumes rebalancing contracts
+	ConfirmPrice(context.Context, *AdminConfirmPriceRequest) (*AdminConfirmPriceResponse, error)
+	// GetStatus returns the connection state of the realtime feeds the
+	// server depends on
+	GetStatus(context.Context, *AdminGetStatusRequest) (*AdminGetStatusResponse, error)
+	// ListHedgeDrift returns the recorded differences between our exchange
+	// positions and the exposure of our open contracts, newest first
+	ListHedgeDrift(context.Context, *AdminListHedgeDriftRequest) (*AdminListHedgeDriftResponse, error)
+	// ListHedgeOrders returns the orders we placed to hedge contracts,
+	// newest first
+	ListHedgeOrders(context.Context, *AdminListHedgeOrdersRequest) (*AdminListHedgeOrdersResponse, error)
+	// ListFundingPayments returns the funding we paid or received on our
+	// hedge positions, newest first, and what each contract was allocated
+	ListFundingPayments(context.Context, *AdminListFundingPaymentsRequest) (*AdminListFundingPaymentsResponse, error)
+}
+
+// UnimplementedAdminServerServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServerServer struct {
+}
+
+func (*UnimplementedAdminServerServer) ConfirmPrice(ctx context.Context, req *AdminConfirmPriceRequest) (*AdminConfirmPriceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmPrice not implemented")
+}
+func (*UnimplementedAdminServerServer) GetStatus(ctx context.Context, req *AdminGetStatusRequest) (*AdminGetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (*UnimplementedAdminServerServer) ListHedgeDrift(ctx context.Context, req *AdminListHedgeDriftRequest) (*AdminListHedgeDriftResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHedgeDrift not implemented")
+}
+func (*UnimplementedAdminServerServer) ListHedgeOrders(ctx context.Context, req *AdminListHedgeOrdersRequest) (*AdminListHedgeOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHedgeOrders not implemented")
+}
+func (*UnimplementedAdminServerServer) ListFundingPayments(ctx context.Context, req *AdminListFundingPaymentsRequest) (*AdminListFundingPaymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFundingPayments not implemented")
+}
+
+func RegisterAdminServerServer(s *grpc.Server, srv AdminServerServer) {
+	s.RegisterService(&_AdminServer_serviceDesc, srv)
+}
+
+func _AdminServer_ConfirmPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminConfirmPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServerServer).ConfirmPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ladrpc.AdminServer/ConfirmPrice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServerServer).ConfirmPrice(ctx, req.(*AdminConfirmPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminServer_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminGetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ladrpc.AdminServer/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServerServer).GetStatus(ctx, req.(*AdminGetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminServer_ListHedgeDrift_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListHedgeDriftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServerServer).ListHedgeDrift(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ladrpc.AdminServer/ListHedgeDrift",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServerServer).ListHedgeDrift(ctx, req.(*AdminListHedgeDriftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminServer_ListHedgeOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListHedgeOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServerServer).ListHedgeOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ladrpc.AdminServer/ListHedgeOrders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServerServer).ListHedgeOrders(ctx, req.(*AdminListHedgeOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminServer_ListFundingPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListFundingPaymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServerServer).ListFundingPayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ladrpc.AdminServer/ListFundingPayments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServerServer).ListFundingPayments(ctx, req.(*AdminListFundingPaymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _AdminServer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "ladrpc.AdminServer",
+	HandlerType: (*AdminServerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ConfirmPrice",
+			Handler:    _AdminServer_ConfirmPrice_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _AdminServer_GetStatus_Handler,
+		},
+		{
+			MethodName: "ListHedgeDrift",
+			Handler:    _AdminServer_ListHedgeDrift_Handler,
+		},
+		{
+			MethodName: "ListHedgeOrders",
+			Handler:    _AdminServer_ListHedgeOrders_Handler,
+		},
+		{
+			MethodName: "ListFundingPayments",
+			Handler:    _AdminServer_ListFundingPayments_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
@@ -0,0 +1,52 @@
+package larpc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+// SigningMessage returns the message that is signed for a PriceAttestation.
+// Both the server and the client must build the message the same way, so
+// the client can verify the signature with lnd's VerifyMessage.
+func (m *PriceAttestation) SigningMessage() (string, error) {
+	timestamp, err := ptypes.Timestamp(m.Timestamp)
+	if err != nil {
+		return "", fmt.Errorf("could not convert attestation timestamp: %w", err)
+	}
+
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	msg := fmt.Sprintf("lightning assets price attestation\n"+
+		"symbol: %s\nprice: %s\nsource: %s\ntimestamp: %d\n"+
+		"asset: %s\nasset price: %s\npricing: %s\ntwap window: %d\nformula: %s",
+		m.Symbol, formatFloat(m.Price), m.Source, timestamp.UnixNano(),
+		m.Asset, formatFloat(m.AssetPrice), m.Pricing, m.TwapWindowSeconds, m.Formula)
+
+	// inputs and rates are signed in the order the asset price was
+	// computed from them
+	for _, input := range m.Inputs {
+		inputTimestamp, err := ptypes.Timestamp(input.Timestamp)
+		if err != nil {
+			return "", fmt.Errorf("could not convert input timestamp: %w", err)
+		}
+
+		msg += fmt.Sprintf("\ninput: %s %s %s %d", input.Symbol, formatFloat(input.Price),
+			input.Source, inputTimestamp.UnixNano())
+	}
+
+	for _, rate := range m.FxRates {
+		rateTimestamp, err := ptypes.Timestamp(rate.Timestamp)
+		if err != nil {
+			return "", fmt.Errorf("could not convert rate timestamp: %w", err)
+		}
+
+		msg += fmt.Sprintf("\nfx rate: %s/%s %s %s %d", rate.Base, rate.Quote, formatFloat(rate.Rate),
+			rate.Source, rateTimestamp.UnixNano())
+	}
+
+	return msg, nil
+}
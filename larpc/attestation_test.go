@@ -0,0 +1,103 @@
+package larpc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func testAttestation(t *testing.T) *PriceAttestation {
+	timestamp, err := ptypes.TimestampProto(time.Unix(1583064000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &PriceAttestation{
+		Symbol:            "XBTUSD",
+		Price:             8500.5,
+		Source:            "bitmex",
+		Timestamp:         timestamp,
+		Asset:             "NOK",
+		AssetPrice:        85005,
+		Pricing:           "twap",
+		TwapWindowSeconds: 300,
+		Inputs: []*PriceInput{
+			{Symbol: "XBTUSD", Price: 8500.5, Source: "twap(5m0s,bitmex)", Timestamp: timestamp},
+		},
+		FxRates: []*FxRate{
+			{Base: "USD", Quote: "NOK", Rate: 10, Source: "frankfurter", Timestamp: timestamp},
+		},
+	}
+}
+
+func TestSigningMessage(t *testing.T) {
+	msg, err := testAttestation(t).SigningMessage()
+	if err != nil {
+		t.Fatalf("could not build message: %v", err)
+	}
+
+	want := "lightning assets price attestation\n" +
+		"symbol: XBTUSD\nprice: 8500.5\nsource: bitmex\ntimestamp: 1583064000000000000\n" +
+		"asset: NOK\nasset price: 85005\npricing: twap\ntwap window: 300\nformula: \n" +
+		"input: XBTUSD 8500.5 twap(5m0s,bitmex) 1583064000000000000\n" +
+		"fx rate: USD/NOK 10 frankfurter 1583064000000000000"
+	if msg != want {
+		t.Fatalf("message = %q, want %q", msg, want)
+	}
+}
+
+func TestSigningMessageCoversFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(m *PriceAttestation)
+	}{
+		{name: "asset price", change: func(m *PriceAttestation) { m.AssetPrice = 85006 }},
+		{name: "pricing", change: func(m *PriceAttestation) { m.Pricing = "last" }},
+		{name: "twap window", change: func(m *PriceAttestation) { m.TwapWindowSeconds = 60 }},
+		{name: "formula", change: func(m *PriceAttestation) { m.Formula = "XBTUSD" }},
+		{name: "input price", change: func(m *PriceAttestation) { m.Inputs[0].Price = 8501 }},
+		{name: "fx rate", change: func(m *PriceAttestation) { m.FxRates[0].Rate = 10.5 }},
+		{name: "fx source", change: func(m *PriceAttestation) { m.FxRates[0].Source = "other" }},
+		{name: "fx timestamp", change: func(m *PriceAttestation) {
+			m.FxRates[0].Timestamp = proto.Clone(m.FxRates[0].Timestamp).(*timestamp.Timestamp)
+			m.FxRates[0].Timestamp.Seconds++
+		}},
+		{name: "extra fx rate", change: func(m *PriceAttestation) {
+			m.FxRates = append(m.FxRates, &FxRate{Base: "NOK", Quote: "SEK", Rate: 1, Timestamp: m.Timestamp})
+		}},
+	}
+
+	original, err := testAttestation(t).SigningMessage()
+	if err != nil {
+		t.Fatalf("could not build message: %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attestation := testAttestation(t)
+			test.change(attestation)
+
+			msg, err := attestation.SigningMessage()
+			if err != nil {
+				t.Fatalf("could not build message: %v", err)
+			}
+			if msg == original {
+				t.Fatal("changing the field did not change the signed message")
+			}
+			if !strings.HasPrefix(msg, "lightning assets price attestation\n") {
+				t.Fatalf("message does not start with the header: %q", msg)
+			}
+		})
+	}
+
+	attestation := testAttestation(t)
+	attestation.FxRates[0].Timestamp = nil
+	_, err = attestation.SigningMessage()
+	if err == nil {
+		t.Fatal("built message from fx rate without timestamp")
+	}
+}
@@ -481,10 +481,12 @@ func (m *ClientListContractsResponse) GetContracts() []*ClientContract {
 }
 
 type ClientRequestPaymentRequestRequest struct {
-	AmountSat            int64    `protobuf:"varint,1,opt,name=amount_sat,json=amountSat,proto3" json:"amount_sat,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	AmountSat int64 `protobuf:"varint,1,opt,name=amount_sat,json=amountSat,proto3" json:"amount_sat,omitempty"`
+	// the price the server used to calculate the amount
+	Attestation          *PriceAttestation `protobuf:"bytes,2,opt,name=attestation,proto3" json:"attestation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ClientRequestPaymentRequestRequest) Reset()         { *m = ClientRequestPaymentRequestRequest{} }
@@ -519,6 +521,13 @@ func (m *ClientRequestPaymentRequestRequest) GetAmountSat() int64 {
 	return 0
 }
 
+func (m *ClientRequestPaymentRequestRequest) GetAttestation() *PriceAttestation {
+	if m != nil {
+		return m.Attestation
+	}
+	return nil
+}
+
 type ClientRequestPaymentRequestResponse struct {
 	PayReq               string   `protobuf:"bytes,1,opt,name=pay_req,json=payReq,proto3" json:"pay_req,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -559,10 +568,12 @@ func (m *ClientRequestPaymentRequestResponse) GetPayReq() string {
 }
 
 type ClientRequestPaymentRequest struct {
-	PayReq               string   `protobuf:"bytes,1,opt,name=pay_req,json=payReq,proto3" json:"pay_req,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	PayReq string `protobuf:"bytes,1,opt,name=pay_req,json=payReq,proto3" json:"pay_req,omitempty"`
+	// the price the server used to calculate the amount
+	Attestation          *PriceAttestation `protobuf:"bytes,2,opt,name=attestation,proto3" json:"attestation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ClientRequestPaymentRequest) Reset()         { *m = ClientRequestPaymentRequest{} }
@@ -597,6 +608,13 @@ func (m *ClientRequestPaymentRequest) GetPayReq() string {
 	return ""
 }
 
+func (m *ClientRequestPaymentRequest) GetAttestation() *PriceAttestation {
+	if m != nil {
+		return m.Attestation
+	}
+	return nil
+}
+
 type ClientRequestPaymentResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -679,57 +697,58 @@ func init() {
 func init() { proto.RegisterFile("client.proto", fileDescriptor_014de31d7ac8c57c) }
 
 var fileDescriptor_014de31d7ac8c57c = []byte{
-	// 785 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xdd, 0x4e, 0x13, 0x41,
-	0x14, 0xce, 0xb6, 0xb4, 0xb4, 0xa7, 0x3f, 0xe8, 0x84, 0x9f, 0x75, 0x29, 0x5a, 0x06, 0x21, 0x95,
-	0xc4, 0x16, 0xc1, 0x98, 0xe8, 0x85, 0x09, 0xf6, 0x8a, 0x44, 0x03, 0x59, 0x0c, 0x89, 0x7a, 0xb1,
-	0x19, 0xb6, 0x13, 0xb2, 0x49, 0xd9, 0x1d, 0x66, 0xa6, 0xc4, 0xde, 0x7a, 0xe1, 0x03, 0xe8, 0x0b,
-	0xf8, 0x48, 0x26, 0x5e, 0xf8, 0x02, 0x3e, 0x88, 0xe9, 0xcc, 0xec, 0xb6, 0xbb, 0x6e, 0x1b, 0xe2,
-	0x5d, 0xe7, 0x9c, 0xef, 0xfc, 0x7d, 0xe7, 0x3b, 0xb0, 0x50, 0xf7, 0x87, 0x01, 0x0d, 0x65, 0x97,
-	0xf1, 0x48, 0x46, 0xa8, 0x34, 0x24, 0x9c, 0xf9, 0x4e, 0x5d, 0x50, 0x7e, 0x4b, 0xb9, 0x36, 0x3a,
-	0xad, 0xab, 0x28, 0xba, 0x1a, 0xd2, 0x1e, 0x61, 0x41, 0x8f, 0x84, 0x61, 0x24, 0x89, 0x0c, 0xa2,
-	0x50, 0x68, 0x2f, 0xfe, 0x59, 0x80, 0x66, 0x5f, 0xe5, 0xe8, 0x47, 0xa1, 0xe4, 0xc4, 0x97, 0x08,
-	0xc1, 0xd2, 0x68, 0x14, 0x0c, 0x6c, 0xab, 0x6d, 0x75, 0xaa, 0xae, 0xfa, 0x8d, 0x56, 0xa1, 0x44,
-	0x84, 0xa0, 0xd2, 0x2e, 0x28, 0xa3, 0x7e, 0xa0, 0x75, 0x28, 0x93, 0xeb, 0x68, 0x14, 0x4a, 0xbb,
-	0xd8, 0xb6, 0x3a, 0x96, 0x6b, 0x5e, 0x68, 0x1f, 0xee, 0xeb, 0x5f, 0x9e, 0x20, 0xd2, 0xbb, 0x26,
-	0xfc, 0x2a, 0x08, 0xed, 0x52, 0xdb, 0xea, 0x14, 0xdd, 0x15, 0xed, 0x38, 0x27, 0xf2, 0x9d, 0x32,
-	0xa3, 0x3d, 0x58, 0x99, 0xc1, 0x06, 0x61, 0x20, 0xed, 0xb2, 0x42, 0x36, 0x12, 0xe4, 0x49, 0x18,
-	0x48, 0xb4, 0x0b, 0x4d, 0x9d, 0xc8, 0x0b, 0xc2, 0xdb, 0x28, 0xf0, 0xa9, 0xbd, 0xac, 0x5a, 0x69,
-	0x68, 0xeb, 0x89, 0x36, 0xa2, 0x6d, 0xa8, 0x4f, 0x72, 0x24, 0xa0, 0x8a, 0x02, 0xd5, 0x26, 0xb6,
-	0x18, 0xf2, 0x12, 0x1a, 0xbe, 0x99, 0xd5, 0x93, 0x63, 0x46, 0xed, 0x6a, 0xdb, 0xea, 0x34, 0x0f,
-	0x57, 0xbb, 0x43, 0x32, 0xe0, 0xcc, 0xef, 0xc6, 0x44, 0xbc, 0x1f, 0x33, 0xea, 0xd6, 0xfd, 0x99,
-	0x17, 0xda, 0x81, 0x86, 0x49, 0x2c, 0x3c, 0x46, 0x82, 0x81, 0x0d, 0x6d, 0xab, 0x53, 0x71, 0xeb,
-	0xb1, 0xf1, 0x8c, 0x04, 0x03, 0xfc, 0xd5, 0x82, 0x4d, 0x43, 0x29, 0xa7, 0x44, 0xd2, 0x38, 0x9f,
-	0x4b, 0x6f, 0x46, 0x54, 0xc8, 0x29, 0x97, 0x56, 0x3e, 0x97, 0x85, 0x14, 0x97, 0xff, 0x74, 0x5b,
-	0xbc, 0x6b, 0xb7, 0xf8, 0x47, 0x01, 0x5a, 0xf9, 0x8d, 0x08, 0x16, 0x85, 0x82, 0xa2, 0x67, 0x50,
-	0x89, 0x03, 0x54, 0x33, 0xb5, 0xc3, 0xb5, 0xae, 0x92, 0x50, 0x37, 0x2d, 0x09, 0x37, 0x81, 0xa1,
-	0xe7, 0xb0, 0x4e, 0x3f, 0x33, 0xea, 0x4b, 0x3a, 0x30, 0x8b, 0xf5, 0x66, 0xda, 0x2e, 0xba, 0xab,
-	0xb1, 0x57, 0xaf, 0xf7, 0x58, 0x0f, 0x71, 0x00, 0x89, 0x5d, 0xad, 0xd8, 0x9b, 0x91, 0x4d, 0xd1,
-	0x45, 0xb1, 0x6f, 0xb2, 0x68, 0x13, 0xb1, 0x09, 0xd5, 0x68, 0xc4, 0x3d, 0xc6, 0x27, 0x4b, 0x5c,
-	0x52, 0x8c, 0x54, 0xa2, 0x11, 0x3f, 0xe3, 0x66, 0xc9, 0x5a, 0xe2, 0xc6, 0x5f, 0x52, 0xfe, 0x9a,
-	0xb6, 0x69, 0xc8, 0x2e, 0x34, 0x19, 0xe5, 0x3e, 0x0d, 0x13, 0xfd, 0x95, 0x15, 0xa8, 0x61, 0xac,
-	0xba, 0x3d, 0xdc, 0x83, 0x07, 0x7a, 0xd4, 0x53, 0x46, 0xc3, 0xec, 0xa2, 0x72, 0x0e, 0x01, 0x9f,
-	0x82, 0x93, 0x17, 0xf0, 0xdf, 0x84, 0xe2, 0x83, 0x38, 0x61, 0x7f, 0x18, 0x09, 0x7a, 0x97, 0x16,
-	0xb6, 0x12, 0x79, 0xa5, 0x23, 0x74, 0x0f, 0xb8, 0x15, 0x27, 0x7c, 0x1b, 0x88, 0xa4, 0xa0, 0x30,
-	0x09, 0xb1, 0x1b, 0x07, 0x67, 0xbc, 0x66, 0x80, 0x23, 0xa8, 0xc6, 0x9d, 0x09, 0xdb, 0x6a, 0x17,
-	0xe7, 0x4f, 0x30, 0xc5, 0xe1, 0x3e, 0x60, 0xed, 0x34, 0x45, 0xce, 0xc8, 0xf8, 0x7a, 0xfa, 0x8a,
-	0x47, 0xd9, 0x02, 0x98, 0x1e, 0xba, 0x1a, 0xa8, 0xe8, 0x56, 0x93, 0x1b, 0xc7, 0xaf, 0x61, 0x67,
-	0x61, 0x12, 0xd3, 0xe0, 0x06, 0x2c, 0x33, 0x32, 0xf6, 0x38, 0xbd, 0x31, 0x9c, 0x94, 0x19, 0x19,
-	0xbb, 0xf4, 0x06, 0xbf, 0x88, 0x07, 0xcb, 0x8d, 0x9f, 0x1f, 0xf7, 0x30, 0xbe, 0x91, 0x6c, 0x9c,
-	0xa1, 0x73, 0x1b, 0x1e, 0x69, 0xff, 0xf9, 0xe8, 0x52, 0xf8, 0x3c, 0xb8, 0xa4, 0x59, 0x4e, 0x0f,
-	0x7f, 0x97, 0xa0, 0x76, 0x3c, 0x39, 0x62, 0x0d, 0x44, 0x1f, 0xa0, 0x99, 0x3e, 0x38, 0x84, 0xd3,
-	0x1c, 0xe6, 0xfd, 0x59, 0x70, 0x76, 0x16, 0x62, 0xcc, 0xf8, 0xe7, 0x50, 0x9f, 0x15, 0x1e, 0x6a,
-	0xa7, 0x82, 0x72, 0x44, 0xec, 0x6c, 0x2f, 0x40, 0x98, 0xa4, 0x17, 0xd0, 0x48, 0x49, 0x09, 0xa5,
-	0x63, 0xf2, 0x84, 0xe9, 0xe0, 0x45, 0x10, 0x93, 0xf7, 0x9b, 0x05, 0x6b, 0xf9, 0xdb, 0x78, 0x92,
-	0x8a, 0x5e, 0x24, 0x1b, 0x67, 0xff, 0x2e, 0x50, 0xb3, 0x2b, 0xfc, 0xe5, 0xd7, 0x9f, 0xef, 0x85,
-	0x16, 0xde, 0xe8, 0x71, 0xed, 0xe9, 0x31, 0x0d, 0x34, 0xcf, 0x57, 0xd6, 0x3e, 0xba, 0x85, 0x66,
-	0x3a, 0x49, 0x66, 0x39, 0xb9, 0x15, 0x32, 0xcb, 0x99, 0x23, 0x95, 0x4d, 0x55, 0x7e, 0x0d, 0xdf,
-	0xcb, 0x96, 0x9f, 0xd4, 0xbd, 0x80, 0x46, 0xea, 0xe4, 0x32, 0x24, 0xe7, 0x1d, 0x6b, 0x86, 0xe4,
-	0xfc, 0x8b, 0xfd, 0x04, 0xf6, 0x54, 0x99, 0xa9, 0x13, 0x15, 0x68, 0x2f, 0x15, 0x3f, 0x57, 0xc0,
-	0x4e, 0xfe, 0x89, 0x1f, 0x58, 0x6f, 0x1e, 0x7f, 0xc4, 0x84, 0xfb, 0x24, 0xa4, 0x3e, 0x1f, 0x33,
-	0x19, 0xf5, 0x86, 0xa1, 0xfa, 0x67, 0x25, 0x9e, 0xea, 0xcf, 0x8e, 0x9e, 0x0a, 0xbb, 0x2c, 0xab,
-	0x4f, 0x89, 0xa3, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x81, 0xbb, 0xb8, 0x2f, 0x8d, 0x08, 0x00,
-	0x00,
+	// 814 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xcd, 0x6e, 0x1a, 0x49,
+	0x10, 0xd6, 0x80, 0xc1, 0x50, 0xfc, 0x78, 0xb7, 0xe5, 0x9f, 0xd9, 0x01, 0xef, 0xe2, 0xf6, 0xda,
+	0x62, 0x2d, 0x2d, 0x38, 0x38, 0x97, 0xf8, 0x10, 0xc9, 0xf1, 0xc9, 0x52, 0x22, 0x5b, 0xe3, 0xc8,
+	0x52, 0x92, 0xc3, 0xa8, 0x3d, 0xb4, 0xac, 0x91, 0x70, 0x4f, 0xbb, 0xbb, 0xb1, 0xc2, 0x29, 0x52,
+	0x0e, 0x79, 0x80, 0xe4, 0x05, 0xf2, 0x48, 0x91, 0x72, 0xc8, 0x0b, 0xe4, 0x41, 0x22, 0xba, 0x7b,
+	0x80, 0x21, 0x03, 0xb2, 0x92, 0x1b, 0x5d, 0xf5, 0x55, 0x75, 0x55, 0x7d, 0x5f, 0x35, 0x03, 0xd5,
+	0x70, 0x10, 0x51, 0xa6, 0x3a, 0x5c, 0xc4, 0x2a, 0x46, 0x85, 0x01, 0x11, 0x3c, 0xf4, 0xaa, 0x92,
+	0x8a, 0x7b, 0x2a, 0x8c, 0xd1, 0x6b, 0xde, 0xc4, 0xf1, 0xcd, 0x80, 0x76, 0x09, 0x8f, 0xba, 0x84,
+	0xb1, 0x58, 0x11, 0x15, 0xc5, 0x4c, 0x1a, 0x2f, 0xfe, 0x92, 0x83, 0xfa, 0xa9, 0xce, 0x71, 0x1a,
+	0x33, 0x25, 0x48, 0xa8, 0x10, 0x82, 0x95, 0xe1, 0x30, 0xea, 0xbb, 0x4e, 0xcb, 0x69, 0x97, 0x7d,
+	0xfd, 0x1b, 0xad, 0x43, 0x81, 0x48, 0x49, 0x95, 0x9b, 0xd3, 0x46, 0x73, 0x40, 0x9b, 0x50, 0x24,
+	0xb7, 0xf1, 0x90, 0x29, 0x37, 0xdf, 0x72, 0xda, 0x8e, 0x6f, 0x4f, 0xe8, 0x00, 0xfe, 0x34, 0xbf,
+	0x02, 0x49, 0x54, 0x70, 0x4b, 0xc4, 0x4d, 0xc4, 0xdc, 0x42, 0xcb, 0x69, 0xe7, 0xfd, 0x35, 0xe3,
+	0xb8, 0x24, 0xea, 0x85, 0x36, 0xa3, 0x7d, 0x58, 0x9b, 0xc1, 0x46, 0x2c, 0x52, 0x6e, 0x51, 0x23,
+	0x6b, 0x13, 0xe4, 0x19, 0x8b, 0x14, 0xda, 0x83, 0xba, 0x49, 0x14, 0x44, 0xec, 0x3e, 0x8e, 0x42,
+	0xea, 0xae, 0xea, 0x52, 0x6a, 0xc6, 0x7a, 0x66, 0x8c, 0x68, 0x07, 0xaa, 0xe3, 0x1c, 0x13, 0x50,
+	0x49, 0x83, 0x2a, 0x63, 0x5b, 0x02, 0x79, 0x02, 0xb5, 0xd0, 0xf6, 0x1a, 0xa8, 0x11, 0xa7, 0x6e,
+	0xb9, 0xe5, 0xb4, 0xeb, 0xbd, 0xf5, 0xce, 0x80, 0xf4, 0x05, 0x0f, 0x3b, 0xc9, 0x20, 0x5e, 0x8e,
+	0x38, 0xf5, 0xab, 0xe1, 0xcc, 0x09, 0xed, 0x42, 0xcd, 0x26, 0x96, 0x01, 0x27, 0x51, 0xdf, 0x85,
+	0x96, 0xd3, 0x2e, 0xf9, 0xd5, 0xc4, 0x78, 0x41, 0xa2, 0x3e, 0xfe, 0xe0, 0x40, 0xc3, 0x8e, 0x54,
+	0x50, 0xa2, 0x68, 0x92, 0xcf, 0xa7, 0x77, 0x43, 0x2a, 0xd5, 0x74, 0x96, 0x4e, 0xf6, 0x2c, 0x73,
+	0xa9, 0x59, 0xfe, 0x54, 0x6d, 0xfe, 0xa1, 0xd5, 0xe2, 0xcf, 0x39, 0x68, 0x66, 0x17, 0x22, 0x79,
+	0xcc, 0x24, 0x45, 0x8f, 0xa0, 0x94, 0x04, 0xe8, 0x62, 0x2a, 0xbd, 0x8d, 0x8e, 0x96, 0x50, 0x27,
+	0x2d, 0x09, 0x7f, 0x02, 0x43, 0x8f, 0x61, 0x93, 0xbe, 0xe5, 0x34, 0x54, 0xb4, 0x6f, 0x89, 0x0d,
+	0x66, 0xca, 0xce, 0xfb, 0xeb, 0x89, 0xd7, 0xd0, 0x7b, 0x62, 0x9a, 0x38, 0x84, 0x89, 0x5d, 0x53,
+	0x1c, 0xcc, 0xc8, 0x26, 0xef, 0xa3, 0xc4, 0x37, 0x26, 0xda, 0x46, 0x34, 0xa0, 0x1c, 0x0f, 0x45,
+	0xc0, 0xc5, 0x98, 0xc4, 0x15, 0x3d, 0x91, 0x52, 0x3c, 0x14, 0x17, 0xc2, 0x92, 0x6c, 0x24, 0x6e,
+	0xfd, 0x05, 0xed, 0xaf, 0x18, 0x9b, 0x81, 0xec, 0x41, 0x9d, 0x53, 0x11, 0x52, 0x36, 0xd1, 0x5f,
+	0x51, 0x83, 0x6a, 0xd6, 0x6a, 0xca, 0xc3, 0x5d, 0xf8, 0xcb, 0xb4, 0x7a, 0xce, 0x29, 0x9b, 0x27,
+	0x2a, 0x63, 0x11, 0xf0, 0x39, 0x78, 0x59, 0x01, 0xbf, 0x3c, 0x50, 0x7c, 0x98, 0x24, 0x3c, 0x1d,
+	0xc4, 0x92, 0x3e, 0xa4, 0x84, 0x6d, 0x68, 0x64, 0x46, 0x98, 0x1a, 0x70, 0x33, 0x49, 0xf8, 0x3c,
+	0x92, 0x93, 0x0b, 0xa5, 0x4d, 0x88, 0x7d, 0x68, 0x64, 0x7a, 0x6d, 0x03, 0x47, 0x50, 0x4e, 0x2a,
+	0x93, 0xae, 0xd3, 0xca, 0x2f, 0xee, 0x60, 0x8a, 0xc3, 0xef, 0x00, 0x1b, 0xa7, 0xbd, 0xe4, 0x82,
+	0x8c, 0x6e, 0xa7, 0xa7, 0xa4, 0x95, 0x6d, 0x80, 0xe9, 0xa2, 0xeb, 0x86, 0xf2, 0x7e, 0x79, 0xb2,
+	0xe3, 0xe8, 0x18, 0x2a, 0x44, 0x29, 0x2a, 0xcd, 0xf3, 0xa4, 0xd5, 0x54, 0xe9, 0xb9, 0x89, 0xca,
+	0x35, 0xa9, 0x27, 0x53, 0xbf, 0x3f, 0x0b, 0xc6, 0x4f, 0x61, 0x77, 0x69, 0x01, 0xb6, 0xb9, 0x2d,
+	0x58, 0xe5, 0x64, 0x14, 0x08, 0x7a, 0x67, 0xe7, 0x59, 0xe4, 0x64, 0xe4, 0xd3, 0x3b, 0x2c, 0x92,
+	0xa1, 0x64, 0xc6, 0x2f, 0x8c, 0xfb, 0xad, 0x9a, 0xff, 0x4e, 0x76, 0x73, 0xfe, 0x4e, 0x4b, 0xe3,
+	0x0e, 0xfc, 0x63, 0xfc, 0x97, 0xc3, 0x6b, 0x19, 0x8a, 0xe8, 0x9a, 0xce, 0x73, 0xd9, 0xfb, 0x56,
+	0x80, 0xca, 0x89, 0x94, 0x54, 0x19, 0x20, 0x7a, 0x05, 0xf5, 0xf4, 0xa2, 0x23, 0x9c, 0xe6, 0x2e,
+	0xeb, 0x39, 0xf2, 0x76, 0x97, 0x62, 0xec, 0xe8, 0x2e, 0xa1, 0x3a, 0x2b, 0x78, 0xd4, 0x4a, 0x05,
+	0x65, 0x2c, 0x8f, 0xb7, 0xb3, 0x04, 0x61, 0x93, 0x5e, 0x41, 0x2d, 0x25, 0x61, 0x94, 0x8e, 0xc9,
+	0x5a, 0x08, 0x0f, 0x2f, 0x83, 0xd8, 0xbc, 0x1f, 0x1d, 0xd8, 0xc8, 0x66, 0xf2, 0xbf, 0x54, 0xf4,
+	0x32, 0xb9, 0x7a, 0x07, 0x0f, 0x81, 0x5a, 0xae, 0xf0, 0xfb, 0xaf, 0xdf, 0x3f, 0xe5, 0x9a, 0x78,
+	0xab, 0x2b, 0x8c, 0xa7, 0xcb, 0x0d, 0xd0, 0x1e, 0x8f, 0x9d, 0x03, 0x74, 0x0f, 0xf5, 0x74, 0x92,
+	0x39, 0x72, 0x32, 0x6f, 0x98, 0x23, 0x67, 0x81, 0x54, 0x1a, 0xfa, 0xfa, 0x0d, 0xfc, 0xc7, 0xfc,
+	0xf5, 0xe3, 0x7b, 0xaf, 0xa0, 0x96, 0x5a, 0xf5, 0xb9, 0x21, 0x67, 0x3d, 0x12, 0x1e, 0x5e, 0x06,
+	0xb1, 0x43, 0x7e, 0x03, 0xee, 0x54, 0x99, 0xa9, 0xa7, 0x41, 0xa2, 0xfd, 0x54, 0xfc, 0x42, 0x01,
+	0x7b, 0xd9, 0x4f, 0xcb, 0xa1, 0xf3, 0xec, 0xdf, 0xd7, 0x98, 0x88, 0x90, 0x30, 0x1a, 0x8a, 0x11,
+	0x57, 0x71, 0x77, 0xc0, 0xf4, 0x9f, 0xa4, 0xfc, 0xdf, 0x7c, 0xee, 0x74, 0x75, 0xd8, 0x75, 0x51,
+	0x7f, 0xc2, 0x1c, 0xfd, 0x18, 0x00, 0x85, 0x1f, 0xc2, 0x15, 0x05, 0x09, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -51,21 +51,67 @@ func (ContractType) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_ad098daeda4239f7, []int{0}
 }
 
+type CandleInterval int32
+
+const (
+	// TICK returns every single tick instead of candles
+	CandleInterval_TICK   CandleInterval = 0
+	CandleInterval_MINUTE CandleInterval = 1
+	CandleInterval_HOUR   CandleInterval = 2
+	CandleInterval_DAY    CandleInterval = 3
+)
+
+var CandleInterval_name = map[int32]string{
+	0: "TICK",
+	1: "MINUTE",
+	2: "HOUR",
+	3: "DAY",
+}
+
+var CandleInterval_value = map[string]int32{
+	"TICK":   0,
+	"MINUTE": 1,
+	"HOUR":   2,
+	"DAY":    3,
+}
+
+func (x CandleInterval) String() string {
+	return proto.EnumName(CandleInterval_name, int32(x))
+}
+
+func (CandleInterval) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{1}
+}
+
 // Contract is the type of our contract, used to marshal/unmarshal
 // and send between hosts
 type ServerContract struct {
-	Uuid                 string               `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	Asset                string               `protobuf:"bytes,2,opt,name=asset,proto3" json:"asset,omitempty"`
-	Amount               float64              `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	AmountSats           int64                `protobuf:"varint,4,opt,name=amount_sats,json=amountSats,proto3" json:"amount_sats,omitempty"`
-	ClientHost           string               `protobuf:"bytes,5,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"`
-	MarginPayReq         string               `protobuf:"bytes,6,opt,name=margin_pay_req,json=marginPayReq,proto3" json:"margin_pay_req,omitempty"`
-	InitiatingPayReq     string               `protobuf:"bytes,7,opt,name=initiating_pay_req,json=initiatingPayReq,proto3" json:"initiating_pay_req,omitempty"`
-	LastRebalancedAt     *timestamp.Timestamp `protobuf:"bytes,8,opt,name=last_rebalanced_at,json=lastRebalancedAt,proto3" json:"last_rebalanced_at,omitempty"`
-	MarginPaid           bool                 `protobuf:"varint,9,opt,name=margin_paid,json=marginPaid,proto3" json:"margin_paid,omitempty"`
-	InitiatingPaid       bool                 `protobuf:"varint,10,opt,name=initiating_paid,json=initiatingPaid,proto3" json:"initiating_paid,omitempty"`
-	ContractType         ContractType         `protobuf:"varint,11,opt,name=contract_type,json=contractType,proto3,enum=ladrpc.ContractType" json:"contract_type,omitempty"`
-	NumUpdates           int64                `protobuf:"varint,12,opt,name=num_updates,json=numUpdates,proto3" json:"num_updates,omitempty"`
+	Uuid             string               `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Asset            string               `protobuf:"bytes,2,opt,name=asset,proto3" json:"asset,omitempty"`
+	Amount           float64              `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	AmountSats       int64                `protobuf:"varint,4,opt,name=amount_sats,json=amountSats,proto3" json:"amount_sats,omitempty"`
+	ClientHost       string               `protobuf:"bytes,5,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"`
+	MarginPayReq     string               `protobuf:"bytes,6,opt,name=margin_pay_req,json=marginPayReq,proto3" json:"margin_pay_req,omitempty"`
+	InitiatingPayReq string               `protobuf:"bytes,7,opt,name=initiating_pay_req,json=initiatingPayReq,proto3" json:"initiating_pay_req,omitempty"`
+	LastRebalancedAt *timestamp.Timestamp `protobuf:"bytes,8,opt,name=last_rebalanced_at,json=lastRebalancedAt,proto3" json:"last_rebalanced_at,omitempty"`
+	MarginPaid       bool                 `protobuf:"varint,9,opt,name=margin_paid,json=marginPaid,proto3" json:"margin_paid,omitempty"`
+	InitiatingPaid   bool                 `protobuf:"varint,10,opt,name=initiating_paid,json=initiatingPaid,proto3" json:"initiating_paid,omitempty"`
+	ContractType     ContractType         `protobuf:"varint,11,opt,name=contract_type,json=contractType,proto3,enum=ladrpc.ContractType" json:"contract_type,omitempty"`
+	NumUpdates       int64                `protobuf:"varint,12,opt,name=num_updates,json=numUpdates,proto3" json:"num_updates,omitempty"`
+	// which price the asset was priced with when the contract was opened,
+	// one of "last", "mark", "index" or "twap"
+	PricingMode string `protobuf:"bytes,13,opt,name=pricing_mode,json=pricingMode,proto3" json:"pricing_mode,omitempty"`
+	// the price of the hedge instrument when the contract was opened, which
+	// the fill prices of its hedge orders are compared to
+	HedgePrice float64 `protobuf:"fixed64,14,opt,name=hedge_price,json=hedgePrice,proto3" json:"hedge_price,omitempty"`
+	// how many contracts of the hedge instrument hedge the contract, fixed
+	// when it is quoted so its hedge orders are sized without market data
+	HedgeQty float64 `protobuf:"fixed64,15,opt,name=hedge_qty,json=hedgeQty,proto3" json:"hedge_qty,omitempty"`
+	// when the contract was opened by the client paying for it, and when it
+	// was closed. Funding paid on our hedges is allocated to the contracts
+	// open when it was paid
+	OpenedAt             *timestamp.Timestamp `protobuf:"bytes,16,opt,name=opened_at,json=openedAt,proto3" json:"opened_at,omitempty"`
+	ClosedAt             *timestamp.Timestamp `protobuf:"bytes,17,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -180,6 +226,41 @@ func (m *ServerContract) GetNumUpdates() int64 {
 	return 0
 }
 
+func (m *ServerContract) GetPricingMode() string {
+	if m != nil {
+		return m.PricingMode
+	}
+	return ""
+}
+
+func (m *ServerContract) GetHedgePrice() float64 {
+	if m != nil {
+		return m.HedgePrice
+	}
+	return 0
+}
+
+func (m *ServerContract) GetHedgeQty() float64 {
+	if m != nil {
+		return m.HedgeQty
+	}
+	return 0
+}
+
+func (m *ServerContract) GetOpenedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.OpenedAt
+	}
+	return nil
+}
+
+func (m *ServerContract) GetClosedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ClosedAt
+	}
+	return nil
+}
+
 // Payment is a payment type, used to marshal/unmarshal from the db
 type Payment struct {
 	ContractUuid   string `protobuf:"bytes,1,opt,name=contract_uuid,json=contractUuid,proto3" json:"contract_uuid,omitempty"`
@@ -347,6 +428,298 @@ func (m *Price) GetValue() float64 {
 	return 0
 }
 
+// PriceAttestation is a price signed with the lnd node key of the server,
+// which lets clients verify the price used when rebalancing a contract
+type PriceAttestation struct {
+	// the symbol and price reported by the price oracle
+	Symbol    string               `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Price     float64              `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	Source    string               `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// the asset of the contract, and the price of one bitcoin in that asset
+	Asset      string  `protobuf:"bytes,5,opt,name=asset,proto3" json:"asset,omitempty"`
+	AssetPrice float64 `protobuf:"fixed64,6,opt,name=asset_price,json=assetPrice,proto3" json:"asset_price,omitempty"`
+	// signature of the message returned by SigningMessage, as returned
+	// by lnd's SignMessage
+	Signature string `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+	// the identity pubkey of the node that signed the message
+	Pubkey string `protobuf:"bytes,8,opt,name=pubkey,proto3" json:"pubkey,omitempty"`
+	// the prices of the instruments the asset price was computed from, in
+	// the pricing mode of the asset. For assets priced by a formula these
+	// are the instruments in the formula, in the order they appear in it,
+	// for all others the hedge instrument of the asset. They are signed
+	// along with the asset price
+	Inputs []*PriceInput `protobuf:"bytes,9,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	// the exchange rates the price of the hedge instrument was converted to
+	// the asset with, in the order they were applied, for assets priced
+	// through our exchange rates. The asset price is the price of the
+	// single input multiplied with every rate
+	FxRates []*FxRate `protobuf:"bytes,10,rep,name=fx_rates,json=fxRates,proto3" json:"fx_rates,omitempty"`
+	// the pricing mode of the asset, and for the twap mode the window in
+	// seconds prices were averaged over
+	Pricing           string `protobuf:"bytes,11,opt,name=pricing,proto3" json:"pricing,omitempty"`
+	TwapWindowSeconds int64  `protobuf:"varint,12,opt,name=twap_window_seconds,json=twapWindowSeconds,proto3" json:"twap_window_seconds,omitempty"`
+	// the price formula of the asset the inputs were combined with, for
+	// assets priced by a formula
+	Formula              string   `protobuf:"bytes,13,opt,name=formula,proto3" json:"formula,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PriceAttestation) Reset()         { *m = PriceAttestation{} }
+func (m *PriceAttestation) String() string { return proto.CompactTextString(m) }
+func (*PriceAttestation) ProtoMessage()    {}
+func (*PriceAttestation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{4}
+}
+
+func (m *PriceAttestation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PriceAttestation.Unmarshal(m, b)
+}
+func (m *PriceAttestation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PriceAttestation.Marshal(b, m, deterministic)
+}
+func (m *PriceAttestation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PriceAttestation.Merge(m, src)
+}
+func (m *PriceAttestation) XXX_Size() int {
+	return xxx_messageInfo_PriceAttestation.Size(m)
+}
+func (m *PriceAttestation) XXX_DiscardUnknown() {
+	xxx_messageInfo_PriceAttestation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PriceAttestation proto.InternalMessageInfo
+
+func (m *PriceAttestation) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *PriceAttestation) GetPrice() float64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *PriceAttestation) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *PriceAttestation) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *PriceAttestation) GetAsset() string {
+	if m != nil {
+		return m.Asset
+	}
+	return ""
+}
+
+func (m *PriceAttestation) GetAssetPrice() float64 {
+	if m != nil {
+		return m.AssetPrice
+	}
+	return 0
+}
+
+func (m *PriceAttestation) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *PriceAttestation) GetPubkey() string {
+	if m != nil {
+		return m.Pubkey
+	}
+	return ""
+}
+
+func (m *PriceAttestation) GetInputs() []*PriceInput {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *PriceAttestation) GetFxRates() []*FxRate {
+	if m != nil {
+		return m.FxRates
+	}
+	return nil
+}
+
+func (m *PriceAttestation) GetPricing() string {
+	if m != nil {
+		return m.Pricing
+	}
+	return ""
+}
+
+func (m *PriceAttestation) GetTwapWindowSeconds() int64 {
+	if m != nil {
+		return m.TwapWindowSeconds
+	}
+	return 0
+}
+
+func (m *PriceAttestation) GetFormula() string {
+	if m != nil {
+		return m.Formula
+	}
+	return ""
+}
+
+// PriceInput is a price an asset price was computed from
+type PriceInput struct {
+	Symbol               string               `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Price                float64              `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	Source               string               `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Timestamp            *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PriceInput) Reset()         { *m = PriceInput{} }
+func (m *PriceInput) String() string { return proto.CompactTextString(m) }
+func (*PriceInput) ProtoMessage()    {}
+func (*PriceInput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{5}
+}
+
+func (m *PriceInput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PriceInput.Unmarshal(m, b)
+}
+func (m *PriceInput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PriceInput.Marshal(b, m, deterministic)
+}
+func (m *PriceInput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PriceInput.Merge(m, src)
+}
+func (m *PriceInput) XXX_Size() int {
+	return xxx_messageInfo_PriceInput.Size(m)
+}
+func (m *PriceInput) XXX_DiscardUnknown() {
+	xxx_messageInfo_PriceInput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PriceInput proto.InternalMessageInfo
+
+func (m *PriceInput) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *PriceInput) GetPrice() float64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *PriceInput) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *PriceInput) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+// FxRate is an exchange rate an asset price was converted with, meaning
+// one unit of base costs rate units of quote
+type FxRate struct {
+	Base                 string               `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	Quote                string               `protobuf:"bytes,2,opt,name=quote,proto3" json:"quote,omitempty"`
+	Rate                 float64              `protobuf:"fixed64,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	Source               string               `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	Timestamp            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *FxRate) Reset()         { *m = FxRate{} }
+func (m *FxRate) String() string { return proto.CompactTextString(m) }
+func (*FxRate) ProtoMessage()    {}
+func (*FxRate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{6}
+}
+
+func (m *FxRate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FxRate.Unmarshal(m, b)
+}
+func (m *FxRate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FxRate.Marshal(b, m, deterministic)
+}
+func (m *FxRate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FxRate.Merge(m, src)
+}
+func (m *FxRate) XXX_Size() int {
+	return xxx_messageInfo_FxRate.Size(m)
+}
+func (m *FxRate) XXX_DiscardUnknown() {
+	xxx_messageInfo_FxRate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FxRate proto.InternalMessageInfo
+
+func (m *FxRate) GetBase() string {
+	if m != nil {
+		return m.Base
+	}
+	return ""
+}
+
+func (m *FxRate) GetQuote() string {
+	if m != nil {
+		return m.Quote
+	}
+	return ""
+}
+
+func (m *FxRate) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *FxRate) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *FxRate) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
 // ServerNewContractRequest is used to initiate a new contract
 // with another host
 type ServerNewContractRequest struct {
@@ -363,7 +736,7 @@ func (m *ServerNewContractRequest) Reset()         { *m = ServerNewContractReque
 func (m *ServerNewContractRequest) String() string { return proto.CompactTextString(m) }
 func (*ServerNewContractRequest) ProtoMessage()    {}
 func (*ServerNewContractRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{4}
+	return fileDescriptor_ad098daeda4239f7, []int{7}
 }
 
 func (m *ServerNewContractRequest) XXX_Unmarshal(b []byte) error {
@@ -414,11 +787,16 @@ func (m *ServerNewContractRequest) GetContractType() ContractType {
 
 // If successful, the ServerNewContractResponse returns the created contract
 type ServerNewContractResponse struct {
-	Uuid                 string   `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	MarginPayReq         string   `protobuf:"bytes,2,opt,name=margin_pay_req,json=marginPayReq,proto3" json:"margin_pay_req,omitempty"`
-	InitiatingPayReq     string   `protobuf:"bytes,3,opt,name=initiating_pay_req,json=initiatingPayReq,proto3" json:"initiating_pay_req,omitempty"`
-	PercentMargin        float64  `protobuf:"fixed64,4,opt,name=percent_margin,json=percentMargin,proto3" json:"percent_margin,omitempty"`
-	AssetPrice           float64  `protobuf:"fixed64,5,opt,name=asset_price,json=assetPrice,proto3" json:"asset_price,omitempty"`
+	Uuid             string  `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	MarginPayReq     string  `protobuf:"bytes,2,opt,name=margin_pay_req,json=marginPayReq,proto3" json:"margin_pay_req,omitempty"`
+	InitiatingPayReq string  `protobuf:"bytes,3,opt,name=initiating_pay_req,json=initiatingPayReq,proto3" json:"initiating_pay_req,omitempty"`
+	PercentMargin    float64 `protobuf:"fixed64,4,opt,name=percent_margin,json=percentMargin,proto3" json:"percent_margin,omitempty"`
+	AssetPrice       float64 `protobuf:"fixed64,5,opt,name=asset_price,json=assetPrice,proto3" json:"asset_price,omitempty"`
+	// which price asset_price is, one of "last", "mark", "index" or "twap"
+	PricingMode string `protobuf:"bytes,6,opt,name=pricing_mode,json=pricingMode,proto3" json:"pricing_mode,omitempty"`
+	// the amount of the asset the contract is for. Less than requested if
+	// the contract was capped to keep our hedge within its leverage limit
+	Amount               float64  `protobuf:"fixed64,7,opt,name=amount,proto3" json:"amount,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -428,7 +806,7 @@ func (m *ServerNewContractResponse) Reset()         { *m = ServerNewContractResp
 func (m *ServerNewContractResponse) String() string { return proto.CompactTextString(m) }
 func (*ServerNewContractResponse) ProtoMessage()    {}
 func (*ServerNewContractResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{5}
+	return fileDescriptor_ad098daeda4239f7, []int{8}
 }
 
 func (m *ServerNewContractResponse) XXX_Unmarshal(b []byte) error {
@@ -484,6 +862,20 @@ func (m *ServerNewContractResponse) GetAssetPrice() float64 {
 	return 0
 }
 
+func (m *ServerNewContractResponse) GetPricingMode() string {
+	if m != nil {
+		return m.PricingMode
+	}
+	return ""
+}
+
+func (m *ServerNewContractResponse) GetAmount() float64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
 type ServerCloseContractRequest struct {
 	Uuid                 string   `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -495,7 +887,7 @@ func (m *ServerCloseContractRequest) Reset()         { *m = ServerCloseContractR
 func (m *ServerCloseContractRequest) String() string { return proto.CompactTextString(m) }
 func (*ServerCloseContractRequest) ProtoMessage()    {}
 func (*ServerCloseContractRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{6}
+	return fileDescriptor_ad098daeda4239f7, []int{9}
 }
 
 func (m *ServerCloseContractRequest) XXX_Unmarshal(b []byte) error {
@@ -533,7 +925,7 @@ func (m *ServerCloseContractResponse) Reset()         { *m = ServerCloseContract
 func (m *ServerCloseContractResponse) String() string { return proto.CompactTextString(m) }
 func (*ServerCloseContractResponse) ProtoMessage()    {}
 func (*ServerCloseContractResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{7}
+	return fileDescriptor_ad098daeda4239f7, []int{10}
 }
 
 func (m *ServerCloseContractResponse) XXX_Unmarshal(b []byte) error {
@@ -564,7 +956,7 @@ func (m *ServerListAssetsRequest) Reset()         { *m = ServerListAssetsRequest
 func (m *ServerListAssetsRequest) String() string { return proto.CompactTextString(m) }
 func (*ServerListAssetsRequest) ProtoMessage()    {}
 func (*ServerListAssetsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{8}
+	return fileDescriptor_ad098daeda4239f7, []int{11}
 }
 
 func (m *ServerListAssetsRequest) XXX_Unmarshal(b []byte) error {
@@ -587,6 +979,7 @@ var xxx_messageInfo_ServerListAssetsRequest proto.InternalMessageInfo
 
 type ServerListAssetsResponse struct {
 	SupportedAssets      []string `protobuf:"bytes,1,rep,name=supported_assets,json=supportedAssets,proto3" json:"supported_assets,omitempty"`
+	Assets               []*Asset `protobuf:"bytes,2,rep,name=assets,proto3" json:"assets,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -596,7 +989,7 @@ func (m *ServerListAssetsResponse) Reset()         { *m = ServerListAssetsRespon
 func (m *ServerListAssetsResponse) String() string { return proto.CompactTextString(m) }
 func (*ServerListAssetsResponse) ProtoMessage()    {}
 func (*ServerListAssetsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{9}
+	return fileDescriptor_ad098daeda4239f7, []int{12}
 }
 
 func (m *ServerListAssetsResponse) XXX_Unmarshal(b []byte) error {
@@ -624,97 +1017,715 @@ func (m *ServerListAssetsResponse) GetSupportedAssets() []string {
 	return nil
 }
 
-func init() {
-	proto.RegisterEnum("ladrpc.ContractType", ContractType_name, ContractType_value)
-	proto.RegisterType((*ServerContract)(nil), "ladrpc.ServerContract")
-	proto.RegisterType((*Payment)(nil), "ladrpc.Payment")
-	proto.RegisterType((*Quote)(nil), "ladrpc.Quote")
-	proto.RegisterType((*Price)(nil), "ladrpc.Price")
-	proto.RegisterType((*ServerNewContractRequest)(nil), "ladrpc.ServerNewContractRequest")
-	proto.RegisterType((*ServerNewContractResponse)(nil), "ladrpc.ServerNewContractResponse")
-	proto.RegisterType((*ServerCloseContractRequest)(nil), "ladrpc.ServerCloseContractRequest")
-	proto.RegisterType((*ServerCloseContractResponse)(nil), "ladrpc.ServerCloseContractResponse")
-	proto.RegisterType((*ServerListAssetsRequest)(nil), "ladrpc.ServerListAssetsRequest")
-	proto.RegisterType((*ServerListAssetsResponse)(nil), "ladrpc.ServerListAssetsResponse")
+func (m *ServerListAssetsResponse) GetAssets() []*Asset {
+	if m != nil {
+		return m.Assets
+	}
+	return nil
 }
 
-func init() { proto.RegisterFile("server.proto", fileDescriptor_ad098daeda4239f7) }
-
-var fileDescriptor_ad098daeda4239f7 = []byte{
-	// 796 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xcd, 0x72, 0xe3, 0x44,
-	0x10, 0x66, 0xfc, 0xb7, 0x76, 0xdb, 0x71, 0xcc, 0x90, 0xda, 0xd5, 0x0a, 0xb6, 0x56, 0x68, 0x97,
-	0x42, 0xa4, 0xc0, 0xa6, 0xb2, 0x27, 0xf6, 0xb6, 0xb0, 0xa1, 0x72, 0x80, 0x54, 0x50, 0xe2, 0x0b,
-	0x17, 0xd5, 0x58, 0x1a, 0xcc, 0x14, 0xf2, 0xcc, 0x44, 0x33, 0x4a, 0xca, 0x57, 0x1e, 0x01, 0x0e,
-	0x3c, 0x14, 0x47, 0x2e, 0x79, 0x00, 0x1e, 0x64, 0x4b, 0x33, 0x92, 0x2d, 0xc7, 0x76, 0x2a, 0xb7,
-	0xe9, 0xaf, 0x7b, 0xba, 0x7b, 0xbe, 0xaf, 0x5b, 0x82, 0x81, 0xa2, 0xd9, 0x0d, 0xcd, 0xc6, 0x32,
-	0x13, 0x5a, 0xe0, 0x4e, 0x4a, 0x92, 0x4c, 0xc6, 0xee, 0xa1, 0x66, 0x0b, 0xaa, 0x34, 0x59, 0x48,
-	0xeb, 0x70, 0x3f, 0x9b, 0x0b, 0x31, 0x4f, 0xe9, 0x84, 0x48, 0x36, 0x21, 0x9c, 0x0b, 0x4d, 0x34,
-	0x13, 0x5c, 0x59, 0xaf, 0x7f, 0xd7, 0x84, 0xe1, 0xa5, 0xc9, 0xf3, 0x83, 0xe0, 0x3a, 0x23, 0xb1,
-	0xc6, 0x18, 0x5a, 0x79, 0xce, 0x12, 0x07, 0x79, 0x28, 0xe8, 0x85, 0xe6, 0x8c, 0x8f, 0xa0, 0x4d,
-	0x94, 0xa2, 0xda, 0x69, 0x18, 0xd0, 0x1a, 0xf8, 0x29, 0x74, 0xc8, 0x42, 0xe4, 0x5c, 0x3b, 0x4d,
-	0x0f, 0x05, 0x28, 0x2c, 0x2d, 0xfc, 0x12, 0xfa, 0xf6, 0x14, 0x29, 0xa2, 0x95, 0xd3, 0xf2, 0x50,
-	0xd0, 0x0c, 0xc1, 0x42, 0x97, 0x44, 0xab, 0x22, 0x20, 0x4e, 0x19, 0xe5, 0x3a, 0xfa, 0x5d, 0x28,
-	0xed, 0xb4, 0x4d, 0x52, 0xb0, 0xd0, 0x99, 0x50, 0x1a, 0xbf, 0x86, 0xe1, 0x82, 0x64, 0x73, 0xc6,
-	0x23, 0x49, 0x96, 0x51, 0x46, 0xaf, 0x9d, 0x8e, 0x89, 0x19, 0x58, 0xf4, 0x82, 0x2c, 0x43, 0x7a,
-	0x8d, 0xbf, 0x06, 0xcc, 0x38, 0xd3, 0x8c, 0x68, 0xc6, 0xe7, 0xab, 0xc8, 0x27, 0x26, 0x72, 0xb4,
-	0xf6, 0x94, 0xd1, 0x67, 0x80, 0x53, 0xa2, 0x74, 0x94, 0xd1, 0x19, 0x49, 0x09, 0x8f, 0x69, 0x12,
-	0x11, 0xed, 0x74, 0x3d, 0x14, 0xf4, 0x4f, 0xdc, 0xb1, 0x65, 0xc9, 0xb2, 0x32, 0xcb, 0x7f, 0x1b,
-	0x5f, 0x55, 0x34, 0x86, 0xa3, 0xe2, 0x56, 0xb8, 0xba, 0xf4, 0xce, 0xbc, 0x6f, 0xd5, 0x1d, 0x4b,
-	0x9c, 0x9e, 0x87, 0x82, 0x6e, 0x08, 0x55, 0x6b, 0x2c, 0xc1, 0x5f, 0xc2, 0xe1, 0x46, 0x63, 0x2c,
-	0x71, 0xc0, 0x04, 0x0d, 0xeb, 0x5d, 0xb1, 0x04, 0x7f, 0x07, 0x07, 0x71, 0xc9, 0x7b, 0xa4, 0x97,
-	0x92, 0x3a, 0x7d, 0x0f, 0x05, 0xc3, 0x93, 0xa3, 0xb1, 0x55, 0x73, 0x5c, 0x89, 0x72, 0xb5, 0x94,
-	0x34, 0x1c, 0xc4, 0x35, 0xab, 0x68, 0x82, 0xe7, 0x8b, 0x28, 0x97, 0x09, 0xd1, 0x54, 0x39, 0x03,
-	0x4b, 0x32, 0xcf, 0x17, 0x53, 0x8b, 0xf8, 0x7f, 0x21, 0x78, 0x72, 0x41, 0x96, 0x0b, 0xca, 0x35,
-	0x7e, 0x55, 0xab, 0x53, 0x13, 0x77, 0x95, 0x71, 0x5a, 0x88, 0xfc, 0x02, 0x60, 0x2d, 0x9b, 0x51,
-	0xba, 0x19, 0xf6, 0x56, 0xaa, 0x15, 0x8f, 0x92, 0x36, 0x5d, 0x41, 0x73, 0x4e, 0x95, 0x95, 0xbd,
-	0x17, 0x0e, 0x4b, 0x38, 0xb4, 0x28, 0x76, 0xa1, 0x2b, 0x72, 0x3d, 0x13, 0x39, 0x4f, 0x8c, 0xf6,
-	0xdd, 0x70, 0x65, 0xfb, 0x12, 0xda, 0xbf, 0xe4, 0x42, 0x53, 0xfc, 0x05, 0x0c, 0x25, 0xcd, 0xe2,
-	0x22, 0x9b, 0x25, 0xce, 0xb4, 0x84, 0xc2, 0x83, 0x12, 0xfd, 0xd9, 0x80, 0xf7, 0x47, 0xa9, 0xb1,
-	0x6b, 0x94, 0xcc, 0x30, 0x46, 0x32, 0x63, 0x31, 0x2d, 0x07, 0x11, 0x0c, 0x74, 0x51, 0x20, 0xfe,
-	0x1b, 0x68, 0x9b, 0xc3, 0x7a, 0x86, 0x51, 0x7d, 0x86, 0x8f, 0xa0, 0x7d, 0x43, 0xd2, 0x9c, 0x9a,
-	0xd4, 0x28, 0xb4, 0x86, 0xff, 0x0f, 0x02, 0xc7, 0xae, 0xc5, 0x39, 0xbd, 0xad, 0x44, 0xa8, 0xde,
-	0xb7, 0x3b, 0xd1, 0x7a, 0x19, 0x1a, 0x1b, 0xcb, 0x80, 0xa1, 0x65, 0x86, 0xdc, 0x72, 0x65, 0xce,
-	0xdb, 0xb2, 0xb7, 0x1e, 0x2b, 0xbb, 0xff, 0x2f, 0x82, 0xe7, 0x3b, 0x3a, 0x53, 0x52, 0x70, 0x45,
-	0x77, 0xee, 0xee, 0xf6, 0x2e, 0x35, 0x1e, 0xbd, 0x4b, 0xcd, 0x3d, 0xbb, 0xb4, 0xad, 0x5e, 0x6b,
-	0x9f, 0x7a, 0x35, 0x71, 0xda, 0x5b, 0xe2, 0x7c, 0x0b, 0x6e, 0xf9, 0xf5, 0x49, 0x85, 0xa2, 0xf7,
-	0x89, 0xde, 0xf1, 0x1a, 0xff, 0x05, 0x7c, 0xba, 0xf3, 0x86, 0x25, 0xc0, 0x7f, 0x0e, 0xcf, 0xac,
-	0xfb, 0x27, 0xa6, 0xf4, 0xbb, 0xa2, 0x90, 0x2a, 0xb3, 0xf9, 0xa7, 0x95, 0xa4, 0x75, 0x57, 0xc9,
-	0xdb, 0x57, 0x30, 0x52, 0xb9, 0x94, 0x22, 0xd3, 0xc5, 0x57, 0xc1, 0xf8, 0x1c, 0xe4, 0x35, 0x83,
-	0x5e, 0x78, 0xb8, 0xc2, 0xed, 0x95, 0xe3, 0x00, 0x06, 0x75, 0x79, 0x30, 0x40, 0xe7, 0xc7, 0xe9,
-	0xf9, 0xfb, 0xd3, 0xf7, 0xa3, 0x8f, 0xf0, 0x00, 0xba, 0xd3, 0xf3, 0xd2, 0x42, 0x27, 0x77, 0x0d,
-	0xe8, 0x9b, 0x4b, 0xb6, 0x2c, 0xfe, 0x03, 0xfa, 0x35, 0xcd, 0xb0, 0x57, 0xa9, 0xbd, 0x6f, 0xd0,
-	0xdc, 0xcf, 0x1f, 0x88, 0x28, 0xdf, 0xfb, 0xec, 0xcf, 0xff, 0xfe, 0xff, 0xbb, 0xf1, 0xb1, 0x3f,
-	0x98, 0x70, 0x7a, 0x5b, 0x0d, 0xca, 0x5b, 0x74, 0x8c, 0x15, 0x1c, 0x6c, 0x30, 0x84, 0xfd, 0xcd,
-	0x64, 0xbb, 0x08, 0x77, 0x5f, 0x3d, 0x18, 0x53, 0x51, 0x6c, 0x4a, 0x7e, 0xe2, 0x0f, 0x27, 0x71,
-	0xe1, 0xaf, 0x17, 0x9d, 0x03, 0xac, 0xc9, 0xc5, 0x2f, 0x37, 0xb3, 0x6d, 0x29, 0xe2, 0x7a, 0xfb,
-	0x03, 0xca, 0x5a, 0x4f, 0x4d, 0xad, 0x91, 0xdf, 0x9f, 0xa4, 0x4c, 0x69, 0x2b, 0xcc, 0x5b, 0x74,
-	0xfc, 0xfd, 0xeb, 0x5f, 0x7d, 0x92, 0xc5, 0x84, 0xd3, 0x38, 0x5b, 0x4a, 0x2d, 0x26, 0x29, 0xb7,
-	0xbe, 0x6f, 0xec, 0x3f, 0x64, 0x92, 0x92, 0x4c, 0xc6, 0xb3, 0x8e, 0xf9, 0x9a, 0xbf, 0xf9, 0x10,
-	0x00, 0x00, 0xff, 0xff, 0xc6, 0xca, 0xeb, 0xb8, 0x2a, 0x07, 0x00, 0x00,
+// Asset is an asset contracts can be opened in
+type Asset struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// how the price of one bitcoin in the asset is computed from the
+	// instruments the server gets prices for, e.g. "XBTEUR * EURUSD". Empty
+	// if the price is converted from the bitcoin price through exchange rates
+	PriceFormula string `protobuf:"bytes,2,opt,name=price_formula,json=priceFormula,proto3" json:"price_formula,omitempty"`
+	// the instrument the server hedges contracts in the asset with
+	HedgeInstrument string `protobuf:"bytes,3,opt,name=hedge_instrument,json=hedgeInstrument,proto3" json:"hedge_instrument,omitempty"`
+	// the current price of one bitcoin in the asset, 0 if the server has no
+	// price for it yet
+	Price float64 `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	// how many basis points the price has to move before contracts in the
+	// asset are rebalanced
+	RebalanceBps float64 `protobuf:"fixed64,5,opt,name=rebalance_bps,json=rebalanceBps,proto3" json:"rebalance_bps,omitempty"`
+	// the least time between two rebalances
+	MinRebalanceIntervalSeconds int64 `protobuf:"varint,6,opt,name=min_rebalance_interval_seconds,json=minRebalanceIntervalSeconds,proto3" json:"min_rebalance_interval_seconds,omitempty"`
+	// contracts are rebalanced at least this often, even if the price did
+	// not move. 0 if never
+	ForceRebalanceIntervalSeconds int64 `protobuf:"varint,7,opt,name=force_rebalance_interval_seconds,json=forceRebalanceIntervalSeconds,proto3" json:"force_rebalance_interval_seconds,omitempty"`
+	// when contracts in the asset were last rebalanced, unset if never
+	LastRebalancedAt *timestamp.Timestamp `protobuf:"bytes,8,opt,name=last_rebalanced_at,json=lastRebalancedAt,proto3" json:"last_rebalanced_at,omitempty"`
+	// which price of the instruments the asset is priced with, one of
+	// "last", "mark", "index" or "twap"
+	PricingMode string `protobuf:"bytes,9,opt,name=pricing_mode,json=pricingMode,proto3" json:"pricing_mode,omitempty"`
+	// the window prices are averaged over in the twap pricing mode
+	TwapWindowSeconds    int64    `protobuf:"varint,10,opt,name=twap_window_seconds,json=twapWindowSeconds,proto3" json:"twap_window_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// AssetServerClient is the client API for AssetServer service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type AssetServerClient interface {
-	NewContract(ctx context.Context, in *ServerNewContractRequest, opts ...grpc.CallOption) (*ServerNewContractResponse, error)
-	// CloseContract is used to close a contract with a specific uuid
-	CloseContract(ctx context.Context, in *ServerCloseContractRequest, opts ...grpc.CallOption) (*ServerCloseContractResponse, error)
-	// ListAssets lists all supported assets
-	ListAssets(ctx context.Context, in *ServerListAssetsRequest, opts ...grpc.CallOption) (*ServerListAssetsResponse, error)
+func (m *Asset) Reset()         { *m = Asset{} }
+func (m *Asset) String() string { return proto.CompactTextString(m) }
+func (*Asset) ProtoMessage()    {}
+func (*Asset) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{13}
 }
 
-type assetServerClient struct {
-	cc *grpc.ClientConn
+func (m *Asset) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Asset.Unmarshal(m, b)
+}
+func (m *Asset) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Asset.Marshal(b, m, deterministic)
+}
+func (m *Asset) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Asset.Merge(m, src)
+}
+func (m *Asset) XXX_Size() int {
+	return xxx_messageInfo_Asset.Size(m)
+}
+func (m *Asset) XXX_DiscardUnknown() {
+	xxx_messageInfo_Asset.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Asset proto.InternalMessageInfo
+
+func (m *Asset) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Asset) GetPriceFormula() string {
+	if m != nil {
+		return m.PriceFormula
+	}
+	return ""
+}
+
+func (m *Asset) GetHedgeInstrument() string {
+	if m != nil {
+		return m.HedgeInstrument
+	}
+	return ""
+}
+
+func (m *Asset) GetPrice() float64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *Asset) GetRebalanceBps() float64 {
+	if m != nil {
+		return m.RebalanceBps
+	}
+	return 0
+}
+
+func (m *Asset) GetMinRebalanceIntervalSeconds() int64 {
+	if m != nil {
+		return m.MinRebalanceIntervalSeconds
+	}
+	return 0
+}
+
+func (m *Asset) GetForceRebalanceIntervalSeconds() int64 {
+	if m != nil {
+		return m.ForceRebalanceIntervalSeconds
+	}
+	return 0
+}
+
+func (m *Asset) GetLastRebalancedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastRebalancedAt
+	}
+	return nil
+}
+
+func (m *Asset) GetPricingMode() string {
+	if m != nil {
+		return m.PricingMode
+	}
+	return ""
+}
+
+func (m *Asset) GetTwapWindowSeconds() int64 {
+	if m != nil {
+		return m.TwapWindowSeconds
+	}
+	return 0
+}
+
+type ServerSubscribePricesRequest struct {
+	// only stream prices that affect these assets. All prices are streamed
+	// if empty
+	Assets               []string `protobuf:"bytes,1,rep,name=assets,proto3" json:"assets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerSubscribePricesRequest) Reset()         { *m = ServerSubscribePricesRequest{} }
+func (m *ServerSubscribePricesRequest) String() string { return proto.CompactTextString(m) }
+func (*ServerSubscribePricesRequest) ProtoMessage()    {}
+func (*ServerSubscribePricesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{14}
+}
+
+func (m *ServerSubscribePricesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerSubscribePricesRequest.Unmarshal(m, b)
+}
+func (m *ServerSubscribePricesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerSubscribePricesRequest.Marshal(b, m, deterministic)
+}
+func (m *ServerSubscribePricesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerSubscribePricesRequest.Merge(m, src)
+}
+func (m *ServerSubscribePricesRequest) XXX_Size() int {
+	return xxx_messageInfo_ServerSubscribePricesRequest.Size(m)
+}
+func (m *ServerSubscribePricesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerSubscribePricesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerSubscribePricesRequest proto.InternalMessageInfo
+
+func (m *ServerSubscribePricesRequest) GetAssets() []string {
+	if m != nil {
+		return m.Assets
+	}
+	return nil
+}
+
+// ServerPriceUpdate is a price of an instrument accepted by the server, and
+// the asset prices derived from it
+type ServerPriceUpdate struct {
+	Symbol    string               `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Price     float64              `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	Source    string               `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// increases by one for every price the server accepts, across all
+	// instruments
+	Version uint64 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// the price of every asset affected by the price, limited to the
+	// assets subscribed to. They are not signed, as only the prices
+	// contracts are rebalanced on are signed, and sent along with the
+	// rebalance
+	Attestations         []*PriceAttestation `protobuf:"bytes,6,rep,name=attestations,proto3" json:"attestations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *ServerPriceUpdate) Reset()         { *m = ServerPriceUpdate{} }
+func (m *ServerPriceUpdate) String() string { return proto.CompactTextString(m) }
+func (*ServerPriceUpdate) ProtoMessage()    {}
+func (*ServerPriceUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{15}
+}
+
+func (m *ServerPriceUpdate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerPriceUpdate.Unmarshal(m, b)
+}
+func (m *ServerPriceUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerPriceUpdate.Marshal(b, m, deterministic)
+}
+func (m *ServerPriceUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerPriceUpdate.Merge(m, src)
+}
+func (m *ServerPriceUpdate) XXX_Size() int {
+	return xxx_messageInfo_ServerPriceUpdate.Size(m)
+}
+func (m *ServerPriceUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerPriceUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerPriceUpdate proto.InternalMessageInfo
+
+func (m *ServerPriceUpdate) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *ServerPriceUpdate) GetPrice() float64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *ServerPriceUpdate) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *ServerPriceUpdate) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *ServerPriceUpdate) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ServerPriceUpdate) GetAttestations() []*PriceAttestation {
+	if m != nil {
+		return m.Attestations
+	}
+	return nil
+}
+
+// PriceTick is a single price accepted by the server
+type PriceTick struct {
+	Symbol               string               `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Price                float64              `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	Source               string               `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Timestamp            *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PriceTick) Reset()         { *m = PriceTick{} }
+func (m *PriceTick) String() string { return proto.CompactTextString(m) }
+func (*PriceTick) ProtoMessage()    {}
+func (*PriceTick) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{16}
+}
+
+func (m *PriceTick) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PriceTick.Unmarshal(m, b)
+}
+func (m *PriceTick) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PriceTick.Marshal(b, m, deterministic)
+}
+func (m *PriceTick) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PriceTick.Merge(m, src)
+}
+func (m *PriceTick) XXX_Size() int {
+	return xxx_messageInfo_PriceTick.Size(m)
+}
+func (m *PriceTick) XXX_DiscardUnknown() {
+	xxx_messageInfo_PriceTick.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PriceTick proto.InternalMessageInfo
+
+func (m *PriceTick) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *PriceTick) GetPrice() float64 {
+	if m != nil {
+		return m.Price
+	}
+	return 0
+}
+
+func (m *PriceTick) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *PriceTick) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+// PriceCandle aggregates all ticks within an interval
+type PriceCandle struct {
+	Symbol string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// the start of the interval the candle covers
+	Start                *timestamp.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	Open                 float64              `protobuf:"fixed64,3,opt,name=open,proto3" json:"open,omitempty"`
+	High                 float64              `protobuf:"fixed64,4,opt,name=high,proto3" json:"high,omitempty"`
+	Low                  float64              `protobuf:"fixed64,5,opt,name=low,proto3" json:"low,omitempty"`
+	Close                float64              `protobuf:"fixed64,6,opt,name=close,proto3" json:"close,omitempty"`
+	NumTicks             int64                `protobuf:"varint,7,opt,name=num_ticks,json=numTicks,proto3" json:"num_ticks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PriceCandle) Reset()         { *m = PriceCandle{} }
+func (m *PriceCandle) String() string { return proto.CompactTextString(m) }
+func (*PriceCandle) ProtoMessage()    {}
+func (*PriceCandle) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{17}
+}
+
+func (m *PriceCandle) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PriceCandle.Unmarshal(m, b)
+}
+func (m *PriceCandle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PriceCandle.Marshal(b, m, deterministic)
+}
+func (m *PriceCandle) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PriceCandle.Merge(m, src)
+}
+func (m *PriceCandle) XXX_Size() int {
+	return xxx_messageInfo_PriceCandle.Size(m)
+}
+func (m *PriceCandle) XXX_DiscardUnknown() {
+	xxx_messageInfo_PriceCandle.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PriceCandle proto.InternalMessageInfo
+
+func (m *PriceCandle) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *PriceCandle) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *PriceCandle) GetOpen() float64 {
+	if m != nil {
+		return m.Open
+	}
+	return 0
+}
+
+func (m *PriceCandle) GetHigh() float64 {
+	if m != nil {
+		return m.High
+	}
+	return 0
+}
+
+func (m *PriceCandle) GetLow() float64 {
+	if m != nil {
+		return m.Low
+	}
+	return 0
+}
+
+func (m *PriceCandle) GetClose() float64 {
+	if m != nil {
+		return m.Close
+	}
+	return 0
+}
+
+func (m *PriceCandle) GetNumTicks() int64 {
+	if m != nil {
+		return m.NumTicks
+	}
+	return 0
+}
+
+type ServerGetPriceHistoryRequest struct {
+	// the symbol to get prices for, defaults to XBTUSD
+	Symbol   string         `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Interval CandleInterval `protobuf:"varint,2,opt,name=interval,proto3,enum=ladrpc.CandleInterval" json:"interval,omitempty"`
+	// start defaults to 24 hours before end, and end defaults to now
+	Start *timestamp.Timestamp `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End   *timestamp.Timestamp `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	// the max number of ticks or candles to return, defaults to 1000
+	Limit                uint32   `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerGetPriceHistoryRequest) Reset()         { *m = ServerGetPriceHistoryRequest{} }
+func (m *ServerGetPriceHistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*ServerGetPriceHistoryRequest) ProtoMessage()    {}
+func (*ServerGetPriceHistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{18}
+}
+
+func (m *ServerGetPriceHistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerGetPriceHistoryRequest.Unmarshal(m, b)
+}
+func (m *ServerGetPriceHistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerGetPriceHistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *ServerGetPriceHistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerGetPriceHistoryRequest.Merge(m, src)
+}
+func (m *ServerGetPriceHistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_ServerGetPriceHistoryRequest.Size(m)
+}
+func (m *ServerGetPriceHistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerGetPriceHistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerGetPriceHistoryRequest proto.InternalMessageInfo
+
+func (m *ServerGetPriceHistoryRequest) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *ServerGetPriceHistoryRequest) GetInterval() CandleInterval {
+	if m != nil {
+		return m.Interval
+	}
+	return CandleInterval_TICK
+}
+
+func (m *ServerGetPriceHistoryRequest) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *ServerGetPriceHistoryRequest) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *ServerGetPriceHistoryRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type ServerGetPriceHistoryResponse struct {
+	// ticks is only set if the interval is TICK, otherwise candles is set
+	Ticks   []*PriceTick   `protobuf:"bytes,1,rep,name=ticks,proto3" json:"ticks,omitempty"`
+	Candles []*PriceCandle `protobuf:"bytes,2,rep,name=candles,proto3" json:"candles,omitempty"`
+	// the signed prices the server rebalanced contracts on in the period
+	Attestations         []*PriceAttestation `protobuf:"bytes,3,rep,name=attestations,proto3" json:"attestations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *ServerGetPriceHistoryResponse) Reset()         { *m = ServerGetPriceHistoryResponse{} }
+func (m *ServerGetPriceHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*ServerGetPriceHistoryResponse) ProtoMessage()    {}
+func (*ServerGetPriceHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{19}
+}
+
+func (m *ServerGetPriceHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerGetPriceHistoryResponse.Unmarshal(m, b)
+}
+func (m *ServerGetPriceHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerGetPriceHistoryResponse.Marshal(b, m, deterministic)
+}
+func (m *ServerGetPriceHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerGetPriceHistoryResponse.Merge(m, src)
+}
+func (m *ServerGetPriceHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_ServerGetPriceHistoryResponse.Size(m)
+}
+func (m *ServerGetPriceHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerGetPriceHistoryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerGetPriceHistoryResponse proto.InternalMessageInfo
+
+func (m *ServerGetPriceHistoryResponse) GetTicks() []*PriceTick {
+	if m != nil {
+		return m.Ticks
+	}
+	return nil
+}
+
+func (m *ServerGetPriceHistoryResponse) GetCandles() []*PriceCandle {
+	if m != nil {
+		return m.Candles
+	}
+	return nil
+}
+
+func (m *ServerGetPriceHistoryResponse) GetAttestations() []*PriceAttestation {
+	if m != nil {
+		return m.Attestations
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("ladrpc.ContractType", ContractType_name, ContractType_value)
+	proto.RegisterEnum("ladrpc.CandleInterval", CandleInterval_name, CandleInterval_value)
+	proto.RegisterType((*ServerContract)(nil), "ladrpc.ServerContract")
+	proto.RegisterType((*Payment)(nil), "ladrpc.Payment")
+	proto.RegisterType((*Quote)(nil), "ladrpc.Quote")
+	proto.RegisterType((*Price)(nil), "ladrpc.Price")
+	proto.RegisterType((*PriceAttestation)(nil), "ladrpc.PriceAttestation")
+	proto.RegisterType((*PriceInput)(nil), "ladrpc.PriceInput")
+	proto.RegisterType((*FxRate)(nil), "ladrpc.FxRate")
+	proto.RegisterType((*ServerNewContractRequest)(nil), "ladrpc.ServerNewContractRequest")
+	proto.RegisterType((*ServerNewContractResponse)(nil), "ladrpc.ServerNewContractResponse")
+	proto.RegisterType((*ServerCloseContractRequest)(nil), "ladrpc.ServerCloseContractRequest")
+	proto.RegisterType((*ServerCloseContractResponse)(nil), "ladrpc.ServerCloseContractResponse")
+	proto.RegisterType((*ServerListAssetsRequest)(nil), "ladrpc.ServerListAssetsRequest")
+	proto.RegisterType((*ServerListAssetsResponse)(nil), "ladrpc.ServerListAssetsResponse")
+	proto.RegisterType((*Asset)(nil), "ladrpc.Asset")
+	proto.RegisterType((*ServerSubscribePricesRequest)(nil), "ladrpc.ServerSubscribePricesRequest")
+	proto.RegisterType((*ServerPriceUpdate)(nil), "ladrpc.ServerPriceUpdate")
+	proto.RegisterType((*PriceTick)(nil), "ladrpc.PriceTick")
+	proto.RegisterType((*PriceCandle)(nil), "ladrpc.PriceCandle")
+	proto.RegisterType((*ServerGetPriceHistoryRequest)(nil), "ladrpc.ServerGetPriceHistoryRequest")
+	proto.RegisterType((*ServerGetPriceHistoryResponse)(nil), "ladrpc.ServerGetPriceHistoryResponse")
+}
+
+func init() { proto.RegisterFile("server.proto", fileDescriptor_ad098daeda4239f7) }
+
+var fileDescriptor_ad098daeda4239f7 = []byte{
+	// 1657 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x58, 0xcd, 0x6e, 0x1b, 0xc9,
+	0x11, 0xde, 0xe1, 0x3f, 0x8b, 0x3f, 0xa2, 0xda, 0x8e, 0x77, 0x4c, 0xdb, 0x59, 0xee, 0xd8, 0xc6,
+	0x72, 0x85, 0x5d, 0xd1, 0xd0, 0x02, 0x49, 0x76, 0x91, 0x8b, 0x56, 0x5e, 0xaf, 0x84, 0xc4, 0x8a,
+	0x77, 0x24, 0x21, 0x48, 0x2e, 0x44, 0x73, 0xd8, 0xa2, 0x1a, 0x1a, 0xce, 0x8c, 0xa6, 0x7b, 0x24,
+	0xf3, 0x9a, 0x43, 0x2e, 0xc9, 0x29, 0x39, 0x24, 0xef, 0x90, 0x73, 0x2e, 0x39, 0x27, 0x0f, 0x10,
+	0xe4, 0x05, 0x72, 0xc8, 0x2d, 0x2f, 0x11, 0x74, 0x75, 0xcf, 0x70, 0xf8, 0x27, 0x79, 0x81, 0x00,
+	0x3e, 0x71, 0xaa, 0xfa, 0xeb, 0xea, 0xea, 0xea, 0xfa, 0xaa, 0x0a, 0x84, 0xa6, 0x60, 0xf1, 0x35,
+	0x8b, 0x77, 0xa3, 0x38, 0x94, 0x21, 0xa9, 0xf8, 0x74, 0x1c, 0x47, 0x5e, 0x77, 0x4b, 0xf2, 0x29,
+	0x13, 0x92, 0x4e, 0x23, 0xbd, 0xd0, 0x7d, 0x3c, 0x09, 0xc3, 0x89, 0xcf, 0x06, 0x34, 0xe2, 0x03,
+	0x1a, 0x04, 0xa1, 0xa4, 0x92, 0x87, 0x81, 0xd0, 0xab, 0xce, 0x5f, 0xca, 0xd0, 0x3e, 0x41, 0x3b,
+	0x07, 0x61, 0x20, 0x63, 0xea, 0x49, 0x42, 0xa0, 0x94, 0x24, 0x7c, 0x6c, 0x5b, 0x3d, 0xab, 0x5f,
+	0x77, 0xf1, 0x9b, 0xdc, 0x87, 0x32, 0x15, 0x82, 0x49, 0xbb, 0x80, 0x4a, 0x2d, 0x90, 0x07, 0x50,
+	0xa1, 0xd3, 0x30, 0x09, 0xa4, 0x5d, 0xec, 0x59, 0x7d, 0xcb, 0x35, 0x12, 0xf9, 0x08, 0x1a, 0xfa,
+	0x6b, 0x28, 0xa8, 0x14, 0x76, 0xa9, 0x67, 0xf5, 0x8b, 0x2e, 0x68, 0xd5, 0x09, 0x95, 0x42, 0x01,
+	0x3c, 0x9f, 0xb3, 0x40, 0x0e, 0x2f, 0x42, 0x21, 0xed, 0x32, 0x1a, 0x05, 0xad, 0x3a, 0x0c, 0x85,
+	0x24, 0xcf, 0xa0, 0x3d, 0xa5, 0xf1, 0x84, 0x07, 0xc3, 0x88, 0xce, 0x86, 0x31, 0xbb, 0xb2, 0x2b,
+	0x88, 0x69, 0x6a, 0xed, 0x1b, 0x3a, 0x73, 0xd9, 0x15, 0xf9, 0x0c, 0x08, 0x0f, 0xb8, 0xe4, 0x54,
+	0xf2, 0x60, 0x92, 0x21, 0xab, 0x88, 0xec, 0xcc, 0x57, 0x0c, 0xfa, 0x10, 0x88, 0x4f, 0x85, 0x1c,
+	0xc6, 0x6c, 0x44, 0x7d, 0x1a, 0x78, 0x6c, 0x3c, 0xa4, 0xd2, 0xae, 0xf5, 0xac, 0x7e, 0x63, 0xaf,
+	0xbb, 0xab, 0xa3, 0xa4, 0xa3, 0x32, 0x4a, 0xce, 0x77, 0x4f, 0xd3, 0x30, 0xba, 0x1d, 0xb5, 0xcb,
+	0xcd, 0x36, 0xed, 0xe3, 0xfd, 0x32, 0xef, 0xf8, 0xd8, 0xae, 0xf7, 0xac, 0x7e, 0xcd, 0x85, 0xd4,
+	0x35, 0x3e, 0x26, 0x9f, 0xc0, 0xd6, 0x82, 0x63, 0x7c, 0x6c, 0x03, 0x82, 0xda, 0x79, 0xaf, 0xf8,
+	0x98, 0x7c, 0x09, 0x2d, 0xcf, 0xc4, 0x7d, 0x28, 0x67, 0x11, 0xb3, 0x1b, 0x3d, 0xab, 0xdf, 0xde,
+	0xbb, 0xbf, 0xab, 0x5f, 0x73, 0x37, 0x7d, 0x94, 0xd3, 0x59, 0xc4, 0xdc, 0xa6, 0x97, 0x93, 0x94,
+	0x13, 0x41, 0x32, 0x1d, 0x26, 0xd1, 0x98, 0x4a, 0x26, 0xec, 0xa6, 0x0e, 0x72, 0x90, 0x4c, 0xcf,
+	0xb4, 0x86, 0x7c, 0x0c, 0xcd, 0x28, 0xe6, 0x9e, 0xf2, 0x60, 0x1a, 0x8e, 0x99, 0xdd, 0xc2, 0xb8,
+	0x34, 0x8c, 0xee, 0x75, 0x38, 0x46, 0x1b, 0x17, 0x6c, 0x3c, 0x61, 0x43, 0xa5, 0x64, 0x76, 0x1b,
+	0x5f, 0x11, 0x50, 0xf5, 0x46, 0x69, 0xc8, 0x23, 0xa8, 0x6b, 0xc0, 0x95, 0x9c, 0xd9, 0x5b, 0xb8,
+	0x5c, 0x43, 0xc5, 0x77, 0x72, 0x46, 0x7e, 0x0c, 0xf5, 0x30, 0x62, 0x81, 0x8e, 0x63, 0xe7, 0xce,
+	0x38, 0xd6, 0x34, 0x78, 0x5f, 0xaa, 0x8d, 0x9e, 0x1f, 0x0a, 0xbd, 0x71, 0xfb, 0xee, 0x8d, 0x1a,
+	0xbc, 0x2f, 0x9d, 0x3f, 0x58, 0x50, 0x7d, 0x43, 0x67, 0x53, 0x16, 0x48, 0xf2, 0x34, 0x17, 0xba,
+	0x5c, 0xbe, 0x66, 0x41, 0x3a, 0x53, 0x79, 0xfb, 0x04, 0x60, 0x9e, 0x89, 0x98, 0xbc, 0x45, 0xb7,
+	0x9e, 0x25, 0xa2, 0x7a, 0xa7, 0x48, 0x9b, 0x53, 0x99, 0x93, 0x30, 0xa1, 0x33, 0xb9, 0xee, 0xb6,
+	0x8d, 0xda, 0xd5, 0x5a, 0xd2, 0x85, 0x5a, 0x98, 0xc8, 0x51, 0x98, 0x04, 0x63, 0x4c, 0xe7, 0x9a,
+	0x9b, 0xc9, 0x4e, 0x04, 0xe5, 0xef, 0x92, 0x50, 0x32, 0xf2, 0x1c, 0xda, 0x11, 0x8b, 0x3d, 0x65,
+	0x4d, 0xe7, 0x02, 0xba, 0x64, 0xb9, 0x2d, 0xa3, 0x7d, 0x8d, 0xca, 0x65, 0x76, 0x14, 0xd6, 0xb1,
+	0x03, 0xf9, 0x65, 0x5e, 0x45, 0x73, 0x0b, 0x50, 0x85, 0xaf, 0xe2, 0x7c, 0x01, 0x65, 0xfc, 0x98,
+	0xd3, 0xd2, 0xca, 0xd3, 0xf2, 0x3e, 0x94, 0xaf, 0xa9, 0x9f, 0x30, 0x34, 0x6d, 0xb9, 0x5a, 0x70,
+	0xfe, 0x56, 0x84, 0x0e, 0xee, 0xda, 0x97, 0x92, 0x09, 0x5d, 0x05, 0x14, 0x83, 0xc5, 0x6c, 0x3a,
+	0x0a, 0x7d, 0x63, 0xc1, 0x48, 0xca, 0x84, 0x3e, 0xdc, 0x98, 0x40, 0x01, 0xd1, 0x61, 0x12, 0x1b,
+	0x9f, 0xea, 0xae, 0x91, 0xc8, 0x4f, 0xa0, 0x9e, 0x55, 0x1d, 0xbb, 0x74, 0xe7, 0x7b, 0xce, 0xc1,
+	0xf3, 0x0b, 0x94, 0xf3, 0x17, 0x58, 0x0a, 0x40, 0x65, 0x39, 0x00, 0xe4, 0x31, 0xd4, 0x05, 0x9f,
+	0x04, 0x54, 0x26, 0x31, 0x33, 0x7c, 0x9f, 0x2b, 0x94, 0x9b, 0x51, 0x32, 0xba, 0x64, 0x33, 0x24,
+	0x77, 0xdd, 0x35, 0x12, 0xd9, 0x81, 0x0a, 0x0f, 0xa2, 0x44, 0x0a, 0xbb, 0xde, 0x2b, 0xf6, 0x1b,
+	0x7b, 0x24, 0x65, 0x19, 0x1a, 0x3d, 0x52, 0x4b, 0xae, 0x41, 0x90, 0x4f, 0xa1, 0x76, 0xfe, 0x76,
+	0x18, 0x23, 0xb5, 0x00, 0xd1, 0xed, 0x14, 0xfd, 0xea, 0xad, 0x4b, 0x25, 0x73, 0xab, 0xe7, 0xf8,
+	0x2b, 0x88, 0x0d, 0x55, 0xc3, 0x29, 0x64, 0x6f, 0xdd, 0x4d, 0x45, 0xb2, 0x0b, 0xf7, 0xe4, 0x0d,
+	0x8d, 0x86, 0x37, 0x3c, 0x18, 0x87, 0x37, 0x43, 0xc1, 0xbc, 0x30, 0x18, 0xa7, 0x54, 0xdd, 0x56,
+	0x4b, 0xbf, 0xc4, 0x95, 0x13, 0xbd, 0xa0, 0x2c, 0x9d, 0x87, 0xf1, 0x34, 0xf1, 0xa9, 0x21, 0x6b,
+	0x2a, 0x3a, 0xbf, 0xb7, 0x00, 0xe6, 0x5e, 0xbe, 0xef, 0x67, 0x73, 0xfe, 0x6c, 0x41, 0x45, 0x87,
+	0x41, 0x75, 0x8b, 0x11, 0x15, 0x2c, 0xed, 0x16, 0xea, 0x5b, 0xb9, 0x71, 0xa5, 0x18, 0x91, 0x76,
+	0x0b, 0x14, 0x14, 0x52, 0xc5, 0xd3, 0xe4, 0x33, 0x7e, 0xe7, 0x5c, 0x2b, 0x6d, 0x76, 0xad, 0xfc,
+	0x7d, 0x5c, 0xfb, 0x93, 0x05, 0xb6, 0x6e, 0x68, 0xc7, 0xec, 0x26, 0x2d, 0x9f, 0x29, 0x8d, 0xd7,
+	0xf3, 0x65, 0xde, 0xc6, 0x0a, 0x0b, 0x6d, 0x8c, 0x40, 0x09, 0xdb, 0x93, 0x8e, 0x1a, 0x7e, 0xaf,
+	0x16, 0xec, 0xd2, 0xbb, 0x16, 0x6c, 0xe7, 0xb7, 0x05, 0x78, 0xb8, 0xc6, 0x33, 0x11, 0x85, 0x81,
+	0x60, 0x6b, 0xbb, 0xee, 0x6a, 0x17, 0x2c, 0xbc, 0x73, 0x17, 0x2c, 0x6e, 0xe8, 0x82, 0xab, 0x45,
+	0xaa, 0xb4, 0xa9, 0x48, 0xe5, 0x28, 0x58, 0x5e, 0xa1, 0xe0, 0x72, 0x77, 0xa9, 0xac, 0x76, 0x97,
+	0x79, 0x5c, 0xab, 0xf9, 0xb8, 0x3a, 0x2f, 0xa0, 0x6b, 0x46, 0x0e, 0x55, 0xd7, 0x97, 0xdf, 0x68,
+	0x4d, 0x20, 0x9c, 0x27, 0xf0, 0x68, 0xed, 0x0e, 0x1d, 0x3b, 0xe7, 0x21, 0x7c, 0xa8, 0x97, 0x7f,
+	0xce, 0x85, 0xdc, 0x57, 0x3e, 0x0a, 0x63, 0xcd, 0xf1, 0xc1, 0x5e, 0x5d, 0x32, 0x21, 0xff, 0x14,
+	0x3a, 0x22, 0x89, 0xa2, 0x30, 0x96, 0xaa, 0x13, 0xe1, 0x9a, 0x6d, 0xf5, 0x8a, 0xfd, 0xba, 0xbb,
+	0x95, 0xe9, 0xf5, 0x16, 0xf2, 0x1c, 0x2a, 0x06, 0x50, 0xc0, 0x62, 0xd0, 0x4a, 0xdf, 0x1b, 0xd7,
+	0x5d, 0xb3, 0xe8, 0xfc, 0xb3, 0x08, 0x65, 0xd4, 0xa8, 0x5b, 0x04, 0x74, 0x9a, 0xd1, 0x42, 0x7d,
+	0xab, 0x8e, 0x85, 0xd1, 0x1c, 0xa6, 0x24, 0x37, 0xaf, 0x89, 0xca, 0x57, 0x5a, 0xa7, 0x9c, 0xd2,
+	0x1d, 0x97, 0x07, 0x42, 0xc6, 0x89, 0x6a, 0x42, 0xe6, 0x2d, 0xb7, 0x50, 0x7f, 0x94, 0xa9, 0xe7,
+	0x6c, 0x2f, 0xe5, 0xd9, 0xfe, 0x14, 0x5a, 0xd9, 0x84, 0x33, 0x1c, 0x45, 0xc2, 0xbc, 0x5d, 0x33,
+	0x53, 0x7e, 0x1d, 0x09, 0x72, 0x00, 0x3f, 0x9c, 0xf2, 0x60, 0x3e, 0x0a, 0x0d, 0x79, 0x20, 0x59,
+	0x7c, 0x4d, 0xfd, 0xac, 0x48, 0x55, 0xb0, 0x48, 0x3d, 0x9a, 0xf2, 0x20, 0x1b, 0x7d, 0x8e, 0x0c,
+	0x26, 0x2d, 0x57, 0xdf, 0x42, 0xef, 0x3c, 0x8c, 0x3d, 0x76, 0x9b, 0x99, 0x2a, 0x9a, 0x79, 0x82,
+	0xb8, 0x8d, 0x86, 0xfe, 0x7f, 0x93, 0xd9, 0x72, 0x56, 0xd6, 0x57, 0xb3, 0x72, 0x43, 0x51, 0x86,
+	0x0d, 0x45, 0xd9, 0xf9, 0x11, 0x3c, 0xd6, 0x19, 0x74, 0x92, 0x8c, 0x84, 0x17, 0xf3, 0x91, 0x1e,
+	0x8d, 0xd2, 0x0c, 0xc3, 0x2c, 0xcf, 0xe7, 0x4e, 0x9a, 0x0b, 0xff, 0xb5, 0x60, 0x5b, 0x6f, 0x44,
+	0xbc, 0x9e, 0xca, 0xde, 0x7b, 0xc3, 0xb5, 0xa1, 0x7a, 0xcd, 0x62, 0xc1, 0xc3, 0x00, 0xf3, 0xa2,
+	0xe4, 0xa6, 0x22, 0xf9, 0x29, 0x34, 0xe9, 0x7c, 0x32, 0x50, 0x09, 0xa0, 0x12, 0xdd, 0x5e, 0xe8,
+	0x91, 0xb9, 0xd1, 0xc1, 0x5d, 0x40, 0x3b, 0xbf, 0xb3, 0xa0, 0x8e, 0x90, 0x53, 0xee, 0x5d, 0xbe,
+	0xf7, 0xfe, 0xf4, 0x77, 0x0b, 0x1a, 0xe8, 0xcd, 0x01, 0x0d, 0xc6, 0xfe, 0xe6, 0xa8, 0xbf, 0x80,
+	0xb2, 0x90, 0x34, 0xd6, 0x85, 0xff, 0x76, 0xeb, 0x1a, 0xa8, 0x78, 0xad, 0xc6, 0xd8, 0xb4, 0x89,
+	0xa9, 0x6f, 0xec, 0x13, 0x7c, 0x72, 0x61, 0x68, 0x88, 0xdf, 0xa4, 0x03, 0x45, 0x3f, 0xbc, 0x31,
+	0xdc, 0x53, 0x9f, 0xea, 0xee, 0x38, 0xc7, 0x9a, 0x71, 0x46, 0x0b, 0x6a, 0xc0, 0x56, 0x53, 0xbc,
+	0xe4, 0xde, 0x65, 0x4a, 0x96, 0x5a, 0x90, 0x4c, 0x55, 0x14, 0x85, 0xf3, 0x6f, 0x2b, 0xcd, 0xbd,
+	0x6f, 0x4d, 0xd9, 0x3d, 0xe4, 0x42, 0x86, 0xf1, 0x2c, 0x97, 0x7b, 0x6b, 0xef, 0xb5, 0x07, 0xb5,
+	0x94, 0x89, 0x78, 0xb5, 0xf6, 0xde, 0x83, 0xac, 0x41, 0x61, 0x44, 0x52, 0x06, 0xba, 0x19, 0x6e,
+	0x1e, 0x8b, 0xe2, 0xbb, 0xc6, 0xe2, 0x33, 0x28, 0x32, 0x33, 0x0f, 0xdf, 0x8e, 0x57, 0x30, 0x75,
+	0x7f, 0x9f, 0x4f, 0xb9, 0x1e, 0xf5, 0x5a, 0xae, 0x16, 0x9c, 0xbf, 0x5a, 0xf0, 0x64, 0xc3, 0x15,
+	0x4d, 0x95, 0xfe, 0x04, 0xca, 0x3a, 0x3a, 0x16, 0x26, 0xe4, 0xf6, 0x42, 0x42, 0xaa, 0x38, 0xb9,
+	0x7a, 0x9d, 0x7c, 0x0e, 0x55, 0x0f, 0x2f, 0x97, 0x16, 0xe9, 0x7b, 0x0b, 0x50, 0x7d, 0x71, 0x37,
+	0xc5, 0xac, 0xe4, 0x7b, 0xf1, 0xfb, 0xe4, 0xfb, 0x4e, 0x1f, 0x9a, 0xf9, 0x56, 0x4f, 0x00, 0x2a,
+	0xaf, 0xce, 0x8e, 0x5f, 0x7e, 0xf3, 0xb2, 0xf3, 0x01, 0x69, 0x42, 0xed, 0xec, 0xd8, 0x48, 0xd6,
+	0xce, 0x97, 0xd0, 0x5e, 0x8c, 0x39, 0xa9, 0x41, 0xe9, 0xf4, 0xe8, 0xe0, 0x67, 0x9d, 0x0f, 0xd4,
+	0xae, 0xd7, 0x47, 0xc7, 0x67, 0xa7, 0xdf, 0x74, 0x2c, 0xa5, 0x3d, 0xfc, 0xc5, 0x99, 0xdb, 0x29,
+	0x90, 0x2a, 0x14, 0x5f, 0xee, 0xff, 0xaa, 0x53, 0xdc, 0xfb, 0x47, 0x09, 0x1a, 0xd8, 0x4e, 0x74,
+	0x84, 0xc8, 0x25, 0x34, 0x72, 0xa3, 0x03, 0xe9, 0xa5, 0xbe, 0x6e, 0x9a, 0x77, 0xba, 0x1f, 0xdf,
+	0x82, 0x30, 0xbd, 0xf3, 0xc3, 0xdf, 0xfc, 0xeb, 0x3f, 0x7f, 0x2c, 0x6c, 0x3b, 0xcd, 0x41, 0xc0,
+	0x6e, 0xd2, 0x79, 0xe5, 0x2b, 0x6b, 0x87, 0x08, 0x68, 0x2d, 0x74, 0x5b, 0xe2, 0x2c, 0x1a, 0x5b,
+	0xd7, 0xbc, 0xbb, 0x4f, 0x6f, 0xc5, 0xa4, 0xed, 0x1a, 0x8f, 0xbc, 0xe7, 0xb4, 0x07, 0xc8, 0x81,
+	0xfc, 0xa1, 0x13, 0x80, 0x79, 0xa3, 0x26, 0x1f, 0x2d, 0x5a, 0x5b, 0xe9, 0xee, 0xdd, 0xde, 0x66,
+	0x80, 0x39, 0xeb, 0x01, 0x9e, 0xd5, 0x71, 0x1a, 0x03, 0x9f, 0x0b, 0xa9, 0x4b, 0xb3, 0x3a, 0xe8,
+	0x0a, 0xb6, 0x96, 0xea, 0x39, 0x79, 0xb6, 0x68, 0x6c, 0x7d, 0xb9, 0xef, 0x3e, 0x5c, 0x44, 0xe5,
+	0x6a, 0xbb, 0xf3, 0x08, 0xcf, 0xfa, 0x81, 0xd3, 0x19, 0x88, 0x74, 0x2f, 0x16, 0x38, 0x75, 0xe0,
+	0x0b, 0x8b, 0xbc, 0x85, 0xad, 0xa5, 0x1c, 0x5f, 0x3e, 0x72, 0x3d, 0xcb, 0xbb, 0xcf, 0xef, 0x40,
+	0x99, 0xab, 0xda, 0x78, 0x3c, 0x71, 0x5a, 0x03, 0x3c, 0xf5, 0x42, 0x2f, 0x7f, 0x65, 0xed, 0x7c,
+	0xfd, 0xec, 0xd7, 0x0e, 0x8d, 0x3d, 0x1a, 0x30, 0x2f, 0x9e, 0x45, 0x32, 0x1c, 0xf8, 0x81, 0x0e,
+	0xc4, 0xe7, 0xfa, 0x1f, 0x97, 0x81, 0x4f, 0xe3, 0xc8, 0x1b, 0x55, 0x90, 0xb9, 0x5f, 0xfc, 0x6f,
+	0x00, 0x0a, 0x33, 0x5e, 0x3f, 0x58, 0x12, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AssetServerClient is the client API for AssetServer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AssetServerClient interface {
+	NewContract(ctx context.Context, in *ServerNewContractRequest, opts ...grpc.CallOption) (*ServerNewContractResponse, error)
+	// CloseContract is used to close a contract with a specific uuid
+	CloseContract(ctx context.Context, in *ServerCloseContractRequest, opts ...grpc.CallOption) (*ServerCloseContractResponse, error)
+	// ListAssets lists all supported assets
+	ListAssets(ctx context.Context, in *ServerListAssetsRequest, opts ...grpc.CallOption) (*ServerListAssetsResponse, error)
+	// SubscribePrices streams every price change the server accepts. The
+	// latest price of every instrument is sent right away
+	SubscribePrices(ctx context.Context, in *ServerSubscribePricesRequest, opts ...grpc.CallOption) (AssetServer_SubscribePricesClient, error)
+	// GetPriceHistory returns the prices the server has accepted in a period,
+	// either as single ticks or aggregated into candles, along with the
+	// signed prices contracts were rebalanced on
+	GetPriceHistory(ctx context.Context, in *ServerGetPriceHistoryRequest, opts ...grpc.CallOption) (*ServerGetPriceHistoryResponse, error)
+}
+
+type assetServerClient struct {
+	cc *grpc.ClientConn
 }
 
 func NewAssetServerClient(cc *grpc.ClientConn) AssetServerClient {
@@ -748,6 +1759,47 @@ func (c *assetServerClient) ListAssets(ctx context.Context, in *ServerListAssets
 	return out, nil
 }
 
+func (c *assetServerClient) SubscribePrices(ctx context.Context, in *ServerSubscribePricesRequest, opts ...grpc.CallOption) (AssetServer_SubscribePricesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AssetServer_serviceDesc.Streams[0], "/ladrpc.AssetServer/SubscribePrices", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &assetServerSubscribePricesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AssetServer_SubscribePricesClient interface {
+	Recv() (*ServerPriceUpdate, error)
+	grpc.ClientStream
+}
+
+type assetServerSubscribePricesClient struct {
+	grpc.ClientStream
+}
+
+func (x *assetServerSubscribePricesClient) Recv() (*ServerPriceUpdate, error) {
+	m := new(ServerPriceUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *assetServerClient) GetPriceHistory(ctx context.Context, in *ServerGetPriceHistoryRequest, opts ...grpc.CallOption) (*ServerGetPriceHistoryResponse, error) {
+	out := new(ServerGetPriceHistoryResponse)
+	err := c.cc.Invoke(ctx, "/ladrpc.AssetServer/GetPriceHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AssetServerServer is the server API for AssetServer service.
 type AssetServerServer interface {
 	NewContract(context.Context, *ServerNewContractRequest) (*ServerNewContractResponse, error)
@@ -755,6 +1807,13 @@ type AssetServerServer interface {
 	CloseContract(context.Context, *ServerCloseContractRequest) (*ServerCloseContractResponse, error)
 	// ListAssets lists all supported assets
 	ListAssets(context.Context, *ServerListAssetsRequest) (*ServerListAssetsResponse, error)
+	// SubscribePrices streams every price change the server accepts. The
+	// latest price of every instrument is sent right away
+	SubscribePrices(*ServerSubscribePricesRequest, AssetServer_SubscribePricesServer) error
+	// GetPriceHistory returns the prices the server has accepted in a period,
+	// either as single ticks or aggregated into candles, along with the
+	// signed prices contracts were rebalanced on
+	GetPriceHistory(context.Context, *ServerGetPriceHistoryRequest) (*ServerGetPriceHistoryResponse, error)
 }
 
 // UnimplementedAssetServerServer can be embedded to have forward compatible implementations.
@@ -770,6 +1829,12 @@ func (*UnimplementedAssetServerServer) CloseContract(ctx context.Context, req *S
 func (*UnimplementedAssetServerServer) ListAssets(ctx context.Context, req *ServerListAssetsRequest) (*ServerListAssetsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListAssets not implemented")
 }
+func (*UnimplementedAssetServerServer) SubscribePrices(req *ServerSubscribePricesRequest, srv AssetServer_SubscribePricesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePrices not implemented")
+}
+func (*UnimplementedAssetServerServer) GetPriceHistory(ctx context.Context, req *ServerGetPriceHistoryRequest) (*ServerGetPriceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPriceHistory not implemented")
+}
 
 func RegisterAssetServerServer(s *grpc.Server, srv AssetServerServer) {
 	s.RegisterService(&_AssetServer_serviceDesc, srv)
@@ -829,6 +1894,45 @@ func _AssetServer_ListAssets_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AssetServer_SubscribePrices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ServerSubscribePricesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AssetServerServer).SubscribePrices(m, &assetServerSubscribePricesServer{stream})
+}
+
+type AssetServer_SubscribePricesServer interface {
+	Send(*ServerPriceUpdate) error
+	grpc.ServerStream
+}
+
+type assetServerSubscribePricesServer struct {
+	grpc.ServerStream
+}
+
+func (x *assetServerSubscribePricesServer) Send(m *ServerPriceUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AssetServer_GetPriceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerGetPriceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AssetServerServer).GetPriceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ladrpc.AssetServer/GetPriceHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AssetServerServer).GetPriceHistory(ctx, req.(*ServerGetPriceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _AssetServer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "ladrpc.AssetServer",
 	HandlerType: (*AssetServerServer)(nil),
@@ -845,7 +1949,17 @@ var _AssetServer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListAssets",
 			Handler:    _AssetServer_ListAssets_Handler,
 		},
+		{
+			MethodName: "GetPriceHistory",
+			Handler:    _AssetServer_GetPriceHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribePrices",
+			Handler:       _AssetServer_SubscribePrices_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "server.proto",
 }
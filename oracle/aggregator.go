@@ -0,0 +1,234 @@
+package oracle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNoQuorum is returned when not enough sources agree on a price
+	ErrNoQuorum = errors.New("not enough price sources agree")
+)
+
+// Source is a PriceOracle that has to be started before it receives prices
+type Source interface {
+	PriceOracle
+
+	// Name is used to identify the source in logs and in aggregated prices
+	Name() string
+
+	// Listen starts receiving prices, and only returns if the source fails
+	// NOTE: MUST be run in a goroutine
+	Listen() error
+}
+
+var _ Source = &Aggregator{}
+
+// Aggregator is a PriceOracle that combines several price sources. It reports
+// the median price of its sources, discards sources that deviate too much
+// from that median, and only reports a price when a quorum of the
+// remaining sources agree.
+type Aggregator struct {
+	Notifier
+
+	sources []Source
+	// quorum is the minimum amount of sources that have to agree on a price
+	quorum int
+	// maxDeviation is how many percent a source can deviate from the
+	// median before it is discarded
+	maxDeviation float64
+	// maxAge is how old the price of a source can be before it is
+	// discarded. Zero means prices never get too old
+	maxAge time.Duration
+
+	mu sync.Mutex
+	// notified is the last price passed on to subscribers for each symbol
+	notified map[string]Price
+}
+
+// NewAggregator creates an Aggregator over the given sources
+func NewAggregator(sources []Source, quorum int, maxDeviationPercent float64,
+	maxAge time.Duration) (*Aggregator, error) {
+
+	if len(sources) == 0 {
+		return nil, errors.New("aggregator needs at least one price source")
+	}
+	if quorum < 1 || quorum > len(sources) {
+		return nil, fmt.Errorf("quorum must be between 1 and %d, got %d", len(sources), quorum)
+	}
+	if maxDeviationPercent <= 0 {
+		return nil, fmt.Errorf("max deviation must be positive, got %f", maxDeviationPercent)
+	}
+
+	return &Aggregator{
+		sources:      sources,
+		quorum:       quorum,
+		maxDeviation: maxDeviationPercent,
+		maxAge:       maxAge,
+		notified:     make(map[string]Price),
+	}, nil
+}
+
+// Name returns the names of all the sources the aggregator combines
+func (a *Aggregator) Name() string {
+	var names []string
+	for _, source := range a.sources {
+		names = append(names, source.Name())
+	}
+
+	return "median(" + strings.Join(names, ",") + ")"
+}
+
+// LatestPrice aggregates the latest prices of all sources. If a quorum of
+// sources with fresh prices do not agree, ErrNoQuorum is returned
+func (a *Aggregator) LatestPrice(symbol string) (Price, error) {
+	price, _, err := a.aggregate(symbol)
+	return price, err
+}
+
+// Listen starts all sources, and aggregates a new price every time one of
+// them reports a price. A failing source is logged, and the remaining
+// sources keep running.
+func (a *Aggregator) Listen() error {
+	updates := make(chan Price)
+
+	for _, source := range a.sources {
+		go func(source Source) {
+			err := source.Listen()
+			if err != nil {
+				log.WithError(err).WithField("source", source.Name()).
+					Error("price source stopped")
+			}
+		}(source)
+
+		go func(source Source) {
+			prices, cancel := source.Subscribe()
+			defer cancel()
+
+			for price := range prices {
+				updates <- price
+			}
+		}(source)
+	}
+
+	for update := range updates {
+		price, rejected, err := a.aggregate(update.Symbol)
+
+		for _, price := range rejected {
+			log.WithFields(logrus.Fields{
+				"symbol": price.Symbol,
+				"source": price.Source,
+				"price":  price.Value,
+			}).Warn("discarding price deviating from median")
+		}
+
+		if err != nil {
+			log.WithError(err).WithField("quorum", a.quorum).
+				Warn("price sources did not reach quorum")
+			continue
+		}
+
+		// an unchanged price is still passed on when a source confirmed it
+		// again, so subscribers know it is fresh
+		a.mu.Lock()
+		last := a.notified[price.Symbol]
+		fresh := last.Value != price.Value || price.Timestamp.After(last.Timestamp)
+		if fresh {
+			a.notified[price.Symbol] = price
+		}
+		a.mu.Unlock()
+
+		if fresh {
+			a.Notify(price)
+		}
+	}
+
+	return nil
+}
+
+// aggregate computes a price for the symbol from the latest price of every
+// source. It also returns the prices discarded for deviating too much.
+func (a *Aggregator) aggregate(symbol string) (Price, []Price, error) {
+	var observed []Price
+	for _, source := range a.sources {
+		price, err := source.LatestPrice(symbol)
+		if err != nil {
+			continue
+		}
+
+		// a source that stopped updating does not count towards the quorum
+		if a.maxAge > 0 && time.Since(price.Timestamp) > a.maxAge {
+			continue
+		}
+
+		observed = append(observed, price)
+	}
+
+	accepted, rejected := filterOutliers(observed, a.maxDeviation)
+	if len(accepted) < a.quorum {
+		return Price{}, rejected, fmt.Errorf("%s: %d of %d required sources: %w",
+			symbol, len(accepted), a.quorum, ErrNoQuorum)
+	}
+
+	var sources []string
+	var newest time.Time
+	for _, price := range accepted {
+		sources = append(sources, price.Source)
+		if price.Timestamp.After(newest) {
+			newest = price.Timestamp
+		}
+	}
+
+	aggregated := Price{
+		Symbol:    symbol,
+		Value:     median(accepted),
+		Timestamp: newest,
+		Source:    "median(" + strings.Join(sources, ",") + ")",
+		Sources:   sources,
+	}
+
+	return aggregated, rejected, nil
+}
+
+// filterOutliers splits the prices in those within maxDeviation percent of
+// their median, and those outside it
+func filterOutliers(prices []Price, maxDeviation float64) (accepted, rejected []Price) {
+	if len(prices) == 0 {
+		return nil, nil
+	}
+
+	mid := median(prices)
+	for _, price := range prices {
+		deviation := math.Abs(price.Value-mid) / mid * 100
+		if deviation > maxDeviation {
+			rejected = append(rejected, price)
+		} else {
+			accepted = append(accepted, price)
+		}
+	}
+
+	return accepted, rejected
+}
+
+// median returns the median value of the prices. prices can not be empty
+func median(prices []Price) float64 {
+	values := make([]float64, len(prices))
+	for i, price := range prices {
+		values[i] = price.Value
+	}
+	sort.Float64s(values)
+
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+
+	return values[middle]
+}
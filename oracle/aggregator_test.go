@@ -0,0 +1,177 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// staticSource is a Source that always reports the same prices
+type staticSource struct {
+	Notifier
+	name   string
+	prices map[string]Price
+}
+
+func (s *staticSource) Name() string {
+	return s.name
+}
+
+func (s *staticSource) LatestPrice(symbol string) (Price, error) {
+	price, ok := s.prices[symbol]
+	if !ok {
+		return Price{}, ErrNoPrice
+	}
+
+	return price, nil
+}
+
+func (s *staticSource) Listen() error {
+	return nil
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{name: "single", values: []float64{5}, want: 5},
+		{name: "odd", values: []float64{3, 1, 2}, want: 2},
+		{name: "even", values: []float64{4, 1, 3, 2}, want: 2.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var prices []Price
+			for _, value := range test.values {
+				prices = append(prices, Price{Value: value})
+			}
+
+			if got := median(prices); got != test.want {
+				t.Fatalf("median = %f, want %f", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFilterOutliers(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       []float64
+		maxDeviation float64
+		accepted     int
+		rejected     int
+	}{
+		{name: "empty", values: nil, maxDeviation: 1},
+		{name: "all agree", values: []float64{100, 100.5, 99.5}, maxDeviation: 1, accepted: 3},
+		{name: "one outlier", values: []float64{100, 100.5, 120}, maxDeviation: 1, accepted: 2, rejected: 1},
+		{name: "wide band", values: []float64{100, 100.5, 120}, maxDeviation: 50, accepted: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var prices []Price
+			for _, value := range test.values {
+				prices = append(prices, Price{Value: value})
+			}
+
+			accepted, rejected := filterOutliers(prices, test.maxDeviation)
+			if len(accepted) != test.accepted || len(rejected) != test.rejected {
+				t.Fatalf("accepted %d and rejected %d, want %d and %d",
+					len(accepted), len(rejected), test.accepted, test.rejected)
+			}
+		})
+	}
+}
+
+func TestAggregatorLatestPrice(t *testing.T) {
+	now := time.Now()
+	source := func(name string, value float64, age time.Duration) Source {
+		return &staticSource{
+			name: name,
+			prices: map[string]Price{
+				"XBTUSD": {Symbol: "XBTUSD", Value: value, Timestamp: now.Add(-age), Source: name},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sources  []Source
+		quorum   int
+		want     float64
+		noQuorum bool
+	}{
+		{
+			name:    "median of all sources",
+			sources: []Source{source("a", 100, 0), source("b", 101, 0), source("c", 102, 0)},
+			quorum:  2,
+			want:    101,
+		},
+		{
+			name:    "outlier is discarded",
+			sources: []Source{source("a", 100, 0), source("b", 101, 0), source("c", 200, 0)},
+			quorum:  2,
+			want:    100.5,
+		},
+		{
+			name:     "outlier breaks quorum",
+			sources:  []Source{source("a", 100, 0), source("b", 101, 0), source("c", 200, 0)},
+			quorum:   3,
+			noQuorum: true,
+		},
+		{
+			name:    "stale source is not counted",
+			sources: []Source{source("a", 100, 0), source("b", 102, time.Hour)},
+			quorum:  1,
+			want:    100,
+		},
+		{
+			name:     "stale source breaks quorum",
+			sources:  []Source{source("a", 100, 0), source("b", 102, time.Hour)},
+			quorum:   2,
+			noQuorum: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			aggregator, err := NewAggregator(test.sources, test.quorum, 5, time.Minute)
+			if err != nil {
+				t.Fatalf("could not create aggregator: %v", err)
+			}
+
+			price, err := aggregator.LatestPrice("XBTUSD")
+			if test.noQuorum {
+				if !errors.Is(err, ErrNoQuorum) {
+					t.Fatalf("expected ErrNoQuorum, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if price.Value != test.want {
+				t.Fatalf("price = %f, want %f", price.Value, test.want)
+			}
+			if len(price.Feeds()) < test.quorum {
+				t.Fatalf("price from %v does not name the sources of the quorum", price.Feeds())
+			}
+		})
+	}
+}
+
+func TestNewAggregatorValidates(t *testing.T) {
+	sources := []Source{&staticSource{name: "a"}}
+
+	if _, err := NewAggregator(nil, 1, 5, 0); err == nil {
+		t.Error("accepted no sources")
+	}
+	if _, err := NewAggregator(sources, 2, 5, 0); err == nil {
+		t.Error("accepted quorum larger than the sources")
+	}
+	if _, err := NewAggregator(sources, 1, 0, 0); err == nil {
+		t.Error("accepted zero max deviation")
+	}
+}
@@ -0,0 +1,166 @@
+package oracle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+var (
+	// ErrCircuitBreakerTripped is returned when a price moved too much in a
+	// single tick, and has not been confirmed yet
+	ErrCircuitBreakerTripped = errors.New("circuit breaker tripped")
+)
+
+// CircuitBreaker halts acting on the price of a symbol when it moves more
+// than a threshold in a single tick. It stays tripped until the move is
+// confirmed by prices from at least two feeds, confirmed by an operator, or
+// the price returns to where it was before the move. Aggregated prices are
+// confirmed by the feeds they were computed from, not the aggregate.
+type CircuitBreaker struct {
+	// threshold is how many percent a price can move in a single tick
+	// before the breaker trips. Zero disables the breaker
+	threshold float64
+	// confirmBand is how many percent a price from another source can
+	// deviate from the tripping price and still confirm it
+	confirmBand float64
+
+	mu sync.Mutex
+	// last is the last price that passed the breaker
+	last map[string]Price
+	// tripped is the price that tripped the breaker
+	tripped map[string]Price
+	// confirmedBy are the feeds that reported prices within the confirm
+	// band of the price that tripped the breaker
+	confirmedBy map[string]map[string]bool
+}
+
+// minConfirmations is how many feeds must agree on a move that tripped the
+// breaker before it resets on its own
+const minConfirmations = 2
+
+// NewCircuitBreaker creates a new CircuitBreaker
+func NewCircuitBreaker(thresholdPercent, confirmBandPercent float64) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold:   thresholdPercent,
+		confirmBand: confirmBandPercent,
+		last:        make(map[string]Price),
+		tripped:     make(map[string]Price),
+		confirmedBy: make(map[string]map[string]bool),
+	}
+}
+
+// Check registers a new price, and returns ErrCircuitBreakerTripped if
+// nothing should be done with it
+func (b *CircuitBreaker) Check(price Price) error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	last, seen := b.last[price.Symbol]
+
+	if tripped, ok := b.tripped[price.Symbol]; ok {
+		switch {
+		// the price returned to where it was, the move was a one-off
+		case percentChange(last.Value, price.Value) <= b.threshold:
+			log.WithField("symbol", price.Symbol).
+				Info("price returned to previous level, resetting circuit breaker")
+
+		// the price is still where it moved to, which confirms the move if
+		// enough feeds agree on it
+		case percentChange(tripped.Value, price.Value) <= b.confirmBand:
+			for _, feed := range price.Feeds() {
+				b.confirmedBy[price.Symbol][feed] = true
+			}
+			// the latest price is the one an operator confirms
+			b.tripped[price.Symbol] = price
+
+			if len(b.confirmedBy[price.Symbol]) < minConfirmations {
+				return fmt.Errorf("%s moved from %f to %f: %w", price.Symbol,
+					last.Value, price.Value, ErrCircuitBreakerTripped)
+			}
+
+			log.WithField("symbol", price.Symbol).
+				Infof("price move confirmed by %s, resetting circuit breaker", price.Source)
+
+		// the price moved on, so only feeds agreeing with where it is now
+		// can confirm it
+		default:
+			b.trip(price)
+			return fmt.Errorf("%s moved from %f to %f: %w", price.Symbol,
+				last.Value, price.Value, ErrCircuitBreakerTripped)
+		}
+
+		b.reset(price.Symbol)
+		b.last[price.Symbol] = price
+		return nil
+	}
+
+	if seen && percentChange(last.Value, price.Value) > b.threshold {
+		b.trip(price)
+
+		log.WithField("symbol", price.Symbol).
+			Errorf("price moved from %f to %f in a single tick, tripping circuit breaker",
+				last.Value, price.Value)
+
+		return fmt.Errorf("%s moved from %f to %f: %w", price.Symbol,
+			last.Value, price.Value, ErrCircuitBreakerTripped)
+	}
+
+	b.last[price.Symbol] = price
+	return nil
+}
+
+// trip trips the breaker for the symbol of the price, with only the feeds
+// of the price confirming it
+// NOTE: MUST be called with mu held
+func (b *CircuitBreaker) trip(price Price) {
+	b.tripped[price.Symbol] = price
+	b.confirmedBy[price.Symbol] = make(map[string]bool)
+	for _, feed := range price.Feeds() {
+		b.confirmedBy[price.Symbol][feed] = true
+	}
+}
+
+// reset resets the breaker for the symbol
+// NOTE: MUST be called with mu held
+func (b *CircuitBreaker) reset(symbol string) {
+	delete(b.tripped, symbol)
+	delete(b.confirmedBy, symbol)
+}
+
+// Tripped returns the price that tripped the breaker for the symbol, if
+// it is tripped
+func (b *CircuitBreaker) Tripped(symbol string) (Price, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	price, ok := b.tripped[symbol]
+	return price, ok
+}
+
+// Confirm is used by an operator to accept the price that tripped the
+// breaker for the symbol, and returns the confirmed price
+func (b *CircuitBreaker) Confirm(symbol string) (Price, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	price, ok := b.tripped[symbol]
+	if !ok {
+		return Price{}, fmt.Errorf("circuit breaker for %s is not tripped", symbol)
+	}
+
+	b.reset(symbol)
+	b.last[symbol] = price
+
+	return price, nil
+}
+
+// percentChange returns how many percent to differs from from
+func percentChange(from, to float64) float64 {
+	return math.Abs(to-from) / from * 100
+}
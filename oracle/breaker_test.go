@@ -0,0 +1,159 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerCheck(t *testing.T) {
+	type tick struct {
+		value   float64
+		sources []string
+		tripped bool
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		ticks     []tick
+	}{
+		{
+			name:      "small moves pass",
+			threshold: 10,
+			ticks: []tick{
+				{value: 100, sources: []string{"a"}},
+				{value: 105, sources: []string{"a"}},
+				{value: 110, sources: []string{"a"}},
+			},
+		},
+		{
+			name:      "disabled breaker never trips",
+			threshold: 0,
+			ticks: []tick{
+				{value: 100, sources: []string{"a"}},
+				{value: 1000, sources: []string{"a"}},
+			},
+		},
+		{
+			name:      "large move trips",
+			threshold: 10,
+			ticks: []tick{
+				{value: 100, sources: []string{"a"}},
+				{value: 150, sources: []string{"a"}, tripped: true},
+			},
+		},
+		{
+			name:      "same feed can not confirm a move on its own",
+			threshold: 10,
+			ticks: []tick{
+				{value: 100, sources: []string{"a"}},
+				{value: 150, sources: []string{"a"}, tripped: true},
+				{value: 150, sources: []string{"a"}, tripped: true},
+				{value: 150.5, sources: []string{"a"}, tripped: true},
+			},
+		},
+		{
+			name:      "second feed confirms move",
+			threshold: 10,
+			ticks: []tick{
+				{value: 100, sources: []string{"a"}},
+				{value: 150, sources: []string{"a"}, tripped: true},
+				{value: 150.5, sources: []string{"b"}},
+				{value: 151, sources: []string{"a"}},
+			},
+		},
+		{
+			name:      "aggregated price confirms with its underlying feeds",
+			threshold: 10,
+			ticks: []tick{
+				{value: 100, sources: []string{"a", "b"}},
+				{value: 150, sources: []string{"a", "b"}, tripped: true},
+				{value: 150, sources: []string{"a", "b"}},
+			},
+		},
+		{
+			name:      "price outside the confirm band does not confirm",
+			threshold: 10,
+			ticks: []tick{
+				{value: 100, sources: []string{"a"}},
+				{value: 150, sources: []string{"a"}, tripped: true},
+				{value: 140, sources: []string{"b"}, tripped: true},
+				{value: 140, sources: []string{"a"}},
+			},
+		},
+		{
+			name:      "return to previous level resets",
+			threshold: 10,
+			ticks: []tick{
+				{value: 100, sources: []string{"a"}},
+				{value: 150, sources: []string{"a"}, tripped: true},
+				{value: 101, sources: []string{"a"}},
+				{value: 102, sources: []string{"a"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaker := NewCircuitBreaker(test.threshold, 1)
+
+			for i, tick := range test.ticks {
+				price := Price{
+					Symbol:    "XBTUSD",
+					Value:     tick.value,
+					Timestamp: time.Unix(int64(i), 0),
+					Source:    tick.sources[0],
+				}
+				if len(tick.sources) > 1 {
+					price.Source = "median"
+					price.Sources = tick.sources
+				}
+
+				err := breaker.Check(price)
+				tripped := errors.Is(err, ErrCircuitBreakerTripped)
+				if err != nil && !tripped {
+					t.Fatalf("tick %d: unexpected error: %v", i, err)
+				}
+				if tripped != tick.tripped {
+					t.Fatalf("tick %d at %f: tripped = %v, want %v", i, tick.value, tripped, tick.tripped)
+				}
+
+				_, stillTripped := breaker.Tripped(price.Symbol)
+				if stillTripped != tick.tripped {
+					t.Fatalf("tick %d: Tripped() = %v, want %v", i, stillTripped, tick.tripped)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerConfirm(t *testing.T) {
+	breaker := NewCircuitBreaker(10, 1)
+
+	_, err := breaker.Confirm("XBTUSD")
+	if err == nil {
+		t.Fatal("confirmed breaker that was not tripped")
+	}
+
+	if err := breaker.Check(Price{Symbol: "XBTUSD", Value: 100, Source: "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = breaker.Check(Price{Symbol: "XBTUSD", Value: 150, Source: "a"})
+	if !errors.Is(err, ErrCircuitBreakerTripped) {
+		t.Fatalf("breaker did not trip: %v", err)
+	}
+
+	confirmed, err := breaker.Confirm("XBTUSD")
+	if err != nil {
+		t.Fatalf("could not confirm: %v", err)
+	}
+	if confirmed.Value != 150 {
+		t.Fatalf("confirmed %f, want 150", confirmed.Value)
+	}
+
+	// the confirmed price is the new level prices are compared to
+	if err := breaker.Check(Price{Symbol: "XBTUSD", Value: 151, Source: "a"}); err != nil {
+		t.Fatalf("price near confirmed level was rejected: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package oracle
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// NewCoinbaseSource creates a source polling the coinbase spot price of the
+// given pair, e.g. BTC-USD. Prices are reported under symbol.
+func NewCoinbaseSource(symbol, pair string, interval time.Duration) *PollingSource {
+	url := fmt.Sprintf("https://api.coinbase.com/v2/prices/%s/spot", pair)
+
+	return NewPollingSource("coinbase", interval, func() (map[string]float64, error) {
+		var res struct {
+			Data struct {
+				Amount string `json:"amount"`
+			} `json:"data"`
+		}
+		err := getJSON(url, &res)
+		if err != nil {
+			return nil, err
+		}
+
+		price, err := strconv.ParseFloat(res.Data.Amount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse coinbase price: %w", err)
+		}
+
+		return map[string]float64{symbol: price}, nil
+	})
+}
+
+// NewBitstampSource creates a source polling the last trade price of the
+// given bitstamp pair, e.g. btcusd. Prices are reported under symbol.
+func NewBitstampSource(symbol, pair string, interval time.Duration) *PollingSource {
+	url := fmt.Sprintf("https://www.bitstamp.net/api/v2/ticker/%s/", pair)
+
+	return NewPollingSource("bitstamp", interval, func() (map[string]float64, error) {
+		var res struct {
+			Last string `json:"last"`
+		}
+		err := getJSON(url, &res)
+		if err != nil {
+			return nil, err
+		}
+
+		price, err := strconv.ParseFloat(res.Last, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse bitstamp price: %w", err)
+		}
+
+		return map[string]float64{symbol: price}, nil
+	})
+}
+
+// NewKrakenSource creates a source polling the last trade price of the
+// given kraken pair, e.g. XBTUSD. Prices are reported under symbol.
+func NewKrakenSource(symbol, pair string, interval time.Duration) *PollingSource {
+	url := fmt.Sprintf("https://api.kraken.com/0/public/Ticker?pair=%s", pair)
+
+	return NewPollingSource("kraken", interval, func() (map[string]float64, error) {
+		var res struct {
+			Error  []string `json:"error"`
+			Result map[string]struct {
+				// c is the last trade closed, on the form [price, lot volume]
+				C []string `json:"c"`
+			} `json:"result"`
+		}
+		err := getJSON(url, &res)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Error) > 0 {
+			return nil, fmt.Errorf("kraken returned error: %v", res.Error)
+		}
+
+		// kraken uses its own internal name as the key, e.g. XXBTZUSD for
+		// XBTUSD. We only ask for one pair, so we take whatever is there
+		for _, ticker := range res.Result {
+			if len(ticker.C) == 0 {
+				break
+			}
+
+			price, err := strconv.ParseFloat(ticker.C[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse kraken price: %w", err)
+			}
+
+			return map[string]float64{symbol: price}, nil
+		}
+
+		return nil, fmt.Errorf("kraken did not return a price for %s", pair)
+	})
+}
@@ -0,0 +1,106 @@
+// Package oracle defines how lasd learns about prices. Contract code only
+// depends on the PriceOracle interface, so price feeds can be swapped or
+// combined without touching it.
+package oracle
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+var (
+	// ErrNoPrice is returned when an oracle has not seen a price for a symbol yet
+	ErrNoPrice = errors.New("no price available")
+)
+
+// Price is a single price observation for a symbol, e.g. the XBTUSD
+// instrument on bitmex
+type Price struct {
+	Symbol    string
+	Value     float64
+	Timestamp time.Time
+	// Source is the name of the feed the price originated from
+	Source string
+	// Sources are the names of the feeds an aggregated price was computed
+	// from. Empty for prices from a single feed
+	Sources []string
+}
+
+// Feeds returns the names of the feeds the price originated from
+func (p Price) Feeds() []string {
+	if len(p.Sources) > 0 {
+		return p.Sources
+	}
+
+	return []string{p.Source}
+}
+
+// PriceOracle is anything that can tell us the latest price of a symbol, and
+// notify us when it changes
+type PriceOracle interface {
+	// LatestPrice returns the most recent price for the given symbol
+	LatestPrice(symbol string) (Price, error)
+
+	// Subscribe returns a channel that receives every new price accepted by
+	// the oracle, and a function that cancels the subscription
+	Subscribe() (<-chan Price, func())
+}
+
+// subscriptionBuffer is how many prices can be queued up for a slow subscriber
+// before new prices are dropped
+const subscriptionBuffer = 16
+
+// Notifier fans out prices to any number of subscribers. It is meant to be
+// embedded in PriceOracle implementations to provide Subscribe.
+type Notifier struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]chan Price
+}
+
+// Subscribe returns a channel that receives all prices passed to Notify
+func (n *Notifier) Subscribe() (<-chan Price, func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.subscribers == nil {
+		n.subscribers = make(map[int]chan Price)
+	}
+
+	id := n.nextID
+	n.nextID++
+
+	ch := make(chan Price, subscriptionBuffer)
+	n.subscribers[id] = ch
+
+	cancel := func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		if sub, ok := n.subscribers[id]; ok {
+			delete(n.subscribers, id)
+			close(sub)
+		}
+	}
+
+	return ch, cancel
+}
+
+// Notify passes the price on to all subscribers. It never blocks, if a
+// subscriber is not keeping up the price is dropped for that subscriber.
+func (n *Notifier) Notify(price Price) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, sub := range n.subscribers {
+		select {
+		case sub <- price:
+		default:
+		}
+	}
+}
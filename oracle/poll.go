@@ -0,0 +1,158 @@
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval is how often polling sources fetch new prices if
+// nothing else is specified
+const DefaultPollInterval = 10 * time.Second
+
+var _ Source = &PollingSource{}
+
+// PollingSource is a price Source that periodically fetches prices, for
+// venues where we do not have a streaming connection
+type PollingSource struct {
+	Notifier
+
+	name     string
+	interval time.Duration
+	fetch    func() (map[string]float64, error)
+
+	mu     sync.RWMutex
+	prices map[string]Price
+}
+
+// NewPollingSource creates a source that calls fetch every interval. fetch
+// returns the latest prices, keyed by symbol.
+func NewPollingSource(name string, interval time.Duration,
+	fetch func() (map[string]float64, error)) *PollingSource {
+
+	return &PollingSource{
+		name:     name,
+		interval: interval,
+		fetch:    fetch,
+		prices:   make(map[string]Price),
+	}
+}
+
+// Name returns the name of the source
+func (s *PollingSource) Name() string {
+	return s.name
+}
+
+// LatestPrice returns the latest fetched price for the symbol
+func (s *PollingSource) LatestPrice(symbol string) (Price, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	price, ok := s.prices[symbol]
+	if !ok {
+		return Price{}, fmt.Errorf("%s: %w", symbol, ErrNoPrice)
+	}
+
+	return price, nil
+}
+
+// Listen fetches new prices every interval. Failed fetches are logged, and
+// retried at the next interval.
+func (s *PollingSource) Listen() error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.poll()
+		<-ticker.C
+	}
+}
+
+func (s *PollingSource) poll() {
+	prices, err := s.fetch()
+	if err != nil {
+		log.WithError(err).WithField("source", s.name).Error("could not fetch prices")
+		return
+	}
+
+	now := time.Now()
+	for symbol, value := range prices {
+		if value <= 0 {
+			continue
+		}
+
+		price := Price{
+			Symbol:    symbol,
+			Value:     value,
+			Timestamp: now,
+			Source:    s.name,
+		}
+
+		s.mu.Lock()
+		s.prices[symbol] = price
+		s.mu.Unlock()
+
+		s.Notify(price)
+	}
+}
+
+// NewFileSource creates a source that reads prices from a JSON file on the
+// form {"XBTUSD": 7350.5}. It is meant as a stand-in for real exchanges when
+// testing.
+func NewFileSource(path string, interval time.Duration) *PollingSource {
+	return NewPollingSource("file:"+path, interval, func() (map[string]float64, error) {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read price file: %w", err)
+		}
+
+		var prices map[string]float64
+		err = json.Unmarshal(content, &prices)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal price file: %w", err)
+		}
+
+		return prices, nil
+	})
+}
+
+// NewJSONSource creates a source that fetches prices from an HTTP endpoint
+// serving the same format as NewFileSource.
+func NewJSONSource(url string, interval time.Duration) *PollingSource {
+	return NewPollingSource(url, interval, func() (map[string]float64, error) {
+		var prices map[string]float64
+		err := getJSON(url, &prices)
+		if err != nil {
+			return nil, err
+		}
+
+		return prices, nil
+	})
+}
+
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// getJSON fetches the url and unmarshals the response body into v
+func getJSON(url string, v interface{}) error {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("could not get %s: %w", url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not get %s: %s", url, res.Status)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("could not decode response from %s: %w", url, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package oracle
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Source = &ReplaySource{}
+
+// ReplaySource is a price Source that replays ticks recorded in a file,
+// keeping the time between ticks, optionally sped up. It is used to
+// reproduce incidents, and to run without access to any exchange.
+//
+// Replayed prices are stamped with the time they are replayed at, so they
+// pass the same staleness checks as live prices.
+type ReplaySource struct {
+	Notifier
+
+	path string
+	// speed is how many times faster than real time ticks are replayed
+	speed float64
+	// loop starts over from the first tick when the file is done
+	loop bool
+
+	mu     sync.RWMutex
+	prices map[string]Price
+}
+
+// tick is a single recorded price
+type tick struct {
+	Timestamp time.Time
+	Symbol    string
+	Price     float64
+}
+
+// NewReplaySource creates a source replaying the ticks in the file at
+// path. Files ending in .csv have the columns timestamp,asset,price, with an
+// optional header. All other files are read as JSON lines on the form
+// {"timestamp": "2019-12-01T10:00:00Z", "asset": "XBTUSD", "price": 7350.5}.
+// Timestamps are either RFC3339 or unix seconds.
+func NewReplaySource(path string, speed float64, loop bool) (*ReplaySource, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("replay speed must be positive, got %f", speed)
+	}
+
+	return &ReplaySource{
+		path:   path,
+		speed:  speed,
+		loop:   loop,
+		prices: make(map[string]Price),
+	}, nil
+}
+
+// Name returns the name of the source
+func (s *ReplaySource) Name() string {
+	return "replay(" + filepath.Base(s.path) + ")"
+}
+
+// LatestPrice returns the last replayed price for the symbol
+func (s *ReplaySource) LatestPrice(symbol string) (Price, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	price, ok := s.prices[symbol]
+	if !ok {
+		return Price{}, fmt.Errorf("%s: %w", symbol, ErrNoPrice)
+	}
+
+	return price, nil
+}
+
+// Listen replays all ticks in the file, and returns when it is done unless
+// the source loops
+func (s *ReplaySource) Listen() error {
+	ticks, err := readTicks(s.path)
+	if err != nil {
+		return err
+	}
+	if len(ticks) == 0 {
+		return fmt.Errorf("no ticks in %s", s.path)
+	}
+
+	log.WithField("source", s.Name()).
+		Infof("replaying %d ticks from %s to %s at %gx speed", len(ticks),
+			ticks[0].Timestamp.Format(time.RFC3339), ticks[len(ticks)-1].Timestamp.Format(time.RFC3339), s.speed)
+
+	for {
+		previous := ticks[0].Timestamp
+		for _, tick := range ticks {
+			wait := time.Duration(float64(tick.Timestamp.Sub(previous)) / s.speed)
+			time.Sleep(wait)
+			previous = tick.Timestamp
+
+			price := Price{
+				Symbol:    tick.Symbol,
+				Value:     tick.Price,
+				Timestamp: time.Now(),
+				Source:    s.Name(),
+			}
+
+			s.mu.Lock()
+			s.prices[price.Symbol] = price
+			s.mu.Unlock()
+
+			s.Notify(price)
+		}
+
+		if !s.loop {
+			log.WithField("source", s.Name()).Info("replay finished")
+			return nil
+		}
+	}
+}
+
+// readTicks reads all ticks in the file, sorted by time
+func readTicks(path string) ([]tick, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open tick file: %w", err)
+	}
+	defer file.Close()
+
+	var ticks []tick
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		ticks, err = readCSVTicks(file)
+	} else {
+		ticks, err = readJSONTicks(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	sort.SliceStable(ticks, func(i, j int) bool {
+		return ticks[i].Timestamp.Before(ticks[j].Timestamp)
+	})
+
+	return ticks, nil
+}
+
+func readCSVTicks(r io.Reader) ([]tick, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var ticks []tick
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return ticks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "timestamp") {
+			continue
+		}
+
+		timestamp, err := parseTickTimestamp(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		price, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price: %w", line, err)
+		}
+		err = checkTickPrice(price)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		ticks = append(ticks, tick{
+			Timestamp: timestamp,
+			Symbol:    record[1],
+			Price:     price,
+		})
+	}
+}
+
+func readJSONTicks(r io.Reader) ([]tick, error) {
+	var ticks []tick
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record struct {
+			Timestamp json.RawMessage `json:"timestamp"`
+			Asset     string          `json:"asset"`
+			Price     float64         `json:"price"`
+		}
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		timestamp, err := parseTickTimestamp(strings.Trim(string(record.Timestamp), `"`))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		err = checkTickPrice(record.Price)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		ticks = append(ticks, tick{
+			Timestamp: timestamp,
+			Symbol:    record.Asset,
+			Price:     record.Price,
+		})
+	}
+
+	return ticks, scanner.Err()
+}
+
+// checkTickPrice returns an error if the price can not be a real price.
+// Like polled prices, a replayed price must be positive
+func checkTickPrice(price float64) error {
+	if !(price > 0) || math.IsInf(price, 1) {
+		return fmt.Errorf("invalid price %v", price)
+	}
+
+	return nil
+}
+
+// parseTickTimestamp parses either an RFC3339 timestamp, or unix seconds
+func parseTickTimestamp(timestamp string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+		return t, nil
+	}
+
+	seconds, err := strconv.ParseFloat(timestamp, 64)
+	if err != nil {
+		return time.Time{}, errors.New("timestamp is neither RFC3339 nor unix seconds: " + timestamp)
+	}
+
+	// the whole and fractional seconds are converted separately, as unix
+	// nanoseconds do not fit in the precision of a float64
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(math.Round(fraction*float64(time.Second)))), nil
+}
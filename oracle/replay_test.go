@@ -0,0 +1,173 @@
+package oracle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTickTimestamp(t *testing.T) {
+	tests := []struct {
+		timestamp string
+		want      time.Time
+		invalid   bool
+	}{
+		{timestamp: "2019-12-01T10:00:00Z", want: time.Date(2019, 12, 1, 10, 0, 0, 0, time.UTC)},
+		{timestamp: "2019-12-01T11:00:00.5+01:00", want: time.Date(2019, 12, 1, 10, 0, 0, 5e8, time.UTC)},
+		{timestamp: "1575194400", want: time.Date(2019, 12, 1, 10, 0, 0, 0, time.UTC)},
+		{timestamp: "1575194400.25", want: time.Date(2019, 12, 1, 10, 0, 0, 25e7, time.UTC)},
+		{timestamp: "yesterday", invalid: true},
+		{timestamp: "", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.timestamp, func(t *testing.T) {
+			got, err := parseTickTimestamp(test.timestamp)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("parsed invalid timestamp as %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(test.want) {
+				t.Fatalf("timestamp = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadTicks(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+		want    []tick
+		// errLine is the line an invalid file should fail on
+		errLine string
+	}{
+		{
+			name: "csv with header",
+			ext:  ".csv",
+			content: "timestamp,asset,price\n" +
+				"1575194400,XBTUSD,7350.5\n" +
+				"2019-12-01T10:00:01Z, XBTUSD, 7351\n",
+			want: []tick{
+				{Timestamp: time.Unix(1575194400, 0), Symbol: "XBTUSD", Price: 7350.5},
+				{Timestamp: time.Unix(1575194401, 0), Symbol: "XBTUSD", Price: 7351},
+			},
+		},
+		{
+			name:    "csv sorted by time",
+			ext:     ".CSV",
+			content: "1575194401,XBTUSD,2\n1575194400,XBTUSD,1\n",
+			want: []tick{
+				{Timestamp: time.Unix(1575194400, 0), Symbol: "XBTUSD", Price: 1},
+				{Timestamp: time.Unix(1575194401, 0), Symbol: "XBTUSD", Price: 2},
+			},
+		},
+		{
+			name:    "csv with invalid price",
+			ext:     ".csv",
+			content: "1575194400,XBTUSD,7350.5\n1575194401,XBTUSD,abc\n",
+			errLine: "line 2",
+		},
+		{
+			name:    "csv with zero price",
+			ext:     ".csv",
+			content: "timestamp,asset,price\n1575194400,XBTUSD,0\n",
+			errLine: "line 2",
+		},
+		{
+			name:    "csv with negative price",
+			ext:     ".csv",
+			content: "1575194400,XBTUSD,-1\n",
+			errLine: "line 1",
+		},
+		{
+			name:    "csv with NaN price",
+			ext:     ".csv",
+			content: "1575194400,XBTUSD,NaN\n",
+			errLine: "line 1",
+		},
+		{
+			name:    "csv with invalid timestamp",
+			ext:     ".csv",
+			content: "1575194400,XBTUSD,1\nnever,XBTUSD,1\n",
+			errLine: "line 2",
+		},
+		{
+			name: "json lines",
+			ext:  ".jsonl",
+			content: `{"timestamp": "2019-12-01T10:00:00Z", "asset": "XBTUSD", "price": 7350.5}` + "\n\n" +
+				`{"timestamp": 1575194401, "asset": "ETHUSD", "price": 150}` + "\n",
+			want: []tick{
+				{Timestamp: time.Unix(1575194400, 0), Symbol: "XBTUSD", Price: 7350.5},
+				{Timestamp: time.Unix(1575194401, 0), Symbol: "ETHUSD", Price: 150},
+			},
+		},
+		{
+			name:    "json with zero price",
+			ext:     ".jsonl",
+			content: `{"timestamp": 1575194400, "asset": "XBTUSD", "price": 1}` + "\n" + `{"timestamp": 1575194401, "asset": "XBTUSD"}` + "\n",
+			errLine: "line 2",
+		},
+		{
+			name:    "json with negative price",
+			ext:     ".jsonl",
+			content: `{"timestamp": 1575194400, "asset": "XBTUSD", "price": -7350}` + "\n",
+			errLine: "line 1",
+		},
+		{
+			name:    "invalid json",
+			ext:     ".jsonl",
+			content: "{\n",
+			errLine: "line 1",
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, string(rune('a'+i))+test.ext)
+			err := ioutil.WriteFile(path, []byte(test.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ticks, err := readTicks(path)
+			if test.errLine != "" {
+				if err == nil {
+					t.Fatalf("read invalid file as %v", ticks)
+				}
+				if !strings.Contains(err.Error(), test.errLine) {
+					t.Fatalf("error %q does not name %s", err, test.errLine)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(ticks) != len(test.want) {
+				t.Fatalf("got %d ticks, want %d", len(ticks), len(test.want))
+			}
+			for i, tick := range ticks {
+				want := test.want[i]
+				if !tick.Timestamp.Equal(want.Timestamp) || tick.Symbol != want.Symbol || tick.Price != want.Price {
+					t.Fatalf("tick %d = %+v, want %+v", i, tick, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PriceWindow keeps the prices of every symbol over a period, and computes
+// time-weighted averages over them. It is safe for concurrent use.
+type PriceWindow struct {
+	// period is how long prices are kept
+	period time.Duration
+
+	mu     sync.RWMutex
+	prices map[string][]Price
+}
+
+// NewPriceWindow creates a PriceWindow that keeps prices for period
+func NewPriceWindow(period time.Duration) *PriceWindow {
+	return &PriceWindow{
+		period: period,
+		prices: make(map[string][]Price),
+	}
+}
+
+// Add adds a price to the window, and forgets prices that are no longer
+// needed to compute averages over the period
+func (w *PriceWindow) Add(price Price) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prices := append(w.prices[price.Symbol], price)
+	sort.SliceStable(prices, func(i, j int) bool {
+		return prices[i].Timestamp.Before(prices[j].Timestamp)
+	})
+
+	// keep the newest price older than the period, as it is the price in
+	// effect at the start of the period
+	start := price.Timestamp.Add(-w.period)
+	first := 0
+	for first+1 < len(prices) && !prices[first+1].Timestamp.After(start) {
+		first++
+	}
+
+	w.prices[price.Symbol] = append([]Price(nil), prices[first:]...)
+}
+
+// Latest returns the newest price of the symbol
+func (w *PriceWindow) Latest(symbol string) (Price, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	prices := w.prices[symbol]
+	if len(prices) == 0 {
+		return Price{}, fmt.Errorf("%s: %w", symbol, ErrNoPrice)
+	}
+
+	return prices[len(prices)-1], nil
+}
+
+// TWAP returns the time-weighted average price of the symbol over the
+// window ending at now. Every price is weighted by how long it was the
+// latest price within the window. If we have no prices from the start of
+// the window, the average is taken over the part we have prices for.
+func (w *PriceWindow) TWAP(symbol string, window time.Duration, now time.Time) (Price, error) {
+	if window > w.period {
+		return Price{}, fmt.Errorf("can not average over %s, prices are only kept for %s", window, w.period)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	prices := w.prices[symbol]
+	if len(prices) == 0 {
+		return Price{}, fmt.Errorf("%s: %w", symbol, ErrNoPrice)
+	}
+
+	start := now.Add(-window)
+	latest := prices[len(prices)-1]
+
+	var weighted float64
+	var total time.Duration
+	for i, price := range prices {
+		from := price.Timestamp
+		if from.Before(start) {
+			from = start
+		}
+
+		to := now
+		if i+1 < len(prices) {
+			to = prices[i+1].Timestamp
+		}
+
+		if !to.After(from) {
+			continue
+		}
+
+		weighted += price.Value * float64(to.Sub(from))
+		total += to.Sub(from)
+	}
+
+	// all prices are from the same instant, or in the future
+	if total == 0 {
+		return latest, nil
+	}
+
+	return Price{
+		Symbol:    symbol,
+		Value:     weighted / float64(total),
+		Timestamp: latest.Timestamp,
+		Source:    fmt.Sprintf("twap(%s,%s)", window, latest.Source),
+	}, nil
+}
@@ -0,0 +1,118 @@
+package oracle
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPriceWindowTWAP(t *testing.T) {
+	start := time.Unix(1000, 0)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	type tick struct {
+		at    int
+		value float64
+	}
+
+	tests := []struct {
+		name   string
+		ticks  []tick
+		window time.Duration
+		now    int
+		want   float64
+	}{
+		{
+			name:   "single price",
+			ticks:  []tick{{at: 0, value: 100}},
+			window: time.Minute,
+			now:    60,
+			want:   100,
+		},
+		{
+			name:   "prices weighted by how long they lasted",
+			ticks:  []tick{{at: 0, value: 100}, {at: 45, value: 200}},
+			window: time.Minute,
+			now:    60,
+			want:   125,
+		},
+		{
+			name:   "price from before the window counts from its start",
+			ticks:  []tick{{at: 0, value: 100}, {at: 90, value: 200}},
+			window: time.Minute,
+			now:    120,
+			want:   150,
+		},
+		{
+			name:   "only the part we have prices for is averaged",
+			ticks:  []tick{{at: 30, value: 100}, {at: 45, value: 200}},
+			window: time.Minute,
+			now:    60,
+			want:   150,
+		},
+		{
+			name:   "prices out of order",
+			ticks:  []tick{{at: 45, value: 200}, {at: 0, value: 100}},
+			window: time.Minute,
+			now:    60,
+			want:   125,
+		},
+		{
+			name:   "all prices at now",
+			ticks:  []tick{{at: 60, value: 300}},
+			window: time.Minute,
+			now:    60,
+			want:   300,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window := NewPriceWindow(time.Hour)
+			for _, tick := range test.ticks {
+				window.Add(Price{Symbol: "XBTUSD", Value: tick.value, Timestamp: at(tick.at), Source: "a"})
+			}
+
+			price, err := window.TWAP("XBTUSD", test.window, at(test.now))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(price.Value-test.want) > 1e-9 {
+				t.Fatalf("twap = %f, want %f", price.Value, test.want)
+			}
+		})
+	}
+}
+
+func TestPriceWindowErrors(t *testing.T) {
+	window := NewPriceWindow(time.Minute)
+
+	if _, err := window.TWAP("XBTUSD", time.Minute, time.Now()); err == nil {
+		t.Error("averaged without prices")
+	}
+	if _, err := window.Latest("XBTUSD"); err == nil {
+		t.Error("returned latest price without prices")
+	}
+
+	window.Add(Price{Symbol: "XBTUSD", Value: 100, Timestamp: time.Now()})
+	if _, err := window.TWAP("XBTUSD", time.Hour, time.Now()); err == nil {
+		t.Error("averaged over a window longer than prices are kept")
+	}
+}
+
+func TestPriceWindowForgetsOldPrices(t *testing.T) {
+	start := time.Unix(1000, 0)
+	window := NewPriceWindow(time.Minute)
+
+	for i := 0; i <= 10; i++ {
+		window.Add(Price{Symbol: "XBTUSD", Value: float64(i), Timestamp: start.Add(time.Duration(i) * 30 * time.Second)})
+	}
+
+	// the newest price older than the period is kept, as it is in effect
+	// at its start
+	if kept := len(window.prices["XBTUSD"]); kept != 3 {
+		t.Fatalf("kept %d prices, want 3", kept)
+	}
+}